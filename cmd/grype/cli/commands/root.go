@@ -64,6 +64,7 @@ You can also explicitly specify the scheme to use:
     {{.appName}} sbom:path/to/syft.json                 read Syft JSON from path on disk
     {{.appName}} registry:yourrepo/yourimage:tag        pull image directly from a registry (no container runtime required)
     {{.appName}} purl:path/to/purl/file                 read a newline separated file of package URLs from a path on disk
+    {{.appName}} purl:PURL                              read a single package PURL directly (e.g. purl:pkg:npm/lodash@4.17.15)
     {{.appName}} PURL                                   read a single package PURL directly (e.g. pkg:apk/openssl@3.2.1?distro=alpine-3.20.3)
     {{.appName}} CPE                                    read a single CPE directly (e.g. cpe:2.3:a:openssl:openssl:3.0.14:*:*:*:*:*)
 
@@ -272,16 +273,9 @@ func dbInfo(status *vulnerability.ProviderStatus, vp vulnerability.Provider) any
 }
 
 func applyDistroHint(pkgs []pkg.Package, context *pkg.Context, opts *options.Grype) {
-	if opts.Distro != "" {
+	if d := distroFromOption(opts.Distro); d != nil {
 		log.Infof("using distro: %s", opts.Distro)
-
-		split := strings.Split(opts.Distro, ":")
-		d := split[0]
-		v := ""
-		if len(split) > 1 {
-			v = split[1]
-		}
-		context.Distro = distro.NewFromNameVersion(d, v)
+		context.Distro = d
 	}
 
 	hasOSPackageWithoutDistro := false
@@ -300,6 +294,20 @@ func applyDistroHint(pkgs []pkg.Package, context *pkg.Context, opts *options.Gry
 	}
 }
 
+// distroFromOption parses the --distro option (as "<distro>:<version>"), returning nil when not set.
+func distroFromOption(value string) *distro.Distro {
+	if value == "" {
+		return nil
+	}
+	split := strings.Split(value, ":")
+	d := split[0]
+	v := ""
+	if len(split) > 1 {
+		v = split[1]
+	}
+	return distro.NewFromNameVersion(d, v)
+}
+
 func checkForAppUpdate(id clio.Identification, opts *options.Grype) {
 	if !opts.CheckForAppUpdate {
 		return
@@ -363,6 +371,7 @@ func getProviderConfig(opts *options.Grype) pkg.ProviderConfig {
 			Platform:               opts.Platform,
 			Name:                   opts.Name,
 			DefaultImagePullSource: opts.DefaultImagePullSource,
+			Distro:                 distroFromOption(opts.Distro),
 		},
 		SynthesisConfig: pkg.SynthesisConfig{
 			GenerateMissingCPEs: opts.GenerateMissingCPEs,
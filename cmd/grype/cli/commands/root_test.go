@@ -10,6 +10,7 @@ import (
 
 	"github.com/anchore/clio"
 	"github.com/anchore/grype/cmd/grype/cli/options"
+	"github.com/anchore/grype/grype/distro"
 	"github.com/anchore/grype/grype/match"
 	"github.com/anchore/grype/grype/pkg"
 	"github.com/anchore/grype/grype/vex"
@@ -76,12 +77,36 @@ func Test_getProviderConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "the distro option overrides the distro of SBOM packages",
+			opts: func() *options.Grype {
+				opts := options.DefaultGrype(clio.Identification{
+					Name:    "test",
+					Version: "1.0",
+				})
+				opts.Distro = "debian:11"
+				return opts
+			}(),
+			want: pkg.ProviderConfig{
+				SyftProviderConfig: pkg.SyftProviderConfig{
+					SBOMOptions: func() *syft.CreateSBOMConfig {
+						cfg := syft.DefaultCreateSBOMConfig()
+						cfg.Compliance.MissingVersion = cataloging.ComplianceActionDrop
+						return cfg
+					}(),
+					RegistryOptions: &image.RegistryOptions{
+						Credentials: []image.RegistryCredentials{},
+					},
+					Distro: distro.NewFromNameVersion("debian", "11"),
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			opts := cmp.Options{
 				cmpopts.IgnoreFields(binary.Classifier{}, "EvidenceMatcher"),
-				cmpopts.IgnoreUnexported(syft.CreateSBOMConfig{}),
+				cmpopts.IgnoreUnexported(syft.CreateSBOMConfig{}, distro.Distro{}),
 			}
 			if d := cmp.Diff(tt.want, getProviderConfig(tt.opts), opts...); d != "" {
 				t.Errorf("getProviderConfig() mismatch (-want +got):\n%s", d)
@@ -0,0 +1,64 @@
+package grype
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anchore/packageurl-go"
+
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/matcher"
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/vulnerability"
+)
+
+// FindVulnerabilitiesForCoordinate finds vulnerabilities for a single package coordinate without needing an SBOM.
+// The ecosystem is a package URL type (e.g. "maven", "npm", "pypi", "gem", "golang") and the name may include a
+// namespace separated by a "/" (e.g. "org.apache.logging.log4j/log4j-core" for maven). The coordinate is interpreted
+// exactly as a single package URL input would be, so results are the same as scanning a one-package SBOM.
+func FindVulnerabilitiesForCoordinate(provider vulnerability.Provider, ecosystem, name, version string) (match.Matches, error) {
+	purl, err := coordinatePURL(ecosystem, name, version)
+	if err != nil {
+		return match.Matches{}, err
+	}
+
+	packages, context, _, err := pkg.Provide(purl, pkg.ProviderConfig{})
+	if err != nil {
+		return match.Matches{}, fmt.Errorf("unable to create package from coordinate %q: %w", purl, err)
+	}
+
+	exclusionProvider, _ := provider.(match.ExclusionProvider)
+	runner := VulnerabilityMatcher{
+		VulnerabilityProvider: provider,
+		ExclusionProvider:     exclusionProvider,
+		Matchers:              matcher.NewDefaultMatchers(matcher.Config{}),
+	}
+
+	matches, _, err := runner.FindMatches(packages, context)
+	if err != nil {
+		return match.Matches{}, err
+	}
+	if matches == nil {
+		return match.NewMatches(), nil
+	}
+	return *matches, nil
+}
+
+// coordinatePURL builds a package URL from the given coordinate.
+func coordinatePURL(ecosystem, name, version string) (string, error) {
+	switch {
+	case ecosystem == "":
+		return "", fmt.Errorf("no ecosystem provided")
+	case name == "":
+		return "", fmt.Errorf("no package name provided")
+	case version == "":
+		return "", fmt.Errorf("no package version provided")
+	}
+
+	var namespace string
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		namespace, name = name[:idx], name[idx+1:]
+	}
+
+	return packageurl.NewPackageURL(strings.ToLower(ecosystem), namespace, name, version, nil, "").ToString(), nil
+}
@@ -0,0 +1,71 @@
+package grype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/grype/grype/vulnerability/mock"
+)
+
+func TestFindVulnerabilitiesForCoordinate(t *testing.T) {
+	vp := mock.VulnerabilityProvider(testVulnerabilities()...)
+
+	tests := []struct {
+		name      string
+		ecosystem string
+		pkgName   string
+		version   string
+		wantIDs   []string
+		wantErr   require.ErrorAssertionFunc
+	}{
+		{
+			name:      "vulnerable coordinate",
+			ecosystem: "gem",
+			pkgName:   "activerecord",
+			version:   "3.7.5",
+			wantIDs:   []string{"GHSA-2014-fake-3"},
+		},
+		{
+			name:      "fixed coordinate",
+			ecosystem: "gem",
+			pkgName:   "activerecord",
+			version:   "3.7.6",
+		},
+		{
+			name:      "missing version",
+			ecosystem: "gem",
+			pkgName:   "activerecord",
+			wantErr:   require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr == nil {
+				tt.wantErr = require.NoError
+			}
+
+			actual, err := FindVulnerabilitiesForCoordinate(vp, tt.ecosystem, tt.pkgName, tt.version)
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+
+			var ids []string
+			for _, m := range actual.Sorted() {
+				ids = append(ids, m.Vulnerability.ID)
+				assert.Equal(t, tt.pkgName, m.Package.Name)
+				assert.Equal(t, tt.version, m.Package.Version)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func Test_coordinatePURL(t *testing.T) {
+	actual, err := coordinatePURL("maven", "org.apache.logging.log4j/log4j-core", "2.14.1")
+	require.NoError(t, err)
+	assert.Equal(t, "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1", actual)
+}
@@ -0,0 +1,268 @@
+package grype
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/scylladb/go-set/strset"
+
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/search"
+	"github.com/anchore/grype/grype/vulnerability"
+	"github.com/anchore/stereoscope/pkg/image"
+	syftPkg "github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/source"
+)
+
+// SkipReason describes why a package could not be evaluated against the vulnerability provider.
+type SkipReason string
+
+const (
+	// SkipReasonUnknownVersion indicates the package version is unknown, so no version constraints can be evaluated.
+	SkipReasonUnknownVersion SkipReason = "unknown-version"
+	// SkipReasonNoDistro indicates an OS package was found without a distro, so no distro namespace could be resolved.
+	SkipReasonNoDistro SkipReason = "no-distro"
+	// SkipReasonNoApplicableNamespace indicates no matcher was able to search for the package (e.g. a niche
+	// ecosystem without a namespace mapping and without any CPEs to search by).
+	SkipReasonNoApplicableNamespace SkipReason = "no-applicable-namespace"
+)
+
+// SkippedPackage is a package that was not looked up against the vulnerability provider at all. This is distinct
+// from a package that was looked up and had no vulnerabilities.
+type SkippedPackage struct {
+	Package pkg.Package
+	Reason  SkipReason
+}
+
+// NamespaceKind describes how a namespace was selected for a package.
+type NamespaceKind string
+
+const (
+	// NamespaceKindLanguage indicates a language ecosystem namespace, selected by the language of the package.
+	NamespaceKindLanguage NamespaceKind = "language"
+	// NamespaceKindDistro indicates a distro namespace, selected by the distro of the package (or of the scan target).
+	NamespaceKindDistro NamespaceKind = "distro"
+	// NamespaceKindCPE indicates the CPE namespace, selected by the CPEs of the package.
+	NamespaceKindCPE NamespaceKind = "cpe"
+)
+
+// SearchedNamespace is a namespace that was searched for a package.
+type SearchedNamespace struct {
+	Kind NamespaceKind
+	// Namespace identifies the namespace independent of the vulnerability provider, which is the same as the suffix
+	// of the namespaces of the provider (e.g. "language:python" for "github:language:python")
+	Namespace string
+	// Reason describes why the namespace was selected for the package
+	Reason string
+	// Found are the (sorted) namespaces of the vulnerabilities returned by the searches of the namespace
+	Found []string
+}
+
+// PackageNamespaces are the namespaces searched for a package, in the order they were first searched.
+type PackageNamespaces struct {
+	Package    pkg.Package
+	Namespaces []SearchedNamespace
+}
+
+// Coverage summarizes how many packages were evaluated against the vulnerability provider.
+type Coverage struct {
+	// Total is the number of packages considered for matching
+	Total int
+	// Skipped are the packages that could not be evaluated
+	Skipped []SkippedPackage
+	// Namespaces are the namespaces searched for each package that was searched by namespace, regardless of whether
+	// any matches were found (in the order the packages were searched)
+	Namespaces []PackageNamespaces
+}
+
+// Evaluated returns the number of packages that were looked up against the vulnerability provider.
+func (c Coverage) Evaluated() int {
+	return c.Total - len(c.Skipped)
+}
+
+// PackageStatus describes the outcome of matching a single package.
+type PackageStatus string
+
+const (
+	// PackageStatusMatched indicates the package has at least one reported match.
+	PackageStatusMatched PackageStatus = "matched"
+	// PackageStatusClean indicates the package was evaluated against the vulnerability provider and has no reported
+	// matches.
+	PackageStatusClean PackageStatus = "evaluated-clean"
+	// PackageStatusSkipped indicates the package could not be evaluated (e.g. there is no applicable namespace), so the
+	// absence of matches says nothing about the package.
+	PackageStatusSkipped PackageStatus = "skipped"
+)
+
+// PackageResult is the outcome of matching a single package.
+type PackageResult struct {
+	Package pkg.Package
+	Status  PackageStatus
+	// SkipReason is why the package could not be evaluated (only set when the status is PackageStatusSkipped)
+	SkipReason SkipReason
+}
+
+// PackageResults returns the status of each of the given packages (the same packages, in the same order, given when
+// the coverage was found) relative to the given reported matches. A skipped package with a reported match (e.g. found
+// by CPE) is considered matched. Packages that were not considered for matching (e.g. after stopping early on the fail
+// severity) are not included.
+func (c Coverage) PackageResults(packages []pkg.Package, matches match.Matches) []PackageResult {
+	skipped := make(map[pkg.ID]SkipReason, len(c.Skipped))
+	for _, s := range c.Skipped {
+		skipped[s.Package.ID] = s.Reason
+	}
+
+	if c.Total < len(packages) {
+		packages = packages[:c.Total]
+	}
+
+	results := make([]PackageResult, 0, len(packages))
+	for _, p := range packages {
+		result := PackageResult{Package: p, Status: PackageStatusClean}
+		if len(matches.GetByPkgID(p.ID)) > 0 {
+			result.Status = PackageStatusMatched
+		} else if reason, ok := skipped[p.ID]; ok {
+			result.Status = PackageStatusSkipped
+			result.SkipReason = reason
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// FindVulnerabilitiesWithPackageResults is the same as FindVulnerabilities, but additionally returns the status of
+// each package: matched, evaluated against the vulnerability provider without any matches, or skipped (along with the
+// reason). This distinguishes a package that is clean from one that could not be evaluated at all.
+func FindVulnerabilitiesWithPackageResults(store vulnerability.Provider, userImageStr string, scopeOpt source.Scope, registryOptions *image.RegistryOptions) (match.Matches, pkg.Context, []pkg.Package, []PackageResult, error) {
+	packages, context, _, err := pkg.Provide(userImageStr, defaultProviderConfig(scopeOpt, registryOptions))
+	if err != nil {
+		return match.Matches{}, pkg.Context{}, nil, nil, err
+	}
+
+	runner := newDefaultVulnerabilityMatcher(store)
+	matches, _, coverage, err := runner.FindMatchesWithCoverage(packages, context)
+	if err != nil {
+		return match.Matches{}, context, packages, nil, fmt.Errorf("unable to find vulnerabilities: %w", err)
+	}
+	return *matches, context, packages, coverage.PackageResults(packages, *matches), nil
+}
+
+// searchTrackingProvider records if any vulnerability searches were made against the underlying provider, along with
+// the namespaces selected by each search.
+type searchTrackingProvider struct {
+	vulnerability.Provider
+	searched bool
+	// distroReason describes where the distro of the package came from
+	distroReason string
+	namespaces   []SearchedNamespace
+}
+
+func (p *searchTrackingProvider) FindVulnerabilities(criteria ...vulnerability.Criteria) ([]vulnerability.Vulnerability, error) {
+	p.searched = true
+	results, err := p.Provider.FindVulnerabilities(criteria...)
+	if err == nil {
+		p.recordNamespaces(criteria, results)
+	}
+	return results, err
+}
+
+func (p *searchTrackingProvider) recordNamespaces(criteria []vulnerability.Criteria, results []vulnerability.Vulnerability) {
+	for _, row := range search.CriteriaIterator(criteria) {
+		for _, c := range row {
+			for _, ns := range p.namespacesFor(c) {
+				p.addNamespace(ns, c, results)
+			}
+		}
+	}
+}
+
+// namespacesFor returns the namespaces selected by the given criteria (if any)
+func (p *searchTrackingProvider) namespacesFor(c vulnerability.Criteria) []SearchedNamespace {
+	switch c := c.(type) {
+	case *search.EcosystemCriteria:
+		if c.Language == syftPkg.UnknownLanguage {
+			return []SearchedNamespace{{
+				Kind:      NamespaceKindLanguage,
+				Namespace: "language:" + string(syftPkg.UnknownLanguage),
+				Reason:    fmt.Sprintf("the package (type %q) has no known language", c.PackageType),
+			}}
+		}
+		return []SearchedNamespace{{
+			Kind:      NamespaceKindLanguage,
+			Namespace: "language:" + strings.ToLower(string(c.Language)),
+			Reason:    fmt.Sprintf("the language of the package is %q", c.Language),
+		}}
+	case *search.DistroCriteria:
+		var out []SearchedNamespace
+		for _, d := range c.Distros {
+			out = append(out, SearchedNamespace{
+				Kind:      NamespaceKindDistro,
+				Namespace: fmt.Sprintf("distro:%s:%s", d.Type, d.Version),
+				Reason:    fmt.Sprintf("%s is %s", p.distroReason, d.String()),
+			})
+		}
+		return out
+	case *search.CPECriteria:
+		return []SearchedNamespace{{
+			Kind:      NamespaceKindCPE,
+			Namespace: "cpe",
+			Reason:    "the package has CPEs",
+		}}
+	}
+	return nil
+}
+
+// addNamespace records the namespace (if not already recorded) along with the namespaces of the results satisfying
+// the criteria that selected the namespace
+func (p *searchTrackingProvider) addNamespace(ns SearchedNamespace, c vulnerability.Criteria, results []vulnerability.Vulnerability) {
+	idx := slices.IndexFunc(p.namespaces, func(existing SearchedNamespace) bool {
+		return existing.Kind == ns.Kind && existing.Namespace == ns.Namespace
+	})
+	if idx < 0 {
+		p.namespaces = append(p.namespaces, ns)
+		idx = len(p.namespaces) - 1
+	}
+
+	found := strset.New(p.namespaces[idx].Found...)
+	for _, v := range results {
+		if matches, _, err := c.MatchesVulnerability(v); err == nil && matches {
+			found.Add(v.Namespace)
+		}
+	}
+	if found.Size() > 0 {
+		p.namespaces[idx].Found = found.List()
+		sort.Strings(p.namespaces[idx].Found)
+	}
+}
+
+// osPackageTypes are the package types that are only matchable relative to a distro namespace.
+var osPackageTypes = map[syftPkg.Type]struct{}{
+	syftPkg.ApkPkg:     {},
+	syftPkg.DebPkg:     {},
+	syftPkg.RpmPkg:     {},
+	syftPkg.PortagePkg: {},
+	syftPkg.AlpmPkg:    {},
+}
+
+// skipReasonFor determines if (and why) a package could not be meaningfully evaluated against the vulnerability
+// provider. Note that a matcher may still issue a search for a package that cannot be evaluated (e.g. searching by
+// ecosystem for a package without a known language), so having been searched alone is not sufficient.
+func skipReasonFor(p pkg.Package, searched bool) (SkipReason, bool) {
+	if p.Version == "" || strings.EqualFold(p.Version, "unknown") {
+		return SkipReasonUnknownVersion, true
+	}
+	if _, ok := osPackageTypes[p.Type]; ok {
+		if p.Distro == nil {
+			return SkipReasonNoDistro, true
+		}
+	} else if p.Language == syftPkg.UnknownLanguage && len(p.CPEs) == 0 {
+		return SkipReasonNoApplicableNamespace, true
+	}
+	if !searched {
+		return SkipReasonNoApplicableNamespace, true
+	}
+	return "", false
+}
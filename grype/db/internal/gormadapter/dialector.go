@@ -0,0 +1,28 @@
+package gormadapter
+
+import (
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// dialector is the sqlite dialector, but with support for batch inserting rows with fields declared as `default:null`.
+type dialector struct {
+	sqlite.Dialector
+}
+
+func newDialector(dsn string) dialector {
+	return dialector{Dialector: sqlite.Dialector{DSN: dsn}}
+}
+
+// DefaultValueOf returns the value used for a zero-valued field with a DB default when inserting multiple rows in a
+// single statement. The sqlite dialector always uses DEFAULT, which sqlite does not support within VALUES (failing the
+// entire insert), so fields with a null default are explicitly inserted as NULL instead.
+func (d dialector) DefaultValueOf(field *schema.Field) clause.Expression {
+	if strings.EqualFold(strings.TrimSpace(field.DefaultValue), "null") {
+		return clause.Expr{SQL: "NULL"}
+	}
+	return d.Dialector.DefaultValueOf(field)
+}
@@ -1,18 +1,25 @@
 package gormadapter
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
 
 	"github.com/anchore/grype/internal/log"
 )
 
+// primary sqlite result codes (see https://www.sqlite.org/rescode.html)
+const (
+	sqliteBusy   = 5 // SQLITE_BUSY
+	sqliteLocked = 6 // SQLITE_LOCKED
+)
+
 var commonStatements = []string{
 	`PRAGMA foreign_keys = ON`, // needed for v6+
 }
@@ -23,6 +30,10 @@ var writerStatements = []string{
 	`PRAGMA journal_mode = MEMORY`, // do not write the journal to disk (maximizing write performance); OFF is faster but less safe in terms of DB consistency
 }
 
+var walStatements = []string{
+	`PRAGMA journal_mode = WAL`, // readers do not block the writer (and vice versa), allowing concurrent read transactions while writing
+}
+
 var heavyWriteStatements = []string{
 	`PRAGMA cache_size = -1073741824`, // ~1 GB (negative means treat as bytes not page count); one caveat is to not pick a value that risks swapping behavior, negating performance gains
 	`PRAGMA mmap_size = 1073741824`,   // ~1 GB; the maximum size of the memory-mapped I/O buffer (to access the database file as if it were a part of the process’s virtual memory)
@@ -44,9 +55,31 @@ type config struct {
 	models                    []any
 	initialData               []any
 	memory                    bool
+	wal                       bool
+	openTimeout               time.Duration
 	statements                []string
 }
 
+// openRetryInterval is the delay between attempts to open a locked DB (see WithOpenTimeout).
+var openRetryInterval = 100 * time.Millisecond
+
+// ErrOpenTimeout is returned by Open when the DB remains locked (e.g. by another process writing to the DB) for longer
+// than the timeout given with WithOpenTimeout.
+type ErrOpenTimeout struct {
+	Path    string
+	Timeout time.Duration
+	// Err is the error from the last attempt to open the DB
+	Err error
+}
+
+func (e *ErrOpenTimeout) Error() string {
+	return fmt.Sprintf("timed out after %s opening DB %q: %v", e.Timeout, e.Path, e.Err)
+}
+
+func (e *ErrOpenTimeout) Unwrap() error {
+	return e.Err
+}
+
 type Option func(*config)
 
 func WithDebug(debug bool) Option {
@@ -73,6 +106,25 @@ func WithStatements(statements ...string) Option {
 	}
 }
 
+// WithWAL uses the write-ahead log journal mode (instead of an in-memory rollback journal) for a writable DB, allowing
+// concurrent read transactions while writing. Note that the journal mode is persisted in the DB file, so callers are
+// expected to reset the journal mode before shipping the DB.
+func WithWAL(enabled bool) Option {
+	return func(c *config) {
+		c.wal = enabled
+	}
+}
+
+// WithOpenTimeout bounds how long Open waits for a DB file that is locked by another connection: each statement waits
+// up to the timeout for the lock to be released (the sqlite busy_timeout), and opening is retried while the DB remains
+// locked until the timeout expires, at which point an *ErrOpenTimeout is returned. A zero timeout uses the default
+// busy_timeout of the driver without retrying.
+func WithOpenTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.openTimeout = timeout
+	}
+}
+
 func WithModels(models []any) Option {
 	return func(c *config) {
 		c.models = append(c.models, models...)
@@ -112,6 +164,10 @@ func (c config) connectionString() string {
 		conn = ":memory:"
 	} else {
 		conn = fmt.Sprintf("file:%s?cache=shared", c.path)
+		if c.wal && c.writable {
+			// a shared cache uses table-level locking between connections, which would block readers during writes
+			conn = fmt.Sprintf("file:%s", c.path)
+		}
 	}
 
 	if !c.writable && !c.memory {
@@ -122,6 +178,15 @@ func (c config) connectionString() string {
 			conn += fmt.Sprintf("&%s", o)
 		}
 	}
+
+	if c.openTimeout > 0 && !c.memory {
+		if strings.Contains(conn, "?") {
+			conn += "&"
+		} else {
+			conn += "?"
+		}
+		conn += fmt.Sprintf("_pragma=busy_timeout(%d)", max(c.openTimeout.Milliseconds(), 1))
+	}
 	return conn
 }
 
@@ -133,21 +198,110 @@ func Open(path string, options ...Option) (*gorm.DB, error) {
 		return nil, fmt.Errorf("cannot truncate a read-only DB")
 	}
 
+	if cfg.wal && (!cfg.writable || cfg.memory) {
+		return nil, fmt.Errorf("WAL journal mode requires a writable DB file")
+	}
+
 	if cfg.truncate {
 		if err := deleteDB(path); err != nil {
 			return nil, err
 		}
 	}
 
-	dbObj, err := gorm.Open(sqlite.Open(cfg.connectionString()), &gorm.Config{Logger: &logAdapter{
-		debug:         cfg.debug,
+	if cfg.openTimeout <= 0 {
+		return cfg.open()
+	}
+	return cfg.openWithRetry()
+}
+
+// openWithRetry opens the DB, retrying while the DB is locked until the open timeout expires.
+func (c config) openWithRetry() (*gorm.DB, error) {
+	deadline := time.Now().Add(c.openTimeout)
+	for {
+		dbObj, err := c.openBefore(deadline)
+		if err == nil || !isLocked(err) {
+			return dbObj, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, &ErrOpenTimeout{Path: c.path, Timeout: c.openTimeout, Err: err}
+		}
+		log.WithFields("path", c.path, "error", err).Debug("DB is locked, retrying")
+		time.Sleep(min(openRetryInterval, remaining))
+	}
+}
+
+// openBefore opens the DB, giving up when the attempt has not completed by the deadline. This is needed since waiting
+// on a lock held by a connection sharing the same cache is not bounded by the busy timeout. An abandoned attempt closes
+// the DB once it completes.
+func (c config) openBefore(deadline time.Time) (*gorm.DB, error) {
+	type result struct {
+		db  *gorm.DB
+		err error
+	}
+	results := make(chan result)
+	abandoned := make(chan struct{})
+
+	go func() {
+		dbObj, err := c.open()
+		select {
+		case results <- result{db: dbObj, err: err}:
+		case <-abandoned:
+			if err == nil {
+				closeDB(dbObj)
+			}
+		}
+	}()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	select {
+	case r := <-results:
+		return r.db, r.err
+	case <-timer.C:
+		close(abandoned)
+		return nil, &ErrOpenTimeout{Path: c.path, Timeout: c.openTimeout, Err: context.DeadlineExceeded}
+	}
+}
+
+func (c config) open() (*gorm.DB, error) {
+	dbObj, err := gorm.Open(newDialector(c.connectionString()), &gorm.Config{Logger: &logAdapter{
+		debug:         c.debug,
 		slowThreshold: 400 * time.Millisecond,
 	}})
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to DB: %w", err)
 	}
 
-	return cfg.prepareDB(dbObj)
+	prepared, err := c.prepareDB(dbObj)
+	if err != nil {
+		// don't leave the connection open (and possibly holding a lock) when the DB is not usable
+		closeDB(dbObj)
+		return nil, err
+	}
+	return prepared, nil
+}
+
+func closeDB(dbObj *gorm.DB) {
+	if sqlDB, err := dbObj.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
+}
+
+// isLocked indicates if the given error is due to the DB being locked by another connection.
+func isLocked(err error) bool {
+	var sqliteErr interface{ Code() int }
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	// the primary result code is the least significant byte of an extended result code
+	switch sqliteErr.Code() & 0xff {
+	case sqliteBusy, sqliteLocked:
+		return true
+	}
+	return false
 }
 
 func (c config) prepareDB(dbObj *gorm.DB) (*gorm.DB, error) {
@@ -158,6 +312,13 @@ func (c config) prepareDB(dbObj *gorm.DB) (*gorm.DB, error) {
 		}
 	}
 
+	if c.wal {
+		log.WithFields("path", c.path).Debug("using WAL journal mode")
+		if err := c.applyStatements(dbObj, walStatements); err != nil {
+			return nil, fmt.Errorf("unable to apply DB WAL statements: %w", err)
+		}
+	}
+
 	if c.truncate && c.allowLargeMemoryFootprint {
 		log.WithFields("path", c.path).Debug("using large memory footprint DB statements")
 		if err := c.applyStatements(dbObj, heavyWriteStatements); err != nil {
@@ -1,9 +1,12 @@
 package gormadapter
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -55,6 +58,8 @@ func TestConfigConnectionString(t *testing.T) {
 		path            string
 		write           bool
 		memory          bool
+		wal             bool
+		openTimeout     time.Duration
 		expectedConnStr string
 	}{
 		{
@@ -69,6 +74,13 @@ func TestConfigConnectionString(t *testing.T) {
 			write:           false,
 			expectedConnStr: "file:test.db?cache=shared&immutable=1&mode=ro&cache=shared",
 		},
+		{
+			name:            "writable path with WAL",
+			path:            "test.db",
+			write:           true,
+			wal:             true,
+			expectedConnStr: "file:test.db",
+		},
 		{
 			name:            "in-memory mode",
 			path:            "",
@@ -76,14 +88,46 @@ func TestConfigConnectionString(t *testing.T) {
 			memory:          true,
 			expectedConnStr: ":memory:",
 		},
+		{
+			name:            "writable path with open timeout",
+			path:            "test.db",
+			write:           true,
+			openTimeout:     2 * time.Second,
+			expectedConnStr: "file:test.db?cache=shared&_pragma=busy_timeout(2000)",
+		},
+		{
+			name:            "writable path with WAL and open timeout",
+			path:            "test.db",
+			write:           true,
+			wal:             true,
+			openTimeout:     2 * time.Second,
+			expectedConnStr: "file:test.db?_pragma=busy_timeout(2000)",
+		},
+		{
+			name:            "read-only path with open timeout",
+			path:            "test.db",
+			write:           false,
+			openTimeout:     time.Millisecond,
+			expectedConnStr: "file:test.db?cache=shared&immutable=1&mode=ro&cache=shared&_pragma=busy_timeout(1)",
+		},
+		{
+			name:            "in-memory mode with open timeout",
+			path:            "",
+			write:           false,
+			memory:          true,
+			openTimeout:     time.Second,
+			expectedConnStr: ":memory:",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := config{
-				path:     tt.path,
-				writable: tt.write,
-				memory:   tt.memory,
+				path:        tt.path,
+				writable:    tt.write,
+				memory:      tt.memory,
+				wal:         tt.wal,
+				openTimeout: tt.openTimeout,
 			}
 			require.Equal(t, tt.expectedConnStr, c.connectionString())
 		})
@@ -128,6 +172,49 @@ func TestPrepareWritableDB(t *testing.T) {
 	})
 }
 
+func TestOpen_OpenTimeout(t *testing.T) {
+	type record struct {
+		ID int
+	}
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	writer, err := Open(dbPath, WithTruncate(true, []any{&record{}}, nil))
+	require.NoError(t, err)
+	sqlDB, err := writer.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	// hold a write lock, as another process would mid-write
+	lock, err := sqlDB.Conn(context.Background())
+	require.NoError(t, err)
+	_, err = lock.ExecContext(context.Background(), "BEGIN EXCLUSIVE")
+	require.NoError(t, err)
+
+	t.Run("times out while locked", func(t *testing.T) {
+		start := time.Now()
+		_, err := Open(dbPath, WithWritable(true, []any{&record{}}), WithOpenTimeout(300*time.Millisecond))
+		elapsed := time.Since(start)
+
+		var timeoutErr *ErrOpenTimeout
+		require.True(t, errors.As(err, &timeoutErr), "expected a timeout error, got: %v", err)
+		require.Equal(t, dbPath, timeoutErr.Path)
+		require.Equal(t, 300*time.Millisecond, timeoutErr.Timeout)
+		require.GreaterOrEqual(t, elapsed, 300*time.Millisecond)
+		require.Less(t, elapsed, 5*time.Second)
+	})
+
+	t.Run("opens once the lock is released", func(t *testing.T) {
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			_, _ = lock.ExecContext(context.Background(), "COMMIT")
+		}()
+
+		db, err := Open(dbPath, WithWritable(true, []any{&record{}}), WithOpenTimeout(5*time.Second))
+		require.NoError(t, err)
+		require.NoError(t, db.Exec("INSERT INTO records (id) VALUES (1)").Error)
+	})
+}
+
 func TestPragmaNameValue(t *testing.T) {
 	tests := []struct {
 		name      string
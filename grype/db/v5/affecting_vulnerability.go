@@ -0,0 +1,9 @@
+package v5
+
+// AffectingVulnerability is a vulnerability record that applies to a specific version of a package, along with the
+// fix information relative to that version.
+type AffectingVulnerability struct {
+	Vulnerability `json:"vulnerability"`
+	Fixed         bool   `json:"fixed"`                // Whether a fix is available for the affected version
+	FixVersion    string `json:"fixVersion,omitempty"` // The lowest fixed version above the affected version (if known)
+}
@@ -0,0 +1,10 @@
+package v5
+
+// ConstraintOverlap describes two vulnerability records for the same package whose version constraints share at
+// least one version, which may indicate duplicate advisories or records that should be merged.
+type ConstraintOverlap struct {
+	Namespace   string    `json:"namespace"`   // The namespace of both vulnerability records
+	PackageName string    `json:"packageName"` // The package both vulnerability records apply to
+	IDs         [2]string `json:"ids"`         // The identifiers of the overlapping vulnerability records
+	Overlap     string    `json:"overlap"`     // The version range shared by both records (e.g. ">= 1.2, < 1.4")
+}
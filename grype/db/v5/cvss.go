@@ -17,6 +17,8 @@ func NewCvss(m []Cvss) []vulnerability.Cvss {
 				BaseScore:           score.Metrics.BaseScore,
 				ExploitabilityScore: score.Metrics.ExploitabilityScore,
 				ImpactScore:         score.Metrics.ImpactScore,
+				TemporalScore:       score.Metrics.TemporalScore,
+				EnvironmentalScore:  score.Metrics.EnvironmentalScore,
 			},
 			VendorMetadata: score.VendorMetadata,
 		})
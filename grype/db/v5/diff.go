@@ -1,5 +1,7 @@
 package v5
 
+import "github.com/wagoodman/go-progress"
+
 type DiffReason = string
 
 const (
@@ -8,9 +10,110 @@ const (
 	DiffRemoved DiffReason = "removed"
 )
 
+// DiffChange classifies what changed within a record that differs between stores (see DiffChanged).
+type DiffChange string
+
+const (
+	// DiffSeverityChanged indicates the metadata severity changed
+	DiffSeverityChanged DiffChange = "severity-changed"
+	// DiffCvssChanged indicates the metadata CVSS scores or vectors changed
+	DiffCvssChanged DiffChange = "cvss-changed"
+	// DiffDescriptionChanged indicates the metadata description changed
+	DiffDescriptionChanged DiffChange = "description-changed"
+	// DiffURLsChanged indicates the metadata URLs changed
+	DiffURLsChanged DiffChange = "urls-changed"
+	// DiffPackageAdded indicates the vulnerability affects a package that it did not previously affect
+	DiffPackageAdded DiffChange = "package-added"
+	// DiffPackageRemoved indicates the vulnerability no longer affects a package that it previously affected
+	DiffPackageRemoved DiffChange = "package-removed"
+)
+
 type Diff struct {
 	Reason    DiffReason `json:"reason"`
 	ID        string     `json:"id"`
 	Namespace string     `json:"namespace"`
 	Packages  []string   `json:"packages"`
+	// Changes classifies what changed for records with the DiffChanged reason (sorted). This may be empty when only
+	// other aspects of the records changed (e.g. a version constraint or fix).
+	Changes []DiffChange `json:"changes,omitempty"`
+}
+
+// DiffField is a cosmetic aspect of a record that can be ignored when comparing stores.
+type DiffField string
+
+const (
+	// DiffFieldURLOrder ignores the order of metadata URLs (but not the URLs themselves)
+	DiffFieldURLOrder DiffField = "url-order"
+	// DiffFieldURLs ignores metadata URLs entirely
+	DiffFieldURLs DiffField = "urls"
+	// DiffFieldDescriptionWhitespace ignores leading, trailing, and repeated whitespace within metadata descriptions
+	DiffFieldDescriptionWhitespace DiffField = "description-whitespace"
+	// DiffFieldDescription ignores metadata descriptions entirely
+	DiffFieldDescription DiffField = "description"
+	// DiffFieldDataSource ignores the metadata data source URL
+	DiffFieldDataSource DiffField = "data-source"
+)
+
+// DiffOptions controls how records are compared when diffing stores. The zero value performs a full comparison.
+type DiffOptions struct {
+	// IgnoreFields are the fields that should not be considered when determining if a record has changed
+	IgnoreFields []DiffField
+	// Namespaces restricts the comparison to records within the given namespaces (all namespaces when empty)
+	Namespaces []string
+	// Progress is called with the progress of the diff when the diff starts. When not set the progress is published on
+	// the grype event bus (for the UI), use NoDiffProgress to diff without reporting progress (e.g. when headless).
+	Progress DiffProgress
+}
+
+// DiffProgress receives the progress of a diff: the current stage, the number of rows compared (out of the total rows
+// within both stores), and the number of differences discovered.
+type DiffProgress func(stage progress.Stager, rows progress.Progressable, differences progress.Monitorable)
+
+// NoDiffProgress is a DiffProgress which ignores the progress of a diff.
+func NoDiffProgress(progress.Stager, progress.Progressable, progress.Monitorable) {}
+
+// Includes indicates if records within the given namespace should be compared.
+func (o DiffOptions) Includes(namespace string) bool {
+	if len(o.Namespaces) == 0 {
+		return true
+	}
+	for _, n := range o.Namespaces {
+		if n == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// Ignores indicates if the given field should not be considered when comparing records.
+func (o DiffOptions) Ignores(field DiffField) bool {
+	for _, f := range o.IgnoreFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// CoverageKey identifies a vulnerability record for a package within a namespace.
+type CoverageKey struct {
+	Namespace       string `json:"namespace"`
+	PackageName     string `json:"package_name"`
+	VulnerabilityID string `json:"vulnerability_id"`
+}
+
+// NamespaceCoverage summarizes the coverage differences within a single namespace.
+type NamespaceCoverage struct {
+	Namespace string `json:"namespace"`
+	Missing   int    `json:"missing"` // the number of keys in the reference store but not in the local store
+	Extra     int    `json:"extra"`   // the number of keys in the local store but not in the reference store
+	Shared    int    `json:"shared"`  // the number of keys in both stores
+}
+
+// CoverageReport describes which vulnerability records for packages exist in one store but not another. Unlike a Diff,
+// no record fields are compared, only the presence of the (namespace, package, vulnerability) keys.
+type CoverageReport struct {
+	Missing    []CoverageKey       `json:"missing"`    // keys in the reference store but not in the local store (sorted)
+	Extra      []CoverageKey       `json:"extra"`      // keys in the local store but not in the reference store (sorted)
+	Namespaces []NamespaceCoverage `json:"namespaces"` // ordered by namespace
 }
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/url"
 	"path"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/olekukonko/tablewriter/renderer"
@@ -125,6 +126,11 @@ func download(curator *legacyDistribution.Curator, listing *legacyDistribution.L
 }
 
 func (d *Differ) DiffDatabases() (*[]v5.Diff, error) {
+	return d.DiffDatabasesWithOptions(v5.DiffOptions{})
+}
+
+// DiffDatabasesWithOptions diffs the base and target databases, ignoring any record fields described by the options.
+func (d *Differ) DiffDatabasesWithOptions(opts v5.DiffOptions) (*[]v5.Diff, error) {
 	baseStore, err := d.baseCurator.GetStore()
 	if err != nil {
 		return nil, err
@@ -139,7 +145,7 @@ func (d *Differ) DiffDatabases() (*[]v5.Diff, error) {
 
 	defer log.CloseAndLogError(targetStore, d.targetCurator.Status().Location)
 
-	return baseStore.DiffStore(targetStore)
+	return baseStore.DiffStoreWithOptions(targetStore, opts)
 }
 
 func (d *Differ) DeleteDatabases() error {
@@ -161,10 +167,14 @@ func (d *Differ) Present(outputFormat string, diff *[]v5.Diff, output io.Writer)
 	case "table":
 		rows := [][]string{}
 		for _, d := range *diff {
-			rows = append(rows, []string{d.ID, d.Namespace, d.Reason})
+			changes := make([]string, len(d.Changes))
+			for idx, c := range d.Changes {
+				changes[idx] = string(c)
+			}
+			rows = append(rows, []string{d.ID, d.Namespace, d.Reason, strings.Join(changes, ", ")})
 		}
 
-		table := newTable(output, []string{"ID", "Namespace", "Reason"})
+		table := newTable(output, []string{"ID", "Namespace", "Reason", "Changes"})
 
 		if err := table.Bulk(rows); err != nil {
 			return fmt.Errorf("failed to add table rows: %+v", err)
@@ -50,9 +50,9 @@ func Test_DifferDirectory(t *testing.T) {
 func TestPresent_Json(t *testing.T) {
 	//GIVEN
 	diffs := []v5.Diff{
-		{v5.DiffAdded, "CVE-1", "nvd", []string{"requests", "vault"}},
-		{v5.DiffRemoved, "CVE-2", "nvd", []string{"k8s"}},
-		{v5.DiffChanged, "CVE-3", "nvd", []string{}},
+		{Reason: v5.DiffAdded, ID: "CVE-1", Namespace: "nvd", Packages: []string{"requests", "vault"}},
+		{Reason: v5.DiffRemoved, ID: "CVE-2", Namespace: "nvd", Packages: []string{"k8s"}},
+		{Reason: v5.DiffChanged, ID: "CVE-3", Namespace: "nvd", Packages: []string{}, Changes: []v5.DiffChange{v5.DiffCvssChanged, v5.DiffSeverityChanged}},
 	}
 	differ := Differ{}
 	var buffer bytes.Buffer
@@ -76,9 +76,9 @@ func TestPresent_Json(t *testing.T) {
 func TestPresent_Table(t *testing.T) {
 	//GIVEN
 	diffs := []v5.Diff{
-		{v5.DiffAdded, "CVE-1", "nvd", []string{"requests", "vault"}},
-		{v5.DiffRemoved, "CVE-2", "nvd", []string{"k8s"}},
-		{v5.DiffChanged, "CVE-3", "nvd", []string{}},
+		{Reason: v5.DiffAdded, ID: "CVE-1", Namespace: "nvd", Packages: []string{"requests", "vault"}},
+		{Reason: v5.DiffRemoved, ID: "CVE-2", Namespace: "nvd", Packages: []string{"k8s"}},
+		{Reason: v5.DiffChanged, ID: "CVE-3", Namespace: "nvd", Packages: []string{}, Changes: []v5.DiffChange{v5.DiffCvssChanged, v5.DiffSeverityChanged}},
 	}
 	differ := Differ{}
 	var buffer bytes.Buffer
@@ -102,7 +102,7 @@ func TestPresent_Table(t *testing.T) {
 func TestPresent_Invalid(t *testing.T) {
 	//GIVEN
 	diffs := []v5.Diff{
-		{v5.DiffRemoved, "CVE-2", "nvd", []string{"k8s"}},
+		{Reason: v5.DiffRemoved, ID: "CVE-2", Namespace: "nvd", Packages: []string{"k8s"}},
 	}
 	differ := Differ{}
 	var buffer bytes.Buffer
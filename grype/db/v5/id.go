@@ -1,19 +1,44 @@
 package v5
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
 	"time"
 )
 
+const (
+	// IDFileName is the conventional name for an ID sidecar file written next to a DB file.
+	IDFileName = "id.json"
+
+	// IDFileVersion is the version of the ID sidecar file JSON schema. This must be incremented on any breaking
+	// change to IDFile.
+	IDFileVersion = 1
+)
+
 // ID represents identifying information for a DB and the data it contains.
 type ID struct {
 	// BuildTimestamp is the timestamp used to define the age of the DB, ideally including the age of the data
 	// contained in the DB, not just when the DB file was created.
 	BuildTimestamp time.Time `json:"build_timestamp"`
 	SchemaVersion  int       `json:"schema_version"`
+	// Checksum is the SHA256 fingerprint of the records within the DB (see DBManifest.Fingerprint) when recorded by the
+	// DB builder, allowing for the DB contents to be verified.
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// IDFile is the stable JSON representation of an ID, suitable for reading DB identifying information without
+// opening the DB itself.
+type IDFile struct {
+	Version        int    `json:"version"`         // the version of this JSON schema (see IDFileVersion)
+	BuildTimestamp string `json:"build_timestamp"` // RFC 3339
+	SchemaVersion  int    `json:"schema_version"`
 }
 
 type IDReader interface {
 	GetID() (*ID, error)
+	// WriteIDFile writes the DB ID as JSON to the given path (see IDFile)
+	WriteIDFile(path string) error
 }
 
 type IDWriter interface {
@@ -26,3 +51,60 @@ func NewID(age time.Time) ID {
 		SchemaVersion:  SchemaVersion,
 	}
 }
+
+// MarshalIDFile encodes the ID into the IDFile JSON representation.
+func (id ID) MarshalIDFile() ([]byte, error) {
+	contents, err := json.MarshalIndent(IDFile{
+		Version:        IDFileVersion,
+		BuildTimestamp: id.BuildTimestamp.UTC().Format(time.RFC3339Nano),
+		SchemaVersion:  id.SchemaVersion,
+	}, "", " ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode DB ID: %w", err)
+	}
+	return contents, nil
+}
+
+// UnmarshalIDFile decodes an ID from the IDFile JSON representation.
+func UnmarshalIDFile(contents []byte) (*ID, error) {
+	var f IDFile
+	if err := json.Unmarshal(contents, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode DB ID: %w", err)
+	}
+
+	if f.Version > IDFileVersion {
+		return nil, fmt.Errorf("unsupported DB ID file version: %d (max supported is %d)", f.Version, IDFileVersion)
+	}
+
+	buildTime, err := time.Parse(time.RFC3339Nano, f.BuildTimestamp)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse build timestamp (%s): %w", f.BuildTimestamp, err)
+	}
+
+	return &ID{
+		BuildTimestamp: buildTime.UTC(),
+		SchemaVersion:  f.SchemaVersion,
+	}, nil
+}
+
+// ReadIDFile reads an ID from a JSON file written with WriteIDFile.
+func ReadIDFile(path string) (*ID, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read DB ID file (%s): %w", path, err)
+	}
+	return UnmarshalIDFile(contents)
+}
+
+// WriteIDFile writes the ID as JSON to the given path.
+func (id ID) WriteIDFile(path string) error {
+	contents, err := id.MarshalIDFile()
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, contents, 0600); err != nil {
+		return fmt.Errorf("failed to write DB ID file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package v5
+
+// DBManifest is a summary of the contents of a DB, suitable for publishing alongside a DB release artifact. All
+// collections are ordered so that the JSON representation is stable for the same DB contents.
+type DBManifest struct {
+	SchemaVersion  int                 `json:"schema_version"`
+	BuildTimestamp string              `json:"build_timestamp,omitempty"` // RFC 3339 (empty if the DB has no ID)
+	Namespaces     []NamespaceManifest `json:"namespaces"`                // ordered by namespace
+	Severities     map[string]int      `json:"severities"`                // metadata record counts by (lowercase) severity
+	SizeBytes      int64               `json:"size_bytes"`                // the size of the DB file
+	Fingerprint    string              `json:"fingerprint"`               // a digest of all vulnerability and metadata records
+}
+
+// NamespaceManifest summarizes the record counts within a single namespace.
+type NamespaceManifest struct {
+	Namespace       string `json:"namespace"`
+	Vulnerabilities int    `json:"vulnerabilities"`
+	Metadata        int    `json:"metadata"`
+}
+
+type ManifestReader interface {
+	// Manifest summarizes the DB contents (see DBManifest)
+	Manifest() (DBManifest, error)
+}
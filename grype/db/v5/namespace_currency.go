@@ -0,0 +1,13 @@
+package v5
+
+import "time"
+
+type NamespaceCurrencyReader interface {
+	// GetNamespaceCurrency retrieves when the data for each namespace was current as of (i.e. fetched from upstream)
+	GetNamespaceCurrency() (map[string]time.Time, error)
+}
+
+type NamespaceCurrencyWriter interface {
+	// SetNamespaceCurrency records when the data for the given namespace was current as of
+	SetNamespaceCurrency(namespace string, asOf time.Time) error
+}
@@ -0,0 +1,10 @@
+package v5
+
+import "time"
+
+// Provenance describes the upstream data feed that a vulnerability record originated from.
+type Provenance struct {
+	SourceName string    `json:"source_name"` // The name of the upstream feed (e.g. "nvd", "debian-security-tracker")
+	SourceURL  string    `json:"source_url"`  // Where the upstream feed was fetched from
+	FetchedAt  time.Time `json:"fetched_at"`  // When the upstream feed was fetched
+}
@@ -0,0 +1,8 @@
+package v5
+
+// RemediationOption is a candidate version to upgrade a package to, along with the vulnerabilities (affecting the
+// current version) that would no longer apply after upgrading.
+type RemediationOption struct {
+	Version  string   `json:"version"`  // The candidate fix version to upgrade to
+	Resolves []string `json:"resolves"` // The IDs of the vulnerabilities resolved by upgrading to the version (sorted)
+}
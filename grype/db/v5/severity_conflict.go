@@ -0,0 +1,13 @@
+package v5
+
+// SeverityConflict describes a vulnerability ID where metadata from different namespaces disagree on severity.
+type SeverityConflict struct {
+	ID         string               `json:"id"`         // The identifier of the vulnerability or advisory
+	Severities []NamespacedSeverity `json:"severities"` // The severity assigned within each namespace (ordered by namespace)
+}
+
+// NamespacedSeverity is the severity assigned to a vulnerability within a single namespace.
+type NamespacedSeverity struct {
+	Namespace string `json:"namespace"`
+	Severity  string `json:"severity"`
+}
@@ -1,6 +1,10 @@
 package v5
 
-import "io"
+import (
+	"context"
+	"io"
+	"time"
+)
 
 type Store interface {
 	StoreReader
@@ -13,6 +17,21 @@ type StoreReader interface {
 	VulnerabilityStoreReader
 	VulnerabilityMetadataStoreReader
 	VulnerabilityMatchExclusionStoreReader
+	VulnerabilityDetailReader
+	ManifestReader
+	NamespaceCurrencyReader
+	// AsOf returns a read-only view of the store only including records modified at or before the given time
+	AsOf(t time.Time) StoreReader
+	// WithContext returns a view of the store where all queries honor the deadline and cancellation of the given context
+	WithContext(ctx context.Context) StoreReader
+	// ExportParquet streams all vulnerability and vulnerability metadata records into parquet files (one per table)
+	ExportParquet(vulnerabilities, metadata io.Writer) error
+	// CopyInto writes the DB ID and all records within the given namespaces (all namespaces when empty) into the target store
+	CopyInto(target Store, namespaces []string) error
+	// ExportJSONL streams the DB ID and all vulnerability, vulnerability metadata, and match exclusion records as typed newline-delimited JSON
+	ExportJSONL(w io.Writer) error
+	// Verify checks the DB file integrity, that there is a single DB ID, and the records match the checksum recorded in the DB ID (if any)
+	Verify() error
 	io.Closer
 }
 
@@ -21,9 +40,30 @@ type StoreWriter interface {
 	VulnerabilityStoreWriter
 	VulnerabilityMetadataStoreWriter
 	VulnerabilityMatchExclusionStoreWriter
+	NamespaceCurrencyWriter
+	// Vacuum rebuilds the DB file, reclaiming unused space (this is done implicitly on close when the DB was written to)
+	Vacuum() error
+	// EnsureIndexes creates any indexes missing from the DB that searches rely on (e.g. for DBs built by other tooling)
+	EnsureIndexes() error
+	// ImportJSONL reads the records of a JSONL export (see StoreReader.ExportJSONL) into the store
+	ImportJSONL(r io.Reader) error
+	// WithTransaction calls the given function with a view of the store where all writes are committed together only when the function returns nil
+	WithTransaction(fn func(tx Store) error) error
 	io.Closer
 }
 
 type DiffReader interface {
 	DiffStore(s StoreReader) (*[]Diff, error)
+	// DiffStoreWithOptions is the same as DiffStore, but allows for ignoring cosmetic differences between records
+	DiffStoreWithOptions(s StoreReader, opts DiffOptions) (*[]Diff, error)
+	// DiffStoreStream is the same as DiffStore, but calls the given function with each diff instead of accumulating them
+	DiffStoreStream(s StoreReader, out func(Diff) error) error
+	// DiffStoreStreamWithOptions is the same as DiffStoreWithOptions, but calls the given function with each diff instead of accumulating them
+	DiffStoreStreamWithOptions(s StoreReader, opts DiffOptions, out func(Diff) error) error
+	// DiffCounts returns the number of added, removed, and changed records relative to the given store
+	DiffCounts(s StoreReader) (added, removed, changed int, err error)
+	// DiffSummary returns the sorted vulnerability IDs added, removed, and modified (across all namespaces) relative to the given store
+	DiffSummary(s StoreReader) (added, removed, modified []string, err error)
+	// CoverageDiff reports which (namespace, package, vulnerability) keys exist in only one of this store or the reference store
+	CoverageDiff(reference StoreReader) (CoverageReport, error)
 }
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	v5 "github.com/anchore/grype/grype/db/v5"
+	"github.com/anchore/grype/grype/db/v5/store/model"
+)
+
+// asOfSettingKey is the gorm statement setting which holds the *asOfScope of a time-scoped view.
+const asOfSettingKey = "grype:as_of"
+
+// asOfScope is the time scope of a view of the store (see AsOf).
+type asOfScope struct {
+	// lastModified is the (formatted) last modified bound
+	lastModified string
+	// tables are the time-scoped tables which have a last_modified column (DBs built by older versions of the store do
+	// not, in which case all records of the table are included)
+	tables map[string]bool
+}
+
+// AsOf returns a read-only view of the store which only includes vulnerability and metadata records last modified at
+// or before the given time, allowing for replaying what a scan would have found at an earlier point in time.
+//
+// There are several limitations to consider:
+//   - this assumes the DB was built by monotonic additions: records that were deleted or replaced before the given
+//     time cannot be recovered, since there is no record history
+//   - merging metadata re-stamps the merged record, so metadata merged after the given time is excluded entirely
+//     (rather than appearing in its pre-merge state)
+//   - records written by older versions of the store have no modification time and are always included
+//   - the DB ID and match exclusions have no modification time and are never filtered
+func (s *store) AsOf(t time.Time) v5.StoreReader {
+	scope := &asOfScope{
+		lastModified: model.FormatLastModified(t),
+		tables: map[string]bool{
+			model.VulnerabilityTableName:         s.db.Migrator().HasColumn(&model.VulnerabilityModel{}, "last_modified"),
+			model.VulnerabilityMetadataTableName: s.db.Migrator().HasColumn(&model.VulnerabilityMetadataModel{}, "last_modified"),
+		},
+	}
+	view := s.newView(s.db.Set(asOfSettingKey, scope).Session(&gorm.Session{}))
+	view.asOf = scope
+	return readOnlyStore{StoreReader: view}
+}
+
+// registerAsOfCallbacks adds the as-of filtering of time-scoped views to all queries against the vulnerability and
+// metadata tables.
+func registerAsOfCallbacks(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register("grype:as_of_query", applyAsOf); err != nil {
+		return err
+	}
+	return db.Callback().Row().Before("gorm:row").Register("grype:as_of_row", applyAsOf)
+}
+
+func applyAsOf(db *gorm.DB) {
+	value, ok := db.Get(asOfSettingKey)
+	if !ok || db.Statement.Schema == nil {
+		return
+	}
+
+	scope, ok := value.(*asOfScope)
+	if !ok || !scope.tables[db.Statement.Schema.Table] {
+		return
+	}
+
+	// note: the statement table is the alias when one is used (e.g. "vulnerability AS v")
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{asOfCondition(db.Statement.Table, scope.lastModified)}})
+}
+
+func asOfCondition(table, lastModified string) clause.Expression {
+	column := clause.Column{Table: table, Name: "last_modified"}
+	return clause.Or(
+		clause.Eq{Column: column, Value: nil},
+		clause.Lte{Column: column, Value: lastModified},
+	)
+}
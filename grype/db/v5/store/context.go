@@ -0,0 +1,16 @@
+package store
+
+import (
+	"context"
+
+	v5 "github.com/anchore/grype/grype/db/v5"
+)
+
+// WithContext returns a view of the store where every query is issued with the given context, so reads honor the
+// context deadline and cancellation (e.g. to abort a hung GetAllVulnerabilities scan over a slow filesystem). When the
+// context is done, in-flight and subsequent queries fail with an error wrapping the context error. Any time scope of
+// this store (see AsOf) is retained. Methods invoked directly on the store (not through a view) use
+// context.Background(). Like AsOf, the view is read-only and closing it does not close this store.
+func (s *store) WithContext(ctx context.Context) v5.StoreReader {
+	return readOnlyStore{StoreReader: s.newView(s.db.WithContext(ctx))}
+}
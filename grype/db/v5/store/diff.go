@@ -1,6 +1,12 @@
 package store
 
 import (
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/OneOfOne/xxhash"
 	"github.com/wagoodman/go-partybus"
 	"github.com/wagoodman/go-progress"
 
@@ -31,22 +37,31 @@ type storeMetadata struct {
 	seen bool
 }
 
-// create manual progress bars for tracking the database diff's progress
-func trackDiff(total int64) (*progress.Manual, *progress.Manual, *progress.Stage) {
+// create manual progress bars for tracking the database diff's progress, reported to the given function (or published
+// on the event bus by default)
+func trackDiff(total int64, report v5.DiffProgress) (*progress.Manual, *progress.Manual, *progress.Stage) {
 	stageProgress := &progress.Manual{}
 	stageProgress.SetTotal(total)
 	differencesDiscovered := &progress.Manual{}
 	stager := &progress.Stage{}
 
+	if report == nil {
+		report = publishDiffProgress
+	}
+	report(stager, stageProgress, differencesDiscovered)
+
+	return stageProgress, differencesDiscovered, stager
+}
+
+func publishDiffProgress(stager progress.Stager, rows progress.Progressable, differences progress.Monitorable) {
 	bus.Publish(partybus.Event{
 		Type: event.DatabaseDiffingStarted,
 		Value: monitor.DBDiff{
 			Stager:                stager,
-			StageProgress:         progress.Progressable(stageProgress),
-			DifferencesDiscovered: progress.Monitorable(differencesDiscovered),
+			StageProgress:         rows,
+			DifferencesDiscovered: differences,
 		},
 	})
-	return stageProgress, differencesDiscovered, stager
 }
 
 // creates a map from an unpackaged key to a list of all packages associated with it
@@ -68,12 +83,15 @@ func buildVulnerabilityPkgsMap(models *[]v5.Vulnerability) *map[storeKey][]strin
 // the relevant packages affected by the update
 func createDiff(baseStore, targetStore *PkgMap, key storeKey, reason v5.DiffReason) *v5.Diff {
 	pkgMap := make(map[string]struct{})
+	basePkgMap := make(map[string]struct{})
+	targetPkgMap := make(map[string]struct{})
 
 	key.packageName = ""
 	if baseStore != nil {
 		if basePkgs, exists := (*baseStore)[key]; exists {
 			for _, pkg := range basePkgs {
 				pkgMap[pkg] = struct{}{}
+				basePkgMap[pkg] = struct{}{}
 			}
 		}
 	}
@@ -81,6 +99,7 @@ func createDiff(baseStore, targetStore *PkgMap, key storeKey, reason v5.DiffReas
 		if targetPkgs, exists := (*targetStore)[key]; exists {
 			for _, pkg := range targetPkgs {
 				pkgMap[pkg] = struct{}{}
+				targetPkgMap[pkg] = struct{}{}
 			}
 		}
 	}
@@ -88,13 +107,57 @@ func createDiff(baseStore, targetStore *PkgMap, key storeKey, reason v5.DiffReas
 	for pkg := range pkgMap {
 		pkgs = append(pkgs, pkg)
 	}
+	sort.Strings(pkgs)
+
+	var changes []v5.DiffChange
+	if reason == v5.DiffChanged && baseStore != nil && targetStore != nil {
+		for _, pkg := range pkgs {
+			_, inBase := basePkgMap[pkg]
+			_, inTarget := targetPkgMap[pkg]
+			switch {
+			case inTarget && !inBase:
+				changes = append(changes, v5.DiffPackageAdded)
+			case inBase && !inTarget:
+				changes = append(changes, v5.DiffPackageRemoved)
+			}
+		}
+	}
 
 	return &v5.Diff{
 		Reason:    reason,
 		ID:        key.id,
 		Namespace: key.namespace,
 		Packages:  pkgs,
+		Changes:   mergeDiffChanges(changes),
+	}
+}
+
+// mergeDiffChanges returns the sorted, distinct changes from all given sets of changes.
+func mergeDiffChanges(changes ...[]v5.DiffChange) []v5.DiffChange {
+	merged := slices.Concat(changes...)
+	if len(merged) == 0 {
+		return nil
+	}
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
+
+// classifyMetadataChanges describes which aspects of the metadata record changed between the base and target.
+func classifyMetadataChanges(base, target v5.VulnerabilityMetadata) []v5.DiffChange {
+	var changes []v5.DiffChange
+	if base.Severity != target.Severity {
+		changes = append(changes, v5.DiffSeverityChanged)
+	}
+	if !reflect.DeepEqual(base.Cvss, target.Cvss) {
+		changes = append(changes, v5.DiffCvssChanged)
+	}
+	if base.Description != target.Description {
+		changes = append(changes, v5.DiffDescriptionChanged)
+	}
+	if !slices.Equal(base.URLs, target.URLs) {
+		changes = append(changes, v5.DiffURLsChanged)
 	}
+	return mergeDiffChanges(changes)
 }
 
 // gets an unpackaged key from a vulnerability
@@ -182,11 +245,14 @@ func (v *VulnerabilitySet) getUnmatched() ([]storeKey, []storeKey) {
 	return notSeen, notEntirelySeen
 }
 
-func diffVulnerabilities(baseModels, targetModels *[]v5.Vulnerability, basePkgsMap, targetPkgsMap *PkgMap, differentItems *progress.Manual) *map[string]*v5.Diff {
+func diffVulnerabilities(baseModels, targetModels *[]v5.Vulnerability, basePkgsMap, targetPkgsMap *PkgMap, differentItems, rowsProgress *progress.Manual) *map[string]*v5.Diff {
 	diffs := make(map[string]*v5.Diff)
 	m := NewVulnerabilitySet(baseModels)
+	// all base rows have been processed once indexed, the remaining rows are processed as they are compared
+	rowsProgress.Add(int64(len(*baseModels)))
 
 	for _, tModel := range *targetModels {
+		rowsProgress.Increment()
 		targetModel := tModel
 		k := getVulnerabilityKey(targetModel)
 		if m.in(targetModel) {
@@ -256,6 +322,14 @@ func (v *MetadataSet) match(item v5.VulnerabilityMetadata) bool {
 	return false
 }
 
+// get returns the record with the same key as the given item (nil if there is none).
+func (v *MetadataSet) get(item v5.VulnerabilityMetadata) *v5.VulnerabilityMetadata {
+	if baseModel, exists := v.data[getMetadataKey(item)]; exists {
+		return baseModel.item
+	}
+	return nil
+}
+
 func (v *MetadataSet) getUnmatched() []storeKey {
 	notSeen := []storeKey{}
 	for k, item := range v.data {
@@ -266,11 +340,14 @@ func (v *MetadataSet) getUnmatched() []storeKey {
 	return notSeen
 }
 
-func diffVulnerabilityMetadata(baseModels, targetModels *[]v5.VulnerabilityMetadata, basePkgsMap, targetPkgsMap *PkgMap, differentItems *progress.Manual) *map[string]*v5.Diff {
+func diffVulnerabilityMetadata(baseModels, targetModels *[]v5.VulnerabilityMetadata, basePkgsMap, targetPkgsMap *PkgMap, differentItems, rowsProgress *progress.Manual) *map[string]*v5.Diff {
 	diffs := make(map[string]*v5.Diff)
 	m := NewMetadataSet(baseModels)
+	// all base rows have been processed once indexed, the remaining rows are processed as they are compared
+	rowsProgress.Add(int64(len(*baseModels)))
 
 	for _, tModel := range *targetModels {
+		rowsProgress.Increment()
 		targetModel := tModel
 		k := getMetadataKey(targetModel)
 		if m.in(targetModel) {
@@ -278,7 +355,11 @@ func diffVulnerabilityMetadata(baseModels, targetModels *[]v5.VulnerabilityMetad
 				if _, exists := diffs[k.id+k.namespace]; exists {
 					continue
 				}
-				diffs[k.id+k.namespace] = createDiff(basePkgsMap, targetPkgsMap, k, v5.DiffChanged)
+				diff := createDiff(basePkgsMap, targetPkgsMap, k, v5.DiffChanged)
+				if base := m.get(targetModel); base != nil {
+					diff.Changes = mergeDiffChanges(diff.Changes, classifyMetadataChanges(*base, targetModel))
+				}
+				diffs[k.id+k.namespace] = diff
 				differentItems.Increment()
 			}
 		} else {
@@ -300,6 +381,141 @@ func diffVulnerabilityMetadata(baseModels, targetModels *[]v5.VulnerabilityMetad
 	return &diffs
 }
 
+// diffRecords creates a diff between the given base and target records, advancing the rows progress by one for each
+// record compared.
+func diffRecords(baseVulns, targetVulns *[]v5.Vulnerability, baseMetadata, targetMetadata *[]v5.VulnerabilityMetadata, opts v5.DiffOptions, diffItems, rowsProgress *progress.Manual) []v5.Diff {
+	baseVulnPkgMap := buildVulnerabilityPkgsMap(baseVulns)
+	targetVulnPkgMap := buildVulnerabilityPkgsMap(targetVulns)
+
+	allDiffsMap := diffVulnerabilities(baseVulns, targetVulns, baseVulnPkgMap, targetVulnPkgMap, diffItems, rowsProgress)
+
+	baseMetadata = normalizeMetadata(baseMetadata, opts)
+	targetMetadata = normalizeMetadata(targetMetadata, opts)
+	metaDiffsMap := diffVulnerabilityMetadata(baseMetadata, targetMetadata, baseVulnPkgMap, targetVulnPkgMap, diffItems, rowsProgress)
+	for k, diff := range *metaDiffsMap {
+		// note: the metadata diff takes precedence, however what is known to have changed in the vulnerability records
+		// is retained
+		if existing, exists := (*allDiffsMap)[k]; exists && diff.Reason == v5.DiffChanged {
+			diff.Changes = mergeDiffChanges(existing.Changes, diff.Changes)
+		}
+		(*allDiffsMap)[k] = diff
+	}
+
+	allDiffs := []v5.Diff{}
+	for _, diff := range *allDiffsMap {
+		allDiffs = append(allDiffs, *diff)
+	}
+	sortDiffs(allDiffs)
+	return allDiffs
+}
+
+// sortDiffs orders diffs deterministically for review: by namespace, then vulnerability ID, then the (sorted) affected
+// packages, and finally the change reason. The sort is stable, so diffs equal by all of these keep their relative order.
+func sortDiffs(diffs []v5.Diff) {
+	sort.SliceStable(diffs, func(i, j int) bool {
+		a, b := diffs[i], diffs[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		if c := slices.Compare(a.Packages, b.Packages); c != 0 {
+			return c < 0
+		}
+		return a.Reason < b.Reason
+	})
+}
+
 func getMetadataKey(metadata v5.VulnerabilityMetadata) storeKey {
 	return storeKey{metadata.ID, metadata.Namespace, ""}
 }
+
+// normalizeMetadata returns a copy of the given metadata with all ignored fields cleared or normalized, such that
+// records only differing by ignored fields are considered equal.
+func normalizeMetadata(metadata *[]v5.VulnerabilityMetadata, opts v5.DiffOptions) *[]v5.VulnerabilityMetadata {
+	if len(opts.IgnoreFields) == 0 {
+		return metadata
+	}
+
+	normalized := make([]v5.VulnerabilityMetadata, len(*metadata))
+	for idx, m := range *metadata {
+		switch {
+		case opts.Ignores(v5.DiffFieldURLs):
+			m.URLs = nil
+		case opts.Ignores(v5.DiffFieldURLOrder):
+			m.URLs = slices.Clone(m.URLs)
+			sort.Strings(m.URLs)
+		}
+
+		switch {
+		case opts.Ignores(v5.DiffFieldDescription):
+			m.Description = ""
+		case opts.Ignores(v5.DiffFieldDescriptionWhitespace):
+			m.Description = strings.Join(strings.Fields(m.Description), " ")
+		}
+
+		if opts.Ignores(v5.DiffFieldDataSource) {
+			m.DataSource = ""
+		}
+
+		normalized[idx] = m
+	}
+	return &normalized
+}
+
+type recordDigest struct {
+	key    storeKey
+	digest uint64
+}
+
+// digestRecords creates a digest representing the stored column values of all vulnerability rows (irrespective of row
+// order) and the metadata row of a group of records.
+func digestRecords(g modelRecordGroup) uint64 {
+	rows := make([]string, 0, len(g.vulns)+len(g.metadata))
+	for _, v := range g.vulns {
+		rows = append(rows, strings.Join([]string{
+			"vulnerability",
+			v.PackageName,
+			v.PackageQualifiers.String,
+			v.VersionConstraint,
+			v.VersionFormat,
+			v.CPEs.String,
+			v.RelatedVulnerabilities.String,
+			v.FixedInVersions.String,
+			v.FixState,
+			v.Advisories.String,
+		}, "\x00"))
+	}
+
+	for _, m := range g.metadata {
+		rows = append(rows, strings.Join([]string{
+			"metadata",
+			m.DataSource,
+			m.RecordSource,
+			m.Severity,
+			m.URLs.String,
+			m.Description,
+			m.Cvss.String,
+		}, "\x00"))
+	}
+
+	sort.Strings(rows)
+	h := xxhash.New64()
+	for _, r := range rows {
+		_, _ = h.WriteString(r)
+		_, _ = h.WriteString("\x01")
+	}
+	return h.Sum64()
+}
+
+// lessStoreKey orders keys by vulnerability ID, namespace, then package name.
+func lessStoreKey(a, b storeKey) bool {
+	if a.id != b.id {
+		return a.id < b.id
+	}
+	if a.namespace != b.namespace {
+		return a.namespace < b.namespace
+	}
+	return a.packageName < b.packageName
+}
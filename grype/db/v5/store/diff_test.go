@@ -1,13 +1,23 @@
 package store
 
 import (
+	"context"
+	"fmt"
+	"math/rand"
 	"os"
+	"slices"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/wagoodman/go-partybus"
+	"github.com/wagoodman/go-progress"
 
 	v5 "github.com/anchore/grype/grype/db/v5"
+	"github.com/anchore/grype/grype/db/v5/store/model"
+	"github.com/anchore/grype/grype/event"
+	"github.com/anchore/grype/internal/bus"
 )
 
 func Test_GetAllVulnerabilities(t *testing.T) {
@@ -233,3 +243,842 @@ func Test_Diff_Metadata(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expectedDiffs, *result)
 }
+
+func Test_DiffCounts(t *testing.T) {
+	//GIVEN
+	s1, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	// note: the target records are stamped with the build time, so are only included in views as of a later time
+	s2, err := New(t.TempDir(), true, WithBuildTime(time.Now()))
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	baseVulns := []v5.Vulnerability{
+		{
+			Namespace:         "github:language:python",
+			ID:                "CVE-123-4567",
+			PackageName:       "pypi:requests",
+			VersionConstraint: "< 2.0 >= 1.29",
+		},
+		{
+			Namespace:         "github:language:python",
+			ID:                "CVE-123-4567",
+			PackageName:       "pypi:requests",
+			VersionConstraint: "< 3.0 >= 2.17",
+		},
+		{
+			Namespace:         "npm",
+			ID:                "CVE-123-7654",
+			PackageName:       "npm:axios",
+			VersionConstraint: "< 3.0 >= 2.17",
+		},
+		{
+			Namespace:         "hex",
+			ID:                "GHSA-^^^^-^^^^^^",
+			PackageName:       "hex:esbuild",
+			VersionConstraint: "< 3.0 >= 2.17",
+		},
+	}
+	baseMetadata := []v5.VulnerabilityMetadata{
+		{
+			Namespace:  "npm",
+			ID:         "CVE-123-7654",
+			DataSource: "nvd",
+		},
+	}
+	targetVulns := []v5.Vulnerability{
+		{
+			Namespace:         "github:language:python",
+			ID:                "CVE-123-4567",
+			PackageName:       "pypi:requests",
+			VersionConstraint: "< 3.0 >= 2.17",
+		},
+		{
+			Namespace:         "github:language:python",
+			ID:                "CVE-123-4567",
+			PackageName:       "pypi:requests",
+			VersionConstraint: "< 2.0 >= 1.29",
+		},
+		{
+			Namespace:         "npm",
+			ID:                "CVE-123-7654",
+			PackageName:       "npm:axios",
+			VersionConstraint: "< 3.0 >= 2.17",
+		},
+		{
+			Namespace:         "github:language:go",
+			ID:                "GHSA-....-....",
+			PackageName:       "hashicorp:nomad",
+			VersionConstraint: "< 3.0 >= 2.17",
+		},
+	}
+	targetMetadata := []v5.VulnerabilityMetadata{
+		{
+			Namespace:  "npm",
+			ID:         "CVE-123-7654",
+			DataSource: "vulndb",
+		},
+	}
+
+	assert.NoError(t, s1.AddVulnerability(baseVulns...))
+	_, err = s1.AddVulnerabilityMetadata(baseMetadata...)
+	assert.NoError(t, err)
+	assert.NoError(t, s2.AddVulnerability(targetVulns...))
+	_, err = s2.AddVulnerabilityMetadata(targetMetadata...)
+	assert.NoError(t, err)
+
+	// wrapped stores are unwrapped to diff the underlying sql database directly, other readers are streamed and compared
+	// the same way
+	targets := []struct {
+		name            string
+		target          v5.StoreReader
+		unwrapped       bool
+		expectedAdded   int
+		expectedRemoved int
+		expectedChanged int
+	}{
+		{name: "store", target: s2, unwrapped: true, expectedAdded: 1, expectedRemoved: 1, expectedChanged: 1},
+		{name: "read-only", target: readOnlyStore{StoreReader: s2}, unwrapped: true, expectedAdded: 1, expectedRemoved: 1, expectedChanged: 1},
+		{name: "as of", target: s2.AsOf(time.Now().Add(time.Hour)), unwrapped: true, expectedAdded: 1, expectedRemoved: 1, expectedChanged: 1},
+		{name: "as of before any records", target: s2.AsOf(time.Now().Add(-time.Hour)), unwrapped: true, expectedRemoved: 3},
+		{name: "with context", target: s2.WithContext(context.Background()), unwrapped: true, expectedAdded: 1, expectedRemoved: 1, expectedChanged: 1},
+		{name: "reader", target: readerOnly{s2}, expectedAdded: 1, expectedRemoved: 1, expectedChanged: 1},
+	}
+
+	for _, tt := range targets {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := asStore(tt.target)
+			assert.Equal(t, tt.unwrapped, ok)
+
+			//WHEN
+			added, removed, changed, err := s1.DiffCounts(tt.target)
+
+			//THEN
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedAdded, added)
+			assert.Equal(t, tt.expectedRemoved, removed)
+			assert.Equal(t, tt.expectedChanged, changed)
+		})
+	}
+}
+
+func Test_DiffCounts_StoredRecordsNotNormalized(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	// note: older DBs may contain duplicate CVSS entries, which are only removed from records when read
+	cvss := v5.Cvss{Version: "3.1", Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}
+	stored := model.NewVulnerabilityMetadataModel(v5.VulnerabilityMetadata{
+		ID:        "CVE-123-4567",
+		Namespace: "nvd:cpe",
+		Cvss:      []v5.Cvss{cvss, cvss},
+	})
+	if err := s.(*store).db.Create(&stored).Error; err != nil {
+		t.Fatalf("could not create record: %+v", err)
+	}
+
+	for _, target := range []v5.StoreReader{s, readerOnly{s}} {
+		added, removed, changed, err := s.DiffCounts(target)
+		assert.NoError(t, err)
+		assert.Zero(t, added)
+		assert.Zero(t, removed)
+		assert.Zero(t, changed)
+	}
+}
+
+func Test_DiffSummary(t *testing.T) {
+	newMetadata := func(id, namespace, severity string) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{Namespace: namespace, ID: id, Severity: severity}
+	}
+
+	tests := []struct {
+		name             string
+		base             []v5.VulnerabilityMetadata
+		target           []v5.VulnerabilityMetadata
+		expectedAdded    []string
+		expectedRemoved  []string
+		expectedModified []string
+	}{
+		{
+			name:             "added and removed IDs",
+			base:             []v5.VulnerabilityMetadata{newMetadata("CVE-1", "nvd:cpe", "high"), newMetadata("CVE-2", "nvd:cpe", "high")},
+			target:           []v5.VulnerabilityMetadata{newMetadata("CVE-2", "nvd:cpe", "high"), newMetadata("CVE-3", "nvd:cpe", "high")},
+			expectedAdded:    []string{"CVE-3"},
+			expectedRemoved:  []string{"CVE-1"},
+			expectedModified: []string{},
+		},
+		{
+			name: "modified IDs",
+			base: []v5.VulnerabilityMetadata{
+				newMetadata("CVE-1", "nvd:cpe", "high"),
+				newMetadata("CVE-2", "nvd:cpe", "high"),
+				newMetadata("CVE-3", "nvd:cpe", "high"),
+				newMetadata("CVE-3", "debian:distro:debian:12", "high"),
+			},
+			target: []v5.VulnerabilityMetadata{
+				// the severity changed
+				newMetadata("CVE-1", "nvd:cpe", "critical"),
+				// added to another namespace
+				newMetadata("CVE-2", "nvd:cpe", "high"),
+				newMetadata("CVE-2", "github:language:python", "high"),
+				newMetadata("CVE-2", "debian:distro:debian:12", "high"),
+				// removed from a namespace
+				newMetadata("CVE-3", "nvd:cpe", "high"),
+			},
+			expectedAdded:    []string{},
+			expectedRemoved:  []string{},
+			expectedModified: []string{"CVE-1", "CVE-2", "CVE-3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s1, err := New(t.TempDir(), true)
+			if err != nil {
+				t.Fatalf("could not create store: %+v", err)
+			}
+			s2, err := New(t.TempDir(), true)
+			if err != nil {
+				t.Fatalf("could not create store: %+v", err)
+			}
+
+			_, err = s1.AddVulnerabilityMetadata(tt.base...)
+			assert.NoError(t, err)
+			_, err = s2.AddVulnerabilityMetadata(tt.target...)
+			assert.NoError(t, err)
+
+			added, removed, modified, err := s1.DiffSummary(s2)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedAdded, added)
+			assert.Equal(t, tt.expectedRemoved, removed)
+			assert.Equal(t, tt.expectedModified, modified)
+
+			// IDs are classified without looking up the records of each ID
+			reader := &detailCountingReader{StoreReader: s2}
+			added, removed, modified, err = s1.DiffSummary(reader)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedAdded, added)
+			assert.Equal(t, tt.expectedRemoved, removed)
+			assert.Equal(t, tt.expectedModified, modified)
+			assert.Zero(t, reader.lookups)
+		})
+	}
+}
+
+// detailCountingReader is a store reader (not backed by this package's store) counting vulnerability detail lookups.
+type detailCountingReader struct {
+	v5.StoreReader
+	lookups int
+}
+
+func (r *detailCountingReader) GetVulnerabilityDetail(id string) (v5.VulnerabilityDetail, error) {
+	r.lookups++
+	return r.StoreReader.GetVulnerabilityDetail(id)
+}
+
+func Test_DiffStoreWithOptions(t *testing.T) {
+	//GIVEN
+	s1, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	s2, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	baseMetadata := []v5.VulnerabilityMetadata{
+		{
+			Namespace:   "npm",
+			ID:          "CVE-123-7654",
+			DataSource:  "nvd",
+			Severity:    "high",
+			URLs:        []string{"https://a.example.com", "https://b.example.com"},
+			Description: "a  vulnerability\nin axios",
+		},
+		{
+			Namespace: "npm",
+			ID:        "CVE-123-8888",
+			Severity:  "low",
+		},
+	}
+	targetMetadata := []v5.VulnerabilityMetadata{
+		{
+			Namespace:   "npm",
+			ID:          "CVE-123-7654",
+			DataSource:  "nvd",
+			Severity:    "high",
+			URLs:        []string{"https://b.example.com", "https://a.example.com"},
+			Description: "a vulnerability in axios ",
+		},
+		{
+			Namespace: "npm",
+			ID:        "CVE-123-8888",
+			Severity:  "critical",
+		},
+	}
+
+	_, err = s1.AddVulnerabilityMetadata(baseMetadata...)
+	assert.NoError(t, err)
+	_, err = s2.AddVulnerabilityMetadata(targetMetadata...)
+	assert.NoError(t, err)
+
+	diffIDs := func(diffs *[]v5.Diff) []string {
+		var ids []string
+		for _, d := range *diffs {
+			ids = append(ids, d.ID)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	//WHEN
+	fullResult, err := s1.DiffStore(s2)
+	assert.NoError(t, err)
+	cosmeticResult, err := s1.DiffStoreWithOptions(s2, v5.DiffOptions{
+		IgnoreFields: []v5.DiffField{v5.DiffFieldURLOrder, v5.DiffFieldDescriptionWhitespace},
+	})
+	assert.NoError(t, err)
+
+	//THEN
+	assert.Equal(t, []string{"CVE-123-7654", "CVE-123-8888"}, diffIDs(fullResult))
+	assert.Equal(t, []string{"CVE-123-8888"}, diffIDs(cosmeticResult))
+}
+
+// readerOnly hides the store backing a reader, so the store is only read through the v5.StoreReader interface.
+type readerOnly struct {
+	v5.StoreReader
+}
+
+type diffEventRecorder struct {
+	events []partybus.Event
+}
+
+func (r *diffEventRecorder) Publish(e partybus.Event) {
+	r.events = append(r.events, e)
+}
+
+func Test_DiffStore_Progress(t *testing.T) {
+	s1, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	s2, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	_, err = s1.AddVulnerabilityMetadata(v5.VulnerabilityMetadata{Namespace: "npm", ID: "CVE-123-7654", Severity: "high"})
+	assert.NoError(t, err)
+	_, err = s2.AddVulnerabilityMetadata(v5.VulnerabilityMetadata{Namespace: "npm", ID: "CVE-123-7654", Severity: "low"})
+	assert.NoError(t, err)
+
+	recorder := &diffEventRecorder{}
+	bus.Set(recorder)
+	defer bus.Set(nil)
+
+	// by default the progress is published on the event bus
+	_, err = s1.DiffStore(s2)
+	assert.NoError(t, err)
+	if assert.Len(t, recorder.events, 1) {
+		assert.Equal(t, event.DatabaseDiffingStarted, recorder.events[0].Type)
+	}
+
+	// the progress can be reported elsewhere (or not at all) without publishing events
+	recorder.events = nil
+	var rows progress.Progressable
+	var differences progress.Monitorable
+	diffs, err := s1.DiffStoreWithOptions(s2, v5.DiffOptions{
+		Progress: func(_ progress.Stager, r progress.Progressable, d progress.Monitorable) {
+			rows = r
+			differences = d
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, *diffs, 1)
+	if assert.NotNil(t, rows) && assert.NotNil(t, differences) {
+		assert.Equal(t, int64(2), rows.Size())
+		assert.True(t, progress.IsCompleted(rows))
+		assert.Equal(t, int64(1), differences.Current())
+	}
+
+	_, err = s1.DiffStoreWithOptions(s2, v5.DiffOptions{Progress: v5.NoDiffProgress})
+	assert.NoError(t, err)
+	assert.Empty(t, recorder.events)
+}
+
+func Test_DiffStore_StoreMatchesReader(t *testing.T) {
+	//GIVEN
+	s1, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	s2, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(id, namespace, pkgName, constraint string) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			Namespace:         namespace,
+			PackageName:       pkgName,
+			VersionConstraint: constraint,
+			VersionFormat:     "semver",
+		}
+	}
+
+	assert.NoError(t, s1.AddVulnerability(
+		newVuln("CVE-1", "npm", "axios", "< 1.0"),
+		newVuln("CVE-2", "npm", "lodash", "< 2.0"),
+		newVuln("CVE-1", "github:language:go", "nomad", "< 1.0"),
+		newVuln("CVE-3", "base-only", "pkg", "< 1.0"),
+	))
+	assert.NoError(t, s2.AddVulnerability(
+		newVuln("CVE-1", "npm", "axios", "< 1.1"),
+		newVuln("CVE-2", "npm", "lodash", "< 2.0"),
+		newVuln("CVE-1", "github:language:go", "nomad", "< 1.0"),
+		newVuln("CVE-4", "github:language:go", "vault", "< 1.0"),
+		newVuln("CVE-5", "target-only", "pkg", "< 1.0"),
+	))
+	_, err = s1.AddVulnerabilityMetadata(
+		v5.VulnerabilityMetadata{ID: "CVE-2", Namespace: "npm", Severity: "low"},
+		v5.VulnerabilityMetadata{ID: "CVE-6", Namespace: "metadata-only", Severity: "low"},
+	)
+	assert.NoError(t, err)
+	_, err = s2.AddVulnerabilityMetadata(
+		v5.VulnerabilityMetadata{ID: "CVE-2", Namespace: "npm", Severity: "high"},
+	)
+	assert.NoError(t, err)
+
+	sortDiffs := func(diffs *[]v5.Diff) []v5.Diff {
+		sort.SliceStable(*diffs, func(i, j int) bool {
+			if (*diffs)[i].ID == (*diffs)[j].ID {
+				return (*diffs)[i].Namespace < (*diffs)[j].Namespace
+			}
+			return (*diffs)[i].ID < (*diffs)[j].ID
+		})
+		return *diffs
+	}
+
+	//WHEN
+	viaStore, err := s1.DiffStore(s2)
+	assert.NoError(t, err)
+	viaReader, err := s1.DiffStore(readerOnly{s2})
+	assert.NoError(t, err)
+
+	//THEN
+	assert.Equal(t, sortDiffs(viaReader), sortDiffs(viaStore))
+	assert.Equal(t, []v5.Diff{
+		{Reason: v5.DiffChanged, ID: "CVE-1", Namespace: "npm", Packages: []string{"axios"}},
+		{Reason: v5.DiffChanged, ID: "CVE-2", Namespace: "npm", Packages: []string{"lodash"}, Changes: []v5.DiffChange{v5.DiffSeverityChanged}},
+		{Reason: v5.DiffRemoved, ID: "CVE-3", Namespace: "base-only", Packages: []string{"pkg"}},
+		{Reason: v5.DiffAdded, ID: "CVE-4", Namespace: "github:language:go", Packages: []string{"vault"}},
+		{Reason: v5.DiffAdded, ID: "CVE-5", Namespace: "target-only", Packages: []string{"pkg"}},
+		{Reason: v5.DiffRemoved, ID: "CVE-6", Namespace: "metadata-only", Packages: []string{}},
+	}, sortDiffs(viaStore))
+}
+
+func Test_sortDiffs(t *testing.T) {
+	//GIVEN
+	expected := []v5.Diff{
+		{Reason: v5.DiffAdded, ID: "CVE-1", Namespace: "github:language:go", Packages: []string{"vault"}},
+		{Reason: v5.DiffChanged, ID: "CVE-1", Namespace: "npm", Packages: []string{"axios"}},
+		{Reason: v5.DiffChanged, ID: "CVE-1", Namespace: "npm", Packages: []string{"axios", "lodash"}},
+		{Reason: v5.DiffAdded, ID: "CVE-1", Namespace: "npm", Packages: []string{"lodash"}},
+		{Reason: v5.DiffChanged, ID: "CVE-1", Namespace: "npm", Packages: []string{"lodash"}},
+		{Reason: v5.DiffRemoved, ID: "CVE-2", Namespace: "npm", Packages: []string{}},
+		{Reason: v5.DiffAdded, ID: "CVE-3", Namespace: "npm", Packages: []string{"axios"}},
+	}
+
+	for i := 0; i < 20; i++ {
+		//WHEN
+		shuffled := slices.Clone(expected)
+		rand.Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		sortDiffs(shuffled)
+
+		//THEN
+		assert.Equal(t, expected, shuffled)
+	}
+}
+
+func Test_DiffStore_DeterministicOrder(t *testing.T) {
+	//GIVEN
+	s1, err := New(t.TempDir(), true)
+	assert.NoError(t, err)
+	s2, err := New(t.TempDir(), true)
+	assert.NoError(t, err)
+
+	var base, target []v5.Vulnerability
+	for i := 0; i < 50; i++ {
+		v := v5.Vulnerability{
+			ID:                fmt.Sprintf("CVE-%d", i),
+			Namespace:         fmt.Sprintf("namespace-%d", i%3),
+			PackageName:       fmt.Sprintf("pkg-%d", i%7),
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+		}
+		base = append(base, v)
+		if i%2 == 0 {
+			v.VersionConstraint = "< 2.0"
+		}
+		target = append(target, v)
+	}
+	assert.NoError(t, s1.AddVulnerability(base...))
+	assert.NoError(t, s2.AddVulnerability(target...))
+
+	//WHEN
+	first, err := s1.DiffStore(s2)
+	assert.NoError(t, err)
+	viaReader, err := s1.DiffStore(readerOnly{s2})
+	assert.NoError(t, err)
+
+	//THEN
+	assert.Len(t, *first, 25)
+	assert.True(t, sort.SliceIsSorted(*first, func(i, j int) bool {
+		a, b := (*first)[i], (*first)[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.ID < b.ID
+	}))
+	assert.Equal(t, *first, *viaReader)
+	for i := 0; i < 5; i++ {
+		again, err := s1.DiffStore(s2)
+		assert.NoError(t, err)
+		assert.Equal(t, *first, *again)
+	}
+}
+
+func Test_diffRecords_RowsProgress(t *testing.T) {
+	//GIVEN
+	newVuln := func(id, pkg string) v5.Vulnerability {
+		return v5.Vulnerability{ID: id, Namespace: "npm", PackageName: pkg, VersionConstraint: "< 1.0", VersionFormat: "semver"}
+	}
+	newMetadata := func(id, severity string) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{ID: id, Namespace: "npm", Severity: severity}
+	}
+
+	baseVulns := []v5.Vulnerability{newVuln("CVE-1", "axios"), newVuln("CVE-1", "lodash"), newVuln("CVE-2", "axios")}
+	targetVulns := []v5.Vulnerability{newVuln("CVE-1", "axios"), newVuln("CVE-3", "axios")}
+	baseMetadata := []v5.VulnerabilityMetadata{newMetadata("CVE-1", "High"), newMetadata("CVE-2", "Low")}
+	targetMetadata := []v5.VulnerabilityMetadata{newMetadata("CVE-1", "Critical"), newMetadata("CVE-3", "Low")}
+
+	rowsProgress := &progress.Manual{}
+	diffItems := &progress.Manual{}
+
+	//WHEN
+	diffs := diffRecords(&baseVulns, &targetVulns, &baseMetadata, &targetMetadata, v5.DiffOptions{}, diffItems, rowsProgress)
+
+	//THEN
+	assert.Len(t, diffs, 3)
+	assert.Equal(t, int64(len(baseVulns)+len(targetVulns)+len(baseMetadata)+len(targetMetadata)), rowsProgress.Current())
+}
+
+func Test_DiffStore_FilteredByNamespace(t *testing.T) {
+	//GIVEN
+	s1, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	s2, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(id, namespace, pkgName, constraint string) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			Namespace:         namespace,
+			PackageName:       pkgName,
+			VersionConstraint: constraint,
+			VersionFormat:     "semver",
+		}
+	}
+
+	assert.NoError(t, s1.AddVulnerability(
+		newVuln("CVE-1", "nvd:cpe", "axios", "< 1.0"),
+		newVuln("CVE-2", "debian:distro:debian:12", "curl", "< 2.0"),
+	))
+	assert.NoError(t, s2.AddVulnerability(
+		newVuln("CVE-1", "nvd:cpe", "axios", "< 1.1"),
+		newVuln("CVE-3", "nvd:cpe", "lodash", "< 1.0"),
+		newVuln("CVE-4", "alpine:distro:alpine:3.18", "curl", "< 1.0"),
+	))
+	_, err = s1.AddVulnerabilityMetadata(
+		v5.VulnerabilityMetadata{ID: "CVE-2", Namespace: "debian:distro:debian:12", Severity: "low"},
+	)
+	assert.NoError(t, err)
+	_, err = s2.AddVulnerabilityMetadata(
+		v5.VulnerabilityMetadata{ID: "CVE-4", Namespace: "alpine:distro:alpine:3.18", Severity: "high"},
+	)
+	assert.NoError(t, err)
+
+	opts := v5.DiffOptions{Namespaces: []string{"nvd:cpe"}}
+
+	//WHEN
+	viaStore, err := s1.DiffStoreWithOptions(s2, opts)
+	assert.NoError(t, err)
+	viaReader, err := s1.DiffStoreWithOptions(readerOnly{s2}, opts)
+	assert.NoError(t, err)
+	unfiltered, err := s1.DiffStore(s2)
+	assert.NoError(t, err)
+
+	//THEN
+	expected := []v5.Diff{
+		{Reason: v5.DiffChanged, ID: "CVE-1", Namespace: "nvd:cpe", Packages: []string{"axios"}},
+		{Reason: v5.DiffAdded, ID: "CVE-3", Namespace: "nvd:cpe", Packages: []string{"lodash"}},
+	}
+	assert.Equal(t, expected, *viaStore)
+	assert.Equal(t, expected, *viaReader)
+	assert.Len(t, *unfiltered, 4)
+}
+
+func Test_DiffStoreStream(t *testing.T) {
+	//GIVEN
+	s1, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	s2, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(id, namespace string) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			Namespace:         namespace,
+			PackageName:       "pkg",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+		}
+	}
+
+	assert.NoError(t, s1.AddVulnerability(newVuln("CVE-1", "npm"), newVuln("CVE-2", "npm")))
+	assert.NoError(t, s2.AddVulnerability(newVuln("CVE-3", "github:language:go"), newVuln("CVE-4", "npm")))
+
+	//WHEN
+	var streamed []v5.Diff
+	err = s1.DiffStoreStream(s2, func(d v5.Diff) error {
+		streamed = append(streamed, d)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	collected, err := s1.DiffStore(s2)
+	assert.NoError(t, err)
+
+	stop := fmt.Errorf("stop")
+	var seen int
+	stopErr := s1.DiffStoreStream(s2, func(v5.Diff) error {
+		seen++
+		return stop
+	})
+
+	//THEN
+	assert.Equal(t, *collected, streamed)
+	assert.Len(t, streamed, 4)
+	assert.Equal(t, stop, stopErr)
+	assert.Equal(t, 1, seen)
+}
+
+// streamedReader records how many vulnerability rows have been read from a store (only through the v5.StoreReader
+// interface), optionally failing after a number of rows.
+type streamedReader struct {
+	v5.StoreReader
+	read   int
+	failAt int
+}
+
+func (r *streamedReader) EachVulnerability(fn func(v5.Vulnerability) error) error {
+	return r.StoreReader.EachVulnerability(func(v v5.Vulnerability) error {
+		if r.failAt > 0 && r.read == r.failAt {
+			return fmt.Errorf("unable to read row %d", r.read)
+		}
+		r.read++
+		return fn(v)
+	})
+}
+
+func Test_DiffStoreStream_EmitsAsFound(t *testing.T) {
+	//GIVEN
+	s1, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	s2, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		v := v5.Vulnerability{
+			ID:                fmt.Sprintf("CVE-%02d", i),
+			Namespace:         "npm",
+			PackageName:       "pkg",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+		}
+		assert.NoError(t, s1.AddVulnerability(v))
+		v.VersionConstraint = "< 2.0"
+		assert.NoError(t, s2.AddVulnerability(v))
+	}
+
+	//WHEN
+	target := &streamedReader{StoreReader: s2}
+	var readWhenEmitted []int
+	err = s1.DiffStoreStream(target, func(v5.Diff) error {
+		readWhenEmitted = append(readWhenEmitted, target.read)
+		return nil
+	})
+
+	//THEN
+	assert.NoError(t, err)
+	if assert.Len(t, readWhenEmitted, 10) {
+		// each diff is emitted once the records of its key have been read (plus the next record, to find the key's end)
+		for i, read := range readWhenEmitted {
+			assert.LessOrEqual(t, read, i+2)
+		}
+	}
+
+	// a failed read is returned rather than reporting the unread records as removed
+	failing := &streamedReader{StoreReader: s2, failAt: 3}
+	var reasons []v5.DiffReason
+	err = s1.DiffStoreStream(failing, func(d v5.Diff) error {
+		reasons = append(reasons, d.Reason)
+		return nil
+	})
+	assert.ErrorContains(t, err, "unable to read row 3")
+	assert.NotContains(t, reasons, v5.DiffRemoved)
+}
+
+func Test_DiffStoreStream_InMemory(t *testing.T) {
+	//GIVEN
+	// in-memory stores are limited to a single connection, which each store's records are streamed over
+	s1, err := NewInMemory()
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	s2, err := NewInMemory()
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	assert.NoError(t, s1.AddVulnerability(v5.Vulnerability{ID: "CVE-1", Namespace: "npm", PackageName: "pkg", VersionConstraint: "< 1.0", VersionFormat: "semver"}))
+	_, err = s1.AddVulnerabilityMetadata(v5.VulnerabilityMetadata{ID: "CVE-1", Namespace: "npm", Severity: "low"})
+	assert.NoError(t, err)
+	_, err = s2.AddVulnerabilityMetadata(v5.VulnerabilityMetadata{ID: "CVE-1", Namespace: "npm", Severity: "high"})
+	assert.NoError(t, err)
+
+	//WHEN
+	done := make(chan struct{})
+	var diffs *[]v5.Diff
+	var selfDiffs, viewDiffs *[]v5.Diff
+	var selfErr, viewErr error
+	go func() {
+		defer close(done)
+		diffs, err = s1.DiffStore(s2)
+		// a view of the store shares its only connection
+		selfDiffs, selfErr = s1.DiffStore(s1)
+		viewDiffs, viewErr = s1.DiffStore(s1.WithContext(context.Background()))
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("diff is blocked waiting for a connection")
+	}
+
+	//THEN
+	assert.NoError(t, err)
+	assert.Equal(t, &[]v5.Diff{
+		{Reason: v5.DiffChanged, ID: "CVE-1", Namespace: "npm", Packages: []string{"pkg"}, Changes: []v5.DiffChange{v5.DiffPackageRemoved, v5.DiffSeverityChanged}},
+	}, diffs)
+	assert.NoError(t, selfErr)
+	assert.Empty(t, *selfDiffs)
+	assert.NoError(t, viewErr)
+	assert.Empty(t, *viewDiffs)
+}
+
+func Test_DiffStore_Changes(t *testing.T) {
+	//GIVEN
+	s1, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	s2, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(id, pkgName, constraint string) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			Namespace:         "npm",
+			PackageName:       pkgName,
+			VersionConstraint: constraint,
+			VersionFormat:     "semver",
+		}
+	}
+
+	newMetadata := func(id, severity, description string, score float64, urls ...string) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{
+			ID:          id,
+			Namespace:   "npm",
+			Severity:    severity,
+			Description: description,
+			URLs:        urls,
+			Cvss: []v5.Cvss{
+				{Version: "3.1", Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", Metrics: v5.NewCvssMetrics(score, 3.9, 5.9)},
+			},
+		}
+	}
+
+	assert.NoError(t, s1.AddVulnerability(
+		newVuln("CVE-1", "axios", "< 1.0"),
+		newVuln("CVE-2", "lodash", "< 1.0"),
+		newVuln("CVE-2", "underscore", "< 1.0"),
+		newVuln("CVE-3", "express", "< 1.0"),
+		newVuln("CVE-5", "react", "< 1.0"),
+	))
+	assert.NoError(t, s2.AddVulnerability(
+		newVuln("CVE-1", "axios", "< 1.0"),
+		newVuln("CVE-1", "fetch", "< 1.0"),
+		newVuln("CVE-2", "lodash", "< 1.0"),
+		newVuln("CVE-3", "express", "< 1.0"),
+		newVuln("CVE-5", "react", "< 1.1"),
+	))
+	_, err = s1.AddVulnerabilityMetadata(
+		newMetadata("CVE-1", "High", "a vulnerability", 9.8),
+		newMetadata("CVE-3", "High", "a vulnerability", 9.8),
+		newMetadata("CVE-4", "High", "a vulnerability", 9.8, "https://example.com/a"),
+	)
+	assert.NoError(t, err)
+	_, err = s2.AddVulnerabilityMetadata(
+		newMetadata("CVE-1", "Critical", "a vulnerability", 9.8),
+		newMetadata("CVE-3", "High", "a vulnerability", 8.1),
+		newMetadata("CVE-4", "High", "a better described vulnerability", 9.8, "https://example.com/b"),
+	)
+	assert.NoError(t, err)
+
+	//WHEN
+	result, err := s1.DiffStore(s2)
+	assert.NoError(t, err)
+
+	//THEN
+	assert.Equal(t, []v5.Diff{
+		{Reason: v5.DiffChanged, ID: "CVE-1", Namespace: "npm", Packages: []string{"axios", "fetch"}, Changes: []v5.DiffChange{v5.DiffPackageAdded, v5.DiffSeverityChanged}},
+		{Reason: v5.DiffChanged, ID: "CVE-2", Namespace: "npm", Packages: []string{"lodash", "underscore"}, Changes: []v5.DiffChange{v5.DiffPackageRemoved}},
+		{Reason: v5.DiffChanged, ID: "CVE-3", Namespace: "npm", Packages: []string{"express"}, Changes: []v5.DiffChange{v5.DiffCvssChanged}},
+		{Reason: v5.DiffChanged, ID: "CVE-4", Namespace: "npm", Packages: []string{}, Changes: []v5.DiffChange{v5.DiffDescriptionChanged, v5.DiffURLsChanged}},
+		// only the version constraint changed, which is not classified
+		{Reason: v5.DiffChanged, ID: "CVE-5", Namespace: "npm", Packages: []string{"react"}},
+	}, *result)
+}
@@ -0,0 +1,132 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+
+	"github.com/anchore/grype/grype/db/v5/store/model"
+	"github.com/anchore/grype/internal/parquet"
+)
+
+const parquetCreatedBy = "grype"
+
+// vulnerabilityParquetColumns are the columns of the exported vulnerability table. Column names and values mirror the
+// sqlite "vulnerability" table: every column is a nullable UTF-8 string, where nested values (e.g. cpes, fixed in
+// versions, advisories) are JSON encoded exactly as stored. New columns may only ever be appended.
+var vulnerabilityParquetColumns = []string{
+	"id",
+	"package_name",
+	"namespace",
+	"package_qualifiers",
+	"version_constraint",
+	"version_format",
+	"cpes",
+	"related_vulnerabilities",
+	"fixed_in_versions",
+	"fix_state",
+	"advisories",
+	"provenance_source_name",
+	"provenance_source_url",
+	"provenance_fetched_at",
+	"last_modified",
+}
+
+// metadataParquetColumns are the columns of the exported vulnerability metadata table. Column names and values mirror
+// the sqlite "vulnerability_metadata" table: every column is a nullable UTF-8 string, where nested values (urls and
+// cvss) are JSON encoded exactly as stored. New columns may only ever be appended.
+var metadataParquetColumns = []string{
+	"id",
+	"namespace",
+	"data_source",
+	"record_source",
+	"severity",
+	"urls",
+	"description",
+	"cvss",
+	"last_modified",
+}
+
+// ExportParquet streams all vulnerability records and all vulnerability metadata records into two parquet files
+// (written to the given writers respectively), suitable for querying with columnar tools such as DuckDB or Spark. Each
+// batch of records read from the database is written as a single row group, so memory is bounded by the batch size
+// regardless of the size of the database. See vulnerabilityParquetColumns and metadataParquetColumns for the schemas.
+func (s *store) ExportParquet(vulnerabilities, metadata io.Writer) error {
+	if err := s.exportVulnerabilitiesParquet(vulnerabilities); err != nil {
+		return fmt.Errorf("unable to export vulnerabilities: %w", err)
+	}
+	if err := s.exportMetadataParquet(metadata); err != nil {
+		return fmt.Errorf("unable to export vulnerability metadata: %w", err)
+	}
+	return nil
+}
+
+func (s *store) exportVulnerabilitiesParquet(w io.Writer) error {
+	pw, err := parquet.NewWriter(w, parquetCreatedBy, vulnerabilityParquetColumns...)
+	if err != nil {
+		return err
+	}
+
+	err = s.forEachVulnerabilityBatch(nil, func(models []model.VulnerabilityModel) error {
+		rows := make([][]sql.NullString, len(models))
+		for i, m := range models {
+			rows[i] = []sql.NullString{
+				value(m.ID),
+				value(m.PackageName),
+				value(m.Namespace),
+				m.PackageQualifiers.NullString,
+				value(m.VersionConstraint),
+				value(m.VersionFormat),
+				m.CPEs.NullString,
+				m.RelatedVulnerabilities.NullString,
+				m.FixedInVersions.NullString,
+				value(m.FixState),
+				m.Advisories.NullString,
+				m.ProvenanceSourceName.NullString,
+				m.ProvenanceSourceURL.NullString,
+				m.ProvenanceFetchedAt.NullString,
+				m.LastModified.NullString,
+			}
+		}
+		return pw.WriteRowGroup(rows)
+	})
+	if err != nil {
+		return err
+	}
+
+	return pw.Close()
+}
+
+func (s *store) exportMetadataParquet(w io.Writer) error {
+	pw, err := parquet.NewWriter(w, parquetCreatedBy, metadataParquetColumns...)
+	if err != nil {
+		return err
+	}
+
+	err = s.forEachMetadataBatch(nil, func(models []model.VulnerabilityMetadataModel) error {
+		rows := make([][]sql.NullString, len(models))
+		for i, m := range models {
+			rows[i] = []sql.NullString{
+				value(m.ID),
+				value(m.Namespace),
+				value(m.DataSource),
+				value(m.RecordSource),
+				value(m.Severity),
+				m.URLs.NullString,
+				value(m.Description),
+				m.Cvss.NullString,
+				m.LastModified.NullString,
+			}
+		}
+		return pw.WriteRowGroup(rows)
+	})
+	if err != nil {
+		return err
+	}
+
+	return pw.Close()
+}
+
+func value(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: true}
+}
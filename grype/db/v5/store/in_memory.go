@@ -0,0 +1,30 @@
+package store
+
+import (
+	"fmt"
+
+	v5 "github.com/anchore/grype/grype/db/v5"
+)
+
+// NewInMemory creates a new, empty writable store backed by an in-memory sqlite DB (e.g. for tests or short-lived
+// scans that build a DB programmatically). Nothing is written to disk: closing the store only closes the connection
+// (no VACUUM is performed), discarding the DB. Note that the WAL journal mode is not supported (see WithWAL).
+func NewInMemory(opts ...Option) (v5.Store, error) {
+	s, err := New("", true, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	st := s.(*store)
+	st.inMemory = true
+
+	sqlDB, err := st.db.DB()
+	if err != nil {
+		_ = st.closeConnection()
+		return nil, fmt.Errorf("unable to get DB connection: %w", err)
+	}
+	// each connection to ":memory:" is a separate DB, so all queries must share a single connection
+	sqlDB.SetMaxOpenConns(1)
+
+	return st, nil
+}
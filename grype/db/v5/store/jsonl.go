@@ -0,0 +1,197 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"gorm.io/gorm"
+
+	v5 "github.com/anchore/grype/grype/db/v5"
+	"github.com/anchore/grype/grype/db/v5/store/model"
+)
+
+// the record types of the lines within a JSONL export
+const (
+	jsonlIDType            = "id"
+	jsonlVulnerabilityType = "vulnerability"
+	jsonlMetadataType      = "vulnerability_metadata"
+	jsonlExclusionType     = "vulnerability_match_exclusion"
+)
+
+// jsonlLine is a single line of a JSONL export: the type of record (see the jsonl*Type constants) and the record
+// itself, encoded exactly as the v5 record types are.
+type jsonlLine struct {
+	Type   string          `json:"type"`
+	Record json.RawMessage `json:"record"`
+}
+
+// ExportJSONL writes the DB ID, all vulnerability records, all vulnerability metadata records, and all vulnerability
+// match exclusions as newline-delimited JSON, one record per line (see jsonlLine), suitable for archival or ingestion by
+// other tools. Records are read in batches, so memory is bounded by the batch size regardless of the size of the
+// database. The export can be read back into a store with ImportJSONL.
+func (s *store) ExportJSONL(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	write := func(recordType string, record any) error {
+		raw, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("unable to encode %s record: %w", recordType, err)
+		}
+		return enc.Encode(jsonlLine{Type: recordType, Record: raw})
+	}
+
+	id, err := s.GetID()
+	if err != nil {
+		return fmt.Errorf("unable to read DB ID: %w", err)
+	}
+	if id != nil {
+		if err := write(jsonlIDType, id); err != nil {
+			return err
+		}
+	}
+
+	if err := s.ForEachVulnerability(func(v v5.Vulnerability) error {
+		return write(jsonlVulnerabilityType, v)
+	}); err != nil {
+		return fmt.Errorf("unable to export vulnerabilities: %w", err)
+	}
+
+	if err := s.ForEachMetadata(func(m v5.VulnerabilityMetadata) error {
+		return write(jsonlMetadataType, m)
+	}); err != nil {
+		return fmt.Errorf("unable to export vulnerability metadata: %w", err)
+	}
+
+	if err := s.forEachMatchExclusion(func(e v5.VulnerabilityMatchExclusion) error {
+		return write(jsonlExclusionType, e)
+	}); err != nil {
+		return fmt.Errorf("unable to export vulnerability match exclusions: %w", err)
+	}
+
+	return bw.Flush()
+}
+
+// forEachMatchExclusion calls the given function with every vulnerability match exclusion in the database (in primary
+// key order), reading records in batches to keep memory bounded. Exclusions which are not usable by this version of
+// grype are skipped.
+func (s *store) forEachMatchExclusion(fn func(v5.VulnerabilityMatchExclusion) error) error {
+	var models []model.VulnerabilityMatchExclusionModel
+	result := s.db.Order("pk").FindInBatches(&models, iterationBatchSize, func(_ *gorm.DB, _ int) error {
+		for _, m := range models {
+			exclusion, err := m.Inflate()
+			if err != nil {
+				return err
+			}
+			if exclusion == nil {
+				continue
+			}
+			if err := fn(*exclusion); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return result.Error
+}
+
+// ImportJSONL reads records written by ExportJSONL into the store, writing records in batches as they are read (so
+// memory is bounded by the batch size regardless of the size of the export). Blank lines are ignored, while lines with
+// an unknown record type are an error.
+func (s *store) ImportJSONL(r io.Reader) error {
+	var (
+		vulns      []v5.Vulnerability
+		metadata   []v5.VulnerabilityMetadata
+		exclusions []v5.VulnerabilityMatchExclusion
+	)
+
+	flush := func() error {
+		if len(vulns) > 0 {
+			if err := s.AddVulnerability(vulns...); err != nil {
+				return fmt.Errorf("unable to import vulnerabilities: %w", err)
+			}
+			vulns = vulns[:0]
+		}
+		if len(metadata) > 0 {
+			if _, err := s.AddVulnerabilityMetadata(metadata...); err != nil {
+				return fmt.Errorf("unable to import vulnerability metadata: %w", err)
+			}
+			metadata = metadata[:0]
+		}
+		if len(exclusions) > 0 {
+			if err := s.AddVulnerabilityMatchExclusion(exclusions...); err != nil {
+				return fmt.Errorf("unable to import vulnerability match exclusions: %w", err)
+			}
+			exclusions = exclusions[:0]
+		}
+		return nil
+	}
+
+	br := bufio.NewReader(r)
+	for lineNumber := 1; ; lineNumber++ {
+		// note: lines are read without a length limit since records (e.g. descriptions) may be arbitrarily large
+		raw, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("unable to read line %d: %w", lineNumber, err)
+		}
+		eof := err != nil
+
+		if raw = bytes.TrimSpace(raw); len(raw) > 0 {
+			if err := s.importJSONLLine(raw, &vulns, &metadata, &exclusions); err != nil {
+				return fmt.Errorf("unable to import line %d: %w", lineNumber, err)
+			}
+		}
+
+		if eof {
+			break
+		}
+		if len(vulns)+len(metadata)+len(exclusions) >= iterationBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return flush()
+}
+
+func (s *store) importJSONLLine(raw []byte, vulns *[]v5.Vulnerability, metadata *[]v5.VulnerabilityMetadata, exclusions *[]v5.VulnerabilityMatchExclusion) error {
+	var line jsonlLine
+	if err := json.Unmarshal(raw, &line); err != nil {
+		return err
+	}
+
+	switch line.Type {
+	case jsonlIDType:
+		var id v5.ID
+		if err := json.Unmarshal(line.Record, &id); err != nil {
+			return err
+		}
+		return s.SetID(id)
+	case jsonlVulnerabilityType:
+		var v v5.Vulnerability
+		if err := json.Unmarshal(line.Record, &v); err != nil {
+			return err
+		}
+		*vulns = append(*vulns, v)
+	case jsonlMetadataType:
+		var m v5.VulnerabilityMetadata
+		if err := json.Unmarshal(line.Record, &m); err != nil {
+			return err
+		}
+		*metadata = append(*metadata, m)
+	case jsonlExclusionType:
+		var e v5.VulnerabilityMatchExclusion
+		if err := json.Unmarshal(line.Record, &e); err != nil {
+			return err
+		}
+		*exclusions = append(*exclusions, e)
+	default:
+		return fmt.Errorf("unknown record type %q", line.Type)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+
+	v5 "github.com/anchore/grype/grype/db/v5"
+	"github.com/anchore/grype/grype/db/v5/store/model"
+)
+
+// Manifest summarizes the DB contents: the ID, record counts per namespace, the severity distribution of metadata
+// records, the DB size, and a fingerprint over all vulnerability and metadata records.
+func (s *store) Manifest() (v5.DBManifest, error) {
+	var manifest v5.DBManifest
+
+	id, err := s.GetID()
+	if err != nil {
+		return manifest, fmt.Errorf("unable to fetch DB ID: %w", err)
+	}
+	if id != nil {
+		manifest.SchemaVersion = id.SchemaVersion
+		manifest.BuildTimestamp = id.BuildTimestamp.UTC().Format(time.RFC3339Nano)
+	}
+
+	if manifest.Namespaces, err = s.namespaceManifests(); err != nil {
+		return manifest, err
+	}
+
+	if manifest.Severities, err = s.severityCounts(); err != nil {
+		return manifest, err
+	}
+
+	if manifest.SizeBytes, err = s.size(); err != nil {
+		return manifest, err
+	}
+
+	digests, err := s.recordDigests()
+	if err != nil {
+		return manifest, fmt.Errorf("unable to digest records: %w", err)
+	}
+	manifest.Fingerprint = fingerprintDigests(digests)
+
+	return manifest, nil
+}
+
+type namespaceCount struct {
+	Namespace string
+	Count     int
+}
+
+func (s *store) namespaceManifests() ([]v5.NamespaceManifest, error) {
+	var vulnCounts, metadataCounts []namespaceCount
+
+	if result := s.db.Model(&model.VulnerabilityModel{}).Select("namespace, COUNT(*) AS count").Group("namespace").Scan(&vulnCounts); result.Error != nil {
+		return nil, fmt.Errorf("unable to count vulnerabilities: %w", result.Error)
+	}
+
+	if result := s.db.Model(&model.VulnerabilityMetadataModel{}).Select("namespace, COUNT(*) AS count").Group("namespace").Scan(&metadataCounts); result.Error != nil {
+		return nil, fmt.Errorf("unable to count vulnerability metadata: %w", result.Error)
+	}
+
+	byNamespace := make(map[string]*v5.NamespaceManifest)
+	get := func(namespace string) *v5.NamespaceManifest {
+		if _, ok := byNamespace[namespace]; !ok {
+			byNamespace[namespace] = &v5.NamespaceManifest{Namespace: namespace}
+		}
+		return byNamespace[namespace]
+	}
+	for _, c := range vulnCounts {
+		get(c.Namespace).Vulnerabilities = c.Count
+	}
+	for _, c := range metadataCounts {
+		get(c.Namespace).Metadata = c.Count
+	}
+
+	manifests := make([]v5.NamespaceManifest, 0, len(byNamespace))
+	for _, m := range byNamespace {
+		manifests = append(manifests, *m)
+	}
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].Namespace < manifests[j].Namespace
+	})
+
+	return manifests, nil
+}
+
+// severityCounts counts metadata records by lowercase severity, where records without a severity are counted as "unknown".
+func (s *store) severityCounts() (map[string]int, error) {
+	const severity = "COALESCE(NULLIF(lower(severity), ''), 'unknown')"
+
+	var rows []struct {
+		Severity string
+		Count    int
+	}
+
+	result := s.db.Model(&model.VulnerabilityMetadataModel{}).
+		Select(severity + " AS severity, COUNT(*) AS count").
+		Group(severity).
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, fmt.Errorf("unable to count severities: %w", result.Error)
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, r := range rows {
+		counts[r.Severity] = r.Count
+	}
+	return counts, nil
+}
+
+// size returns the size of the DB in bytes.
+func (s *store) size() (int64, error) {
+	var pageCount, pageSize int64
+	if err := s.db.Raw("PRAGMA page_count").Scan(&pageCount).Error; err != nil {
+		return 0, fmt.Errorf("unable to determine DB page count: %w", err)
+	}
+	if err := s.db.Raw("PRAGMA page_size").Scan(&pageSize).Error; err != nil {
+		return 0, fmt.Errorf("unable to determine DB page size: %w", err)
+	}
+	return pageCount * pageSize, nil
+}
+
+// fingerprintDigests combines the (sorted) record digests into a single content digest.
+func fingerprintDigests(digests []recordDigest) string {
+	h := sha256.New()
+	buf := make([]byte, 8)
+	for _, d := range digests {
+		_, _ = h.Write([]byte(d.key.id))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(d.key.namespace))
+		_, _ = h.Write([]byte{0})
+		binary.BigEndian.PutUint64(buf, d.digest)
+		_, _ = h.Write(buf)
+	}
+	return fmt.Sprintf("sha256:%x", h.Sum(nil))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	sqlite "github.com/anchore/grype/grype/db/internal/sqlite"
 	v5 "github.com/anchore/grype/grype/db/v5"
 )
 
@@ -12,14 +13,16 @@ const (
 )
 
 type IDModel struct {
-	BuildTimestamp string `gorm:"column:build_timestamp"`
-	SchemaVersion  int    `gorm:"column:schema_version"`
+	BuildTimestamp string            `gorm:"column:build_timestamp"`
+	SchemaVersion  int               `gorm:"column:schema_version"`
+	Checksum       sqlite.NullString `gorm:"column:checksum; default:null"`
 }
 
 func NewIDModel(id v5.ID) IDModel {
 	return IDModel{
 		BuildTimestamp: id.BuildTimestamp.Format(time.RFC3339Nano),
 		SchemaVersion:  id.SchemaVersion,
+		Checksum:       sqlite.NewNullString(id.Checksum, id.Checksum != ""),
 	}
 }
 
@@ -36,5 +39,6 @@ func (m *IDModel) Inflate() (v5.ID, error) {
 	return v5.ID{
 		BuildTimestamp: buildTime,
 		SchemaVersion:  m.SchemaVersion,
+		Checksum:       m.Checksum.String,
 	}, nil
 }
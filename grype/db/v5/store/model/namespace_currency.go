@@ -0,0 +1,39 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	NamespaceCurrencyTableName = "namespace_currency"
+)
+
+// NamespaceCurrencyModel is a struct used to serialize when the data for a namespace was current (i.e. fetched from
+// the upstream data source) into a sqlite3 DB. The timestamp uses the same fixed width layout as last_modified columns,
+// so values can be compared as strings.
+type NamespaceCurrencyModel struct {
+	Namespace string `gorm:"primary_key; column:namespace;"`
+	AsOf      string `gorm:"column:as_of"`
+}
+
+func NewNamespaceCurrencyModel(namespace string, asOf time.Time) NamespaceCurrencyModel {
+	return NamespaceCurrencyModel{
+		Namespace: namespace,
+		AsOf:      asOf.UTC().Format(lastModifiedLayout),
+	}
+}
+
+// TableName returns the table which all namespace currency model instances are stored into.
+func (NamespaceCurrencyModel) TableName() string {
+	return NamespaceCurrencyTableName
+}
+
+// Inflate returns the time the namespace data was current as of.
+func (m *NamespaceCurrencyModel) Inflate() (time.Time, error) {
+	asOf, err := time.Parse(lastModifiedLayout, m.AsOf)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse namespace currency timestamp (%+v): %w", m.AsOf, err)
+	}
+	return asOf, nil
+}
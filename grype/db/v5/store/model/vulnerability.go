@@ -3,6 +3,7 @@ package model
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	sqlite "github.com/anchore/grype/grype/db/internal/sqlite"
 	v5 "github.com/anchore/grype/grype/db/v5"
@@ -28,11 +29,16 @@ type VulnerabilityModel struct {
 	FixedInVersions        sqlite.NullString `gorm:"column:fixed_in_versions; default:null"`
 	FixState               string            `gorm:"column:fix_state"`
 	Advisories             sqlite.NullString `gorm:"column:advisories; default:null"`
+	ProvenanceSourceName   sqlite.NullString `gorm:"column:provenance_source_name; default:null"`
+	ProvenanceSourceURL    sqlite.NullString `gorm:"column:provenance_source_url; default:null"`
+	ProvenanceFetchedAt    sqlite.NullString `gorm:"column:provenance_fetched_at; default:null"`
+	LastModified           sqlite.NullString `gorm:"column:last_modified; default:null"`
 }
 
-// NewVulnerabilityModel generates a new model from a db.Vulnerability struct.
+// NewVulnerabilityModel generates a new model from a db.Vulnerability struct. The model has no modification time, which
+// is stamped by the store when the record is written.
 func NewVulnerabilityModel(vulnerability v5.Vulnerability) VulnerabilityModel {
-	return VulnerabilityModel{
+	m := VulnerabilityModel{
 		ID:                     vulnerability.ID,
 		PackageName:            vulnerability.PackageName,
 		Namespace:              vulnerability.Namespace,
@@ -45,6 +51,20 @@ func NewVulnerabilityModel(vulnerability v5.Vulnerability) VulnerabilityModel {
 		CPEs:                   sqlite.ToNullString(vulnerability.CPEs),
 		RelatedVulnerabilities: sqlite.ToNullString(vulnerability.RelatedVulnerabilities),
 	}
+
+	if p := vulnerability.Provenance; p != nil {
+		m.ProvenanceSourceName = sqlite.NewNullString(p.SourceName, true)
+		m.ProvenanceSourceURL = sqlite.NewNullString(p.SourceURL, true)
+		m.ProvenanceFetchedAt = sqlite.NewNullString(p.FetchedAt.UTC().Format(time.RFC3339Nano), true)
+	}
+
+	return m
+}
+
+// GetVulnerabilityIndexStatement returns the statement to create the (package_name, namespace) vulnerability index,
+// matching the index created by migrations for the VulnerabilityModel.
+func GetVulnerabilityIndexStatement() string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (package_name, namespace)", GetVulnerabilityIndexName, VulnerabilityTableName)
 }
 
 // TableName returns the table which all db.Vulnerability model instances are stored into.
@@ -84,6 +104,11 @@ func (m *VulnerabilityModel) Inflate() (v5.Vulnerability, error) {
 		return v5.Vulnerability{}, fmt.Errorf("unable to unmarshal package_qualifiers (%+v): %w", m.PackageQualifiers, err)
 	}
 
+	provenance, err := m.inflateProvenance()
+	if err != nil {
+		return v5.Vulnerability{}, err
+	}
+
 	return v5.Vulnerability{
 		ID:                     m.ID,
 		PackageName:            m.PackageName,
@@ -98,5 +123,29 @@ func (m *VulnerabilityModel) Inflate() (v5.Vulnerability, error) {
 			State:    v5.FixState(m.FixState),
 		},
 		Advisories: advisories,
+		Provenance: provenance,
+	}, nil
+}
+
+// inflateProvenance generates a db.Provenance object from the serialized model instance, which is only present
+// for databases that were built with provenance information.
+func (m *VulnerabilityModel) inflateProvenance() (*v5.Provenance, error) {
+	if !m.ProvenanceSourceName.Valid && !m.ProvenanceSourceURL.Valid && !m.ProvenanceFetchedAt.Valid {
+		return nil, nil
+	}
+
+	var fetchedAt time.Time
+	if m.ProvenanceFetchedAt.Valid && m.ProvenanceFetchedAt.String != "" {
+		var err error
+		fetchedAt, err = time.Parse(time.RFC3339Nano, m.ProvenanceFetchedAt.String)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse provenance fetch timestamp (%+v): %w", m.ProvenanceFetchedAt, err)
+		}
+	}
+
+	return &v5.Provenance{
+		SourceName: m.ProvenanceSourceName.String,
+		SourceURL:  m.ProvenanceSourceURL.String,
+		FetchedAt:  fetchedAt,
 	}, nil
 }
@@ -1,15 +1,34 @@
 package model
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-test/deep"
 
 	sqlite "github.com/anchore/grype/grype/db/internal/sqlite"
 	v5 "github.com/anchore/grype/grype/db/v5"
+	intCvss "github.com/anchore/grype/internal/cvss"
+	"github.com/anchore/grype/internal/log"
 )
 
 const (
 	VulnerabilityMetadataTableName = "vulnerability_metadata"
+
+	// HighSeverityMetadataIndexName is a partial index over only the Critical and High severity metadata records,
+	// which are the records of interest when gating on severity.
+	HighSeverityMetadataIndexName = "high_severity_metadata_index"
+
+	// HighSeverityCondition is the predicate for the high severity partial index. Queries must include this exact
+	// predicate for the query planner to consider the partial index.
+	HighSeverityCondition = "severity IN ('Critical','High')"
+
+	// lastModifiedLayout is a fixed-width UTC timestamp layout, allowing for last_modified values to be ordered lexically
+	lastModifiedLayout = "2006-01-02T15:04:05.000000000Z"
 )
 
 // VulnerabilityMetadataModel is a struct used to serialize db.VulnerabilityMetadata information into a sqlite3 DB.
@@ -22,12 +41,16 @@ type VulnerabilityMetadataModel struct {
 	URLs         sqlite.NullString `gorm:"column:urls; default:null"`
 	Description  string            `gorm:"column:description"`
 	Cvss         sqlite.NullString `gorm:"column:cvss; default:null"`
+	LastModified sqlite.NullString `gorm:"column:last_modified; default:null"`
 }
 
-// NewVulnerabilityMetadataModel generates a new model from a db.VulnerabilityMetadata struct.
+// NewVulnerabilityMetadataModel generates a new model from a db.VulnerabilityMetadata struct. The model has no
+// modification time, which is stamped by the store when the record is written.
 func NewVulnerabilityMetadataModel(metadata v5.VulnerabilityMetadata) VulnerabilityMetadataModel {
-	if metadata.Cvss == nil {
-		metadata.Cvss = make([]v5.Cvss, 0)
+	// adjusted scores are always computed on read from the persisted vectors
+	cvss := make([]v5.Cvss, 0, len(metadata.Cvss))
+	for _, c := range metadata.Cvss {
+		cvss = append(cvss, c.WithoutAdjustedScores())
 	}
 
 	return VulnerabilityMetadataModel{
@@ -38,10 +61,20 @@ func NewVulnerabilityMetadataModel(metadata v5.VulnerabilityMetadata) Vulnerabil
 		Severity:     metadata.Severity,
 		URLs:         sqlite.ToNullString(metadata.URLs),
 		Description:  metadata.Description,
-		Cvss:         sqlite.ToNullString(metadata.Cvss),
+		Cvss:         sqlite.ToNullString(cvss),
 	}
 }
 
+// FormatLastModified formats the given time as a last_modified column value.
+func FormatLastModified(t time.Time) string {
+	return t.UTC().Format(lastModifiedLayout)
+}
+
+// HighSeverityMetadataIndexStatement returns the statement to create the high severity partial index.
+func HighSeverityMetadataIndexStatement() string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (namespace, id) WHERE %s", HighSeverityMetadataIndexName, VulnerabilityMetadataTableName, HighSeverityCondition)
+}
+
 // TableName returns the table which all db.VulnerabilityMetadata model instances are stored into.
 func (VulnerabilityMetadataModel) TableName() string {
 	return VulnerabilityMetadataTableName
@@ -49,6 +82,34 @@ func (VulnerabilityMetadataModel) TableName() string {
 
 // Inflate generates a db.VulnerabilityMetadataModel object from the serialized model instance.
 func (m *VulnerabilityMetadataModel) Inflate() (v5.VulnerabilityMetadata, error) {
+	metadata, err := m.InflateStored()
+	if err != nil {
+		return v5.VulnerabilityMetadata{}, err
+	}
+
+	// note: older DBs may contain duplicate CVSS entries (from before duplicates were detected on write), which are
+	// only removed from the returned value (the stored record is unchanged)
+	cvss := dedupCvss(metadata.Cvss)
+
+	for idx := range cvss {
+		c := &cvss[idx]
+		temporal, environmental, err := intCvss.ParseAdjustedScores(c.Vector, c.TemporalVector, c.EnvironmentalVector)
+		if err != nil {
+			log.WithFields("id", m.ID, "namespace", m.Namespace, "vector", c.Vector, "error", err).Debug("unable to compute adjusted CVSS scores")
+			continue
+		}
+		c.Metrics.TemporalScore = temporal
+		c.Metrics.EnvironmentalScore = environmental
+	}
+
+	metadata.Cvss = cvss
+	return metadata, nil
+}
+
+// InflateStored generates a db.VulnerabilityMetadata object from the serialized model instance exactly as stored: CVSS
+// entries are neither deduplicated nor sorted, and no adjusted scores are computed (see Inflate). This is intended for
+// rewriting stored records (e.g. merging), so the normalization applied on read is not persisted.
+func (m *VulnerabilityMetadataModel) InflateStored() (v5.VulnerabilityMetadata, error) {
 	var links []string
 	var cvss []v5.Cvss
 
@@ -72,3 +133,87 @@ func (m *VulnerabilityMetadataModel) Inflate() (v5.VulnerabilityMetadata, error)
 		Cvss:         cvss,
 	}, nil
 }
+
+// NewMergedVulnerabilityMetadataModel generates a new model for a record merged into the given stored model, where the
+// stored CVSS entries are retained as-is and only the CVSS entries appended by the merge are serialized. The merged
+// record must be based on the stored record as returned by InflateStored.
+func NewMergedVulnerabilityMetadataModel(stored VulnerabilityMetadataModel, merged v5.VulnerabilityMetadata) (VulnerabilityMetadataModel, error) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(stored.Cvss.ToByteSlice(), &entries); err != nil {
+		return VulnerabilityMetadataModel{}, fmt.Errorf("unable to unmarshal cvss data (%+v): %w", stored.Cvss, err)
+	}
+	if len(merged.Cvss) < len(entries) {
+		return VulnerabilityMetadataModel{}, fmt.Errorf("merged metadata (id=%q namespace=%q) is missing stored cvss entries", merged.ID, merged.Namespace)
+	}
+
+	m := NewVulnerabilityMetadataModel(merged)
+	if len(merged.Cvss) == len(entries) {
+		m.Cvss = stored.Cvss
+		return m, nil
+	}
+
+	for _, c := range merged.Cvss[len(entries):] {
+		by, err := json.Marshal(c.WithoutAdjustedScores())
+		if err != nil {
+			return VulnerabilityMetadataModel{}, fmt.Errorf("unable to marshal cvss data: %w", err)
+		}
+		entries = append(entries, by)
+	}
+	m.Cvss = sqlite.ToNullString(entries)
+	return m, nil
+}
+
+// NormalizeCvss returns the identity of the given CVSS entry for the purposes of deduplication: the computed adjusted
+// scores are dropped (the vectors are part of the identity instead), the vector is put in canonical metric order, and
+// the vendor metadata is reduced to its JSON form (with sorted keys), which is how it reads back from the store.
+func NormalizeCvss(c v5.Cvss) v5.Cvss {
+	c = c.WithoutAdjustedScores()
+
+	if c.Vector != "" {
+		if vector, err := intCvss.CanonicalVector(c.Vector); err == nil {
+			c.Vector = vector
+		}
+	}
+
+	if c.VendorMetadata != nil {
+		if by, err := json.Marshal(c.VendorMetadata); err == nil {
+			var vendorMetadata interface{}
+			if err := json.Unmarshal(by, &vendorMetadata); err == nil {
+				c.VendorMetadata = vendorMetadata
+			}
+		}
+	}
+
+	return c
+}
+
+// dedupCvss removes CVSS entries with the same identity as an earlier entry (see NormalizeCvss) and stably sorts the
+// remaining entries by version, type, source, and vector.
+func dedupCvss(cvss []v5.Cvss) []v5.Cvss {
+	if len(cvss) == 0 {
+		return cvss
+	}
+
+	var result []v5.Cvss
+	var identities []v5.Cvss
+	for _, c := range cvss {
+		identity := NormalizeCvss(c)
+		if slices.ContainsFunc(identities, func(existing v5.Cvss) bool {
+			return len(deep.Equal(identity, existing)) == 0
+		}) {
+			continue
+		}
+		identities = append(identities, identity)
+		result = append(result, c)
+	}
+
+	slices.SortStableFunc(result, func(a, b v5.Cvss) int {
+		return cmp.Or(
+			strings.Compare(a.Version, b.Version),
+			strings.Compare(a.Type, b.Type),
+			strings.Compare(a.Source, b.Source),
+			strings.Compare(a.Vector, b.Vector),
+		)
+	})
+	return result
+}
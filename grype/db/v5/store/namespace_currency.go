@@ -0,0 +1,58 @@
+package store
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/anchore/grype/grype/db/v5/store/model"
+)
+
+// GetNamespaceCurrency retrieves when the data for each namespace was current as of, which may be considerably older
+// than the DB build timestamp (e.g. when an upstream data source could not be refreshed). Namespaces without a
+// recorded currency are not included. DBs built before namespace currency was recorded return an empty map.
+func (s *store) GetNamespaceCurrency() (map[string]time.Time, error) {
+	currency := make(map[string]time.Time)
+	if !s.db.Migrator().HasTable(&model.NamespaceCurrencyModel{}) {
+		return currency, nil
+	}
+
+	var models []model.NamespaceCurrencyModel
+	if result := s.db.Find(&models); result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, m := range models {
+		asOf, err := m.Inflate()
+		if err != nil {
+			return nil, err
+		}
+		currency[m.Namespace] = asOf
+	}
+	return currency, nil
+}
+
+// SetNamespaceCurrency records when the data for the given namespace was current as of, replacing any existing value.
+// Note that adding vulnerabilities with provenance information already records the namespace currency (as the most
+// recent fetch time), so this is only needed to override that value or when there is no provenance information.
+func (s *store) SetNamespaceCurrency(namespace string, asOf time.Time) error {
+	s.dirty = true
+
+	m := model.NewNamespaceCurrencyModel(namespace, asOf)
+	return s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&m).Error
+}
+
+// advanceNamespaceCurrency records the given time as the currency of the namespace, unless a more recent time has
+// already been recorded.
+func advanceNamespaceCurrency(db *gorm.DB, namespace string, asOf time.Time) error {
+	m := model.NewNamespaceCurrencyModel(namespace, asOf)
+	// note: timestamps are fixed width and normalized to UTC, so can be compared as strings
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "namespace"}},
+		DoUpdates: clause.Set{{
+			Column: clause.Column{Name: "as_of"},
+			Value:  gorm.Expr("MAX(as_of, excluded.as_of)"),
+		}},
+	}).Create(&m).Error
+}
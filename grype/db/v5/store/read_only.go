@@ -0,0 +1,40 @@
+package store
+
+import (
+	"github.com/anchore/grype/grype/db/internal/gormadapter"
+	v5 "github.com/anchore/grype/grype/db/v5"
+)
+
+// readOnlyStore only exposes the read operations of a store, so cannot be used (or type asserted) as a writer.
+type readOnlyStore struct {
+	v5.StoreReader
+}
+
+// NewReadOnly opens an existing DB for reading only (e.g. from a read-only volume). The sqlite connection is opened in
+// read-only mode, and closing the store only closes the connection (no VACUUM or other writes are performed).
+func NewReadOnly(dbFilePath string) (v5.StoreReader, error) {
+	db, err := gormadapter.Open(dbFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := prepareDB(db); err != nil {
+		return nil, err
+	}
+
+	return readOnlyStore{
+		StoreReader: &store{
+			db:       db,
+			readOnly: true,
+		},
+	}, nil
+}
+
+// asStore returns the store backing the given reader, if it is backed by this package's store.
+func asStore(reader v5.StoreReader) (*store, bool) {
+	if r, ok := reader.(readOnlyStore); ok {
+		reader = r.StoreReader
+	}
+	s, ok := reader.(*store)
+	return s, ok
+}
@@ -1,16 +1,25 @@
 package store
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
+	"time"
 
 	_ "github.com/glebarez/sqlite" // provide the sqlite dialect to gorm via import
 	"github.com/go-test/deep"
+	"github.com/scylladb/go-set/strset"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/anchore/grype/grype/db/internal/gormadapter"
+	"github.com/anchore/grype/grype/db/internal/sqlite"
 	v5 "github.com/anchore/grype/grype/db/v5"
 	"github.com/anchore/grype/grype/db/v5/store/model"
+	"github.com/anchore/grype/grype/version"
+	intCvss "github.com/anchore/grype/internal/cvss"
 	"github.com/anchore/grype/internal/log"
 	"github.com/anchore/grype/internal/stringutil"
 )
@@ -18,6 +27,37 @@ import (
 // store holds an instance of the database connection
 type store struct {
 	db *gorm.DB
+	// asOf is the time scope when this is a time-scoped view of the store (see AsOf)
+	asOf *asOfScope
+	// insertBatchSize is the number of vulnerability records inserted per statement (see WithInsertBatchSize)
+	insertBatchSize int
+	// readOnly indicates the DB connection cannot be written to (see NewReadOnly)
+	readOnly bool
+	// dirty indicates the DB may have been written to since it was opened (or last vacuumed)
+	dirty bool
+	// closeConfig describes the PRAGMAs applied before vacuuming (see WithCloseConfig)
+	closeConfig CloseConfig
+	// mergePolicy describes how conflicting metadata is resolved by AddVulnerabilityMetadata (see WithMetadataMergePolicy)
+	mergePolicy MergePolicy
+	// updateSeverity indicates incoming metadata severities replace existing severities (see WithSeverityUpdates)
+	updateSeverity bool
+	// preserveURLOrder indicates merged metadata URLs keep the order they were added in (see WithPreservedURLOrder)
+	preserveURLOrder bool
+	// wal indicates the DB is in the write-ahead log journal mode, which must be reset before vacuuming (see WithWAL)
+	wal bool
+	// inMemory indicates the DB is not backed by a file, so is never vacuumed (see NewInMemory)
+	inMemory bool
+	// transaction indicates this is the view of the store within a transaction, so cannot be closed or vacuumed (see
+	// WithTransaction)
+	transaction bool
+	// lastModified is the (formatted) modification time stamped on written vulnerability and metadata records, which is
+	// the build time of the DB (see WithBuildTime and SetID); records are not stamped when empty
+	lastModified string
+	// fixedBuildTime indicates the modification time was given by WithBuildTime, so is not replaced by SetID
+	fixedBuildTime bool
+	// view indicates this is a view of another store (see AsOf and WithContext), which shares the DB connection of the
+	// other store, so closing the view does nothing
+	view bool
 }
 
 func models() []any {
@@ -26,19 +66,337 @@ func models() []any {
 		model.VulnerabilityModel{},
 		model.VulnerabilityMetadataModel{},
 		model.VulnerabilityMatchExclusionModel{},
+		model.NamespaceCurrencyModel{},
+	}
+}
+
+// defaultInsertBatchSize is the number of vulnerability records inserted per statement when none is configured.
+const defaultInsertBatchSize = 1000
+
+type config struct {
+	highSeverityIndex bool
+	insertBatchSize   int
+	closeConfig       CloseConfig
+	mergePolicy       MergePolicy
+	updateSeverity    bool
+	preserveURLOrder  bool
+	wal               bool
+	openTimeout       time.Duration
+	buildTime         time.Time
+}
+
+// MergePolicy describes how AddVulnerabilityMetadata resolves a conflicting severity or description when merging an
+// incoming metadata record into an existing record (CVSS entries and URLs are always merged).
+type MergePolicy string
+
+const (
+	// MergePolicyError fails the merge on any conflict (the default).
+	MergePolicyError MergePolicy = "error"
+	// MergePolicyKeepExisting retains the existing severity and description.
+	MergePolicyKeepExisting MergePolicy = "keep-existing"
+	// MergePolicyPreferIncoming replaces the existing severity and description with the incoming values.
+	MergePolicyPreferIncoming MergePolicy = "prefer-incoming"
+)
+
+// CloseConfig describes the PRAGMAs applied before vacuuming the DB (see Vacuum). Zero values use the defaults (see
+// DefaultCloseConfig), which favor a small memory footprint over speed.
+type CloseConfig struct {
+	// CacheSize is the page cache size (PRAGMA cache_size), where negative values are in KiB and positive values are a
+	// number of pages
+	CacheSize int
+	// MmapSize is the maximum number of bytes of the DB file to memory map (PRAGMA mmap_size)
+	MmapSize int64
+	// TempStore is where temporary tables and indices are stored (PRAGMA temp_store), e.g. FILE or MEMORY
+	TempStore string
+	// JournalMode is the rollback journal mode (PRAGMA journal_mode), e.g. TRUNCATE or MEMORY
+	JournalMode string
+}
+
+// DefaultCloseConfig returns the PRAGMA values applied before vacuuming when none are configured.
+func DefaultCloseConfig() CloseConfig {
+	return CloseConfig{
+		CacheSize:   -32768,     // 32MB
+		MmapSize:    67108864,   // 64MB
+		TempStore:   "FILE",     // use disk for temp storage
+		JournalMode: "TRUNCATE", // disk-based journal, no directory modifications
+	}
+}
+
+func (c CloseConfig) withDefaults() CloseConfig {
+	defaults := DefaultCloseConfig()
+	if c.CacheSize == 0 {
+		c.CacheSize = defaults.CacheSize
+	}
+	if c.MmapSize == 0 {
+		c.MmapSize = defaults.MmapSize
+	}
+	if c.TempStore == "" {
+		c.TempStore = defaults.TempStore
+	}
+	if c.JournalMode == "" {
+		c.JournalMode = defaults.JournalMode
+	}
+	return c
+}
+
+func (c CloseConfig) statements() []string {
+	c = c.withDefaults()
+	return []string{
+		fmt.Sprintf("PRAGMA cache_size = %d", c.CacheSize),
+		fmt.Sprintf("PRAGMA temp_store = %s", c.TempStore),
+		fmt.Sprintf("PRAGMA mmap_size = %d", c.MmapSize),
+		fmt.Sprintf("PRAGMA journal_mode = %s", c.JournalMode),
+	}
+}
+
+// Option configures how a new store is created.
+type Option func(*config)
+
+// WithHighSeverityIndex creates a partial index over Critical and High severity metadata records when building a new
+// DB, making severity gating queries (e.g. HasHighSeverityVulnerabilities) fast on large databases. This has no effect
+// when opening an existing DB.
+func WithHighSeverityIndex(enabled bool) Option {
+	return func(c *config) {
+		c.highSeverityIndex = enabled
+	}
+}
+
+// WithInsertBatchSize sets the number of vulnerability records inserted per statement by AddVulnerability (1000 by
+// default). Note that sqlite limits the number of bound parameters per statement, so very large batch sizes will fail.
+func WithInsertBatchSize(size int) Option {
+	return func(c *config) {
+		c.insertBatchSize = size
+	}
+}
+
+// WithCloseConfig sets the PRAGMAs applied before vacuuming the DB (see CloseConfig), for instance to allow a faster
+// VACUUM on hosts with plenty of memory.
+func WithCloseConfig(closeConfig CloseConfig) Option {
+	return func(c *config) {
+		c.closeConfig = closeConfig
+	}
+}
+
+// WithMetadataMergePolicy sets how AddVulnerabilityMetadata resolves severity and description conflicts with
+// existing records (MergePolicyError by default), for instance to allow a delta import to correct stale descriptions.
+func WithMetadataMergePolicy(policy MergePolicy) Option {
+	return func(c *config) {
+		c.mergePolicy = policy
+	}
+}
+
+// WithSeverityUpdates allows AddVulnerabilityMetadata to replace the severity of an existing record with the incoming
+// severity (e.g. as NVD revises a CVE once analysis completes), regardless of the merge policy. The previous severity
+// is logged. CVSS entries and URLs are merged as usual.
+func WithSeverityUpdates(enabled bool) Option {
+	return func(c *config) {
+		c.updateSeverity = enabled
+	}
+}
+
+// WithPreservedURLOrder keeps the URLs of merged metadata records in the order they were added (the existing URLs
+// followed by any new incoming URLs) instead of sorting them, so the first URL remains the primary advisory of the
+// source record. URLs are deduplicated either way.
+func WithPreservedURLOrder(enabled bool) Option {
+	return func(c *config) {
+		c.preserveURLOrder = enabled
+	}
+}
+
+// WithWAL opens a new DB in the write-ahead log journal mode, so that read transactions do not block the writer (and
+// vice versa), for instance when reading from one store while building another. The WAL is checkpointed and the
+// journal mode is reset (see CloseConfig) before vacuuming on Close, so the final DB file does not depend on a WAL file.
+// This has no effect when opening an existing DB.
+func WithWAL() Option {
+	return func(c *config) {
+		c.wal = true
+	}
+}
+
+// WithBuildTime stamps written vulnerability and metadata records with the given build time as their modification time
+// (see AsOf and GetRecentlyModifiedMetadata), so building the same input twice results in the same DB. Otherwise records
+// are stamped with the build timestamp of the DB ID once it has been set (see SetID), and are not stamped before then.
+func WithBuildTime(t time.Time) Option {
+	return func(c *config) {
+		c.buildTime = t
+	}
+}
+
+// WithOpenTimeout bounds how long New waits for a DB file that is locked (e.g. by a concurrent DB update mid-write)
+// instead of waiting indefinitely: opening is retried while the DB is locked, and an *ErrOpenTimeout is returned once
+// the timeout expires.
+func WithOpenTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.openTimeout = timeout
 	}
 }
 
 // New creates a new instance of the store.
-func New(dbFilePath string, overwrite bool) (v5.Store, error) {
-	db, err := gormadapter.Open(dbFilePath, gormadapter.WithTruncate(overwrite, models(), nil))
+func New(dbFilePath string, overwrite bool, opts ...Option) (v5.Store, error) {
+	var cfg config
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	switch cfg.mergePolicy {
+	case "", MergePolicyError, MergePolicyKeepExisting, MergePolicyPreferIncoming:
+	default:
+		return nil, fmt.Errorf("unknown metadata merge policy: %q", cfg.mergePolicy)
+	}
+
+	wal := overwrite && cfg.wal
+	db, err := gormadapter.Open(dbFilePath, gormadapter.WithTruncate(overwrite, models(), nil), gormadapter.WithWAL(wal), gormadapter.WithOpenTimeout(cfg.openTimeout))
 	if err != nil {
 		return nil, err
 	}
 
+	if err := prepareDB(db); err != nil {
+		return nil, err
+	}
+
+	if overwrite && cfg.highSeverityIndex {
+		if err := db.Exec(model.HighSeverityMetadataIndexStatement()).Error; err != nil {
+			return nil, fmt.Errorf("unable to create high severity index: %w", err)
+		}
+	}
+
+	s := &store{
+		db:               db,
+		insertBatchSize:  cfg.insertBatchSize,
+		closeConfig:      cfg.closeConfig,
+		mergePolicy:      cfg.mergePolicy,
+		updateSeverity:   cfg.updateSeverity,
+		preserveURLOrder: cfg.preserveURLOrder,
+		wal:              wal,
+		dirty:            overwrite,
+	}
+	if !cfg.buildTime.IsZero() {
+		s.lastModified = model.FormatLastModified(cfg.buildTime)
+		s.fixedBuildTime = true
+	}
+	return s, nil
+}
+
+// prepareDB validates and configures a newly opened DB connection (closing the connection on failure).
+func prepareDB(db *gorm.DB) error {
+	if err := checkSchemaVersion(db); err != nil {
+		if sqlDB, dbErr := db.DB(); dbErr == nil {
+			_ = sqlDB.Close()
+		}
+		return err
+	}
+
+	if err := registerAsOfCallbacks(db); err != nil {
+		return fmt.Errorf("unable to register DB callbacks: %w", err)
+	}
+	return nil
+}
+
+// derive returns a store with the same configuration as this store, using the given DB connection (which must be
+// derived from the DB connection of this store).
+func (s *store) derive(db *gorm.DB) *store {
 	return &store{
-		db: db,
-	}, nil
+		db:               db,
+		asOf:             s.asOf,
+		insertBatchSize:  s.insertBatchSize,
+		readOnly:         s.readOnly,
+		closeConfig:      s.closeConfig,
+		mergePolicy:      s.mergePolicy,
+		updateSeverity:   s.updateSeverity,
+		preserveURLOrder: s.preserveURLOrder,
+		wal:              s.wal,
+		inMemory:         s.inMemory,
+		transaction:      s.transaction,
+		lastModified:     s.lastModified,
+		fixedBuildTime:   s.fixedBuildTime,
+		view:             s.view,
+	}
+}
+
+// newView returns a view of this store using the given DB connection (see derive), which never closes the connection.
+func (s *store) newView(db *gorm.DB) *store {
+	v := s.derive(db)
+	v.view = true
+	return v
+}
+
+// newVulnerabilityModel creates the model for a vulnerability record to be written, stamped with the modification time.
+func (s *store) newVulnerabilityModel(vulnerability v5.Vulnerability) model.VulnerabilityModel {
+	m := model.NewVulnerabilityModel(vulnerability)
+	m.LastModified = s.lastModifiedValue()
+	return m
+}
+
+// newMetadataModel creates the model for a vulnerability metadata record to be written, stamped with the modification
+// time.
+func (s *store) newMetadataModel(metadata v5.VulnerabilityMetadata) model.VulnerabilityMetadataModel {
+	m := model.NewVulnerabilityMetadataModel(metadata)
+	m.LastModified = s.lastModifiedValue()
+	return m
+}
+
+// newMergedMetadataModel creates the model of a record merged into the given stored model, stamped with the modification
+// time of written records (see model.NewMergedVulnerabilityMetadataModel).
+func (s *store) newMergedMetadataModel(stored model.VulnerabilityMetadataModel, merged v5.VulnerabilityMetadata) (model.VulnerabilityMetadataModel, error) {
+	m, err := model.NewMergedVulnerabilityMetadataModel(stored, merged)
+	if err != nil {
+		return m, err
+	}
+	m.LastModified = s.lastModifiedValue()
+	return m, nil
+}
+
+func (s *store) lastModifiedValue() sqlite.NullString {
+	return sqlite.NewNullString(s.lastModified, s.lastModified != "")
+}
+
+func (s *store) batchSize() int {
+	if s.insertBatchSize <= 0 {
+		return defaultInsertBatchSize
+	}
+	return s.insertBatchSize
+}
+
+var (
+	// ErrMultipleIDs is returned when the DB has more than one ID record, indicating a corrupt DB.
+	ErrMultipleIDs = errors.New("found multiple DB IDs")
+	// ErrMultipleMetadata is returned when the DB has more than one metadata record for a vulnerability ID within a
+	// namespace, indicating a corrupt DB.
+	ErrMultipleMetadata = errors.New("found multiple metadatas")
+)
+
+// ErrOpenTimeout is returned by New when the DB remains locked for longer than the timeout given with WithOpenTimeout.
+type ErrOpenTimeout = gormadapter.ErrOpenTimeout
+
+// ErrSchemaMismatch is returned when opening a DB that was built for a different schema version than this store
+// supports (e.g. a v4 DB).
+type ErrSchemaMismatch struct {
+	Expected int
+	Got      int
+}
+
+func (e *ErrSchemaMismatch) Error() string {
+	return fmt.Sprintf("unsupported DB schema version: expected %d, got %d", e.Expected, e.Got)
+}
+
+// checkSchemaVersion ensures the schema version recorded in the DB ID matches this store. DBs without an ID (e.g. a
+// newly created DB) are not checked.
+func checkSchemaVersion(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&model.IDModel{}) {
+		return nil
+	}
+
+	var models []model.IDModel
+	if result := db.Find(&models); result.Error != nil {
+		return fmt.Errorf("unable to read DB ID: %w", result.Error)
+	}
+
+	for _, m := range models {
+		if m.SchemaVersion != v5.SchemaVersion {
+			return &ErrSchemaMismatch{Expected: v5.SchemaVersion, Got: m.SchemaVersion}
+		}
+	}
+	return nil
 }
 
 // GetID fetches the metadata about the databases schema version and build time.
@@ -51,7 +409,7 @@ func (s *store) GetID() (*v5.ID, error) {
 
 	switch {
 	case len(models) > 1:
-		return nil, fmt.Errorf("found multiple DB IDs")
+		return nil, fmt.Errorf("%w (%d rows)", ErrMultipleIDs, len(models))
 	case len(models) == 1:
 		id, err := models[0].Inflate()
 		if err != nil {
@@ -63,8 +421,11 @@ func (s *store) GetID() (*v5.ID, error) {
 	return nil, nil
 }
 
-// SetID stores the databases schema version and build time.
+// SetID stores the databases schema version and build time. Unless a build time was given with WithBuildTime, records
+// written afterward are stamped with the build time as their modification time.
 func (s *store) SetID(id v5.ID) error {
+	s.dirty = true
+
 	var ids []model.IDModel
 
 	// replace the existing ID with the given one
@@ -77,9 +438,26 @@ func (s *store) SetID(id v5.ID) error {
 		return fmt.Errorf("unable to add id (%d rows affected)", result.RowsAffected)
 	}
 
+	if result.Error == nil && !s.fixedBuildTime && !id.BuildTimestamp.IsZero() {
+		s.lastModified = model.FormatLastModified(id.BuildTimestamp)
+	}
+
 	return result.Error
 }
 
+// WriteIDFile writes the databases schema version and build time as JSON to the given path, allowing for the DB
+// freshness to be checked without opening the DB.
+func (s *store) WriteIDFile(path string) error {
+	id, err := s.GetID()
+	if err != nil {
+		return fmt.Errorf("unable to fetch DB ID: %w", err)
+	}
+	if id == nil {
+		return fmt.Errorf("no DB ID found")
+	}
+	return id.WriteIDFile(path)
+}
+
 // GetVulnerabilityNamespaces retrieves all possible namespaces from the database.
 func (s *store) GetVulnerabilityNamespaces() ([]string, error) {
 	var names []string
@@ -87,6 +465,34 @@ func (s *store) GetVulnerabilityNamespaces() ([]string, error) {
 	return names, result.Error
 }
 
+// GetPackageNamespaces retrieves the sorted, distinct namespaces of the vulnerability (package) records. Unlike
+// GetVulnerabilityNamespaces (which reads the namespaces of the metadata records) this includes namespaces without any
+// metadata records, for instance when metadata lags behind the vulnerability records during a partial import.
+func (s *store) GetPackageNamespaces() ([]string, error) {
+	names := make([]string, 0)
+	result := s.db.Model(&model.VulnerabilityModel{}).Distinct().Order("namespace").Pluck("namespace", &names)
+	return names, result.Error
+}
+
+// HasNamespace indicates if the given namespace is one of the vulnerability namespaces (see
+// GetVulnerabilityNamespaces), without reading every namespace.
+func (s *store) HasNamespace(namespace string) (bool, error) {
+	var exists bool
+	subquery := s.db.Model(&model.VulnerabilityMetadataModel{}).Select("1").Where("namespace = ?", namespace)
+	if result := s.db.Raw("SELECT EXISTS (?)", subquery).Scan(&exists); result.Error != nil {
+		return false, result.Error
+	}
+	return exists, nil
+}
+
+// GetPackageNames retrieves the sorted, distinct package names with vulnerability records within the given namespace.
+// An unknown namespace results in an empty list.
+func (s *store) GetPackageNames(namespace string) ([]string, error) {
+	names := make([]string, 0)
+	result := s.db.Model(&model.VulnerabilityModel{}).Where("namespace = ?", namespace).Distinct().Order("package_name").Pluck("package_name", &names)
+	return names, result.Error
+}
+
 // GetVulnerability retrieves vulnerabilities by namespace and id
 func (s *store) GetVulnerability(namespace, id string) ([]v5.Vulnerability, error) {
 	var models []model.VulnerabilityModel
@@ -111,11 +517,19 @@ func (s *store) GetVulnerability(namespace, id string) ([]v5.Vulnerability, erro
 	return vulnerabilities, result.Error
 }
 
-// SearchForVulnerabilities retrieves vulnerabilities by namespace and package
-func (s *store) SearchForVulnerabilities(namespace, packageName string) ([]v5.Vulnerability, error) {
+// SearchVulnerabilitiesByIDPattern retrieves the vulnerabilities (across all namespaces) with an ID matching the given
+// glob pattern, where "*" matches any number of characters and "?" matches a single character (e.g. "GHSA-*" or
+// "CVE-2021-*"). All other characters are matched literally (including "%" and "_"), and as with sqlite LIKE ASCII
+// characters are matched case-insensitively. Results are ordered by ID, namespace, and package name.
+func (s *store) SearchVulnerabilitiesByIDPattern(pattern string) ([]v5.Vulnerability, error) {
 	var models []model.VulnerabilityModel
 
-	result := s.db.Where("namespace = ? AND package_name = ?", namespace, packageName).Find(&models)
+	result := s.db.Where(`id LIKE ? ESCAPE '\'`, globToLike(pattern)).
+		Order("id").Order("namespace").Order("package_name").Order("pk").
+		Find(&models)
+	if result.Error != nil {
+		return nil, result.Error
+	}
 
 	vulnerabilities := make([]v5.Vulnerability, len(models))
 	for idx, m := range models {
@@ -126,204 +540,1639 @@ func (s *store) SearchForVulnerabilities(namespace, packageName string) ([]v5.Vu
 		vulnerabilities[idx] = vulnerability
 	}
 
-	return vulnerabilities, result.Error
+	return vulnerabilities, nil
 }
 
-// AddVulnerability saves one or more vulnerabilities into the sqlite3 store.
-func (s *store) AddVulnerability(vulnerabilities ...v5.Vulnerability) error {
-	for _, vulnerability := range vulnerabilities {
-		m := model.NewVulnerabilityModel(vulnerability)
-
-		result := s.db.Create(&m)
-		if result.Error != nil {
-			return result.Error
-		}
-
-		if result.RowsAffected != 1 {
-			return fmt.Errorf("unable to add vulnerability (%d rows affected)", result.RowsAffected)
+// globToLike translates a glob pattern ("*" and "?" wildcards) into a LIKE pattern escaped with a backslash, so that
+// LIKE wildcards ("%" and "_") and the escape character within the pattern are matched literally.
+func globToLike(pattern string) string {
+	var sb strings.Builder
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteRune('%')
+		case '?':
+			sb.WriteRune('_')
+		case '%', '_', '\\':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune(r)
 		}
 	}
-	return nil
+	return sb.String()
 }
 
-// GetVulnerabilityMetadata retrieves metadata for the given vulnerability ID relative to a specific record source.
-func (s *store) GetVulnerabilityMetadata(id, namespace string) (*v5.VulnerabilityMetadata, error) {
-	var models []model.VulnerabilityMetadataModel
-
-	result := s.db.Where(&model.VulnerabilityMetadataModel{ID: id, Namespace: namespace}).Find(&models)
-	if result.Error != nil {
+// GetVulnerabilitiesByNamespace retrieves all vulnerabilities within the given namespace. Paired with
+// GetVulnerabilityNamespaces this allows for reading the DB one namespace at a time.
+func (s *store) GetVulnerabilitiesByNamespace(namespace string) ([]v5.Vulnerability, error) {
+	var models []model.VulnerabilityModel
+	if result := s.db.Where("namespace = ?", namespace).Find(&models); result.Error != nil {
 		return nil, result.Error
 	}
 
-	switch {
-	case len(models) > 1:
-		return nil, fmt.Errorf("found multiple metadatas for single ID=%q Namespace=%q", id, namespace)
-	case len(models) == 1:
-		metadata, err := models[0].Inflate()
+	vulnerabilities := make([]v5.Vulnerability, len(models))
+	for idx, m := range models {
+		vulnerability, err := m.Inflate()
 		if err != nil {
 			return nil, err
 		}
-
-		return &metadata, nil
+		vulnerabilities[idx] = vulnerability
 	}
 
-	return nil, nil
+	return vulnerabilities, nil
 }
 
-// AddVulnerabilityMetadata stores one or more vulnerability metadata models into the sqlite DB.
-//
-//nolint:gocognit
-func (s *store) AddVulnerabilityMetadata(metadata ...v5.VulnerabilityMetadata) error {
-	for _, m := range metadata {
-		existing, err := s.GetVulnerabilityMetadata(m.ID, m.Namespace)
-		if err != nil {
-			return fmt.Errorf("failed to verify existing entry: %w", err)
-		}
+// SearchForVulnerabilities retrieves vulnerabilities by namespace and package
+func (s *store) SearchForVulnerabilities(namespace, packageName string) ([]v5.Vulnerability, error) {
+	return s.SearchForVulnerabilitiesWithOptions(namespace, packageName, v5.SearchOptions{})
+}
 
-		if existing != nil {
-			// merge with the existing entry
+// SearchForVulnerabilitiesWithOptions retrieves vulnerabilities by namespace and package, optionally matching the
+// package name case-insensitively and returning a single page of results. Pages are ordered by vulnerability ID,
+// package name, then insertion order (so rows sharing an ID are never skipped or repeated across pages). Note that
+// case-insensitive searches cannot use the package name index, so are slower than exact searches on large namespaces.
+func (s *store) SearchForVulnerabilitiesWithOptions(namespace, packageName string, opts v5.SearchOptions) ([]v5.Vulnerability, error) {
+	var models []model.VulnerabilityModel
 
-			switch {
-			case existing.Severity != m.Severity:
-				return fmt.Errorf("existing metadata has mismatched severity (%q!=%q)", existing.Severity, m.Severity)
-			case existing.Description != m.Description:
-				return fmt.Errorf("existing metadata has mismatched description (%q!=%q)", existing.Description, m.Description)
-			}
+	query := s.db.Where("namespace = ?", namespace)
+	if opts.CaseInsensitivePackageName {
+		query = query.Where("package_name = ? COLLATE NOCASE", packageName)
+	} else {
+		query = query.Where("package_name = ?", packageName)
+	}
+	if opts.Limit > 0 || opts.Offset > 0 {
+		query = query.Order("id").Order("package_name").Order("pk")
+	}
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+	if opts.Offset > 0 {
+		query = query.Offset(opts.Offset)
+	}
 
-		incoming:
-			// go through all incoming CVSS and see if they are already stored.
-			// If they exist already in the database then skip adding them,
-			// preventing a duplicate
-			for _, incomingCvss := range m.Cvss {
-				for _, existingCvss := range existing.Cvss {
-					if len(deep.Equal(incomingCvss, existingCvss)) == 0 {
-						// duplicate found, so incoming CVSS shouldn't get added
-						continue incoming
-					}
-				}
-				// a duplicate CVSS entry wasn't found, so append the incoming CVSS
-				existing.Cvss = append(existing.Cvss, incomingCvss)
-			}
+	result := query.Find(&models)
 
-			links := stringutil.NewStringSetFromSlice(existing.URLs)
-			for _, l := range m.URLs {
-				links.Add(l)
-			}
+	vulnerabilities := make([]v5.Vulnerability, len(models))
+	for idx, m := range models {
+		vulnerability, err := m.Inflate()
+		if err != nil {
+			return nil, err
+		}
+		vulnerabilities[idx] = vulnerability
+	}
 
-			existing.URLs = links.ToSlice()
-			sort.Strings(existing.URLs)
+	return vulnerabilities, result.Error
+}
 
-			newModel := model.NewVulnerabilityMetadataModel(*existing)
-			result := s.db.Save(&newModel)
+// packageNameBatchSize is the max number of package names used within a single IN clause, keeping each query well
+// under the sqlite limit for bound parameters
+const packageNameBatchSize = 500
 
-			if result.RowsAffected != 1 {
-				return fmt.Errorf("unable to merge vulnerability metadata (%d rows affected)", result.RowsAffected)
-			}
+// GetVulnerabilitiesForPackages retrieves all vulnerabilities within a namespace that affect any of the given package
+// names, issuing as few queries as possible. Results are ordered by package name, vulnerability ID, and insertion order.
+func (s *store) GetVulnerabilitiesForPackages(namespace string, packageNames []string) ([]v5.Vulnerability, error) {
+	names := stringutil.NewStringSetFromSlice(packageNames).ToSlice()
+	sort.Strings(names)
 
-			if result.Error != nil {
-				return result.Error
-			}
-		} else {
-			// this is a new entry
-			newModel := model.NewVulnerabilityMetadataModel(m)
-			result := s.db.Create(&newModel)
-			if result.Error != nil {
-				return result.Error
-			}
+	var vulnerabilities []v5.Vulnerability
+	for start := 0; start < len(names); start += packageNameBatchSize {
+		end := min(start+packageNameBatchSize, len(names))
 
-			if result.RowsAffected != 1 {
-				return fmt.Errorf("unable to add vulnerability metadata (%d rows affected)", result.RowsAffected)
+		var models []model.VulnerabilityModel
+		result := s.db.Where("namespace = ? AND package_name IN ?", namespace, names[start:end]).
+			Order("package_name").Order("id").Order("pk").
+			Find(&models)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+
+		for _, m := range models {
+			vulnerability, err := m.Inflate()
+			if err != nil {
+				return nil, err
 			}
+			vulnerabilities = append(vulnerabilities, vulnerability)
 		}
 	}
-	return nil
+
+	return vulnerabilities, nil
 }
 
-// GetVulnerabilityMatchExclusion retrieves one or more vulnerability match exclusion records given a vulnerability identifier.
-func (s *store) GetVulnerabilityMatchExclusion(id string) ([]v5.VulnerabilityMatchExclusion, error) {
-	var models []model.VulnerabilityMatchExclusionModel
+// GetProvenance retrieves the upstream data source information for the given vulnerability ID and namespace. A nil
+// result is returned if there are no records or the records were stored without provenance information.
+func (s *store) GetProvenance(id, namespace string) (*v5.Provenance, error) {
+	var models []model.VulnerabilityModel
 
-	result := s.db.Where("id = ?", id).Find(&models)
+	result := s.db.Where("id = ? AND namespace = ?", id, namespace).Order("pk").Find(&models)
+	if result.Error != nil {
+		return nil, result.Error
+	}
 
-	var exclusions []v5.VulnerabilityMatchExclusion
 	for _, m := range models {
-		exclusion, err := m.Inflate()
+		vulnerability, err := m.Inflate()
 		if err != nil {
 			return nil, err
 		}
-
-		if exclusion != nil {
-			exclusions = append(exclusions, *exclusion)
+		if vulnerability.Provenance != nil {
+			return vulnerability.Provenance, nil
 		}
 	}
 
-	return exclusions, result.Error
+	return nil, nil
 }
 
-// AddVulnerabilityMatchExclusion saves one or more vulnerability match exclusion records into the sqlite3 store.
-func (s *store) AddVulnerabilityMatchExclusion(exclusions ...v5.VulnerabilityMatchExclusion) error {
-	for _, exclusion := range exclusions {
-		m := model.NewVulnerabilityMatchExclusionModel(exclusion)
+// GetVulnerabilitiesByConstraintOperator retrieves all vulnerabilities within a namespace where the version constraint
+// uses the given range operator (e.g. "=", ">="). Results are ordered by vulnerability ID, package name, and insertion
+// order. Constraints that cannot be parsed are not considered to use any operator and are omitted.
+func (s *store) GetVulnerabilitiesByConstraintOperator(namespace, operator string) ([]v5.Vulnerability, error) {
+	op, err := version.ParseOperator(strings.TrimSpace(operator))
+	if err != nil {
+		return nil, err
+	}
 
-		result := s.db.Create(&m)
-		if result.Error != nil {
-			return result.Error
+	var models []model.VulnerabilityModel
+
+	result := s.db.Where("namespace = ?", namespace).Order("id").Order("package_name").Order("pk").Find(&models)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var vulnerabilities []v5.Vulnerability
+	for _, m := range models {
+		operators, err := version.ConstraintOperators(m.VersionConstraint)
+		if err != nil || !slices.Contains(operators, op) {
+			continue
 		}
 
-		if result.RowsAffected != 1 {
-			return fmt.Errorf("unable to add vulnerability match exclusion (%d rows affected)", result.RowsAffected)
+		vulnerability, err := m.Inflate()
+		if err != nil {
+			return nil, err
 		}
+		vulnerabilities = append(vulnerabilities, vulnerability)
 	}
 
-	return nil
+	return vulnerabilities, nil
 }
 
-func (s *store) Close() error {
-	log.Debug("optimizing database settings for memory-efficient VACUUM")
+// GetVulnerabilitiesAboveCVSS retrieves all vulnerabilities within a namespace where the best (highest) CVSS base score
+// from the associated metadata is at or above the given minimum score. When includeUnscored is true, vulnerabilities
+// whose metadata has no CVSS scores (or that have no metadata at all) are also returned. Results are ordered by
+// vulnerability ID, package name, and insertion order.
+func (s *store) GetVulnerabilitiesAboveCVSS(namespace string, minScore float64, includeUnscored bool) ([]v5.Vulnerability, error) {
+	var models []model.VulnerabilityModel
 
-	// Reduce memory footprint for VACUUM operation
-	memoryEfficientStatements := []string{
-		"PRAGMA cache_size = -32768",     // 32MB instead of 1GB
-		"PRAGMA temp_store = FILE",       // Use disk for temp storage
-		"PRAGMA mmap_size = 67108864",    // 64MB instead of 1GB
-		"PRAGMA journal_mode = TRUNCATE", // Disk-based journal, no directory modifications
-	}
+	// the best score is computed within sqlite (from the serialized cvss JSON array) so that records below
+	// the threshold never need to be read or inflated
+	const bestScore = "(SELECT MAX(json_extract(c.value, '$.metrics.base_score')) FROM json_each(m.cvss) c)"
 
-	for _, stmt := range memoryEfficientStatements {
-		if err := s.db.Exec(stmt).Error; err != nil {
-			log.WithFields("statement", stmt, "error", err).Warn("failed to apply memory optimization")
-		} else {
-			log.WithFields("statement", stmt).Debug("applied memory optimization")
-		}
+	condition := bestScore + " >= ?"
+	if includeUnscored {
+		condition = "(" + condition + " OR " + bestScore + " IS NULL)"
 	}
 
-	log.Debug("starting database VACUUM operation")
-	s.db.Exec("VACUUM;")
-	log.Debug("database VACUUM operation completed")
-
-	sqlDB, _ := s.db.DB()
-	if sqlDB != nil {
-		_ = sqlDB.Close()
+	join := "LEFT JOIN " + model.VulnerabilityMetadataTableName + " AS m ON m.id = v.id AND m.namespace = v.namespace"
+	var joinArgs []any
+	if s.asOf != nil && s.asOf.tables[model.VulnerabilityMetadataTableName] {
+		// the joined metadata table is not covered by the as-of filtering of the queried table
+		join += " AND (m.last_modified IS NULL OR m.last_modified <= ?)"
+		joinArgs = append(joinArgs, s.asOf.lastModified)
 	}
 
-	return nil
-}
+	result := s.db.
+		Table(model.VulnerabilityTableName+" AS v").
+		Select("v.*").
+		Joins(join, joinArgs...).
+		Where("v.namespace = ?", namespace).
+		Where(condition, minScore).
+		Order("v.id").Order("v.package_name").Order("v.pk").
+		Find(&models)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	vulnerabilities := make([]v5.Vulnerability, len(models))
+	for idx, m := range models {
+		vulnerability, err := m.Inflate()
+		if err != nil {
+			return nil, err
+		}
+		vulnerabilities[idx] = vulnerability
+	}
+
+	return vulnerabilities, nil
+}
+
+// GetAffectingVulnerabilities retrieves the vulnerabilities in a namespace for the given package whose version
+// constraints are satisfied by the given version, along with whether a fix is available and the lowest fix version
+// above the given version. Constraints are evaluated exactly as they are when matching (see v5.NewVulnerability).
+func (s *store) GetAffectingVulnerabilities(namespace, packageName, packageVersion string) ([]v5.AffectingVulnerability, error) {
+	vulns, err := s.SearchForVulnerabilities(namespace, packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	var affecting []v5.AffectingVulnerability
+	for _, v := range vulns {
+		vuln, err := v5.NewVulnerability(v)
+		if err != nil {
+			return nil, err
+		}
+
+		pkgVersion := version.NewVersion(packageVersion, vuln.Constraint.Format())
+		satisfied, err := vuln.Constraint.Satisfied(pkgVersion)
+		if err != nil {
+			log.WithFields("vuln", v.ID, "constraint", v.VersionConstraint, "version", packageVersion, "error", err).Debug("unable to evaluate vulnerability version constraint")
+			continue
+		}
+		if !satisfied {
+			continue
+		}
+
+		var fixVersion string
+		if above := version.FixVersionsAbove(pkgVersion, v.Fix.Versions); len(above) > 0 {
+			fixVersion = above[0].Raw
+		}
+
+		affecting = append(affecting, v5.AffectingVulnerability{
+			Vulnerability: v,
+			Fixed:         v.Fix.State == v5.FixedState,
+			FixVersion:    fixVersion,
+		})
+	}
+
+	return affecting, nil
+}
+
+// GetRemediationOptions retrieves the candidate versions to upgrade the given package to, based on the fix versions of
+// the vulnerabilities affecting the current version. Each option lists every vulnerability affecting the current version
+// that no longer applies at the candidate version (so later options are typically supersets of earlier ones). Options
+// are ordered by version ascending, and options that resolve nothing are omitted.
+func (s *store) GetRemediationOptions(namespace, packageName, currentVersion string) ([]v5.RemediationOption, error) {
+	affecting, err := s.GetAffectingVulnerabilities(namespace, packageName, currentVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	type affectingConstraint struct {
+		id         string
+		constraint version.Constraint
+	}
+
+	var constraints []affectingConstraint
+	var candidates []*version.Version
+	seenCandidates := strset.New()
+	for _, a := range affecting {
+		vuln, err := v5.NewVulnerability(a.Vulnerability)
+		if err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, affectingConstraint{id: a.ID, constraint: vuln.Constraint})
+
+		current := version.NewVersion(currentVersion, vuln.Constraint.Format())
+		for _, candidate := range version.FixVersionsAbove(current, a.Fix.Versions) {
+			if seenCandidates.Has(candidate.Raw) {
+				continue
+			}
+			seenCandidates.Add(candidate.Raw)
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		cmp, err := candidates[i].Compare(candidates[j])
+		return err == nil && cmp < 0
+	})
+
+	var options []v5.RemediationOption
+	for _, candidate := range candidates {
+		resolves := strset.New()
+		for _, c := range constraints {
+			satisfied, err := c.constraint.Satisfied(version.NewVersion(candidate.Raw, c.constraint.Format()))
+			if err != nil {
+				log.WithFields("vuln", c.id, "version", candidate.Raw, "error", err).Debug("unable to evaluate vulnerability version constraint")
+				continue
+			}
+			if !satisfied {
+				resolves.Add(c.id)
+			}
+		}
+		if resolves.IsEmpty() {
+			continue
+		}
+
+		ids := resolves.List()
+		sort.Strings(ids)
+		options = append(options, v5.RemediationOption{
+			Version:  candidate.Raw,
+			Resolves: ids,
+		})
+	}
+
+	return options, nil
+}
+
+// FindOverlappingConstraints reports pairs of vulnerability records for the given package whose version constraints
+// share at least one version, along with the shared version range. Records with constraints that cannot be parsed
+// or compared are skipped.
+func (s *store) FindOverlappingConstraints(namespace, packageName string) ([]v5.ConstraintOverlap, error) {
+	var models []model.VulnerabilityModel
+	result := s.db.Where("namespace = ? AND package_name = ?", namespace, packageName).Order("id").Order("pk").Find(&models)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	type record struct {
+		id        string
+		format    version.Format
+		intervals []version.Interval
+	}
+
+	var records []record
+	for _, m := range models {
+		format := version.ParseFormat(m.VersionFormat)
+		intervals, err := version.ConstraintIntervals(m.VersionConstraint, format)
+		if err != nil {
+			log.WithFields("id", m.ID, "constraint", m.VersionConstraint, "error", err).Debug("unable to parse vulnerability version constraint")
+			continue
+		}
+		records = append(records, record{id: m.ID, format: format, intervals: intervals})
+	}
+
+	var overlaps []v5.ConstraintOverlap
+	for i := 0; i < len(records); i++ {
+		for j := i + 1; j < len(records); j++ {
+			a, b := records[i], records[j]
+			if a.format != b.format {
+				continue
+			}
+
+			shared, err := intersectAll(a.intervals, b.intervals, a.format)
+			if err != nil {
+				log.WithFields("ids", []string{a.id, b.id}, "error", err).Debug("unable to compare vulnerability version constraints")
+				continue
+			}
+			if len(shared) == 0 {
+				continue
+			}
+
+			overlaps = append(overlaps, v5.ConstraintOverlap{
+				Namespace:   namespace,
+				PackageName: packageName,
+				IDs:         [2]string{a.id, b.id},
+				Overlap:     strings.Join(shared, " || "),
+			})
+		}
+	}
+
+	return overlaps, nil
+}
+
+// intersectAll returns the (string form of the) non-empty intersections of every pair of intervals from each set.
+func intersectAll(as, bs []version.Interval, format version.Format) ([]string, error) {
+	var shared []string
+	for _, a := range as {
+		for _, b := range bs {
+			overlap, ok, err := version.IntersectIntervals(a, b, format)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				shared = append(shared, overlap.String())
+			}
+		}
+	}
+	return shared, nil
+}
+
+// AddVulnerability saves one or more vulnerabilities into the sqlite3 store.
+func (s *store) AddVulnerability(vulnerabilities ...v5.Vulnerability) error {
+	s.dirty = true
+
+	if len(vulnerabilities) == 0 {
+		return nil
+	}
+
+	models := make([]model.VulnerabilityModel, len(vulnerabilities))
+	currency := make(map[string]time.Time)
+	for idx, vulnerability := range vulnerabilities {
+		models[idx] = s.newVulnerabilityModel(vulnerability)
+
+		if p := vulnerability.Provenance; p != nil && p.FetchedAt.After(currency[vulnerability.Namespace]) {
+			currency[vulnerability.Namespace] = p.FetchedAt
+		}
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.CreateInBatches(&models, s.batchSize())
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected != int64(len(models)) {
+			return fmt.Errorf("unable to add vulnerabilities (%d of %d rows affected)", result.RowsAffected, len(models))
+		}
+
+		for namespace, asOf := range currency {
+			if err := advanceNamespaceCurrency(tx, namespace, asOf); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpsertVulnerability is the same as AddVulnerability, except that any existing record with the same identity (ID,
+// package name, namespace, and version constraint) is replaced rather than duplicated, making repeated imports
+// idempotent. Only the last of any incoming records with the same identity is kept, and any duplicates of the identity
+// already in the DB are collapsed into the single replaced record.
+func (s *store) UpsertVulnerability(vulnerabilities ...v5.Vulnerability) error {
+	s.dirty = true
+
+	if len(vulnerabilities) == 0 {
+		return nil
+	}
+
+	type identity struct {
+		id, packageName, namespace, versionConstraint string
+	}
+
+	var models []model.VulnerabilityModel
+	indexes := make(map[identity]int)
+	currency := make(map[string]time.Time)
+	for _, vulnerability := range vulnerabilities {
+		key := identity{vulnerability.ID, vulnerability.PackageName, vulnerability.Namespace, vulnerability.VersionConstraint}
+		if idx, ok := indexes[key]; ok {
+			models[idx] = s.newVulnerabilityModel(vulnerability)
+		} else {
+			indexes[key] = len(models)
+			models = append(models, s.newVulnerabilityModel(vulnerability))
+		}
+
+		if p := vulnerability.Provenance; p != nil && p.FetchedAt.After(currency[vulnerability.Namespace]) {
+			currency[vulnerability.Namespace] = p.FetchedAt
+		}
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// note: the table has no constraint over the identity of a record (only a surrogate primary key), so the
+		// existing primary key for each record is resolved first and used as the conflict target
+		for idx := range models {
+			m := &models[idx]
+			var pks []uint64
+			result := tx.Model(&model.VulnerabilityModel{}).
+				Where("id = ? AND package_name = ? AND namespace = ? AND version_constraint = ?", m.ID, m.PackageName, m.Namespace, m.VersionConstraint).
+				Order("pk").
+				Pluck("pk", &pks)
+			if result.Error != nil {
+				return fmt.Errorf("unable to find existing vulnerability: %w", result.Error)
+			}
+			if len(pks) == 0 {
+				continue
+			}
+			m.PK = pks[0]
+			if len(pks) > 1 {
+				if err := tx.Delete(&model.VulnerabilityModel{}, pks[1:]).Error; err != nil {
+					return fmt.Errorf("unable to remove duplicate vulnerabilities: %w", err)
+				}
+			}
+		}
+
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "pk"}},
+			UpdateAll: true,
+		}).CreateInBatches(&models, s.batchSize())
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected != int64(len(models)) {
+			return fmt.Errorf("unable to upsert vulnerabilities (%d of %d rows affected)", result.RowsAffected, len(models))
+		}
+
+		for namespace, asOf := range currency {
+			if err := advanceNamespaceCurrency(tx, namespace, asOf); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteVulnerability removes all vulnerability records with the given ID within a namespace (across all packages),
+// returning the number of records removed.
+func (s *store) DeleteVulnerability(id, namespace string) (int64, error) {
+	s.dirty = true
+
+	var deleted int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND namespace = ?", id, namespace).Delete(&model.VulnerabilityModel{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to delete vulnerability ID=%q Namespace=%q: %w", id, namespace, err)
+	}
+	return deleted, nil
+}
+
+// GetVulnerabilityMetadata retrieves metadata for the given vulnerability ID relative to a specific record source.
+func (s *store) GetVulnerabilityMetadata(id, namespace string) (*v5.VulnerabilityMetadata, error) {
+	m, err := s.getMetadataModel(id, namespace)
+	if err != nil || m == nil {
+		return nil, err
+	}
+
+	metadata, err := m.Inflate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
+// getMetadataModel retrieves the stored metadata record for the given vulnerability ID relative to a specific record
+// source (nil if there is none).
+func (s *store) getMetadataModel(id, namespace string) (*model.VulnerabilityMetadataModel, error) {
+	var models []model.VulnerabilityMetadataModel
+
+	result := s.db.Where(&model.VulnerabilityMetadataModel{ID: id, Namespace: namespace}).Find(&models)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	switch {
+	case len(models) > 1:
+		return nil, fmt.Errorf("%w for single ID=%q Namespace=%q", ErrMultipleMetadata, id, namespace)
+	case len(models) == 1:
+		return &models[0], nil
+	}
+
+	return nil, nil
+}
+
+// GetAllVulnerabilityMetadataByID retrieves metadata for the given vulnerability ID across all namespaces (ordered by
+// namespace), where the namespace of each record identifies the record source.
+func (s *store) GetAllVulnerabilityMetadataByID(id string) ([]v5.VulnerabilityMetadata, error) {
+	var models []model.VulnerabilityMetadataModel
+
+	result := s.db.Where("id = ?", id).Order("namespace").Find(&models)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	metadata := make([]v5.VulnerabilityMetadata, len(models))
+	for idx, m := range models {
+		data, err := m.Inflate()
+		if err != nil {
+			return nil, err
+		}
+		metadata[idx] = data
+	}
+
+	return metadata, nil
+}
+
+// metadataKeyBatchSize is the max number of (id, namespace) pairs used within a single IN clause, keeping each query
+// under the sqlite limit of 999 bound parameters (two per pair)
+const metadataKeyBatchSize = 450
+
+// GetVulnerabilityMetadataBatch retrieves the metadata for each of the given vulnerability ID and namespace pairs,
+// issuing as few queries as possible. Pairs without metadata are absent from the returned map.
+func (s *store) GetVulnerabilityMetadataBatch(keys []v5.MetadataKey) (map[v5.MetadataKey]v5.VulnerabilityMetadata, error) {
+	models, err := s.getMetadataModelBatch(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make(map[v5.MetadataKey]v5.VulnerabilityMetadata, len(models))
+	for key, m := range models {
+		data, err := m.Inflate()
+		if err != nil {
+			return nil, err
+		}
+		metadata[key] = data
+	}
+
+	return metadata, nil
+}
+
+// getMetadataModelBatch retrieves the stored metadata records for all the given keys (see GetVulnerabilityMetadataBatch).
+func (s *store) getMetadataModelBatch(keys []v5.MetadataKey) (map[v5.MetadataKey]model.VulnerabilityMetadataModel, error) {
+	seen := make(map[v5.MetadataKey]struct{}, len(keys))
+	unique := make([]v5.MetadataKey, 0, len(keys))
+	for _, k := range keys {
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		unique = append(unique, k)
+	}
+
+	stored := make(map[v5.MetadataKey]model.VulnerabilityMetadataModel, len(unique))
+
+	for start := 0; start < len(unique); start += metadataKeyBatchSize {
+		end := min(start+metadataKeyBatchSize, len(unique))
+
+		pairs := make([][]any, 0, end-start)
+		for _, k := range unique[start:end] {
+			pairs = append(pairs, []any{k.ID, k.Namespace})
+		}
+
+		var models []model.VulnerabilityMetadataModel
+		if result := s.db.Where("(id, namespace) IN ?", pairs).Find(&models); result.Error != nil {
+			return nil, result.Error
+		}
+
+		for _, m := range models {
+			key := v5.MetadataKey{ID: m.ID, Namespace: m.Namespace}
+			if _, ok := stored[key]; ok {
+				return nil, fmt.Errorf("%w for single ID=%q Namespace=%q", ErrMultipleMetadata, m.ID, m.Namespace)
+			}
+			stored[key] = m
+		}
+	}
+
+	return stored, nil
+}
+
+// GetVulnerabilityMetadataWithOptions retrieves metadata for the given vulnerability ID relative to a specific record
+// source, optionally recomputing the severity from the highest stored CVSS base score. This allows for surfacing (and
+// optionally correcting) records where the stored severity label disagrees with the stored CVSS scores.
+func (s *store) GetVulnerabilityMetadataWithOptions(id, namespace string, opts v5.MetadataReadOptions) (*v5.VulnerabilityMetadata, error) {
+	metadata, err := s.GetVulnerabilityMetadata(id, namespace)
+	if err != nil || metadata == nil || !opts.RecomputeSeverity {
+		return metadata, err
+	}
+
+	metadata.StoredSeverity = metadata.Severity
+	if computed, ok := v5.SeverityFromCVSS(metadata.Cvss); ok {
+		metadata.ComputedSeverity = computed
+		if opts.PreferComputedSeverity {
+			metadata.Severity = computed
+		}
+	}
+
+	return metadata, nil
+}
+
+// AddVulnerabilityMetadata stores one or more vulnerability metadata models into the sqlite DB, summarizing how many
+// records were newly created and how many were merged into existing records. On failure, the summary reflects the
+// records written before the failure.
+//
+//nolint:gocognit
+func (s *store) AddVulnerabilityMetadata(metadata ...v5.VulnerabilityMetadata) (v5.MetadataImportSummary, error) {
+	s.dirty = true
+
+	var summary v5.MetadataImportSummary
+
+	// note: all records are validated before any are written, so a malformed record does not result in a partial import
+	for _, m := range metadata {
+		if err := validateCvss(m); err != nil {
+			return summary, err
+		}
+	}
+
+	for _, m := range metadata {
+		stored, err := s.getMetadataModel(m.ID, m.Namespace)
+		if err != nil {
+			return summary, fmt.Errorf("failed to verify existing entry: %w", err)
+		}
+
+		if stored != nil {
+			// merge with the existing entry (as stored, so the normalization applied on read is not persisted)
+			existing, err := stored.InflateStored()
+			if err != nil {
+				return summary, err
+			}
+			if err := s.mergeMetadata(&existing, m); err != nil {
+				return summary, err
+			}
+
+			// note: this stamps the merged record with the current modification time
+			newModel, err := s.newMergedMetadataModel(*stored, existing)
+			if err != nil {
+				return summary, err
+			}
+			result := s.db.Save(&newModel)
+
+			if result.RowsAffected != 1 {
+				return summary, fmt.Errorf("unable to merge vulnerability metadata (%d rows affected)", result.RowsAffected)
+			}
+
+			if result.Error != nil {
+				return summary, result.Error
+			}
+			summary.Merged++
+		} else {
+			// this is a new entry
+			newModel := s.newMetadataModel(m)
+			result := s.db.Create(&newModel)
+			if result.Error != nil {
+				return summary, result.Error
+			}
+
+			if result.RowsAffected != 1 {
+				return summary, fmt.Errorf("unable to add vulnerability metadata (%d rows affected)", result.RowsAffected)
+			}
+			summary.Created++
+		}
+	}
+	return summary, nil
+}
+
+// AddVulnerabilityMetadataBulk is the same as AddVulnerabilityMetadata (with identical merge semantics), but is intended
+// for large imports: the existing records for all incoming records are fetched up front (see
+// GetVulnerabilityMetadataBatch), the merge-or-create decision for each record is made in memory (including merging
+// multiple incoming records for the same ID and namespace), and the results are written in batches within a single
+// transaction. When a record cannot be merged the records before it are still written, the same as
+// AddVulnerabilityMetadata.
+func (s *store) AddVulnerabilityMetadataBulk(metadata ...v5.VulnerabilityMetadata) (v5.MetadataImportSummary, error) {
+	s.dirty = true
+
+	var summary v5.MetadataImportSummary
+
+	// note: all records are validated before any are written, so a malformed record does not result in a partial import
+	for _, m := range metadata {
+		if err := validateCvss(m); err != nil {
+			return summary, err
+		}
+	}
+
+	keys := make([]v5.MetadataKey, 0, len(metadata))
+	for _, m := range metadata {
+		keys = append(keys, v5.MetadataKey{ID: m.ID, Namespace: m.Namespace})
+	}
+
+	// note: records are merged as stored, so the normalization applied on read is not persisted
+	stored, err := s.getMetadataModelBatch(keys)
+	if err != nil {
+		return summary, fmt.Errorf("failed to verify existing entries: %w", err)
+	}
+
+	var (
+		pending   = make(map[v5.MetadataKey]*v5.VulnerabilityMetadata)
+		order     []v5.MetadataKey
+		decisions v5.MetadataImportSummary
+		mergeErr  error
+	)
+
+	for _, m := range metadata {
+		key := v5.MetadataKey{ID: m.ID, Namespace: m.Namespace}
+
+		current, ok := pending[key]
+		if !ok {
+			if sm, exists := stored[key]; exists {
+				e, err := sm.InflateStored()
+				if err != nil {
+					return summary, err
+				}
+				current = &e
+			}
+		}
+
+		if current == nil {
+			// this is a new entry
+			incoming := m
+			pending[key] = &incoming
+			order = append(order, key)
+			decisions.Created++
+			continue
+		}
+
+		// merge with the existing (or previously incoming) entry, without modifying the entry on conflict
+		merged := *current
+		merged.Cvss = slices.Clone(current.Cvss)
+		if mergeErr = s.mergeMetadata(&merged, m); mergeErr != nil {
+			break
+		}
+		if !ok {
+			order = append(order, key)
+		}
+		pending[key] = &merged
+		decisions.Merged++
+	}
+
+	if err := s.writeMetadata(order, pending, stored); err != nil {
+		return summary, err
+	}
+
+	return decisions, mergeErr
+}
+
+// writeMetadata writes the given records within a single transaction, creating new records in batches and replacing
+// the given stored records.
+func (s *store) writeMetadata(order []v5.MetadataKey, records map[v5.MetadataKey]*v5.VulnerabilityMetadata, stored map[v5.MetadataKey]model.VulnerabilityMetadataModel) error {
+	var creates, merges []model.VulnerabilityMetadataModel
+	for _, key := range order {
+		// note: this stamps each record with the current modification time
+		sm, exists := stored[key]
+		if !exists {
+			creates = append(creates, s.newMetadataModel(*records[key]))
+			continue
+		}
+		m, err := s.newMergedMetadataModel(sm, *records[key])
+		if err != nil {
+			return err
+		}
+		merges = append(merges, m)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if len(creates) > 0 {
+			result := tx.CreateInBatches(&creates, s.batchSize())
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected != int64(len(creates)) {
+				return fmt.Errorf("unable to add vulnerability metadata (%d of %d rows affected)", result.RowsAffected, len(creates))
+			}
+		}
+
+		for i := range merges {
+			result := tx.Save(&merges[i])
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected != 1 {
+				return fmt.Errorf("unable to merge vulnerability metadata (%d rows affected)", result.RowsAffected)
+			}
+		}
+		return nil
+	})
+}
+
+// mergeMetadata merges the incoming record into the existing record: conflicting severities and descriptions are
+// resolved by the configured merge policy, incoming CVSS entries are added unless already present, and URLs are the
+// (sorted) union of both records.
+func (s *store) mergeMetadata(existing *v5.VulnerabilityMetadata, m v5.VulnerabilityMetadata) error {
+	if err := s.resolveMetadataConflicts(existing, m); err != nil {
+		return err
+	}
+
+incoming:
+	// go through all incoming CVSS and see if they are already stored.
+	// If they exist already in the database then skip adding them,
+	// preventing a duplicate
+	for _, incomingCvss := range m.Cvss {
+		for _, existingCvss := range existing.Cvss {
+			if len(deep.Equal(model.NormalizeCvss(incomingCvss), model.NormalizeCvss(existingCvss))) == 0 {
+				// duplicate found, so incoming CVSS shouldn't get added
+				continue incoming
+			}
+		}
+		// a duplicate CVSS entry wasn't found, so append the incoming CVSS
+		existing.Cvss = append(existing.Cvss, incomingCvss)
+	}
+
+	if s.preserveURLOrder {
+		existing.URLs = unionURLs(existing.URLs, m.URLs)
+		return nil
+	}
+
+	links := stringutil.NewStringSetFromSlice(existing.URLs)
+	for _, l := range m.URLs {
+		links.Add(l)
+	}
+
+	existing.URLs = links.ToSlice()
+	sort.Strings(existing.URLs)
+	return nil
+}
+
+// unionURLs returns the distinct URLs from both lists, in the order they first appear.
+func unionURLs(existing, incoming []string) []string {
+	seen := strset.New()
+	var links []string
+	for _, l := range append(slices.Clone(existing), incoming...) {
+		if seen.Has(l) {
+			continue
+		}
+		seen.Add(l)
+		links = append(links, l)
+	}
+	return links
+}
+
+// DeleteVulnerabilityMetadata removes the vulnerability metadata record with the given ID within a namespace,
+// returning the number of records removed.
+func (s *store) DeleteVulnerabilityMetadata(id, namespace string) (int64, error) {
+	s.dirty = true
+
+	var deleted int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND namespace = ?", id, namespace).Delete(&model.VulnerabilityMetadataModel{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to delete vulnerability metadata ID=%q Namespace=%q: %w", id, namespace, err)
+	}
+	return deleted, nil
+}
+
+// resolveMetadataConflicts reconciles the severity and description of the existing record with the incoming record
+// according to the configured merge policy (and whether severity updates are enabled).
+func (s *store) resolveMetadataConflicts(existing *v5.VulnerabilityMetadata, incoming v5.VulnerabilityMetadata) error {
+	if existing.Severity != incoming.Severity {
+		switch {
+		case s.updateSeverity || s.mergePolicy == MergePolicyPreferIncoming:
+			log.WithFields("id", existing.ID, "namespace", existing.Namespace, "previous", existing.Severity, "severity", incoming.Severity).Info("updating vulnerability metadata severity")
+			existing.Severity = incoming.Severity
+		case s.mergePolicy == MergePolicyKeepExisting:
+			log.WithFields("id", existing.ID, "namespace", existing.Namespace).Debug("keeping existing metadata severity")
+		default:
+			return fmt.Errorf("existing metadata has mismatched severity (%q!=%q)", existing.Severity, incoming.Severity)
+		}
+	}
+
+	if existing.Description != incoming.Description {
+		switch s.mergePolicy {
+		case MergePolicyPreferIncoming:
+			log.WithFields("id", existing.ID, "namespace", existing.Namespace).Debug("replacing metadata description with incoming value")
+			existing.Description = incoming.Description
+		case MergePolicyKeepExisting:
+			log.WithFields("id", existing.ID, "namespace", existing.Namespace).Debug("keeping existing metadata description")
+		default:
+			return fmt.Errorf("existing metadata has mismatched description (%q!=%q)", existing.Description, incoming.Description)
+		}
+	}
+	return nil
+}
+
+// validateCvss ensures every CVSS vector of the given metadata record is of the declared CVSS version and can be parsed.
+// Entries without a vector (only scores) are not validated.
+func validateCvss(m v5.VulnerabilityMetadata) error {
+	for _, c := range m.Cvss {
+		if c.Vector == "" {
+			continue
+		}
+		if err := validateCvssVector(c.Version, c.Vector); err != nil {
+			return fmt.Errorf("invalid CVSS %s vector %q for vulnerability metadata (id=%q namespace=%q): %w", c.Version, c.Vector, m.ID, m.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// validateCvssVector ensures the given vector is of the given CVSS version (e.g. "3.1") and can be parsed. CVSS v3 and
+// v4 vectors must be prefixed with their version (e.g. "CVSS:3.1/"), while CVSS v2 vectors have no prefix. Vectors of
+// any other version are only parsed (according to the CVSS version indicated by the vector prefix).
+func validateCvssVector(version, vector string) error {
+	switch {
+	case version == "2.0":
+		if strings.HasPrefix(vector, "CVSS:") {
+			return fmt.Errorf("expected a CVSS v2 vector")
+		}
+	case strings.HasPrefix(version, "3.") || strings.HasPrefix(version, "4."):
+		if prefix := "CVSS:" + version + "/"; !strings.HasPrefix(vector, prefix) {
+			return fmt.Errorf("expected a vector prefixed with %q", prefix)
+		}
+	}
+	_, err := intCvss.ParseMetricsFromVector(vector)
+	return err
+}
+
+// GetRecentlyModifiedMetadata retrieves up to the given number of vulnerability metadata records, ordered by the most
+// recently added or merged first. Records saved without a modification time are ordered last. A limit of zero or less
+// returns all records. DBs built before modification times were recorded return no records.
+func (s *store) GetRecentlyModifiedMetadata(limit int) ([]v5.VulnerabilityMetadata, error) {
+	if !s.db.Migrator().HasColumn(&model.VulnerabilityMetadataModel{}, "last_modified") {
+		return []v5.VulnerabilityMetadata{}, nil
+	}
+
+	var models []model.VulnerabilityMetadataModel
+
+	query := s.db.Order("last_modified DESC").Order("id").Order("namespace")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	result := query.Find(&models)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	metadata := make([]v5.VulnerabilityMetadata, len(models))
+	for idx, m := range models {
+		inflated, err := m.Inflate()
+		if err != nil {
+			return nil, err
+		}
+		metadata[idx] = inflated
+	}
+
+	return metadata, nil
+}
+
+// HasHighSeverityVulnerabilities indicates if there are any Critical or High severity metadata records within the
+// given namespace (or any namespace if none is given). This uses the high severity partial index when present.
+func (s *store) HasHighSeverityVulnerabilities(namespace string) (bool, error) {
+	query := s.db.Model(&model.VulnerabilityMetadataModel{}).Select("1").Where(model.HighSeverityCondition)
+	if namespace != "" {
+		query = query.Where("namespace = ?", namespace)
+	}
+
+	var found []int
+	result := query.Limit(1).Find(&found)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return len(found) > 0, nil
+}
+
+// GetHighSeverityMetadata retrieves all Critical and High severity metadata records within the given namespace (or
+// any namespace if none is given), ordered by ID then namespace. This uses the high severity partial index when present.
+func (s *store) GetHighSeverityMetadata(namespace string) ([]v5.VulnerabilityMetadata, error) {
+	var models []model.VulnerabilityMetadataModel
+
+	query := s.db.Where(model.HighSeverityCondition)
+	if namespace != "" {
+		query = query.Where("namespace = ?", namespace)
+	}
+
+	result := query.Order("id").Order("namespace").Find(&models)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	metadata := make([]v5.VulnerabilityMetadata, len(models))
+	for idx, m := range models {
+		inflated, err := m.Inflate()
+		if err != nil {
+			return nil, err
+		}
+		metadata[idx] = inflated
+	}
+
+	return metadata, nil
+}
+
+// FindSeverityConflicts finds all vulnerability IDs where the metadata records across namespaces assign differing
+// (case-insensitive) severities. Records without a severity or with an "unknown" severity are not considered
+// conflicting. Results are ordered by vulnerability ID.
+func (s *store) FindSeverityConflicts() ([]v5.SeverityConflict, error) {
+	const knownSeverity = "severity != '' AND lower(severity) != 'unknown'"
+
+	conflicting := s.db.Model(&model.VulnerabilityMetadataModel{}).
+		Select("id").
+		Where(knownSeverity).
+		Group("id").
+		Having("COUNT(DISTINCT lower(severity)) > 1")
+
+	var rows []struct {
+		ID        string
+		Namespace string
+		Severity  string
+	}
+
+	result := s.db.Model(&model.VulnerabilityMetadataModel{}).
+		Select("id, namespace, severity").
+		Where(knownSeverity).
+		Where("id IN (?)", conflicting).
+		Order("id").Order("namespace").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var conflicts []v5.SeverityConflict
+	for _, r := range rows {
+		if len(conflicts) == 0 || conflicts[len(conflicts)-1].ID != r.ID {
+			conflicts = append(conflicts, v5.SeverityConflict{ID: r.ID})
+		}
+		last := &conflicts[len(conflicts)-1]
+		last.Severities = append(last.Severities, v5.NamespacedSeverity{
+			Namespace: r.Namespace,
+			Severity:  r.Severity,
+		})
+	}
+
+	return conflicts, nil
+}
+
+// GetVulnerabilityMatchExclusion retrieves one or more vulnerability match exclusion records given a vulnerability identifier.
+func (s *store) GetVulnerabilityMatchExclusion(id string) ([]v5.VulnerabilityMatchExclusion, error) {
+	var models []model.VulnerabilityMatchExclusionModel
+
+	result := s.db.Where("id = ?", id).Find(&models)
+
+	var exclusions []v5.VulnerabilityMatchExclusion
+	for _, m := range models {
+		exclusion, err := m.Inflate()
+		if err != nil {
+			return nil, err
+		}
+
+		if exclusion != nil {
+			exclusions = append(exclusions, *exclusion)
+		}
+	}
+
+	return exclusions, result.Error
+}
+
+// GetApplicableExclusions retrieves the match exclusions for the given vulnerability ID which apply to the given
+// namespace, package name, and package version. Only the applicable constraints are kept on each returned exclusion.
+// Constraint criteria that cannot be evaluated from the given arguments (e.g. package language, type, or location) are
+// assumed to apply. The package version criteria may be an exact version or a version constraint (e.g. "< 2.0"), which
+// is evaluated using the version format of the vulnerability record for the package.
+func (s *store) GetApplicableExclusions(id, namespace, packageName, packageVersion string) ([]v5.VulnerabilityMatchExclusion, error) {
+	exclusions, err := s.GetVulnerabilityMatchExclusion(id)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := s.versionFormatFor(id, namespace, packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	var applicable []v5.VulnerabilityMatchExclusion
+	for _, e := range exclusions {
+		if len(e.Constraints) == 0 {
+			// an exclusion without constraints applies to all matches for the vulnerability
+			applicable = append(applicable, e)
+			continue
+		}
+
+		var constraints []v5.VulnerabilityMatchExclusionConstraint
+		for _, c := range e.Constraints {
+			if exclusionConstraintApplies(c, namespace, packageName, packageVersion, format) {
+				constraints = append(constraints, c)
+			}
+		}
+
+		if len(constraints) > 0 {
+			e.Constraints = constraints
+			applicable = append(applicable, e)
+		}
+	}
+
+	return applicable, nil
+}
+
+// IsMatchExcluded indicates if a match of the given vulnerability ID (within a namespace) against the given package
+// name and version is excluded, returning the first applicable exclusion (with only the applicable constraints kept)
+// to explain why. Exclusions are evaluated the same as GetApplicableExclusions: constraints without a package name
+// apply to every package, and version criteria may be an exact version or a version constraint.
+func (s *store) IsMatchExcluded(id, namespace, packageName, packageVersion string) (bool, *v5.VulnerabilityMatchExclusion, error) {
+	applicable, err := s.GetApplicableExclusions(id, namespace, packageName, packageVersion)
+	if err != nil {
+		return false, nil, err
+	}
+	if len(applicable) == 0 {
+		return false, nil, nil
+	}
+	return true, &applicable[0], nil
+}
+
+// GetVulnerabilityMatchExclusionsByConstraint retrieves all match exclusions (ordered by vulnerability ID) which may
+// apply to the given filter. A constraint applies when each of its namespace and package name criteria is either
+// unset (applying to everything) or equal to the filter value, so exclusions without constraints match any filter. Only
+// the applicable constraints are kept on each returned exclusion. Empty filter fields are not filtered on.
+func (s *store) GetVulnerabilityMatchExclusionsByConstraint(filter v5.VulnerabilityMatchExclusionFilter) ([]v5.VulnerabilityMatchExclusion, error) {
+	var models []model.VulnerabilityMatchExclusionModel
+
+	query := s.db.Order("id").Order("pk")
+	if filter.ID != "" {
+		query = query.Where("id = ?", filter.ID)
+	}
+	if result := query.Find(&models); result.Error != nil {
+		return nil, result.Error
+	}
+
+	var exclusions []v5.VulnerabilityMatchExclusion
+	for _, m := range models {
+		exclusion, err := m.Inflate()
+		if err != nil {
+			return nil, err
+		}
+		if exclusion == nil {
+			continue
+		}
+
+		if len(exclusion.Constraints) == 0 {
+			exclusions = append(exclusions, *exclusion)
+			continue
+		}
+
+		var constraints []v5.VulnerabilityMatchExclusionConstraint
+		for _, c := range exclusion.Constraints {
+			if c.Vulnerability.Namespace != "" && filter.Namespace != "" && c.Vulnerability.Namespace != filter.Namespace {
+				continue
+			}
+			if c.Package.Name != "" && filter.PackageName != "" && c.Package.Name != filter.PackageName {
+				continue
+			}
+			constraints = append(constraints, c)
+		}
+
+		if len(constraints) > 0 {
+			exclusion.Constraints = constraints
+			exclusions = append(exclusions, *exclusion)
+		}
+	}
+
+	return exclusions, nil
+}
+
+// versionFormatFor returns the version format of the vulnerability record for the given package, or the unknown
+// format if there is no such record.
+func (s *store) versionFormatFor(id, namespace, packageName string) (version.Format, error) {
+	var formats []string
+	result := s.db.Model(&model.VulnerabilityModel{}).
+		Where("id = ? AND namespace = ? AND package_name = ?", id, namespace, packageName).
+		Limit(1).
+		Pluck("version_format", &formats)
+	if result.Error != nil {
+		return version.UnknownFormat, result.Error
+	}
+
+	if len(formats) == 0 {
+		return version.UnknownFormat, nil
+	}
+	return version.ParseFormat(formats[0]), nil
+}
+
+func exclusionConstraintApplies(c v5.VulnerabilityMatchExclusionConstraint, namespace, packageName, packageVersion string, format version.Format) bool {
+	if !c.Usable() {
+		return false
+	}
+
+	if c.Vulnerability.Namespace != "" && c.Vulnerability.Namespace != namespace {
+		return false
+	}
+
+	if c.Package.Name != "" && c.Package.Name != packageName {
+		return false
+	}
+
+	if c.Package.Version == "" || c.Package.Version == packageVersion {
+		return true
+	}
+
+	constraint, err := version.GetConstraint(c.Package.Version, format)
+	if err != nil {
+		log.WithFields("constraint", c.Package.Version, "error", err).Debug("unable to parse exclusion version constraint")
+		return false
+	}
+
+	satisfied, err := constraint.Satisfied(version.NewVersion(packageVersion, format))
+	if err != nil {
+		log.WithFields("constraint", c.Package.Version, "version", packageVersion, "error", err).Debug("unable to evaluate exclusion version constraint")
+		return false
+	}
+	return satisfied
+}
+
+// GetVulnerabilityDetail retrieves all records related to the given vulnerability ID across all namespaces: the
+// vulnerability records, metadata, match exclusions, and distinct aliases (related vulnerabilities).
+func (s *store) GetVulnerabilityDetail(id string) (v5.VulnerabilityDetail, error) {
+	var vulnModels []model.VulnerabilityModel
+	result := s.db.Where("id = ?", id).Order("namespace").Order("package_name").Order("pk").Find(&vulnModels)
+	if result.Error != nil {
+		return v5.VulnerabilityDetail{}, result.Error
+	}
+
+	var metadataModels []model.VulnerabilityMetadataModel
+	result = s.db.Where("id = ?", id).Order("namespace").Find(&metadataModels)
+	if result.Error != nil {
+		return v5.VulnerabilityDetail{}, result.Error
+	}
+
+	exclusions, err := s.GetVulnerabilityMatchExclusion(id)
+	if err != nil {
+		return v5.VulnerabilityDetail{}, err
+	}
+
+	detail := v5.VulnerabilityDetail{
+		ID:              id,
+		Vulnerabilities: make([]v5.Vulnerability, len(vulnModels)),
+		Metadata:        make([]v5.VulnerabilityMetadata, len(metadataModels)),
+		MatchExclusions: exclusions,
+	}
+
+	seenAliases := make(map[v5.VulnerabilityReference]struct{})
+	for idx, m := range vulnModels {
+		vulnerability, err := m.Inflate()
+		if err != nil {
+			return v5.VulnerabilityDetail{}, err
+		}
+		detail.Vulnerabilities[idx] = vulnerability
+
+		for _, related := range vulnerability.RelatedVulnerabilities {
+			if related.ID == id {
+				continue
+			}
+			if _, ok := seenAliases[related]; ok {
+				continue
+			}
+			seenAliases[related] = struct{}{}
+			detail.Aliases = append(detail.Aliases, related)
+		}
+	}
+
+	for idx, m := range metadataModels {
+		metadata, err := m.Inflate()
+		if err != nil {
+			return v5.VulnerabilityDetail{}, err
+		}
+		detail.Metadata[idx] = metadata
+	}
+
+	sort.Slice(detail.Aliases, func(i, j int) bool {
+		if detail.Aliases[i].ID == detail.Aliases[j].ID {
+			return detail.Aliases[i].Namespace < detail.Aliases[j].Namespace
+		}
+		return detail.Aliases[i].ID < detail.Aliases[j].ID
+	})
+
+	return detail, nil
+}
+
+// AddVulnerabilityMatchExclusion saves one or more vulnerability match exclusion records into the sqlite3 store.
+func (s *store) AddVulnerabilityMatchExclusion(exclusions ...v5.VulnerabilityMatchExclusion) error {
+	s.dirty = true
+
+	for _, exclusion := range exclusions {
+		m := model.NewVulnerabilityMatchExclusionModel(exclusion)
+
+		result := s.db.Create(&m)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected != 1 {
+			return fmt.Errorf("unable to add vulnerability match exclusion (%d rows affected)", result.RowsAffected)
+		}
+	}
+
+	return nil
+}
+
+// ReplaceVulnerabilityMatchExclusions replaces all vulnerability match exclusion records for the given vulnerability
+// identifier with the given exclusions. This is done within a single transaction, so on any failure the existing
+// exclusions are left intact.
+func (s *store) ReplaceVulnerabilityMatchExclusions(id string, exclusions []v5.VulnerabilityMatchExclusion) error {
+	s.dirty = true
+
+	for _, exclusion := range exclusions {
+		if exclusion.ID != id {
+			return fmt.Errorf("vulnerability match exclusion ID=%q does not match the ID being replaced (%q)", exclusion.ID, id)
+		}
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if result := tx.Where("id = ?", id).Delete(&model.VulnerabilityMatchExclusionModel{}); result.Error != nil {
+			return result.Error
+		}
+
+		for _, exclusion := range exclusions {
+			m := model.NewVulnerabilityMatchExclusionModel(exclusion)
+
+			result := tx.Create(&m)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected != 1 {
+				return fmt.Errorf("unable to add vulnerability match exclusion (%d rows affected)", result.RowsAffected)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Close closes the DB connection. When the DB has been written to since it was opened, the DB is vacuumed first (see
+// Vacuum); otherwise (e.g. the store was only read from) no writes are performed at all. Closing a view of the store
+// (see AsOf and WithContext) does nothing, since the connection is shared with the store the view was created from.
+func (s *store) Close() error {
+	if s.view {
+		// the connection is owned by the store the view was created from
+		return nil
+	}
+
+	if s.transaction {
+		return fmt.Errorf("unable to close DB: %w", errTransactionalStore)
+	}
+
+	if !s.readOnly && !s.inMemory && s.dirty {
+		if err := s.Vacuum(); err != nil {
+			log.WithFields("error", err).Warn("unable to vacuum database")
+		}
+	}
+
+	if s.wal {
+		if err := s.resetJournalMode(); err != nil {
+			log.WithFields("error", err).Warn("unable to reset database journal mode")
+		}
+	}
+
+	return s.closeConnection()
+}
+
+// Vacuum rebuilds the DB file, reclaiming unused space and defragmenting it. This is done implicitly on Close when the
+// DB has been written to, but may be called explicitly by DB build tooling. Before vacuuming the cache_size, temp_store,
+// mmap_size, and journal_mode PRAGMAs are applied as described by the configured CloseConfig (by default reducing the
+// memory footprint of the VACUUM operation). When the DB is in the WAL journal mode (see WithWAL) the WAL is
+// checkpointed and the journal mode is reset first.
+func (s *store) Vacuum() error {
+	if s.transaction {
+		return fmt.Errorf("unable to vacuum DB: %w", errTransactionalStore)
+	}
+
+	if s.wal {
+		if err := s.resetJournalMode(); err != nil {
+			return fmt.Errorf("unable to reset journal mode: %w", err)
+		}
+	}
+
+	log.Debug("optimizing database settings for VACUUM")
+
+	for _, stmt := range s.closeConfig.statements() {
+		if err := s.db.Exec(stmt).Error; err != nil {
+			log.WithFields("statement", stmt, "error", err).Warn("failed to apply memory optimization")
+		} else {
+			log.WithFields("statement", stmt).Debug("applied memory optimization")
+		}
+	}
+
+	log.Debug("starting database VACUUM operation")
+	if err := s.db.Exec("VACUUM;").Error; err != nil {
+		return fmt.Errorf("unable to vacuum database: %w", err)
+	}
+	log.Debug("database VACUUM operation completed")
+
+	s.dirty = false
+	return nil
+}
+
+// EnsureIndexes creates any indexes that searches rely on which are missing from the DB, which is the case for DBs that
+// were built by tooling other than this store. Without the (package_name, namespace) vulnerability index every
+// SearchForVulnerabilities call is a full table scan. New DBs are created with all indexes, making this a no-op.
+func (s *store) EnsureIndexes() error {
+	var indexes []string
+	if err := s.db.Raw("SELECT name FROM sqlite_master WHERE type = 'index' AND name = ?", model.GetVulnerabilityIndexName).Scan(&indexes).Error; err != nil {
+		return fmt.Errorf("unable to list indexes: %w", err)
+	}
+	if len(indexes) > 0 {
+		return nil
+	}
+
+	s.dirty = true
+	log.WithFields("index", model.GetVulnerabilityIndexName).Debug("creating missing vulnerability index")
+	if err := s.db.Exec(model.GetVulnerabilityIndexStatement()).Error; err != nil {
+		return fmt.Errorf("unable to create vulnerability index: %w", err)
+	}
+	return nil
+}
+
+// resetJournalMode checkpoints the WAL into the DB file and switches from the WAL journal mode to the journal mode
+// described by the configured CloseConfig. This should only be called once no other transactions are in flight.
+func (s *store) resetJournalMode() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	// the journal mode cannot be changed from WAL while other connections to the DB are open
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := s.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error; err != nil {
+		return fmt.Errorf("unable to checkpoint WAL: %w", err)
+	}
+
+	journalMode := s.closeConfig.withDefaults().JournalMode
+	var result string
+	if err := s.db.Raw(fmt.Sprintf("PRAGMA journal_mode = %s", journalMode)).Scan(&result).Error; err != nil {
+		return fmt.Errorf("unable to set journal mode: %w", err)
+	}
+	if strings.EqualFold(result, "wal") {
+		return fmt.Errorf("journal mode was not set to %q (%q)", journalMode, result)
+	}
+
+	log.WithFields("journal_mode", result).Debug("reset database journal mode from WAL")
+	s.wal = false
+	return nil
+}
+
+func (s *store) closeConnection() error {
+	sqlDB, _ := s.db.DB()
+	if sqlDB != nil {
+		_ = sqlDB.Close()
+	}
+
+	return nil
+}
+
+// CountVulnerabilities returns the number of vulnerability records in the database without reading them.
+func (s *store) CountVulnerabilities() (int64, error) {
+	var count int64
+	if result := s.db.Model(&model.VulnerabilityModel{}).Count(&count); result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
+
+// CountVulnerabilityMetadata returns the number of vulnerability metadata records in the database without reading them.
+func (s *store) CountVulnerabilityMetadata() (int64, error) {
+	var count int64
+	if result := s.db.Model(&model.VulnerabilityMetadataModel{}).Count(&count); result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
 
 // GetAllVulnerabilities gets all vulnerabilities in the database
 func (s *store) GetAllVulnerabilities() (*[]v5.Vulnerability, error) {
-	var models []model.VulnerabilityModel
-	if result := s.db.Find(&models); result.Error != nil {
-		return nil, result.Error
+	vulns := make([]v5.Vulnerability, 0)
+	err := s.EachVulnerability(func(v v5.Vulnerability) error {
+		vulns = append(vulns, v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	vulns := make([]v5.Vulnerability, len(models))
-	for idx, m := range models {
+	return &vulns, nil
+}
+
+// EachVulnerability calls the given function with every vulnerability in the database (ordered by namespace, ID, then
+// package name), reading one row at a time from a single query so only one record is inflated in memory at any point.
+// Iteration stops at the first error returned by the function, which is returned as-is. Note that the query is held
+// open for the duration of the iteration, so the function must not query a store limited to a single connection
+// (e.g. an in-memory store) while iterating it; use ForEachVulnerability instead.
+func (s *store) EachVulnerability(fn func(v5.Vulnerability) error) error {
+	return eachVulnerabilityModel(s.db, func(m model.VulnerabilityModel) error {
 		vuln, err := m.Inflate()
 		if err != nil {
-			return nil, err
+			return err
 		}
-		vulns[idx] = vuln
+		return fn(vuln)
+	})
+}
+
+// eachVulnerabilityModel calls the given function with every vulnerability model read by the given query, one row at
+// a time (ordered by namespace, ID, then package name). The row iterator is always closed before returning.
+func eachVulnerabilityModel(db *gorm.DB, fn func(model.VulnerabilityModel) error) error {
+	rows, err := db.Model(&model.VulnerabilityModel{}).
+		Order("namespace").Order("id").Order("package_name").Order("pk").
+		Rows()
+	if err != nil {
+		return err
 	}
-	return &vulns, nil
+	defer rows.Close()
+
+	for rows.Next() {
+		var m model.VulnerabilityModel
+		if err := db.ScanRows(rows, &m); err != nil {
+			return fmt.Errorf("unable to read vulnerability row: %w", err)
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// EachVulnerabilityMetadata calls the given function with every vulnerability metadata record in the database (ordered
+// by namespace then ID), reading one row at a time from a single query. Iteration stops at the first error returned by
+// the function, which is returned as-is. The same caveats as EachVulnerability apply.
+func (s *store) EachVulnerabilityMetadata(fn func(v5.VulnerabilityMetadata) error) error {
+	return eachMetadataModel(s.db, func(m model.VulnerabilityMetadataModel) error {
+		metadata, err := m.Inflate()
+		if err != nil {
+			return err
+		}
+		return fn(metadata)
+	})
+}
+
+// eachMetadataModel calls the given function with every vulnerability metadata model read by the given query, one row
+// at a time (ordered by namespace then ID). The row iterator is always closed before returning.
+func eachMetadataModel(db *gorm.DB, fn func(model.VulnerabilityMetadataModel) error) error {
+	rows, err := db.Model(&model.VulnerabilityMetadataModel{}).Order("namespace").Order("id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m model.VulnerabilityMetadataModel
+		if err := db.ScanRows(rows, &m); err != nil {
+			return fmt.Errorf("unable to read vulnerability metadata row: %w", err)
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
 }
 
 // GetAllVulnerabilityMetadata gets all vulnerability metadata in the database
@@ -343,58 +2192,475 @@ func (s *store) GetAllVulnerabilityMetadata() (*[]v5.VulnerabilityMetadata, erro
 	return &metadata, nil
 }
 
+// iterationBatchSize is the number of records read from the DB at a time when iterating over all records.
+const iterationBatchSize = 1000
+
+// ForEachVulnerability calls the given function with every vulnerability in the database, reading records in
+// batches to keep memory bounded. Iteration stops at the first error returned by the function, which is returned.
+func (s *store) ForEachVulnerability(fn func(v5.Vulnerability) error) error {
+	return s.forEachVulnerabilityBatch(nil, func(models []model.VulnerabilityModel) error {
+		for _, m := range models {
+			vuln, err := m.Inflate()
+			if err != nil {
+				return err
+			}
+			if err := fn(vuln); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ForEachMetadata calls the given function with every vulnerability metadata record in the database (ordered by ID
+// then namespace), reading records in batches to keep memory bounded. Iteration stops at the first error returned
+// by the function, which is returned.
+func (s *store) ForEachMetadata(fn func(v5.VulnerabilityMetadata) error) error {
+	return s.forEachMetadataBatch(nil, func(models []model.VulnerabilityMetadataModel) error {
+		for _, m := range models {
+			metadata, err := m.Inflate()
+			if err != nil {
+				return err
+			}
+			if err := fn(metadata); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// forEachVulnerabilityBatch calls the given function with each batch of vulnerability models (in primary key order)
+// within the given namespaces (or all namespaces when none are given).
+func (s *store) forEachVulnerabilityBatch(namespaces []string, fn func([]model.VulnerabilityModel) error) error {
+	var models []model.VulnerabilityModel
+	result := s.db.Scopes(inNamespaces(namespaces)).FindInBatches(&models, iterationBatchSize, func(_ *gorm.DB, _ int) error {
+		return fn(models)
+	})
+	return result.Error
+}
+
+// forEachMetadataBatch calls the given function with each batch of vulnerability metadata models (ordered by ID then
+// namespace) within the given namespaces (or all namespaces when none are given).
+func (s *store) forEachMetadataBatch(namespaces []string, fn func([]model.VulnerabilityMetadataModel) error) error {
+	// note: the metadata primary key is composite (id, namespace), so batches are seeked by both key columns
+	// (FindInBatches only considers a single primary key column, which would skip IDs spanning batches)
+	var lastID, lastNamespace string
+	for first := true; ; first = false {
+		var models []model.VulnerabilityMetadataModel
+
+		query := s.db.Scopes(inNamespaces(namespaces)).Order("id").Order("namespace").Limit(iterationBatchSize)
+		if !first {
+			query = query.Where("id > ? OR (id = ? AND namespace > ?)", lastID, lastID, lastNamespace)
+		}
+
+		if result := query.Find(&models); result.Error != nil {
+			return result.Error
+		}
+
+		if len(models) > 0 {
+			if err := fn(models); err != nil {
+				return err
+			}
+		}
+
+		if len(models) < iterationBatchSize {
+			return nil
+		}
+
+		last := models[len(models)-1]
+		lastID, lastNamespace = last.ID, last.Namespace
+	}
+}
+
+// inNamespaces restricts a query to records within the given namespaces (no restriction when none are given).
+func inNamespaces(namespaces []string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(namespaces) == 0 {
+			return db
+		}
+		return db.Where("namespace IN ?", namespaces)
+	}
+}
+
 // DiffStore creates a diff between the current sql database and the given store
 func (s *store) DiffStore(targetStore v5.StoreReader) (*[]v5.Diff, error) {
-	// 7 stages, one for each step of the diff process (stages)
-	rowsProgress, diffItems, stager := trackDiff(7)
+	return s.DiffStoreWithOptions(targetStore, v5.DiffOptions{})
+}
 
-	stager.Current = "reading target vulnerabilities"
-	targetVulns, err := targetStore.GetAllVulnerabilities()
-	rowsProgress.Increment()
+// DiffStoreWithOptions creates a diff between the current sql database and the given store, where the options
+// describe which (cosmetic) record fields should not be considered a difference. Diffs are returned in a deterministic
+// order: by namespace then vulnerability ID (see DiffStoreStreamWithOptions).
+func (s *store) DiffStoreWithOptions(targetStore v5.StoreReader, opts v5.DiffOptions) (*[]v5.Diff, error) {
+	allDiffs := []v5.Diff{}
+	err := s.DiffStoreStreamWithOptions(targetStore, opts, func(d v5.Diff) error {
+		allDiffs = append(allDiffs, d)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return &allDiffs, nil
+}
+
+// DiffStoreStream is the same as DiffStore, but calls the given function with each diff as it is computed instead of
+// accumulating all diffs (see DiffStoreStreamWithOptions).
+func (s *store) DiffStoreStream(targetStore v5.StoreReader, out func(v5.Diff) error) error {
+	return s.DiffStoreStreamWithOptions(targetStore, v5.DiffOptions{}, out)
+}
 
-	stager.Current = "reading base vulnerabilities"
-	baseVulns, err := s.GetAllVulnerabilities()
-	rowsProgress.Increment()
+// DiffStoreStreamWithOptions is the same as DiffStoreWithOptions, but calls the given function with each diff as soon
+// as it is found instead of accumulating all diffs. The records of both stores are read one row at a time in namespace
+// then ID order (see EachVulnerability) and walked in step, so the only records held in memory are those of the
+// vulnerability ID and namespace being compared (which yields at most one diff). The diff stops at the first error
+// returned by the function, which is returned as-is.
+func (s *store) DiffStoreStreamWithOptions(targetStore v5.StoreReader, opts v5.DiffOptions, out func(v5.Diff) error) error {
+	totalRows, err := s.diffRowTotal(targetStore, opts.Namespaces)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	stager.Current = "preparing"
-	baseVulnPkgMap := buildVulnerabilityPkgsMap(baseVulns)
-	targetVulnPkgMap := buildVulnerabilityPkgsMap(targetVulns)
+	// progress is tracked by the number of rows compared from both stores
+	rowsProgress, diffItems, stager := trackDiff(totalRows, opts.Progress)
 
-	stager.Current = "comparing vulnerabilities"
-	allDiffsMap := diffVulnerabilities(baseVulns, targetVulns, baseVulnPkgMap, targetVulnPkgMap, diffItems)
+	var namespace string
+	err = s.walkRecords(targetStore, opts.Namespaces, func(base, target inflatedRecordGroup) error {
+		if base.key.namespace != namespace {
+			namespace = base.key.namespace
+			stager.Current = fmt.Sprintf("comparing %s", namespace)
+		}
 
-	stager.Current = "reading base metadata"
-	baseMetadata, err := s.GetAllVulnerabilityMetadata()
+		for _, d := range diffRecords(&base.vulns, &target.vulns, &base.metadata, &target.metadata, opts, diffItems, rowsProgress) {
+			if err := out(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	rowsProgress.SetCompleted()
+	diffItems.SetCompleted()
+
+	return nil
+}
+
+// diffRowTotal returns the number of rows compared when diffing against the given store within the given namespaces
+// (all namespaces when empty), for tracking progress. The rows of a store not backed by this package's store cannot be
+// counted by namespace, in which case all of its rows are counted.
+func (s *store) diffRowTotal(targetStore v5.StoreReader, namespaces []string) (int64, error) {
+	if len(namespaces) == 0 {
+		return diffRowCount(s, targetStore)
+	}
+
+	if t, ok := asStore(targetStore); ok {
+		return diffRowCountInNamespaces(namespaces, s, t)
+	}
+
+	base, err := diffRowCountInNamespaces(namespaces, s)
+	if err != nil {
+		return 0, err
+	}
+	target, err := recordCount(targetStore)
+	if err != nil {
+		return 0, err
+	}
+	return base + target, nil
+}
+
+// diffRowCount returns the total number of vulnerability and metadata rows across both stores (as compared by a diff).
+func diffRowCount(stores ...v5.StoreReader) (int64, error) {
+	var total int64
+	for _, reader := range stores {
+		n, err := recordCount(reader)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// recordCount returns the number of vulnerability and metadata rows in the given store.
+func recordCount(reader v5.StoreReader) (int64, error) {
+	var total int64
+	for _, count := range []func() (int64, error){
+		reader.CountVulnerabilities,
+		reader.CountVulnerabilityMetadata,
+	} {
+		n, err := count()
+		if err != nil {
+			return 0, fmt.Errorf("unable to count records: %w", err)
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// diffRowCountInNamespaces returns the total number of vulnerability and metadata rows within the given namespaces
+// across all stores.
+func diffRowCountInNamespaces(namespaces []string, stores ...*store) (int64, error) {
+	var total int64
+	for _, s := range stores {
+		for _, m := range []any{&model.VulnerabilityModel{}, &model.VulnerabilityMetadataModel{}} {
+			var count int64
+			if result := s.db.Model(m).Where("namespace IN ?", namespaces).Count(&count); result.Error != nil {
+				return 0, fmt.Errorf("unable to count records: %w", result.Error)
+			}
+			total += count
+		}
+	}
+	return total, nil
+}
+
+// DiffCounts computes the number of added, removed, and changed records (by vulnerability ID and namespace) between
+// the current sql database and the given store. Unlike DiffStore, no diffs are built: the records of both stores are
+// streamed in step and compared by a digest of each vulnerability ID and namespace, making this suitable for quick
+// summaries. Records of both stores are digested by their stored column values when the given store is backed by this
+// package's store, otherwise the records of both stores are digested as they would be stored once read.
+func (s *store) DiffCounts(targetStore v5.StoreReader) (added, removed, changed int, err error) {
+	count := func(base, target modelRecordGroup) error {
+		switch {
+		case len(base.vulns) == 0 && len(base.metadata) == 0:
+			added++
+		case len(target.vulns) == 0 && len(target.metadata) == 0:
+			removed++
+		case digestRecords(base) != digestRecords(target):
+			changed++
+		}
+		return nil
+	}
+
+	t, ok := asStore(targetStore)
+	if !ok {
+		err = s.walkRecords(targetStore, nil, func(base, target inflatedRecordGroup) error {
+			return count(toModelRecordGroup(base), toModelRecordGroup(target))
+		})
+	} else {
+		err = s.withConnections(t, func(db, tdb *gorm.DB) error {
+			base := newModelRecordStream(db)
+			defer base.close()
+			target := newModelRecordStream(tdb)
+			defer target.close()
+			return walkRecordStreams(base, target, count)
+		})
+	}
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return added, removed, changed, nil
+}
+
+// DiffSummary summarizes the differences between the current sql database and the given store by vulnerability ID
+// (across all namespaces), for instance for the release notes of a DB build: added IDs only have records in the given
+// store, removed IDs only have records in the current database, and modified IDs have records in both with any
+// differences (including being added to or removed from a namespace). Each list is sorted and deduplicated. This is
+// built on the streaming diff (see DiffStoreStream), so only the IDs are held in memory.
+func (s *store) DiffSummary(targetStore v5.StoreReader) (added, removed, modified []string, err error) {
+	changed := strset.New()
+	err = s.DiffStoreStreamWithOptions(targetStore, v5.DiffOptions{Progress: v5.NoDiffProgress}, func(d v5.Diff) error {
+		changed.Add(d.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	baseIDs, err := vulnerabilityIDs(s)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	targetIDs, err := vulnerabilityIDs(targetStore)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// note: an ID added to (or removed from) one namespace may still have unchanged records in other namespaces,
+	// which is a modification of the ID rather than a new (or withdrawn) ID
+	added, removed, modified = []string{}, []string{}, []string{}
+	changed.Each(func(id string) bool {
+		switch {
+		case !baseIDs.Has(id):
+			added = append(added, id)
+		case !targetIDs.Has(id):
+			removed = append(removed, id)
+		default:
+			modified = append(modified, id)
+		}
+		return true
+	})
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(modified)
+	return added, removed, modified, nil
+}
+
+// vulnerabilityIDs returns the IDs of all vulnerability and metadata records in the given store (across all
+// namespaces).
+func vulnerabilityIDs(reader v5.StoreReader) (*strset.Set, error) {
+	ids := strset.New()
+	if s, ok := asStore(reader); ok {
+		for _, m := range []any{&model.VulnerabilityModel{}, &model.VulnerabilityMetadataModel{}} {
+			var batch []string
+			if result := s.db.Model(m).Distinct().Pluck("id", &batch); result.Error != nil {
+				return nil, fmt.Errorf("unable to read vulnerability IDs: %w", result.Error)
+			}
+			ids.Add(batch...)
+		}
+		return ids, nil
 	}
-	rowsProgress.Increment()
 
-	stager.Current = "reading target metadata"
-	targetMetadata, err := targetStore.GetAllVulnerabilityMetadata()
+	err := reader.EachVulnerability(func(v v5.Vulnerability) error {
+		ids.Add(v.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read vulnerability IDs: %w", err)
+	}
+	err = reader.EachVulnerabilityMetadata(func(m v5.VulnerabilityMetadata) error {
+		ids.Add(m.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read vulnerability IDs: %w", err)
+	}
+	return ids, nil
+}
+
+// recordDigests summarizes all vulnerability and metadata rows by a digest for each vulnerability ID and namespace
+// (ordered by vulnerability ID then namespace). The rows are streamed, so only the digests are held in memory.
+func (s *store) recordDigests() ([]recordDigest, error) {
+	var digests []recordDigest
+	err := s.withConnection(func(db *gorm.DB) error {
+		records := newModelRecordStream(db)
+		defer records.close()
+		for {
+			k, ok := records.nextKey()
+			if !ok {
+				return records.err()
+			}
+			digests = append(digests, recordDigest{key: k, digest: digestRecords(records.take(k))})
+		}
+	})
 	if err != nil {
 		return nil, err
 	}
-	rowsProgress.Increment()
 
-	stager.Current = "comparing metadata"
-	metaDiffsMap := diffVulnerabilityMetadata(baseMetadata, targetMetadata, baseVulnPkgMap, targetVulnPkgMap, diffItems)
-	for k, diff := range *metaDiffsMap {
-		(*allDiffsMap)[k] = diff
+	sort.Slice(digests, func(i, j int) bool {
+		return lessStoreKey(digests[i].key, digests[j].key)
+	})
+	return digests, nil
+}
+
+// CoverageDiff reports which (namespace, package, vulnerability) keys exist in the reference store but not the current
+// sql database (missing) and vice versa (extra), with counts per namespace. Only the keys are read, no records are
+// inflated or compared field by field (unless the reference store is not backed by a sql database).
+func (s *store) CoverageDiff(reference v5.StoreReader) (v5.CoverageReport, error) {
+	local, err := s.coverageKeys()
+	if err != nil {
+		return v5.CoverageReport{}, err
 	}
-	allDiffs := []v5.Diff{}
-	for _, diff := range *allDiffsMap {
-		allDiffs = append(allDiffs, *diff)
+
+	var ref map[v5.CoverageKey]struct{}
+	if r, ok := asStore(reference); ok {
+		ref, err = r.coverageKeys()
+	} else {
+		ref, err = coverageKeysFromReader(reference)
+	}
+	if err != nil {
+		return v5.CoverageReport{}, err
 	}
 
-	rowsProgress.SetCompleted()
-	diffItems.SetCompleted()
+	return buildCoverageReport(local, ref), nil
+}
 
-	return &allDiffs, nil
+// coverageKeys returns the set of distinct (namespace, package, vulnerability) keys in the database.
+func (s *store) coverageKeys() (map[v5.CoverageKey]struct{}, error) {
+	var rows []struct {
+		Namespace   string
+		PackageName string
+		ID          string
+	}
+
+	result := s.db.Model(&model.VulnerabilityModel{}).Distinct("namespace", "package_name", "id").Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	keys := make(map[v5.CoverageKey]struct{}, len(rows))
+	for _, r := range rows {
+		keys[v5.CoverageKey{Namespace: r.Namespace, PackageName: r.PackageName, VulnerabilityID: r.ID}] = struct{}{}
+	}
+	return keys, nil
+}
+
+// coverageKeysFromReader returns the set of distinct keys from a store reader that is not backed by this package's store.
+func coverageKeysFromReader(reader v5.StoreReader) (map[v5.CoverageKey]struct{}, error) {
+	keys := make(map[v5.CoverageKey]struct{})
+	err := reader.ForEachVulnerability(func(v v5.Vulnerability) error {
+		keys[v5.CoverageKey{Namespace: v.Namespace, PackageName: v.PackageName, VulnerabilityID: v.ID}] = struct{}{}
+		return nil
+	})
+	return keys, err
+}
+
+func buildCoverageReport(local, reference map[v5.CoverageKey]struct{}) v5.CoverageReport {
+	report := v5.CoverageReport{
+		Missing: []v5.CoverageKey{},
+		Extra:   []v5.CoverageKey{},
+	}
+
+	byNamespace := make(map[string]*v5.NamespaceCoverage)
+	get := func(namespace string) *v5.NamespaceCoverage {
+		if _, ok := byNamespace[namespace]; !ok {
+			byNamespace[namespace] = &v5.NamespaceCoverage{Namespace: namespace}
+		}
+		return byNamespace[namespace]
+	}
+
+	for k := range reference {
+		if _, ok := local[k]; ok {
+			get(k.Namespace).Shared++
+			continue
+		}
+		get(k.Namespace).Missing++
+		report.Missing = append(report.Missing, k)
+	}
+
+	for k := range local {
+		if _, ok := reference[k]; ok {
+			continue
+		}
+		get(k.Namespace).Extra++
+		report.Extra = append(report.Extra, k)
+	}
+
+	sortCoverageKeys(report.Missing)
+	sortCoverageKeys(report.Extra)
+
+	report.Namespaces = make([]v5.NamespaceCoverage, 0, len(byNamespace))
+	for _, c := range byNamespace {
+		report.Namespaces = append(report.Namespaces, *c)
+	}
+	sort.Slice(report.Namespaces, func(i, j int) bool {
+		return report.Namespaces[i].Namespace < report.Namespaces[j].Namespace
+	})
+
+	return report
+}
+
+func sortCoverageKeys(keys []v5.CoverageKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.PackageName != b.PackageName {
+			return a.PackageName < b.PackageName
+		}
+		return a.VulnerabilityID < b.VulnerabilityID
+	})
 }
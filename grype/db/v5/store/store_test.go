@@ -1,13 +1,22 @@
 package store
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-test/deep"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 
 	v5 "github.com/anchore/grype/grype/db/v5"
 	"github.com/anchore/grype/grype/db/v5/store/model"
@@ -296,7 +305,7 @@ func TestStore_GetVulnerabilityMetadata_SetVulnerabilityMetadata(t *testing.T) {
 						2.2,
 						3.3,
 					),
-					Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--NOT",
+					Vector: "AV:L/AC:L/Au:N/C:P/I:P/A:P",
 				},
 				{
 					Version: "3.0",
@@ -305,7 +314,7 @@ func TestStore_GetVulnerabilityMetadata_SetVulnerabilityMetadata(t *testing.T) {
 						2.1,
 						3.2,
 					),
-					Vector:         "AV:N/AC:L/Au:N/C:P/I:P/A:P--NICE",
+					Vector:         "CVSS:3.0/AV:N/AC:H/PR:N/UI:N/S:U/C:L/I:L/A:L",
 					VendorMetadata: nil,
 				},
 			},
@@ -325,7 +334,7 @@ func TestStore_GetVulnerabilityMetadata_SetVulnerabilityMetadata(t *testing.T) {
 						5.2,
 						6.3,
 					),
-					Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
+					Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
 				},
 				{
 					Version: "3.0",
@@ -334,13 +343,13 @@ func TestStore_GetVulnerabilityMetadata_SetVulnerabilityMetadata(t *testing.T) {
 						2.5,
 						3.6,
 					),
-					Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+					Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 				},
 			},
 		},
 	}
 
-	if err = s.AddVulnerabilityMetadata(total...); err != nil {
+	if _, err = s.AddVulnerabilityMetadata(total...); err != nil {
 		t.Fatalf("failed to set metadata: %+v", err)
 	}
 
@@ -377,7 +386,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 								5.2,
 								6.3,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
+							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
 						},
 						{
 							Version: "3.0",
@@ -386,7 +395,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 								2.5,
 								3.6,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+							Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 						},
 					},
 				},
@@ -406,7 +415,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 							5.2,
 							6.3,
 						),
-						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
+						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
 					},
 					{
 						Version: "3.0",
@@ -415,7 +424,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 							2.5,
 							3.6,
 						),
-						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+						Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 					},
 				},
 			},
@@ -494,7 +503,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 								5.2,
 								6.3,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
+							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
 						},
 						{
 							Version: "3.0",
@@ -503,7 +512,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 								2.5,
 								3.6,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+							Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 						},
 					},
 				},
@@ -522,7 +531,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 								5.2,
 								6.3,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
+							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
 						},
 						{
 							Version: "3.0",
@@ -531,7 +540,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 								2.5,
 								3.6,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+							Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 						},
 					},
 				},
@@ -556,7 +565,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 								5.2,
 								6.3,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
+							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
 						},
 						{
 							Version: "3.0",
@@ -565,7 +574,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 								2.5,
 								3.6,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+							Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 						},
 					},
 				},
@@ -584,7 +593,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 								5.2,
 								6.3,
 							),
-							Vector: "AV:P--VERY",
+							Vector: "AV:A/AC:L/Au:N/C:P/I:P/A:P",
 						},
 						{
 							Version: "3.0",
@@ -593,7 +602,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 								2.5,
 								3.6,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+							Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 						},
 					},
 				},
@@ -613,16 +622,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 							5.2,
 							6.3,
 						),
-						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
-					},
-					{
-						Version: "3.0",
-						Metrics: v5.NewCvssMetrics(
-							1.4,
-							2.5,
-							3.6,
-						),
-						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+						Vector: "AV:A/AC:L/Au:N/C:P/I:P/A:P",
 					},
 					{
 						Version: "2.0",
@@ -631,7 +631,16 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 							5.2,
 							6.3,
 						),
-						Vector: "AV:P--VERY",
+						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
+					},
+					{
+						Version: "3.0",
+						Metrics: v5.NewCvssMetrics(
+							1.4,
+							2.5,
+							3.6,
+						),
+						Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 					},
 				},
 			},
@@ -655,7 +664,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 								5.2,
 								6.3,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
+							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
 						},
 						{
 							Version: "3.0",
@@ -664,7 +673,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 								2.5,
 								3.6,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+							Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 						},
 					},
 				},
@@ -683,7 +692,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 								5.2,
 								6.3,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
+							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
 						},
 						{
 							Version: "3.0",
@@ -692,7 +701,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 								0,
 								3.6,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+							Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 						},
 					},
 				},
@@ -712,7 +721,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 							5.2,
 							6.3,
 						),
-						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
+						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
 					},
 					{
 						Version: "3.0",
@@ -721,7 +730,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 							2.5,
 							3.6,
 						),
-						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+						Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 					},
 					{
 						Version: "3.0",
@@ -730,7 +739,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 							0,
 							3.6,
 						),
-						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+						Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 					},
 				},
 			},
@@ -749,7 +758,7 @@ func TestStore_MergeVulnerabilityMetadata(t *testing.T) {
 			// add each metadata in order
 			var theErr error
 			for _, metadata := range test.add {
-				err = s.AddVulnerabilityMetadata(metadata)
+				_, err = s.AddVulnerabilityMetadata(metadata)
 				if err != nil {
 					theErr = err
 					break
@@ -811,7 +820,7 @@ func TestCvssScoresInMetadata(t *testing.T) {
 								5.2,
 								6.3,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
+							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
 						},
 					},
 				},
@@ -830,7 +839,7 @@ func TestCvssScoresInMetadata(t *testing.T) {
 								2.5,
 								3.6,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+							Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 						},
 					},
 				},
@@ -850,7 +859,7 @@ func TestCvssScoresInMetadata(t *testing.T) {
 							5.2,
 							6.3,
 						),
-						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
+						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
 					},
 					{
 						Version: "3.0",
@@ -859,7 +868,7 @@ func TestCvssScoresInMetadata(t *testing.T) {
 							2.5,
 							3.6,
 						),
-						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+						Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 					},
 				},
 			},
@@ -882,7 +891,7 @@ func TestCvssScoresInMetadata(t *testing.T) {
 								5.2,
 								6.3,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
+							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
 						},
 					},
 				},
@@ -901,7 +910,7 @@ func TestCvssScoresInMetadata(t *testing.T) {
 								5.2,
 								6.3,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
+							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
 							VendorMetadata: CustomMetadata{
 								SuperScore: "100",
 								Vendor:     "debian",
@@ -925,7 +934,7 @@ func TestCvssScoresInMetadata(t *testing.T) {
 							5.2,
 							6.3,
 						),
-						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
+						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
 					},
 					{
 						Version: "2.0",
@@ -934,7 +943,7 @@ func TestCvssScoresInMetadata(t *testing.T) {
 							5.2,
 							6.3,
 						),
-						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--VERY",
+						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
 						VendorMetadata: CustomMetadata{
 							SuperScore: "100",
 							Vendor:     "debian",
@@ -961,7 +970,7 @@ func TestCvssScoresInMetadata(t *testing.T) {
 								2.5,
 								3.6,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+							Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 						},
 					},
 				},
@@ -980,7 +989,7 @@ func TestCvssScoresInMetadata(t *testing.T) {
 								2.5,
 								3.6,
 							),
-							Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+							Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 						},
 					},
 				},
@@ -1000,7 +1009,7 @@ func TestCvssScoresInMetadata(t *testing.T) {
 							2.5,
 							3.6,
 						),
-						Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P--GOOD",
+						Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
 					},
 				},
 			},
@@ -1017,7 +1026,7 @@ func TestCvssScoresInMetadata(t *testing.T) {
 
 			// add each metadata in order
 			for _, metadata := range test.add {
-				err = s.AddVulnerabilityMetadata(metadata)
+				_, err = s.AddVulnerabilityMetadata(metadata)
 				if err != nil {
 					t.Fatalf("unable to s vulnerability metadata: %+v", err)
 				}
@@ -1373,3 +1382,4332 @@ func Test_DiffStore(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expectedDiffs, *result)
 }
+
+func TestStore_SearchForVulnerabilitiesWithOptions_PagesSharedIDs(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(id, name, constraint string) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			PackageName:       name,
+			Namespace:         "my-namespace",
+			VersionConstraint: constraint,
+			VersionFormat:     "semver",
+		}
+	}
+
+	var vulns []v5.Vulnerability
+	for _, id := range []string{"CVE-4", "CVE-2", "CVE-5", "CVE-1", "CVE-3"} {
+		vulns = append(vulns, newVuln(id, "package-name", "< 1.0"))
+	}
+	// rows sharing the same ID (and package) are ordered by insertion
+	vulns = append(vulns, newVuln("CVE-2", "package-name", "< 2.0"), newVuln("CVE-0", "other-package-name", "< 1.0"))
+
+	if err = s.AddVulnerability(vulns...); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+
+	keys := func(vulns []v5.Vulnerability) []string {
+		var out []string
+		for _, v := range vulns {
+			out = append(out, v.ID+" "+v.VersionConstraint)
+		}
+		return out
+	}
+
+	page := func(limit, offset int) []string {
+		vulns, err := s.SearchForVulnerabilitiesWithOptions("my-namespace", "package-name", v5.SearchOptions{Limit: limit, Offset: offset})
+		assert.NoError(t, err)
+		return keys(vulns)
+	}
+
+	// the page boundary falls between the rows of CVE-2
+	assert.Equal(t, []string{"CVE-1 < 1.0", "CVE-2 < 1.0"}, page(2, 0))
+	assert.Equal(t, []string{"CVE-2 < 2.0", "CVE-3 < 1.0"}, page(2, 2))
+	assert.Equal(t, []string{"CVE-4 < 1.0", "CVE-5 < 1.0"}, page(0, 4))
+	assert.Len(t, page(0, 0), 6)
+}
+
+func TestStore_GetProvenance(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	fetchedAt := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+	vulns := []v5.Vulnerability{
+		{
+			ID:                "CVE-with-provenance",
+			PackageName:       "package-name",
+			Namespace:         "my-namespace",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+			Provenance: &v5.Provenance{
+				SourceName: "my-feed",
+				SourceURL:  "https://example.com/feed.json",
+				FetchedAt:  fetchedAt,
+			},
+		},
+		{
+			ID:                "CVE-without-provenance",
+			PackageName:       "package-name",
+			Namespace:         "my-namespace",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+		},
+	}
+
+	if err = s.AddVulnerability(vulns...); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+
+	actual, err := s.GetProvenance("CVE-with-provenance", "my-namespace")
+	assert.NoError(t, err)
+	assert.Equal(t, vulns[0].Provenance, actual)
+
+	actual, err = s.GetProvenance("CVE-without-provenance", "my-namespace")
+	assert.NoError(t, err)
+	assert.Nil(t, actual)
+
+	actual, err = s.GetProvenance("CVE-with-provenance", "other-namespace")
+	assert.NoError(t, err)
+	assert.Nil(t, actual)
+
+	assertVulnerabilityReader(t, s, "my-namespace", "package-name", vulns)
+}
+
+func TestStore_GetVulnerabilitiesByConstraintOperator(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(id, pkgName, namespace, constraint string) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			PackageName:       pkgName,
+			Namespace:         namespace,
+			VersionConstraint: constraint,
+			VersionFormat:     "semver",
+		}
+	}
+
+	vulns := []v5.Vulnerability{
+		newVuln("CVE-3", "pkg-a", "my-namespace", "= 1.0.0"),
+		newVuln("CVE-1", "pkg-b", "my-namespace", ">= 2.0, < 2.5 || 3.0.0"),
+		newVuln("CVE-1", "pkg-a", "my-namespace", ">= 1.0"),
+		newVuln("CVE-2", "pkg-a", "my-namespace", "< 1.0"),
+		newVuln("CVE-4", "pkg-a", "my-namespace", "(unparsable"),
+		newVuln("CVE-5", "pkg-a", "other-namespace", "= 1.0.0"),
+	}
+
+	if err = s.AddVulnerability(vulns...); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+
+	ids := func(vs []v5.Vulnerability) []string {
+		var out []string
+		for _, v := range vs {
+			out = append(out, v.ID+"/"+v.PackageName)
+		}
+		return out
+	}
+
+	actual, err := s.GetVulnerabilitiesByConstraintOperator("my-namespace", ">=")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CVE-1/pkg-a", "CVE-1/pkg-b"}, ids(actual))
+
+	// a bare version within a constraint is an implicit equality
+	actual, err = s.GetVulnerabilitiesByConstraintOperator("my-namespace", "=")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CVE-1/pkg-b", "CVE-3/pkg-a"}, ids(actual))
+
+	actual, err = s.GetVulnerabilitiesByConstraintOperator("my-namespace", "<=")
+	assert.NoError(t, err)
+	assert.Empty(t, actual)
+
+	_, err = s.GetVulnerabilitiesByConstraintOperator("my-namespace", "~>")
+	assert.Error(t, err)
+}
+
+func TestStore_GetRecentlyModifiedMetadata(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newMetadata := func(id string, cvss ...v5.Cvss) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{
+			ID:           id,
+			Namespace:    "my-namespace",
+			RecordSource: "record-source",
+			Severity:     "medium",
+			Cvss:         cvss,
+		}
+	}
+
+	build := func(buildTime time.Time) {
+		if err := s.SetID(v5.ID{BuildTimestamp: buildTime, SchemaVersion: v5.SchemaVersion}); err != nil {
+			t.Fatalf("failed to set ID: %+v", err)
+		}
+	}
+
+	build(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	for _, id := range []string{"CVE-1", "CVE-2", "CVE-3"} {
+		if _, err = s.AddVulnerabilityMetadata(newMetadata(id)); err != nil {
+			t.Fatalf("failed to add metadata: %+v", err)
+		}
+	}
+
+	// re-scoring an older record in a later build should make it the most recently modified
+	build(time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC))
+	rescored := newMetadata("CVE-1", v5.Cvss{
+		Version: "3.1",
+		Vector:  "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		Metrics: v5.NewCvssMetrics(9.8, 3.9, 5.9),
+	})
+	if _, err = s.AddVulnerabilityMetadata(rescored); err != nil {
+		t.Fatalf("failed to merge metadata: %+v", err)
+	}
+
+	ids := func(ms []v5.VulnerabilityMetadata) []string {
+		var out []string
+		for _, m := range ms {
+			out = append(out, m.ID)
+		}
+		return out
+	}
+
+	// records modified at the same time are ordered by ID
+	actual, err := s.GetRecentlyModifiedMetadata(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CVE-1", "CVE-2"}, ids(actual))
+	assert.Len(t, actual[0].Cvss, 1)
+
+	actual, err = s.GetRecentlyModifiedMetadata(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CVE-1", "CVE-2", "CVE-3"}, ids(actual))
+}
+
+func TestStore_LastModified(t *testing.T) {
+	buildTime := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	idTime := time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		setID    bool
+		expected []string
+	}{
+		{
+			name:     "no build time",
+			expected: []string{""},
+		},
+		{
+			name:     "build time of the DB ID",
+			setID:    true,
+			expected: []string{model.FormatLastModified(idTime)},
+		},
+		{
+			name:     "explicit build time",
+			opts:     []Option{WithBuildTime(buildTime)},
+			setID:    true,
+			expected: []string{model.FormatLastModified(buildTime)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(t.TempDir(), true, tt.opts...)
+			if err != nil {
+				t.Fatalf("could not create store: %+v", err)
+			}
+			if tt.setID {
+				if err = s.SetID(v5.ID{BuildTimestamp: idTime, SchemaVersion: v5.SchemaVersion}); err != nil {
+					t.Fatalf("failed to set ID: %+v", err)
+				}
+			}
+			if err = s.AddVulnerability(v5.Vulnerability{ID: "CVE-1", Namespace: "nvd:cpe", PackageName: "pkg", VersionConstraint: "< 1.0", VersionFormat: "semver"}); err != nil {
+				t.Fatalf("failed to add vulnerability: %+v", err)
+			}
+			if _, err = s.AddVulnerabilityMetadata(v5.VulnerabilityMetadata{ID: "CVE-1", Namespace: "nvd:cpe", Severity: "High"}); err != nil {
+				t.Fatalf("failed to add metadata: %+v", err)
+			}
+
+			// the same modification time is stamped regardless of when the records were written
+			db := s.(*store).db
+			for _, table := range []string{model.VulnerabilityTableName, model.VulnerabilityMetadataTableName} {
+				var actual []string
+				err := db.Raw("SELECT COALESCE(last_modified, '') FROM " + table).Scan(&actual).Error
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, actual, table)
+			}
+		})
+	}
+}
+
+func TestStore_GetRecentlyModifiedMetadata_NoLastModifiedColumn(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	if _, err = s.AddVulnerabilityMetadata(v5.VulnerabilityMetadata{ID: "CVE-1", Namespace: "my-namespace", Severity: "medium"}); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+	dropLastModifiedColumns(t, s)
+
+	actual, err := s.GetRecentlyModifiedMetadata(0)
+	assert.NoError(t, err)
+	assert.Empty(t, actual)
+}
+
+// dropLastModifiedColumns removes the last_modified columns, as found in DBs built before modification times were
+// recorded.
+func dropLastModifiedColumns(t *testing.T, s v5.Store) {
+	t.Helper()
+	for _, table := range []string{model.VulnerabilityTableName, model.VulnerabilityMetadataTableName} {
+		if err := s.(*store).db.Exec("ALTER TABLE " + table + " DROP COLUMN last_modified").Error; err != nil {
+			t.Fatalf("failed to drop last_modified column: %+v", err)
+		}
+	}
+}
+
+func TestStore_GetVulnerabilityDetail(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	ghsa := v5.VulnerabilityReference{ID: "GHSA-1234", Namespace: "github:language:python"}
+	nvd := v5.VulnerabilityReference{ID: "CVE-1234", Namespace: "nvd:cpe"}
+
+	vulns := []v5.Vulnerability{
+		{
+			ID:                     "CVE-1234",
+			PackageName:            "requests",
+			Namespace:              "github:language:python",
+			VersionConstraint:      "< 2.0",
+			VersionFormat:          "python",
+			RelatedVulnerabilities: []v5.VulnerabilityReference{ghsa, nvd},
+			Fix: v5.Fix{
+				Versions: []string{"2.0"},
+				State:    v5.FixedState,
+			},
+		},
+		{
+			ID:                     "CVE-1234",
+			PackageName:            "python-requests",
+			Namespace:              "debian:distro:debian:12",
+			VersionConstraint:      "< 2.0-1",
+			VersionFormat:          "deb",
+			RelatedVulnerabilities: []v5.VulnerabilityReference{nvd},
+		},
+		{
+			ID:                "CVE-other",
+			PackageName:       "requests",
+			Namespace:         "github:language:python",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "python",
+		},
+	}
+	metadata := []v5.VulnerabilityMetadata{
+		{
+			ID:           "CVE-1234",
+			Namespace:    "nvd:cpe",
+			RecordSource: "nvdv2:nvdv2:cves",
+			Severity:     "high",
+		},
+		{
+			ID:           "CVE-1234",
+			Namespace:    "debian:distro:debian:12",
+			RecordSource: "vulnerabilities:debian:12",
+			Severity:     "medium",
+		},
+	}
+	exclusion := v5.VulnerabilityMatchExclusion{
+		ID: "CVE-1234",
+		Constraints: []v5.VulnerabilityMatchExclusionConstraint{
+			{
+				Package: v5.PackageExclusionConstraint{
+					Name: "requests",
+				},
+			},
+		},
+		Justification: "false positive",
+	}
+
+	if err = s.AddVulnerability(vulns...); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+	if _, err = s.AddVulnerabilityMetadata(metadata...); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+	if err = s.AddVulnerabilityMatchExclusion(exclusion); err != nil {
+		t.Fatalf("failed to add match exclusion: %+v", err)
+	}
+
+	actual, err := s.GetVulnerabilityDetail("CVE-1234")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "CVE-1234", actual.ID)
+	if assert.Len(t, actual.Vulnerabilities, 2) {
+		assert.Equal(t, "debian:distro:debian:12", actual.Vulnerabilities[0].Namespace)
+		assert.Equal(t, "github:language:python", actual.Vulnerabilities[1].Namespace)
+		assert.Equal(t, vulns[0].Fix, actual.Vulnerabilities[1].Fix)
+	}
+	if assert.Len(t, actual.Metadata, 2) {
+		assert.Equal(t, "debian:distro:debian:12", actual.Metadata[0].Namespace)
+		assert.Equal(t, "nvd:cpe", actual.Metadata[1].Namespace)
+	}
+	assert.Equal(t, []v5.VulnerabilityMatchExclusion{exclusion}, actual.MatchExclusions)
+	assert.Equal(t, []v5.VulnerabilityReference{ghsa}, actual.Aliases)
+
+	actual, err = s.GetVulnerabilityDetail("CVE-missing")
+	assert.NoError(t, err)
+	assert.Empty(t, actual.Vulnerabilities)
+	assert.Empty(t, actual.Metadata)
+}
+
+func TestStore_GetVulnerabilitiesAboveCVSS(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(id string) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			PackageName:       "package-name",
+			Namespace:         "my-namespace",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+		}
+	}
+	newMetadata := func(id string, scores ...float64) v5.VulnerabilityMetadata {
+		var cvss []v5.Cvss
+		for _, score := range scores {
+			cvss = append(cvss, v5.Cvss{
+				Version: "3.1",
+				Metrics: v5.NewCvssMetrics(score, 0, 0),
+			})
+		}
+		return v5.VulnerabilityMetadata{
+			ID:           id,
+			Namespace:    "my-namespace",
+			RecordSource: "record-source",
+			Cvss:         cvss,
+		}
+	}
+
+	vulns := []v5.Vulnerability{
+		newVuln("CVE-critical"),
+		newVuln("CVE-mixed"),
+		newVuln("CVE-low"),
+		newVuln("CVE-unscored"),
+		newVuln("CVE-no-metadata"),
+	}
+	metadata := []v5.VulnerabilityMetadata{
+		newMetadata("CVE-critical", 9.8),
+		newMetadata("CVE-mixed", 4.0, 7.5),
+		newMetadata("CVE-low", 3.1),
+		newMetadata("CVE-unscored"),
+	}
+
+	if err = s.AddVulnerability(vulns...); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+	if _, err = s.AddVulnerabilityMetadata(metadata...); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	ids := func(vs []v5.Vulnerability) []string {
+		var out []string
+		for _, v := range vs {
+			out = append(out, v.ID)
+		}
+		return out
+	}
+
+	actual, err := s.GetVulnerabilitiesAboveCVSS("my-namespace", 7.0, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CVE-critical", "CVE-mixed"}, ids(actual))
+
+	actual, err = s.GetVulnerabilitiesAboveCVSS("my-namespace", 7.0, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CVE-critical", "CVE-mixed", "CVE-no-metadata", "CVE-unscored"}, ids(actual))
+
+	actual, err = s.GetVulnerabilitiesAboveCVSS("other-namespace", 0, true)
+	assert.NoError(t, err)
+	assert.Empty(t, actual)
+}
+
+func TestStore_WriteIDFile(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	idPath := filepath.Join(t.TempDir(), v5.IDFileName)
+
+	// there is no ID to write yet
+	assert.Error(t, s.WriteIDFile(idPath))
+
+	expected := v5.ID{
+		BuildTimestamp: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		SchemaVersion:  v5.SchemaVersion,
+	}
+
+	if err = s.SetID(expected); err != nil {
+		t.Fatalf("failed to set ID: %+v", err)
+	}
+
+	if err = s.WriteIDFile(idPath); err != nil {
+		t.Fatalf("failed to write ID file: %+v", err)
+	}
+
+	actual, err := v5.ReadIDFile(idPath)
+	assert.NoError(t, err)
+	assert.Equal(t, &expected, actual)
+
+	contents, err := os.ReadFile(idPath)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version": 1, "build_timestamp": "2024-01-02T03:04:05.000000006Z", "schema_version": 5}`, string(contents))
+
+	_, err = v5.UnmarshalIDFile([]byte(`{"version": 99, "build_timestamp": "2024-01-02T03:04:05Z", "schema_version": 5}`))
+	assert.Error(t, err)
+}
+
+func TestStore_GetVulnerabilitiesForPackages(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	var vulns []v5.Vulnerability
+	for i := 0; i < packageNameBatchSize+10; i++ {
+		vulns = append(vulns, v5.Vulnerability{
+			ID:                fmt.Sprintf("CVE-%04d", i),
+			PackageName:       fmt.Sprintf("package-%04d", i),
+			Namespace:         "my-namespace",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+		})
+	}
+	vulns = append(vulns, v5.Vulnerability{
+		ID:                "CVE-other-namespace",
+		PackageName:       "package-0000",
+		Namespace:         "other-namespace",
+		VersionConstraint: "< 1.0",
+		VersionFormat:     "semver",
+	})
+
+	if err = s.AddVulnerability(vulns...); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+
+	// all package names (spanning multiple batches) with duplicates and a name with no vulnerabilities
+	var names []string
+	for i := 0; i < packageNameBatchSize+10; i++ {
+		names = append(names, fmt.Sprintf("package-%04d", i))
+	}
+	names = append(names, "package-0000", "package-missing")
+
+	actual, err := s.GetVulnerabilitiesForPackages("my-namespace", names)
+	assert.NoError(t, err)
+	if assert.Len(t, actual, packageNameBatchSize+10) {
+		for i, v := range actual {
+			assert.Equal(t, fmt.Sprintf("CVE-%04d", i), v.ID)
+		}
+	}
+
+	actual, err = s.GetVulnerabilitiesForPackages("my-namespace", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, actual)
+}
+
+func BenchmarkStore_GetVulnerabilitiesForPackages(b *testing.B) {
+	s, err := New(b.TempDir(), true)
+	if err != nil {
+		b.Fatalf("could not create store: %+v", err)
+	}
+
+	var vulns []v5.Vulnerability
+	var names []string
+	for i := 0; i < 2000; i++ {
+		name := fmt.Sprintf("package-%04d", i)
+		names = append(names, name)
+		vulns = append(vulns, v5.Vulnerability{
+			ID:                fmt.Sprintf("CVE-%04d", i),
+			PackageName:       name,
+			Namespace:         "my-namespace",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+		})
+	}
+
+	if err = s.AddVulnerability(vulns...); err != nil {
+		b.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+
+	b.Run("per package", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, name := range names {
+				if _, err := s.SearchForVulnerabilities("my-namespace", name); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := s.GetVulnerabilitiesForPackages("my-namespace", names); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestStore_ReplaceVulnerabilityMatchExclusions(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newExclusion := func(id, pkgName string) v5.VulnerabilityMatchExclusion {
+		return v5.VulnerabilityMatchExclusion{
+			ID: id,
+			Constraints: []v5.VulnerabilityMatchExclusionConstraint{
+				{
+					Package: v5.PackageExclusionConstraint{
+						Name: pkgName,
+					},
+				},
+			},
+			Justification: "not affected",
+		}
+	}
+
+	existing := []v5.VulnerabilityMatchExclusion{
+		newExclusion("CVE-1234", "abc"),
+		newExclusion("CVE-1234", "def"),
+		newExclusion("CVE-1234", "ghi"),
+		newExclusion("CVE-other", "abc"),
+	}
+	if err = s.AddVulnerabilityMatchExclusion(existing...); err != nil {
+		t.Fatalf("failed to add exclusions: %+v", err)
+	}
+
+	// replace with fewer exclusions
+	replacement := []v5.VulnerabilityMatchExclusion{
+		newExclusion("CVE-1234", "xyz"),
+	}
+	assert.NoError(t, s.ReplaceVulnerabilityMatchExclusions("CVE-1234", replacement))
+
+	actual, err := s.GetVulnerabilityMatchExclusion("CVE-1234")
+	assert.NoError(t, err)
+	assert.Equal(t, replacement, actual)
+
+	// other IDs are untouched
+	actual, err = s.GetVulnerabilityMatchExclusion("CVE-other")
+	assert.NoError(t, err)
+	assert.Equal(t, existing[3:], actual)
+
+	// a failed replacement leaves the existing exclusions intact
+	assert.Error(t, s.ReplaceVulnerabilityMatchExclusions("CVE-1234", []v5.VulnerabilityMatchExclusion{newExclusion("CVE-mismatch", "abc")}))
+	actual, err = s.GetVulnerabilityMatchExclusion("CVE-1234")
+	assert.NoError(t, err)
+	assert.Equal(t, replacement, actual)
+
+	// replacing with nothing removes all exclusions
+	assert.NoError(t, s.ReplaceVulnerabilityMatchExclusions("CVE-1234", nil))
+	actual, err = s.GetVulnerabilityMatchExclusion("CVE-1234")
+	assert.NoError(t, err)
+	assert.Empty(t, actual)
+}
+
+func TestStore_FindSeverityConflicts(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newMetadata := func(id, namespace, severity string) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{
+			ID:           id,
+			Namespace:    namespace,
+			RecordSource: "record-source",
+			Severity:     severity,
+		}
+	}
+
+	metadata := []v5.VulnerabilityMetadata{
+		newMetadata("CVE-conflict", "nvd:cpe", "Critical"),
+		newMetadata("CVE-conflict", "debian:distro:debian:12", "Low"),
+		newMetadata("CVE-conflict", "ubuntu:distro:ubuntu:22.04", "Unknown"),
+		newMetadata("CVE-agree", "nvd:cpe", "High"),
+		newMetadata("CVE-agree", "debian:distro:debian:12", "high"),
+		newMetadata("CVE-agree", "ubuntu:distro:ubuntu:22.04", ""),
+		newMetadata("CVE-single", "nvd:cpe", "Medium"),
+	}
+
+	if _, err = s.AddVulnerabilityMetadata(metadata...); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	actual, err := s.FindSeverityConflicts()
+	assert.NoError(t, err)
+	assert.Equal(t, []v5.SeverityConflict{
+		{
+			ID: "CVE-conflict",
+			Severities: []v5.NamespacedSeverity{
+				{Namespace: "debian:distro:debian:12", Severity: "Low"},
+				{Namespace: "nvd:cpe", Severity: "Critical"},
+			},
+		},
+	}, actual)
+}
+
+func TestStore_HighSeverityQueries(t *testing.T) {
+	newMetadata := func(id, namespace, severity string) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{
+			ID:           id,
+			Namespace:    namespace,
+			RecordSource: "record-source",
+			Severity:     severity,
+		}
+	}
+
+	metadata := []v5.VulnerabilityMetadata{
+		newMetadata("CVE-critical", "nvd:cpe", "Critical"),
+		newMetadata("CVE-high", "debian:distro:debian:12", "High"),
+		newMetadata("CVE-medium", "debian:distro:debian:12", "Medium"),
+		newMetadata("CVE-low", "ubuntu:distro:ubuntu:22.04", "Low"),
+	}
+
+	for _, withIndex := range []bool{false, true} {
+		t.Run(fmt.Sprintf("index=%v", withIndex), func(t *testing.T) {
+			s, err := New(t.TempDir(), true, WithHighSeverityIndex(withIndex))
+			if err != nil {
+				t.Fatalf("could not create store: %+v", err)
+			}
+
+			if _, err = s.AddVulnerabilityMetadata(metadata...); err != nil {
+				t.Fatalf("failed to add metadata: %+v", err)
+			}
+
+			var indexes []string
+			s.(*store).db.Raw("SELECT name FROM sqlite_master WHERE type = 'index' AND name = ?", model.HighSeverityMetadataIndexName).Scan(&indexes)
+			assert.Equal(t, withIndex, len(indexes) == 1)
+
+			for namespace, expected := range map[string]bool{
+				"":                           true,
+				"nvd:cpe":                    true,
+				"debian:distro:debian:12":    true,
+				"ubuntu:distro:ubuntu:22.04": false,
+			} {
+				actual, err := s.HasHighSeverityVulnerabilities(namespace)
+				assert.NoError(t, err)
+				assert.Equal(t, expected, actual, "namespace %q", namespace)
+			}
+
+			actual, err := s.GetHighSeverityMetadata("")
+			assert.NoError(t, err)
+			var ids []string
+			for _, m := range actual {
+				ids = append(ids, m.ID)
+			}
+			assert.Equal(t, []string{"CVE-critical", "CVE-high"}, ids)
+		})
+	}
+}
+
+func BenchmarkStore_HasHighSeverityVulnerabilities(b *testing.B) {
+	for _, withIndex := range []bool{false, true} {
+		s, err := New(b.TempDir(), true, WithHighSeverityIndex(withIndex))
+		if err != nil {
+			b.Fatalf("could not create store: %+v", err)
+		}
+
+		// mostly low severity records, with the few high severity records in a different namespace than is queried
+		var metadata []v5.VulnerabilityMetadata
+		for i := 0; i < 50000; i++ {
+			m := v5.VulnerabilityMetadata{
+				ID:           fmt.Sprintf("CVE-%05d", i),
+				Namespace:    "my-namespace",
+				RecordSource: "record-source",
+				Severity:     "Low",
+			}
+			if i%100 == 0 {
+				m.Namespace = "other-namespace"
+				m.Severity = "Critical"
+			}
+			metadata = append(metadata, m)
+		}
+
+		if _, err = s.AddVulnerabilityMetadata(metadata...); err != nil {
+			b.Fatalf("failed to add metadata: %+v", err)
+		}
+
+		b.Run(fmt.Sprintf("index=%v", withIndex), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := s.HasHighSeverityVulnerabilities("my-namespace"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestStore_Manifest(t *testing.T) {
+	build := func(t *testing.T, severity string) v5.Store {
+		s, err := New(t.TempDir(), true)
+		if err != nil {
+			t.Fatalf("could not create store: %+v", err)
+		}
+
+		if err = s.SetID(v5.ID{BuildTimestamp: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), SchemaVersion: v5.SchemaVersion}); err != nil {
+			t.Fatalf("failed to set ID: %+v", err)
+		}
+
+		if err = s.AddVulnerability(
+			v5.Vulnerability{ID: "CVE-1", PackageName: "pkg-a", Namespace: "nvd:cpe", VersionConstraint: "< 1.0", VersionFormat: "semver"},
+			v5.Vulnerability{ID: "CVE-2", PackageName: "pkg-b", Namespace: "nvd:cpe", VersionConstraint: "< 2.0", VersionFormat: "semver"},
+			v5.Vulnerability{ID: "CVE-1", PackageName: "pkg-a", Namespace: "debian:distro:debian:12", VersionConstraint: "< 1.0", VersionFormat: "dpkg"},
+		); err != nil {
+			t.Fatalf("failed to add vulnerabilities: %+v", err)
+		}
+
+		if _, err = s.AddVulnerabilityMetadata(
+			v5.VulnerabilityMetadata{ID: "CVE-1", Namespace: "nvd:cpe", RecordSource: "nvdv2", Severity: severity},
+			v5.VulnerabilityMetadata{ID: "CVE-2", Namespace: "nvd:cpe", RecordSource: "nvdv2", Severity: "High"},
+			v5.VulnerabilityMetadata{ID: "CVE-1", Namespace: "debian:distro:debian:12", RecordSource: "debian"},
+		); err != nil {
+			t.Fatalf("failed to add metadata: %+v", err)
+		}
+
+		return s
+	}
+
+	s := build(t, "Critical")
+	actual, err := s.Manifest()
+	if err != nil {
+		t.Fatalf("failed to get manifest: %+v", err)
+	}
+
+	assert.Equal(t, v5.SchemaVersion, actual.SchemaVersion)
+	assert.Equal(t, "2024-05-01T12:00:00Z", actual.BuildTimestamp)
+	assert.Equal(t, []v5.NamespaceManifest{
+		{Namespace: "debian:distro:debian:12", Vulnerabilities: 1, Metadata: 1},
+		{Namespace: "nvd:cpe", Vulnerabilities: 2, Metadata: 2},
+	}, actual.Namespaces)
+	assert.Equal(t, map[string]int{"critical": 1, "high": 1, "unknown": 1}, actual.Severities)
+	assert.Greater(t, actual.SizeBytes, int64(0))
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", actual.Fingerprint)
+
+	// the same content yields the same fingerprint, while different content does not
+	same, err := build(t, "Critical").Manifest()
+	if err != nil {
+		t.Fatalf("failed to get manifest: %+v", err)
+	}
+	assert.Equal(t, actual.Fingerprint, same.Fingerprint)
+
+	different, err := build(t, "Low").Manifest()
+	if err != nil {
+		t.Fatalf("failed to get manifest: %+v", err)
+	}
+	assert.NotEqual(t, actual.Fingerprint, different.Fingerprint)
+
+	// the JSON representation is stable
+	first, err := json.Marshal(actual)
+	assert.NoError(t, err)
+	second, err := json.Marshal(same)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(first), string(second))
+	assert.Contains(t, string(first), `"severities":{"critical":1,"high":1,"unknown":1}`)
+}
+
+func TestStore_GetVulnerabilityMetadataWithOptions(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	if _, err = s.AddVulnerabilityMetadata(
+		v5.VulnerabilityMetadata{
+			ID:           "CVE-inconsistent",
+			Namespace:    "nvd:cpe",
+			RecordSource: "nvdv2",
+			Severity:     "Medium",
+			Cvss: []v5.Cvss{
+				{Version: "2.0", Metrics: v5.NewCvssMetrics(5.0, 10, 2.9)},
+				{Version: "3.1", Metrics: v5.NewCvssMetrics(9.0, 3.9, 5.9)},
+			},
+		},
+		v5.VulnerabilityMetadata{
+			ID:           "CVE-unscored",
+			Namespace:    "nvd:cpe",
+			RecordSource: "nvdv2",
+			Severity:     "Low",
+		},
+	); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	tests := []struct {
+		name             string
+		id               string
+		opts             v5.MetadataReadOptions
+		wantSeverity     string
+		wantStored       string
+		wantComputed     string
+		wantInconsistent bool
+	}{
+		{
+			name:         "no recomputation",
+			id:           "CVE-inconsistent",
+			wantSeverity: "Medium",
+		},
+		{
+			name:             "recompute surfaces the inconsistency",
+			id:               "CVE-inconsistent",
+			opts:             v5.MetadataReadOptions{RecomputeSeverity: true},
+			wantSeverity:     "Medium",
+			wantStored:       "Medium",
+			wantComputed:     "Critical",
+			wantInconsistent: true,
+		},
+		{
+			name:             "recompute and prefer the computed severity",
+			id:               "CVE-inconsistent",
+			opts:             v5.MetadataReadOptions{RecomputeSeverity: true, PreferComputedSeverity: true},
+			wantSeverity:     "Critical",
+			wantStored:       "Medium",
+			wantComputed:     "Critical",
+			wantInconsistent: true,
+		},
+		{
+			name:         "no CVSS to recompute from",
+			id:           "CVE-unscored",
+			opts:         v5.MetadataReadOptions{RecomputeSeverity: true, PreferComputedSeverity: true},
+			wantSeverity: "Low",
+			wantStored:   "Low",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := s.GetVulnerabilityMetadataWithOptions(tt.id, "nvd:cpe", tt.opts)
+			if err != nil {
+				t.Fatalf("failed to get metadata: %+v", err)
+			}
+			if actual == nil {
+				t.Fatalf("no metadata found")
+			}
+
+			assert.Equal(t, tt.wantSeverity, actual.Severity)
+			assert.Equal(t, tt.wantStored, actual.StoredSeverity)
+			assert.Equal(t, tt.wantComputed, actual.ComputedSeverity)
+			assert.Equal(t, tt.wantInconsistent, actual.HasSeverityInconsistency())
+		})
+	}
+}
+
+func TestStore_GetApplicableExclusions(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	if err = s.AddVulnerability(v5.Vulnerability{
+		ID:                "CVE-1234",
+		Namespace:         "github:language:javascript",
+		PackageName:       "axios",
+		VersionConstraint: "< 3.1.0",
+		VersionFormat:     "semver",
+	}); err != nil {
+		t.Fatalf("failed to add vulnerability: %+v", err)
+	}
+
+	bounded := v5.VulnerabilityMatchExclusionConstraint{
+		Vulnerability: v5.VulnerabilityExclusionConstraint{Namespace: "github:language:javascript"},
+		Package:       v5.PackageExclusionConstraint{Name: "axios", Version: ">= 1.2.0, < 2.0.0"},
+	}
+	exact := v5.VulnerabilityMatchExclusionConstraint{
+		Package: v5.PackageExclusionConstraint{Name: "axios", Version: "3.0.0"},
+	}
+	otherNamespace := v5.VulnerabilityMatchExclusionConstraint{
+		Vulnerability: v5.VulnerabilityExclusionConstraint{Namespace: "nvd:cpe"},
+		Package:       v5.PackageExclusionConstraint{Name: "axios"},
+	}
+
+	if err = s.AddVulnerabilityMatchExclusion(v5.VulnerabilityMatchExclusion{
+		ID:            "CVE-1234",
+		Constraints:   []v5.VulnerabilityMatchExclusionConstraint{bounded, exact, otherNamespace},
+		Justification: "not affected",
+	}); err != nil {
+		t.Fatalf("failed to add exclusion: %+v", err)
+	}
+
+	tests := []struct {
+		version string
+		want    []v5.VulnerabilityMatchExclusionConstraint
+	}{
+		{version: "1.1.9"},
+		{version: "1.2.0", want: []v5.VulnerabilityMatchExclusionConstraint{bounded}},
+		{version: "1.9.9", want: []v5.VulnerabilityMatchExclusionConstraint{bounded}},
+		{version: "2.0.0"},
+		{version: "3.0.0", want: []v5.VulnerabilityMatchExclusionConstraint{exact}},
+		{version: "3.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			actual, err := s.GetApplicableExclusions("CVE-1234", "github:language:javascript", "axios", tt.version)
+			assert.NoError(t, err)
+
+			if len(tt.want) == 0 {
+				assert.Empty(t, actual)
+				return
+			}
+
+			if assert.Len(t, actual, 1) {
+				assert.Equal(t, "CVE-1234", actual[0].ID)
+				assert.Equal(t, "not affected", actual[0].Justification)
+				assert.Equal(t, tt.want, actual[0].Constraints)
+			}
+		})
+	}
+
+	// other packages are not excluded by package-specific constraints
+	actual, err := s.GetApplicableExclusions("CVE-1234", "github:language:javascript", "lodash", "1.5.0")
+	assert.NoError(t, err)
+	assert.Empty(t, actual)
+}
+
+func TestStore_IsMatchExcluded(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	if err = s.AddVulnerability(
+		v5.Vulnerability{
+			ID:                "CVE-1234",
+			Namespace:         "github:language:javascript",
+			PackageName:       "axios",
+			VersionConstraint: "< 3.1.0",
+			VersionFormat:     "semver",
+		},
+		v5.Vulnerability{
+			ID:                "CVE-5678",
+			Namespace:         "debian:distro:debian:12",
+			PackageName:       "curl",
+			VersionConstraint: "< 7.88.1-10+deb12u5",
+			VersionFormat:     "dpkg",
+		},
+	); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+
+	versionRange := v5.VulnerabilityMatchExclusion{
+		ID: "CVE-1234",
+		Constraints: []v5.VulnerabilityMatchExclusionConstraint{
+			{Package: v5.PackageExclusionConstraint{Name: "axios", Version: ">= 1.2.0, < 2.0.0"}},
+		},
+		Justification: "not affected",
+	}
+	allPackages := v5.VulnerabilityMatchExclusion{
+		ID: "CVE-5678",
+		Constraints: []v5.VulnerabilityMatchExclusionConstraint{
+			{Vulnerability: v5.VulnerabilityExclusionConstraint{Namespace: "debian:distro:debian:12"}},
+		},
+		Justification: "disputed",
+	}
+	if err = s.AddVulnerabilityMatchExclusion(versionRange, allPackages); err != nil {
+		t.Fatalf("failed to add exclusions: %+v", err)
+	}
+
+	tests := []struct {
+		name        string
+		id          string
+		namespace   string
+		packageName string
+		version     string
+		want        *v5.VulnerabilityMatchExclusion
+	}{
+		{
+			name:        "within the excluded version range",
+			id:          "CVE-1234",
+			namespace:   "github:language:javascript",
+			packageName: "axios",
+			version:     "1.9.9",
+			want:        &versionRange,
+		},
+		{
+			name:        "outside of the excluded version range",
+			id:          "CVE-1234",
+			namespace:   "github:language:javascript",
+			packageName: "axios",
+			version:     "2.0.0",
+		},
+		{
+			name:        "another package",
+			id:          "CVE-1234",
+			namespace:   "github:language:javascript",
+			packageName: "fetch",
+			version:     "1.9.9",
+		},
+		{
+			name:        "any package within the namespace",
+			id:          "CVE-5678",
+			namespace:   "debian:distro:debian:12",
+			packageName: "libcurl4",
+			version:     "7.88.1-10+deb12u4",
+			want:        &allPackages,
+		},
+		{
+			name:        "another namespace",
+			id:          "CVE-5678",
+			namespace:   "debian:distro:debian:11",
+			packageName: "curl",
+			version:     "7.74.0-1.3+deb11u11",
+		},
+		{
+			name:        "no exclusions for the vulnerability",
+			id:          "CVE-9999",
+			namespace:   "github:language:javascript",
+			packageName: "axios",
+			version:     "1.9.9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			excluded, exclusion, err := s.IsMatchExcluded(tt.id, tt.namespace, tt.packageName, tt.version)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want != nil, excluded)
+			assert.Equal(t, tt.want, exclusion)
+		})
+	}
+}
+
+func TestStore_ForEach(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	// span multiple batches, where metadata IDs are repeated across namespaces at the batch boundaries
+	count := iterationBatchSize + 5
+	var vulns []v5.Vulnerability
+	var metadata []v5.VulnerabilityMetadata
+	for i := 0; i < count; i++ {
+		vulns = append(vulns, v5.Vulnerability{
+			ID:                fmt.Sprintf("CVE-%05d", i),
+			PackageName:       "pkg",
+			Namespace:         "my-namespace",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+		})
+		metadata = append(metadata, v5.VulnerabilityMetadata{
+			ID:           fmt.Sprintf("CVE-%05d", i/2),
+			Namespace:    fmt.Sprintf("namespace-%d", i%2),
+			RecordSource: "record-source",
+		})
+	}
+
+	if err = s.AddVulnerability(vulns...); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+	if _, err = s.AddVulnerabilityMetadata(metadata...); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	seenVulns := make(map[string]int)
+	assert.NoError(t, s.ForEachVulnerability(func(v v5.Vulnerability) error {
+		seenVulns[v.ID]++
+		return nil
+	}))
+	assert.Len(t, seenVulns, count)
+
+	seenMetadata := make(map[string]int)
+	assert.NoError(t, s.ForEachMetadata(func(m v5.VulnerabilityMetadata) error {
+		seenMetadata[m.ID+"/"+m.Namespace]++
+		return nil
+	}))
+	assert.Len(t, seenMetadata, count)
+	for k, n := range seenMetadata {
+		assert.Equal(t, 1, n, "metadata %q visited more than once", k)
+	}
+
+	// errors from the callback stop iteration and are propagated
+	stop := errors.New("stop")
+
+	var visited int
+	err = s.ForEachVulnerability(func(v5.Vulnerability) error {
+		visited++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, visited)
+
+	visited = 0
+	err = s.ForEachMetadata(func(v5.VulnerabilityMetadata) error {
+		visited++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, visited)
+}
+
+func TestStore_AdjustedCvssScores(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	base := v5.Cvss{
+		Version: "3.1",
+		Vector:  "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		Metrics: v5.NewCvssMetrics(9.8, 3.9, 5.9),
+	}
+	temporal := base
+	temporal.TemporalVector = "E:U/RL:O/RC:U"
+
+	newMetadata := func(cvss ...v5.Cvss) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{
+			ID:           "CVE-1234",
+			Namespace:    "nvd:cpe",
+			RecordSource: "nvdv2",
+			Severity:     "Critical",
+			Cvss:         cvss,
+		}
+	}
+
+	// the temporal vector is part of the CVSS identity, so both entries are kept when merging...
+	if _, err = s.AddVulnerabilityMetadata(newMetadata(base)); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+	if _, err = s.AddVulnerabilityMetadata(newMetadata(temporal)); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	// ...however re-adding a previously read entry (with computed scores) is still a duplicate
+	read, err := s.GetVulnerabilityMetadata("CVE-1234", "nvd:cpe")
+	if err != nil {
+		t.Fatalf("failed to get metadata: %+v", err)
+	}
+	if _, err = s.AddVulnerabilityMetadata(*read); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	actual, err := s.GetVulnerabilityMetadata("CVE-1234", "nvd:cpe")
+	if err != nil {
+		t.Fatalf("failed to get metadata: %+v", err)
+	}
+
+	if assert.Len(t, actual.Cvss, 2) {
+		assert.Nil(t, actual.Cvss[0].Metrics.TemporalScore)
+		assert.Equal(t, 9.8, actual.Cvss[0].Metrics.AdjustedScore())
+
+		if assert.NotNil(t, actual.Cvss[1].Metrics.TemporalScore) {
+			assert.Equal(t, 7.8, *actual.Cvss[1].Metrics.TemporalScore)
+		}
+		assert.Nil(t, actual.Cvss[1].Metrics.EnvironmentalScore)
+		assert.Equal(t, 7.8, actual.Cvss[1].Metrics.AdjustedScore())
+	}
+}
+
+func TestStore_CoverageDiff(t *testing.T) {
+	newStore := func(t *testing.T, vulns ...v5.Vulnerability) v5.Store {
+		s, err := New(t.TempDir(), true)
+		if err != nil {
+			t.Fatalf("could not create store: %+v", err)
+		}
+		if err = s.AddVulnerability(vulns...); err != nil {
+			t.Fatalf("failed to add vulnerabilities: %+v", err)
+		}
+		return s
+	}
+
+	newVuln := func(id, namespace, pkgName, constraint string) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			Namespace:         namespace,
+			PackageName:       pkgName,
+			VersionConstraint: constraint,
+			VersionFormat:     "semver",
+		}
+	}
+
+	local := newStore(t,
+		newVuln("CVE-1", "npm", "axios", "< 1.0"),
+		// multiple records for the same key are only counted once
+		newVuln("CVE-2", "npm", "lodash", "< 2.0"),
+		newVuln("CVE-2", "npm", "lodash", ">= 3.0, < 3.1"),
+		newVuln("CVE-3", "local-only", "pkg", "< 1.0"),
+	)
+	reference := newStore(t,
+		// field level differences do not affect coverage
+		newVuln("CVE-1", "npm", "axios", "< 1.1"),
+		newVuln("CVE-2", "npm", "lodash", "< 2.0"),
+		newVuln("CVE-4", "npm", "lodash", "< 2.0"),
+		newVuln("CVE-5", "reference-only", "pkg", "< 1.0"),
+	)
+
+	actual, err := local.CoverageDiff(reference)
+	assert.NoError(t, err)
+	assert.Equal(t, v5.CoverageReport{
+		Missing: []v5.CoverageKey{
+			{Namespace: "npm", PackageName: "lodash", VulnerabilityID: "CVE-4"},
+			{Namespace: "reference-only", PackageName: "pkg", VulnerabilityID: "CVE-5"},
+		},
+		Extra: []v5.CoverageKey{
+			{Namespace: "local-only", PackageName: "pkg", VulnerabilityID: "CVE-3"},
+		},
+		Namespaces: []v5.NamespaceCoverage{
+			{Namespace: "local-only", Extra: 1},
+			{Namespace: "npm", Missing: 1, Shared: 2},
+			{Namespace: "reference-only", Missing: 1},
+		},
+	}, actual)
+}
+
+func TestStore_AsOf(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(id string) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			Namespace:         "nvd:cpe",
+			PackageName:       "pkg",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+		}
+	}
+	newMetadata := func(id, namespace, severity string, score float64) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{
+			ID:           id,
+			Namespace:    namespace,
+			RecordSource: "record-source",
+			Severity:     severity,
+			Cvss:         []v5.Cvss{{Version: "3.1", Metrics: v5.NewCvssMetrics(score, 0, 0)}},
+		}
+	}
+
+	// records are stamped with the build time of the DB ID, so each build adds records at a later time
+	build := func(buildTime time.Time) {
+		if err := s.SetID(v5.ID{BuildTimestamp: buildTime, SchemaVersion: v5.SchemaVersion}); err != nil {
+			t.Fatalf("failed to set ID: %+v", err)
+		}
+	}
+	snapshot := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	build(snapshot.Add(-time.Hour))
+	if err = s.AddVulnerability(newVuln("CVE-early"), newVuln("CVE-late-metadata")); err != nil {
+		t.Fatalf("failed to add vulnerability: %+v", err)
+	}
+	if _, err = s.AddVulnerabilityMetadata(newMetadata("CVE-early", "nvd:cpe", "High", 8.0)); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	build(snapshot.Add(time.Hour))
+	if err = s.AddVulnerability(newVuln("CVE-late")); err != nil {
+		t.Fatalf("failed to add vulnerability: %+v", err)
+	}
+	if _, err = s.AddVulnerabilityMetadata(
+		newMetadata("CVE-late", "nvd:cpe", "Critical", 9.5),
+		newMetadata("CVE-late-metadata", "nvd:cpe", "Critical", 9.5),
+		newMetadata("CVE-early", "other:namespace", "Low", 2.0),
+	); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	vulnIDs := func(vulns []v5.Vulnerability) []string {
+		var ids []string
+		for _, v := range vulns {
+			ids = append(ids, v.ID)
+		}
+		return ids
+	}
+
+	view := s.AsOf(snapshot)
+
+	// the view is read-only, even though the store is writable
+	_, ok := view.(v5.StoreWriter)
+	assert.False(t, ok)
+
+	vulns, err := view.SearchForVulnerabilities("nvd:cpe", "pkg")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CVE-early", "CVE-late-metadata"}, vulnIDs(vulns))
+
+	all, err := view.GetAllVulnerabilityMetadata()
+	assert.NoError(t, err)
+	assert.Len(t, *all, 1)
+
+	namespaces, err := view.GetVulnerabilityNamespaces()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"nvd:cpe"}, namespaces)
+
+	// records joined from the metadata table are also time scoped
+	vulns, err = view.GetVulnerabilitiesAboveCVSS("nvd:cpe", 9.0, false)
+	assert.NoError(t, err)
+	assert.Empty(t, vulns)
+
+	vulns, err = view.GetVulnerabilitiesAboveCVSS("nvd:cpe", 9.0, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CVE-late-metadata"}, vulnIDs(vulns))
+
+	// records in subqueries are also time scoped
+	conflicts, err := view.FindSeverityConflicts()
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	// the original store is not affected by the view
+	vulns, err = s.SearchForVulnerabilities("nvd:cpe", "pkg")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CVE-early", "CVE-late-metadata", "CVE-late"}, vulnIDs(vulns))
+
+	conflicts, err = s.FindSeverityConflicts()
+	assert.NoError(t, err)
+	assert.Len(t, conflicts, 1)
+
+	// a view as of now includes everything
+	vulns, err = s.AsOf(time.Now()).GetVulnerabilitiesAboveCVSS("nvd:cpe", 9.0, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CVE-late", "CVE-late-metadata"}, vulnIDs(vulns))
+}
+
+func TestStore_AsOf_NoLastModifiedColumn(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	if err = s.AddVulnerability(v5.Vulnerability{
+		ID:                "CVE-1",
+		Namespace:         "nvd:cpe",
+		PackageName:       "pkg",
+		VersionConstraint: "< 1.0",
+		VersionFormat:     "semver",
+	}); err != nil {
+		t.Fatalf("failed to add vulnerability: %+v", err)
+	}
+	if _, err = s.AddVulnerabilityMetadata(v5.VulnerabilityMetadata{
+		ID:        "CVE-1",
+		Namespace: "nvd:cpe",
+		Severity:  "Critical",
+		Cvss:      []v5.Cvss{{Version: "3.1", Metrics: v5.NewCvssMetrics(9.5, 0, 0)}},
+	}); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+	dropLastModifiedColumns(t, s)
+
+	// records without a modification time are always included
+	view := s.AsOf(time.Now().Add(-time.Hour))
+
+	vulns, err := view.SearchForVulnerabilities("nvd:cpe", "pkg")
+	assert.NoError(t, err)
+	assert.Len(t, vulns, 1)
+
+	all, err := view.GetAllVulnerabilityMetadata()
+	assert.NoError(t, err)
+	assert.Len(t, *all, 1)
+
+	vulns, err = view.GetVulnerabilitiesAboveCVSS("nvd:cpe", 9.0, false)
+	assert.NoError(t, err)
+	assert.Len(t, vulns, 1)
+
+	count, err := view.CountVulnerabilities()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestStore_FindOverlappingConstraints(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(id, constraint string) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			Namespace:         "github:language:python",
+			PackageName:       "requests",
+			VersionConstraint: constraint,
+			VersionFormat:     "python",
+		}
+	}
+
+	if err = s.AddVulnerability(
+		newVuln("GHSA-a", ">= 1.0, < 2.0"),
+		newVuln("GHSA-b", ">= 1.5, < 3.0"),
+		newVuln("GHSA-c", "< 1.0 || >= 3.0"),
+		newVuln("GHSA-d", "= 2.5"),
+		newVuln("GHSA-e", "not a constraint!"),
+	); err != nil {
+		t.Fatalf("failed to add vulnerability: %+v", err)
+	}
+
+	overlaps, err := s.FindOverlappingConstraints("github:language:python", "requests")
+	assert.NoError(t, err)
+	assert.Equal(t, []v5.ConstraintOverlap{
+		{
+			Namespace:   "github:language:python",
+			PackageName: "requests",
+			IDs:         [2]string{"GHSA-a", "GHSA-b"},
+			Overlap:     ">= 1.5, < 2.0",
+		},
+		{
+			Namespace:   "github:language:python",
+			PackageName: "requests",
+			IDs:         [2]string{"GHSA-b", "GHSA-d"},
+			Overlap:     "= 2.5",
+		},
+	}, overlaps)
+
+	overlaps, err = s.FindOverlappingConstraints("github:language:python", "other")
+	assert.NoError(t, err)
+	assert.Empty(t, overlaps)
+}
+
+func TestStore_ExportParquet(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	if err = s.AddVulnerability(v5.Vulnerability{
+		ID:                "CVE-2024-0001",
+		Namespace:         "nvd:cpe",
+		PackageName:       "pkg",
+		VersionConstraint: "< 1.0",
+		VersionFormat:     "semver",
+		CPEs:              []string{"cpe:2.3:a:vendor:pkg:*:*:*:*:*:*:*:*"},
+	}); err != nil {
+		t.Fatalf("failed to add vulnerability: %+v", err)
+	}
+	if _, err = s.AddVulnerabilityMetadata(v5.VulnerabilityMetadata{
+		ID:          "CVE-2024-0001",
+		Namespace:   "nvd:cpe",
+		Severity:    "High",
+		Description: "a description",
+	}); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	var vulnerabilities, metadata bytes.Buffer
+	assert.NoError(t, s.ExportParquet(&vulnerabilities, &metadata))
+
+	for _, out := range []*bytes.Buffer{&vulnerabilities, &metadata} {
+		data := out.Bytes()
+		assert.Equal(t, "PAR1", string(data[:4]))
+		assert.Equal(t, "PAR1", string(data[len(data)-4:]))
+		assert.Contains(t, string(data), "CVE-2024-0001")
+	}
+
+	assert.Contains(t, vulnerabilities.String(), `["cpe:2.3:a:vendor:pkg:*:*:*:*:*:*:*:*"]`)
+	assert.Contains(t, vulnerabilities.String(), "fixed_in_versions")
+	assert.Contains(t, metadata.String(), "a description")
+	assert.Contains(t, metadata.String(), "record_source")
+}
+
+func TestStore_GetAffectingVulnerabilities(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(id, constraint string, fix v5.Fix) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			Namespace:         "debian:distro:debian:12",
+			PackageName:       "openssl",
+			VersionConstraint: constraint,
+			VersionFormat:     "deb",
+			Fix:               fix,
+		}
+	}
+
+	if err = s.AddVulnerability(
+		newVuln("CVE-fixed", "< 3.0.11-1", v5.Fix{State: v5.FixedState, Versions: []string{"3.0.11-1"}}),
+		newVuln("CVE-multiple-fixes", "< 3.0.13-1", v5.Fix{State: v5.FixedState, Versions: []string{"3.0.13-1", "3.0.9-1", "3.0.12-1"}}),
+		newVuln("CVE-not-fixed", "", v5.Fix{State: v5.NotFixedState}),
+		newVuln("CVE-not-affected", "< 3.0.1-1", v5.Fix{State: v5.FixedState, Versions: []string{"3.0.1-1"}}),
+	); err != nil {
+		t.Fatalf("failed to add vulnerability: %+v", err)
+	}
+
+	affecting, err := s.GetAffectingVulnerabilities("debian:distro:debian:12", "openssl", "3.0.10-1")
+	assert.NoError(t, err)
+
+	type result struct {
+		id         string
+		fixed      bool
+		fixVersion string
+	}
+	var actual []result
+	for _, a := range affecting {
+		actual = append(actual, result{id: a.ID, fixed: a.Fixed, fixVersion: a.FixVersion})
+	}
+	assert.ElementsMatch(t, []result{
+		{id: "CVE-fixed", fixed: true, fixVersion: "3.0.11-1"},
+		{id: "CVE-multiple-fixes", fixed: true, fixVersion: "3.0.12-1"},
+		{id: "CVE-not-fixed", fixed: false},
+	}, actual)
+
+	affecting, err = s.GetAffectingVulnerabilities("debian:distro:debian:12", "other", "3.0.10-1")
+	assert.NoError(t, err)
+	assert.Empty(t, affecting)
+}
+
+func TestStore_NamespaceCurrency(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	currency, err := s.GetNamespaceCurrency()
+	assert.NoError(t, err)
+	assert.Empty(t, currency)
+
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 1, 31, 12, 30, 0, 500, time.UTC)
+
+	newVuln := func(id, namespace string, fetchedAt time.Time) v5.Vulnerability {
+		v := v5.Vulnerability{
+			ID:          id,
+			Namespace:   namespace,
+			PackageName: "pkg",
+		}
+		if !fetchedAt.IsZero() {
+			v.Provenance = &v5.Provenance{SourceName: "feed", FetchedAt: fetchedAt}
+		}
+		return v
+	}
+
+	// adding vulnerabilities records the most recent fetch time for each namespace
+	if err = s.AddVulnerability(
+		newVuln("CVE-1", "debian:distro:debian:12", newer),
+		newVuln("CVE-2", "debian:distro:debian:12", older),
+		newVuln("CVE-3", "nvd:cpe", older),
+		newVuln("CVE-4", "github:language:go", time.Time{}),
+	); err != nil {
+		t.Fatalf("failed to add vulnerability: %+v", err)
+	}
+
+	currency, err = s.GetNamespaceCurrency()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{
+		"debian:distro:debian:12": newer,
+		"nvd:cpe":                 older,
+	}, currency)
+
+	// explicitly set values replace any existing value
+	assert.NoError(t, s.SetNamespaceCurrency("debian:distro:debian:12", older))
+	assert.NoError(t, s.SetNamespaceCurrency("github:language:go", newer.In(time.FixedZone("EST", -5*60*60))))
+
+	currency, err = s.GetNamespaceCurrency()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{
+		"debian:distro:debian:12": older,
+		"github:language:go":      newer,
+		"nvd:cpe":                 older,
+	}, currency)
+}
+
+func TestStore_GetRemediationOptions(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(id, constraint string, fixes ...string) v5.Vulnerability {
+		state := v5.FixedState
+		if len(fixes) == 0 {
+			state = v5.NotFixedState
+		}
+		return v5.Vulnerability{
+			ID:                id,
+			Namespace:         "github:language:javascript",
+			PackageName:       "lodash",
+			VersionConstraint: constraint,
+			VersionFormat:     "semver",
+			Fix:               v5.Fix{State: state, Versions: fixes},
+		}
+	}
+
+	if err = s.AddVulnerability(
+		newVuln("GHSA-1", "< 2.1.0", "2.1.0"),
+		newVuln("GHSA-2", "< 2.1.0", "2.1.0"),
+		newVuln("GHSA-3", "< 2.3.0", "2.3.0"),
+		// fixed in multiple release lines
+		newVuln("GHSA-4", "< 1.9.5 || >= 2.0.0, < 2.0.7", "1.9.5", "2.0.7"),
+		// reintroduced in a later version
+		newVuln("GHSA-5", "< 2.1.0 || >= 2.3.0, < 2.4.0", "2.1.0", "2.4.0"),
+		newVuln("GHSA-not-fixed", ""),
+		newVuln("GHSA-not-affected", "< 1.0.0", "1.0.0"),
+	); err != nil {
+		t.Fatalf("failed to add vulnerability: %+v", err)
+	}
+
+	options, err := s.GetRemediationOptions("github:language:javascript", "lodash", "2.0.5")
+	assert.NoError(t, err)
+	assert.Equal(t, []v5.RemediationOption{
+		{Version: "2.0.7", Resolves: []string{"GHSA-4"}},
+		{Version: "2.1.0", Resolves: []string{"GHSA-1", "GHSA-2", "GHSA-4", "GHSA-5"}},
+		{Version: "2.3.0", Resolves: []string{"GHSA-1", "GHSA-2", "GHSA-3", "GHSA-4"}},
+		{Version: "2.4.0", Resolves: []string{"GHSA-1", "GHSA-2", "GHSA-3", "GHSA-4", "GHSA-5"}},
+	}, options)
+
+	options, err = s.GetRemediationOptions("github:language:javascript", "lodash", "3.0.0")
+	assert.NoError(t, err)
+	assert.Empty(t, options)
+}
+
+func TestStore_Views(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), v5.VulnerabilityStoreFileName)
+	s, err := New(dbPath, true, WithWAL(), WithPreservedURLOrder(true), WithMetadataMergePolicy(MergePolicyKeepExisting))
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	if err = s.AddVulnerability(v5.Vulnerability{ID: "CVE-1", Namespace: "nvd:cpe", PackageName: "pkg", VersionConstraint: "< 1.0", VersionFormat: "semver"}); err != nil {
+		t.Fatalf("failed to add vulnerability: %+v", err)
+	}
+	parent := s.(*store)
+
+	views := map[string]v5.StoreReader{
+		"as of":        s.AsOf(time.Now()),
+		"with context": s.WithContext(context.Background()),
+		"nested":       s.AsOf(time.Now()).WithContext(context.Background()),
+	}
+	for name, view := range views {
+		t.Run(name, func(t *testing.T) {
+			_, ok := view.(v5.StoreWriter)
+			assert.False(t, ok)
+
+			// the configuration of the store is retained
+			v, ok := asStore(view)
+			if !ok {
+				t.Fatalf("view is not backed by a store: %T", view)
+			}
+			assert.True(t, v.view)
+			assert.Equal(t, parent.wal, v.wal)
+			assert.Equal(t, parent.preserveURLOrder, v.preserveURLOrder)
+			assert.Equal(t, parent.mergePolicy, v.mergePolicy)
+			assert.Equal(t, parent.closeConfig, v.closeConfig)
+
+			// closing the view does not close the store
+			assert.NoError(t, view.Close())
+			vulns, err := s.SearchForVulnerabilities("nvd:cpe", "pkg")
+			assert.NoError(t, err)
+			assert.Len(t, vulns, 1)
+		})
+	}
+
+	assert.NoError(t, s.Close())
+}
+
+func TestStore_WithContext(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	// enough records to span multiple iteration batches
+	var vulns []v5.Vulnerability
+	for i := 0; i < iterationBatchSize+1; i++ {
+		vulns = append(vulns, v5.Vulnerability{
+			ID:                fmt.Sprintf("CVE-%04d", i),
+			Namespace:         "nvd:cpe",
+			PackageName:       "pkg",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+		})
+	}
+	if err = s.AddVulnerability(vulns...); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+
+	// an active context does not affect results
+	all, err := s.WithContext(context.Background()).GetAllVulnerabilities()
+	assert.NoError(t, err)
+	assert.Len(t, *all, len(vulns))
+
+	// a context cancelled before the query
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = s.WithContext(ctx).GetAllVulnerabilities()
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = s.WithContext(ctx).SearchForVulnerabilities("nvd:cpe", "pkg")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// a context cancelled mid-iteration stops at the next batch
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	var seen int
+	err = s.WithContext(ctx).ForEachVulnerability(func(v5.Vulnerability) error {
+		seen++
+		cancel()
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, iterationBatchSize, seen)
+
+	// the context is retained by time-scoped views, and vice versa
+	_, err = s.WithContext(ctx).AsOf(time.Now()).SearchForVulnerabilities("nvd:cpe", "pkg")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = s.AsOf(time.Now()).WithContext(ctx).SearchForVulnerabilities("nvd:cpe", "pkg")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// the store itself is unaffected
+	found, err := s.SearchForVulnerabilities("nvd:cpe", "pkg")
+	assert.NoError(t, err)
+	assert.Len(t, found, len(vulns))
+}
+
+func TestStore_EachVulnerability(t *testing.T) {
+	// an in-memory store has a single connection, so a row iterator left open would block all later queries
+	s, err := NewInMemory()
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	var expected []string
+	for _, namespace := range []string{"nvd:cpe", "debian:distro:debian:12"} {
+		for i := 0; i < 3; i++ {
+			id := fmt.Sprintf("CVE-%d", i)
+			expected = append(expected, namespace+"/"+id)
+			if err = s.AddVulnerability(v5.Vulnerability{
+				ID:                id,
+				Namespace:         namespace,
+				PackageName:       "pkg",
+				VersionConstraint: "< 1.0",
+				VersionFormat:     "semver",
+			}); err != nil {
+				t.Fatalf("failed to add vulnerability: %+v", err)
+			}
+		}
+	}
+	sort.Strings(expected)
+
+	// records are read in namespace then ID order
+	var keys []string
+	err = s.EachVulnerability(func(v v5.Vulnerability) error {
+		keys = append(keys, v.Namespace+"/"+v.ID)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, keys)
+
+	// stopping partway through returns the callback error unwrapped, without calling the function again
+	stop := errors.New("stop")
+	var seen int
+	err = s.EachVulnerability(func(v5.Vulnerability) error {
+		seen++
+		if seen == 2 {
+			return stop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 2, seen)
+
+	// the row iterator was closed, so the store remains usable (including for writes)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.AddVulnerability(v5.Vulnerability{
+			ID:                "CVE-3",
+			Namespace:         "nvd:cpe",
+			PackageName:       "pkg",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+		})
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("store is blocked by an unclosed row iterator")
+	}
+
+	all, err := s.GetAllVulnerabilities()
+	assert.NoError(t, err)
+	assert.Len(t, *all, 7)
+}
+
+func TestStore_ForEachVulnerability(t *testing.T) {
+	// an in-memory store has a single connection, so any rows held open across callbacks would deadlock the queries below
+	s, err := NewInMemory()
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	var expected []string
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprintf("CVE-%d", i)
+		expected = append(expected, id)
+		if err = s.AddVulnerability(v5.Vulnerability{
+			ID:                id,
+			Namespace:         "nvd:cpe",
+			PackageName:       "pkg",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+		}); err != nil {
+			t.Fatalf("failed to add vulnerability: %+v", err)
+		}
+	}
+
+	var ids []string
+	err = s.ForEachVulnerability(func(v v5.Vulnerability) error {
+		ids = append(ids, v.ID)
+		_, err := s.GetVulnerability(v.Namespace, v.ID)
+		return err
+	})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, expected, ids)
+
+	// stopping partway through returns the callback error unwrapped
+	stop := errors.New("stop")
+	var seen int
+	err = s.ForEachVulnerability(func(v5.Vulnerability) error {
+		seen++
+		if seen == 2 {
+			return stop
+		}
+		return nil
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 2, seen)
+
+	// the store remains usable (including for writes) after stopping early
+	assert.NoError(t, s.AddVulnerability(v5.Vulnerability{
+		ID:                "CVE-5",
+		Namespace:         "nvd:cpe",
+		PackageName:       "pkg",
+		VersionConstraint: "< 1.0",
+		VersionFormat:     "semver",
+	}))
+
+	all, err := s.GetAllVulnerabilities()
+	assert.NoError(t, err)
+	assert.Len(t, *all, 6)
+}
+
+func TestStore_AddVulnerability_Batches(t *testing.T) {
+	dbStore, err := New(t.TempDir(), true, WithInsertBatchSize(2))
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	s := dbStore.(*store)
+
+	newVuln := func(id string) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			Namespace:         "nvd:cpe",
+			PackageName:       "pkg",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+		}
+	}
+
+	// records within the same batch may have differing null columns
+	withCPEs := newVuln("CVE-1")
+	withCPEs.CPEs = []string{"cpe:2.3:a:vendor:pkg:*:*:*:*:*:*:*:*"}
+	withProvenance := newVuln("CVE-2")
+	withProvenance.Provenance = &v5.Provenance{SourceName: "nvd", FetchedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+
+	assert.NoError(t, s.AddVulnerability(withCPEs, withProvenance, newVuln("CVE-3")))
+
+	all, err := s.GetAllVulnerabilities()
+	assert.NoError(t, err)
+	assert.Len(t, *all, 3)
+
+	found, err := s.GetVulnerability("nvd:cpe", "CVE-1")
+	assert.NoError(t, err)
+	assert.Equal(t, withCPEs.CPEs, found[0].CPEs)
+
+	// a failure in a later batch rolls back the entire call
+	if err := s.db.Exec("CREATE TRIGGER fail_insert BEFORE INSERT ON vulnerability WHEN NEW.id = 'CVE-bad' BEGIN SELECT RAISE(ABORT, 'rejected'); END").Error; err != nil {
+		t.Fatalf("could not create trigger: %+v", err)
+	}
+
+	later := newVuln("CVE-6")
+	later.Provenance = &v5.Provenance{SourceName: "nvd", FetchedAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}
+
+	err = s.AddVulnerability(newVuln("CVE-4"), newVuln("CVE-5"), later, newVuln("CVE-bad"))
+	assert.ErrorContains(t, err, "rejected")
+
+	all, err = s.GetAllVulnerabilities()
+	assert.NoError(t, err)
+	assert.Len(t, *all, 3)
+
+	currency, err := s.GetNamespaceCurrency()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{"nvd:cpe": time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}, currency)
+}
+
+func BenchmarkStore_AddVulnerability(b *testing.B) {
+	var vulns []v5.Vulnerability
+	for i := 0; i < 50000; i++ {
+		vulns = append(vulns, v5.Vulnerability{
+			ID:                fmt.Sprintf("CVE-%05d", i),
+			PackageName:       fmt.Sprintf("package-%05d", i),
+			Namespace:         "my-namespace",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+			CPEs:              []string{fmt.Sprintf("cpe:2.3:a:vendor:package-%05d:*:*:*:*:*:*:*:*", i)},
+		})
+	}
+
+	newStore := func(b *testing.B) v5.Store {
+		b.Helper()
+		s, err := New(b.TempDir(), true)
+		if err != nil {
+			b.Fatalf("could not create store: %+v", err)
+		}
+		return s
+	}
+
+	b.Run("per record", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			s := newStore(b)
+			b.StartTimer()
+
+			for _, v := range vulns {
+				if err := s.AddVulnerability(v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			s := newStore(b)
+			b.StartTimer()
+
+			if err := s.AddVulnerability(vulns...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkStore_AddVulnerabilityMetadata(b *testing.B) {
+	// a 100k record import where half of the records merge into existing records
+	newMetadata := func(i int) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{
+			ID:         fmt.Sprintf("CVE-%06d", i),
+			Namespace:  "nvd:cpe",
+			DataSource: fmt.Sprintf("https://example.com/CVE-%06d", i),
+			Severity:   "High",
+			URLs:       []string{fmt.Sprintf("https://example.com/CVE-%06d", i)},
+			Cvss: []v5.Cvss{
+				{
+					Version: "3.1",
+					Metrics: v5.NewCvssMetrics(7.5, 3.9, 3.6),
+					Vector:  "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N",
+				},
+			},
+		}
+	}
+
+	var existing, metadata []v5.VulnerabilityMetadata
+	for i := 0; i < 50000; i++ {
+		existing = append(existing, newMetadata(i))
+	}
+	for i := 0; i < 100000; i++ {
+		m := newMetadata(i)
+		m.URLs = append(m.URLs, fmt.Sprintf("https://ancho.re/CVE-%06d", i))
+		metadata = append(metadata, m)
+	}
+
+	newStore := func(b *testing.B) v5.Store {
+		b.Helper()
+		s, err := New(b.TempDir(), true)
+		if err != nil {
+			b.Fatalf("could not create store: %+v", err)
+		}
+		if _, err := s.AddVulnerabilityMetadataBulk(existing...); err != nil {
+			b.Fatal(err)
+		}
+		return s
+	}
+
+	b.Run("per record", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			s := newStore(b)
+			b.StartTimer()
+
+			if _, err := s.AddVulnerabilityMetadata(metadata...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("bulk", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			s := newStore(b)
+			b.StartTimer()
+
+			if _, err := s.AddVulnerabilityMetadataBulk(metadata...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestStore_GetAllVulnerabilityMetadataByID(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newMetadata := func(id, namespace, severity string) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{
+			ID:           id,
+			Namespace:    namespace,
+			DataSource:   "https://example.com/" + id,
+			RecordSource: "record-source",
+			Severity:     severity,
+		}
+	}
+
+	if _, err = s.AddVulnerabilityMetadata(
+		newMetadata("CVE-2023-1234", "nvd:cpe", "High"),
+		newMetadata("CVE-2023-1234", "debian:distro:debian:12", "Low"),
+		newMetadata("CVE-2023-1234", "alpine:distro:alpine:3.18", "Medium"),
+		newMetadata("CVE-2023-9999", "nvd:cpe", "Critical"),
+	); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	metadata, err := s.GetAllVulnerabilityMetadataByID("CVE-2023-1234")
+	assert.NoError(t, err)
+
+	var namespaces, severities []string
+	for _, m := range metadata {
+		assert.Equal(t, "CVE-2023-1234", m.ID)
+		namespaces = append(namespaces, m.Namespace)
+		severities = append(severities, m.Severity)
+	}
+	assert.Equal(t, []string{"alpine:distro:alpine:3.18", "debian:distro:debian:12", "nvd:cpe"}, namespaces)
+	assert.Equal(t, []string{"Medium", "Low", "High"}, severities)
+
+	metadata, err = s.GetAllVulnerabilityMetadataByID("CVE-2023-0000")
+	assert.NoError(t, err)
+	assert.Empty(t, metadata)
+}
+
+func TestStore_CountVulnerabilities(t *testing.T) {
+	dbDir := t.TempDir()
+
+	s, err := New(dbDir, true, WithBuildTime(time.Now()))
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	count, err := s.CountVulnerabilities()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	if err = s.AddVulnerability(
+		v5.Vulnerability{ID: "CVE-1", Namespace: "nvd:cpe", PackageName: "pkg-a", VersionConstraint: "< 1.0", VersionFormat: "semver"},
+		v5.Vulnerability{ID: "CVE-1", Namespace: "nvd:cpe", PackageName: "pkg-b", VersionConstraint: "< 1.0", VersionFormat: "semver"},
+		v5.Vulnerability{ID: "CVE-2", Namespace: "nvd:cpe", PackageName: "pkg-a", VersionConstraint: "< 1.0", VersionFormat: "semver"},
+	); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+	if _, err = s.AddVulnerabilityMetadata(
+		v5.VulnerabilityMetadata{ID: "CVE-1", Namespace: "nvd:cpe", RecordSource: "record-source", Severity: "High"},
+		v5.VulnerabilityMetadata{ID: "CVE-2", Namespace: "nvd:cpe", RecordSource: "record-source", Severity: "Low"},
+	); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	count, err = s.CountVulnerabilities()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	count, err = s.CountVulnerabilityMetadata()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// time-scoped views are honored
+	count, err = s.AsOf(time.Now().Add(-time.Hour)).CountVulnerabilities()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	if err = s.Close(); err != nil {
+		t.Fatalf("failed to close store: %+v", err)
+	}
+
+	// reopening with overwrite truncates the DB
+	s, err = New(dbDir, true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	count, err = s.CountVulnerabilities()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	count, err = s.CountVulnerabilityMetadata()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestStore_SchemaVersionMismatch(t *testing.T) {
+	newStoreWithSchema := func(t *testing.T, schemaVersion int) string {
+		t.Helper()
+		dbDir := t.TempDir()
+		s, err := New(dbDir, true)
+		if err != nil {
+			t.Fatalf("could not create store: %+v", err)
+		}
+		if err = s.SetID(v5.ID{BuildTimestamp: time.Now().UTC(), SchemaVersion: schemaVersion}); err != nil {
+			t.Fatalf("failed to set ID: %+v", err)
+		}
+		if err = s.Close(); err != nil {
+			t.Fatalf("failed to close store: %+v", err)
+		}
+		return dbDir
+	}
+
+	t.Run("matching schema version", func(t *testing.T) {
+		s, err := New(newStoreWithSchema(t, v5.SchemaVersion), false)
+		assert.NoError(t, err)
+		assert.NotNil(t, s)
+	})
+
+	t.Run("mismatched schema version", func(t *testing.T) {
+		_, err := New(newStoreWithSchema(t, 4), false)
+
+		var mismatch *ErrSchemaMismatch
+		if assert.ErrorAs(t, err, &mismatch) {
+			assert.Equal(t, &ErrSchemaMismatch{Expected: v5.SchemaVersion, Got: 4}, mismatch)
+		}
+	})
+
+	t.Run("overwritten DB is not checked", func(t *testing.T) {
+		_, err := New(newStoreWithSchema(t, 4), true)
+		assert.NoError(t, err)
+	})
+}
+
+func TestStore_WithOpenTimeout(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "vulnerability.db")
+
+	s, err := New(dbPath, true, WithOpenTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	if err = s.SetID(v5.ID{BuildTimestamp: time.Now().UTC(), SchemaVersion: v5.SchemaVersion}); err != nil {
+		t.Fatalf("failed to set ID: %+v", err)
+	}
+	if err = s.Close(); err != nil {
+		t.Fatalf("failed to close store: %+v", err)
+	}
+
+	// an existing (unlocked) DB is opened as usual
+	s, err = New(dbPath, false, WithOpenTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("could not open store: %+v", err)
+	}
+	id, err := s.GetID()
+	assert.NoError(t, err)
+	assert.Equal(t, v5.SchemaVersion, id.SchemaVersion)
+}
+
+func TestStore_NewReadOnly(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "vulnerability.db")
+
+	s, err := New(dbPath, true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	if err = s.SetID(v5.ID{BuildTimestamp: time.Now().UTC(), SchemaVersion: v5.SchemaVersion}); err != nil {
+		t.Fatalf("failed to set ID: %+v", err)
+	}
+	if err = s.AddVulnerability(v5.Vulnerability{
+		ID:                "CVE-1",
+		Namespace:         "nvd:cpe",
+		PackageName:       "pkg",
+		VersionConstraint: "< 1.0",
+		VersionFormat:     "semver",
+	}); err != nil {
+		t.Fatalf("failed to add vulnerability: %+v", err)
+	}
+	if err = s.Close(); err != nil {
+		t.Fatalf("failed to close store: %+v", err)
+	}
+
+	before, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatalf("failed to read DB: %+v", err)
+	}
+
+	reader, err := NewReadOnly(dbPath)
+	if err != nil {
+		t.Fatalf("could not open read-only store: %+v", err)
+	}
+
+	vulns, err := reader.SearchForVulnerabilities("nvd:cpe", "pkg")
+	assert.NoError(t, err)
+	assert.Len(t, vulns, 1)
+
+	// write methods are not reachable, even from derived views
+	_, ok := reader.(v5.StoreWriter)
+	assert.False(t, ok)
+	_, ok = reader.AsOf(time.Now()).(v5.StoreWriter)
+	assert.False(t, ok)
+	_, ok = reader.WithContext(context.Background()).(v5.StoreWriter)
+	assert.False(t, ok)
+
+	// the read-only store is still diffed as a sql database
+	_, ok = asStore(reader)
+	assert.True(t, ok)
+
+	// closing does not write to the DB
+	assert.NoError(t, reader.Close())
+
+	after, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatalf("failed to read DB: %+v", err)
+	}
+	assert.Equal(t, before, after)
+}
+
+func TestStore_Close_SkipsVacuumWhenUnchanged(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "vulnerability.db")
+
+	s, err := New(dbPath, true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	if err = s.SetID(v5.ID{BuildTimestamp: time.Now().UTC(), SchemaVersion: v5.SchemaVersion}); err != nil {
+		t.Fatalf("failed to set ID: %+v", err)
+	}
+	assert.NoError(t, s.Vacuum())
+	assert.NoError(t, s.Close())
+
+	before, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatalf("failed to read DB: %+v", err)
+	}
+
+	// only reading from the store does not modify the DB on close
+	s, err = New(dbPath, false)
+	if err != nil {
+		t.Fatalf("could not open store: %+v", err)
+	}
+	_, err = s.GetID()
+	assert.NoError(t, err)
+	assert.False(t, s.(*store).dirty)
+	assert.NoError(t, s.Close())
+
+	after, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatalf("failed to read DB: %+v", err)
+	}
+	assert.Equal(t, before, after)
+
+	// writing marks the store as needing a vacuum, until vacuumed
+	s, err = New(dbPath, true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	assert.True(t, s.(*store).dirty)
+	assert.NoError(t, s.Vacuum())
+	assert.False(t, s.(*store).dirty)
+	assert.NoError(t, s.SetNamespaceCurrency("nvd:cpe", time.Now()))
+	assert.True(t, s.(*store).dirty)
+	assert.NoError(t, s.Close())
+}
+
+func TestCloseConfig_Statements(t *testing.T) {
+	// the defaults are unchanged from the original hardcoded values
+	assert.Equal(t, []string{
+		"PRAGMA cache_size = -32768",
+		"PRAGMA temp_store = FILE",
+		"PRAGMA mmap_size = 67108864",
+		"PRAGMA journal_mode = TRUNCATE",
+	}, CloseConfig{}.statements())
+
+	// unset fields fall back to the defaults
+	assert.Equal(t, []string{
+		"PRAGMA cache_size = -4194304",
+		"PRAGMA temp_store = MEMORY",
+		"PRAGMA mmap_size = 67108864",
+		"PRAGMA journal_mode = TRUNCATE",
+	}, CloseConfig{CacheSize: -4194304, TempStore: "MEMORY"}.statements())
+
+	s, err := New(t.TempDir(), true, WithCloseConfig(CloseConfig{CacheSize: -65536, MmapSize: 134217728}))
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	assert.NoError(t, s.Vacuum())
+	assert.NoError(t, s.Close())
+}
+
+func TestStore_CorruptDBErrors(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	db := s.(*store).db
+
+	for i := 0; i < 2; i++ {
+		m := model.NewIDModel(v5.ID{BuildTimestamp: time.Now().UTC(), SchemaVersion: v5.SchemaVersion})
+		if err := db.Create(&m).Error; err != nil {
+			t.Fatalf("failed to add ID: %+v", err)
+		}
+	}
+
+	_, err = s.GetID()
+	assert.ErrorIs(t, err, ErrMultipleIDs)
+
+	// simulate a DB where the metadata table has no primary key constraint
+	for _, stmt := range []string{
+		"DROP TABLE vulnerability_metadata",
+		"CREATE TABLE vulnerability_metadata (id text, namespace text, data_source text, record_source text, severity text, urls text, description text, cvss text, last_modified text)",
+		"INSERT INTO vulnerability_metadata (id, namespace, severity) VALUES ('CVE-1', 'nvd:cpe', 'High'), ('CVE-1', 'nvd:cpe', 'Low')",
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to execute %q: %+v", stmt, err)
+		}
+	}
+
+	_, err = s.GetVulnerabilityMetadata("CVE-1", "nvd:cpe")
+	assert.ErrorIs(t, err, ErrMultipleMetadata)
+	assert.ErrorContains(t, err, `ID="CVE-1" Namespace="nvd:cpe"`)
+}
+
+func TestStore_Verify(t *testing.T) {
+	newStore := func(t *testing.T) v5.Store {
+		t.Helper()
+		s, err := New(t.TempDir(), true)
+		if err != nil {
+			t.Fatalf("could not create store: %+v", err)
+		}
+		if err := s.AddVulnerability(v5.Vulnerability{ID: "CVE-2024-0001", Namespace: "nvd:cpe", PackageName: "curl", VersionConstraint: "< 8.0"}); err != nil {
+			t.Fatalf("failed to add vulnerability: %+v", err)
+		}
+		if _, err := s.AddVulnerabilityMetadata(v5.VulnerabilityMetadata{ID: "CVE-2024-0001", Namespace: "nvd:cpe", Severity: "High"}); err != nil {
+			t.Fatalf("failed to add metadata: %+v", err)
+		}
+		return s
+	}
+
+	setChecksummedID := func(t *testing.T, s v5.Store) {
+		t.Helper()
+		manifest, err := s.Manifest()
+		if err != nil {
+			t.Fatalf("failed to get manifest: %+v", err)
+		}
+		id := v5.NewID(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+		id.Checksum = manifest.Fingerprint
+		if err := s.SetID(id); err != nil {
+			t.Fatalf("failed to set ID: %+v", err)
+		}
+	}
+
+	t.Run("valid DB without a checksum", func(t *testing.T) {
+		s := newStore(t)
+		assert.NoError(t, s.SetID(v5.NewID(time.Now())))
+		assert.NoError(t, s.Verify())
+	})
+
+	t.Run("valid DB with a checksum", func(t *testing.T) {
+		s := newStore(t)
+		setChecksummedID(t, s)
+
+		id, err := s.GetID()
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(id.Checksum, "sha256:"))
+		assert.NoError(t, s.Verify())
+	})
+
+	t.Run("records do not match the checksum", func(t *testing.T) {
+		s := newStore(t)
+		setChecksummedID(t, s)
+		if err := s.AddVulnerability(v5.Vulnerability{ID: "CVE-2024-0002", Namespace: "nvd:cpe", PackageName: "curl", VersionConstraint: "< 9.0"}); err != nil {
+			t.Fatalf("failed to add vulnerability: %+v", err)
+		}
+
+		var verr *ErrVerification
+		if assert.ErrorAs(t, s.Verify(), &verr) {
+			assert.True(t, verr.Failed(VerifyChecksum))
+			assert.False(t, verr.Failed(VerifyIntegrity))
+			assert.False(t, verr.Failed(VerifyID))
+		}
+	})
+
+	t.Run("missing ID", func(t *testing.T) {
+		s := newStore(t)
+
+		var verr *ErrVerification
+		if assert.ErrorAs(t, s.Verify(), &verr) {
+			assert.Equal(t, []VerificationFailure{{Check: VerifyID, Reason: "expected exactly 1 ID record, found 0"}}, verr.Failures)
+		}
+	})
+
+	t.Run("multiple IDs", func(t *testing.T) {
+		s := newStore(t)
+		setChecksummedID(t, s)
+		m := model.NewIDModel(v5.NewID(time.Now()))
+		if err := s.(*store).db.Create(&m).Error; err != nil {
+			t.Fatalf("failed to add ID: %+v", err)
+		}
+
+		var verr *ErrVerification
+		if assert.ErrorAs(t, s.Verify(), &verr) {
+			assert.Equal(t, []VerificationFailure{{Check: VerifyID, Reason: "expected exactly 1 ID record, found 2"}}, verr.Failures)
+		}
+	})
+
+	t.Run("corrupt DB", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), v5.VulnerabilityStoreFileName)
+		s, err := New(path, true)
+		if err != nil {
+			t.Fatalf("could not create store: %+v", err)
+		}
+		var vulns []v5.Vulnerability
+		for i := 0; i < 5000; i++ {
+			vulns = append(vulns, v5.Vulnerability{ID: fmt.Sprintf("CVE-2024-%05d", i), Namespace: "nvd:cpe", PackageName: "curl", VersionConstraint: "< 8.0"})
+		}
+		if err := s.AddVulnerability(vulns...); err != nil {
+			t.Fatalf("failed to add vulnerabilities: %+v", err)
+		}
+		assert.NoError(t, s.SetID(v5.NewID(time.Now())))
+		assert.NoError(t, s.Close())
+
+		// zero out a page near the end of the file (as a partially written download would)
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			t.Fatalf("failed to open DB: %+v", err)
+		}
+		info, err := f.Stat()
+		if err != nil {
+			t.Fatalf("failed to stat DB: %+v", err)
+		}
+		if _, err := f.WriteAt(make([]byte, 4096), info.Size()-8192); err != nil {
+			t.Fatalf("failed to corrupt DB: %+v", err)
+		}
+		assert.NoError(t, f.Close())
+
+		s, err = New(path, false)
+		if err != nil {
+			t.Fatalf("could not open store: %+v", err)
+		}
+		defer s.Close()
+
+		var verr *ErrVerification
+		if assert.ErrorAs(t, s.Verify(), &verr) {
+			assert.True(t, verr.Failed(VerifyIntegrity))
+		}
+	})
+}
+
+func TestStore_SearchForVulnerabilitiesWithOptions_Pagination(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(id, name string) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			PackageName:       name,
+			Namespace:         "github:language:python",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "python",
+		}
+	}
+
+	if err = s.AddVulnerability(
+		newVuln("GHSA-3", "flask"),
+		newVuln("GHSA-1", "flask"),
+		newVuln("GHSA-2", "flask"),
+		newVuln("GHSA-1", "Flask"),
+		newVuln("GHSA-2", "Flask"),
+	); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+
+	keys := func(vulns []v5.Vulnerability) []string {
+		var out []string
+		for _, v := range vulns {
+			out = append(out, v.ID+"/"+v.PackageName)
+		}
+		return out
+	}
+
+	opts := v5.SearchOptions{CaseInsensitivePackageName: true, Limit: 2}
+	first, err := s.SearchForVulnerabilitiesWithOptions("github:language:python", "flask", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GHSA-1/Flask", "GHSA-1/flask"}, keys(first))
+
+	opts.Offset = 2
+	second, err := s.SearchForVulnerabilitiesWithOptions("github:language:python", "flask", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GHSA-2/Flask", "GHSA-2/flask"}, keys(second))
+	for _, k := range keys(first) {
+		assert.NotContains(t, keys(second), k)
+	}
+
+	rest, err := s.SearchForVulnerabilitiesWithOptions("github:language:python", "flask", v5.SearchOptions{CaseInsensitivePackageName: true, Offset: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GHSA-3/flask"}, keys(rest))
+
+	// without a limit or offset everything is returned
+	all, err := s.SearchForVulnerabilitiesWithOptions("github:language:python", "flask", v5.SearchOptions{CaseInsensitivePackageName: true})
+	assert.NoError(t, err)
+	assert.Len(t, all, 5)
+}
+
+func TestStore_SearchVulnerabilitiesByIDPattern(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVulnerability := func(id, namespace string) v5.Vulnerability {
+		return v5.Vulnerability{ID: id, Namespace: namespace, PackageName: "pkg", VersionConstraint: "< 1.0"}
+	}
+	if err := s.AddVulnerability(
+		newVulnerability("CVE-2021-1234", "nvd:cpe"),
+		newVulnerability("CVE-2021-1234", "debian:distro:debian:12"),
+		newVulnerability("CVE-2021-5678", "nvd:cpe"),
+		newVulnerability("CVE-2022-1234", "nvd:cpe"),
+		newVulnerability("GHSA-abcd-efgh-ijkl", "github:language:python"),
+		newVulnerability("GHSA-mnop-qrst-uvwx", "github:language:go"),
+		newVulnerability("VULN_100%", "other"),
+		newVulnerability("VULNX1000", "other"),
+	); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{pattern: "GHSA-*", expected: []string{"GHSA-abcd-efgh-ijkl", "GHSA-mnop-qrst-uvwx"}},
+		{pattern: "CVE-2021-*", expected: []string{"CVE-2021-1234", "CVE-2021-1234", "CVE-2021-5678"}},
+		{pattern: "CVE-202?-1234", expected: []string{"CVE-2021-1234", "CVE-2021-1234", "CVE-2022-1234"}},
+		{pattern: "CVE-2022-1234", expected: []string{"CVE-2022-1234"}},
+		// LIKE wildcards within the pattern are matched literally
+		{pattern: "VULN_100%", expected: []string{"VULN_100%"}},
+		{pattern: "VULN_*", expected: []string{"VULN_100%"}},
+		{pattern: "CVE-2021%"},
+		{pattern: "CVE-2023-*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			actual, err := s.SearchVulnerabilitiesByIDPattern(tt.pattern)
+			assert.NoError(t, err)
+
+			var ids []string
+			for _, v := range actual {
+				ids = append(ids, v.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
+
+func Test_globToLike(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{pattern: "GHSA-*", expected: "GHSA-%"},
+		{pattern: "CVE-202?-*", expected: "CVE-202_-%"},
+		{pattern: `100%_\`, expected: `100\%\_\\`},
+		{pattern: "", expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			assert.Equal(t, tt.expected, globToLike(tt.pattern))
+		})
+	}
+}
+
+func TestStore_SearchForVulnerabilitiesWithOptions(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	if err = s.AddVulnerability(v5.Vulnerability{
+		ID:                "GHSA-1",
+		PackageName:       "flask",
+		Namespace:         "github:language:python",
+		VersionConstraint: "< 2.0",
+		VersionFormat:     "python",
+	}); err != nil {
+		t.Fatalf("failed to add vulnerability: %+v", err)
+	}
+
+	vulns, err := s.SearchForVulnerabilities("github:language:python", "Flask")
+	assert.NoError(t, err)
+	assert.Empty(t, vulns)
+
+	vulns, err = s.SearchForVulnerabilitiesWithOptions("github:language:python", "Flask", v5.SearchOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, vulns)
+
+	vulns, err = s.SearchForVulnerabilitiesWithOptions("github:language:python", "Flask", v5.SearchOptions{CaseInsensitivePackageName: true})
+	assert.NoError(t, err)
+	if assert.Len(t, vulns, 1) {
+		assert.Equal(t, "flask", vulns[0].PackageName)
+	}
+
+	// the namespace is still matched exactly
+	vulns, err = s.SearchForVulnerabilitiesWithOptions("GitHub:language:python", "Flask", v5.SearchOptions{CaseInsensitivePackageName: true})
+	assert.NoError(t, err)
+	assert.Empty(t, vulns)
+}
+
+func TestStore_GetVulnerabilitiesByNamespace(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(id, namespace string) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			PackageName:       "curl",
+			Namespace:         namespace,
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "deb",
+		}
+	}
+
+	if err = s.AddVulnerability(
+		newVuln("CVE-1", "debian:distro:debian:12"),
+		newVuln("CVE-2", "debian:distro:debian:12"),
+		newVuln("CVE-1", "ubuntu:distro:ubuntu:22.04"),
+	); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, namespace := range []string{"debian:distro:debian:12", "ubuntu:distro:ubuntu:22.04"} {
+		vulns, err := s.GetVulnerabilitiesByNamespace(namespace)
+		assert.NoError(t, err)
+		for _, v := range vulns {
+			assert.Equal(t, namespace, v.Namespace)
+		}
+		counts[namespace] = len(vulns)
+	}
+	assert.Equal(t, map[string]int{"debian:distro:debian:12": 2, "ubuntu:distro:ubuntu:22.04": 1}, counts)
+
+	vulns, err := s.GetVulnerabilitiesByNamespace("alpine:distro:alpine:3.18")
+	assert.NoError(t, err)
+	assert.NotNil(t, vulns)
+	assert.Empty(t, vulns)
+}
+
+func TestStore_AddVulnerabilityMetadata_InvalidCvss(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newMetadata := func(id, vector string) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{
+			ID:         id,
+			Namespace:  "nvd:cpe",
+			DataSource: "https://nvd.nist.gov/vuln/detail/" + id,
+			Severity:   "High",
+			URLs:       []string{"https://example.com/" + id},
+			Cvss: []v5.Cvss{
+				{
+					Version: "3.1",
+					Vector:  vector,
+					Metrics: v5.NewCvssMetrics(9.8, 3.9, 5.9),
+				},
+			},
+		}
+	}
+
+	if _, err = s.AddVulnerabilityMetadata(newMetadata("CVE-existing", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H")); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	// a malformed vector in a batch fails the entire batch
+	_, err = s.AddVulnerabilityMetadata(
+		newMetadata("CVE-valid", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"),
+		newMetadata("CVE-invalid", "CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"),
+	)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `id="CVE-invalid"`)
+		assert.Contains(t, err.Error(), `namespace="nvd:cpe"`)
+		assert.Contains(t, err.Error(), "unable to parse CVSS v3.1 vector")
+	}
+
+	m, err := s.GetVulnerabilityMetadata("CVE-valid", "nvd:cpe")
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+
+	// a malformed vector is not merged into an existing record
+	_, err = s.AddVulnerabilityMetadata(newMetadata("CVE-existing", "CVSS:3.1/AV:N/AC:L"))
+	assert.Error(t, err)
+
+	m, err = s.GetVulnerabilityMetadata("CVE-existing", "nvd:cpe")
+	assert.NoError(t, err)
+	if assert.NotNil(t, m) {
+		assert.Len(t, m.Cvss, 1)
+	}
+}
+
+func TestStore_AddVulnerabilityMetadata_CvssVersionMismatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		vector  string
+	}{
+		{name: "v2 vector declared as v3.0", version: "3.0", vector: "AV:N/AC:L/Au:N/C:C/I:C/A:C"},
+		{name: "v3.1 vector declared as v3.0", version: "3.0", vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+		{name: "v3.1 vector declared as v2", version: "2.0", vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+		{name: "v4.0 vector declared as v3.1", version: "3.1", vector: "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(t.TempDir(), true)
+			if err != nil {
+				t.Fatalf("could not create store: %+v", err)
+			}
+
+			_, err = s.AddVulnerabilityMetadata(v5.VulnerabilityMetadata{
+				ID:        "CVE-mismatch",
+				Namespace: "nvd:cpe",
+				Severity:  "High",
+				Cvss:      []v5.Cvss{{Version: tt.version, Vector: tt.vector}},
+			})
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), `id="CVE-mismatch"`)
+				assert.Contains(t, err.Error(), `namespace="nvd:cpe"`)
+				assert.Contains(t, err.Error(), tt.vector)
+			}
+
+			m, err := s.GetVulnerabilityMetadata("CVE-mismatch", "nvd:cpe")
+			assert.NoError(t, err)
+			assert.Nil(t, m)
+		})
+	}
+}
+
+func TestStore_AddVulnerabilityMetadata_RepeatedImportDedupsCvss(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	metadata := v5.VulnerabilityMetadata{
+		ID:         "CVE-2023-1234",
+		Namespace:  "nvd:cpe",
+		DataSource: "https://nvd.nist.gov/vuln/detail/CVE-2023-1234",
+		Severity:   "Critical",
+		URLs:       []string{"https://example.com/CVE-2023-1234"},
+		Cvss: []v5.Cvss{
+			{
+				Version: "3.1",
+				// note: the metrics are not in canonical order
+				Vector:  "CVSS:3.1/AC:L/AV:N/PR:N/UI:N/S:U/C:H/I:H/A:H",
+				Metrics: v5.NewCvssMetrics(9.8, 3.9, 5.9),
+				// note: this reads back from the store as a map rather than a struct
+				VendorMetadata: CustomMetadata{
+					SuperScore: "100",
+					Vendor:     "debian",
+				},
+			},
+			{
+				Version: "2.0",
+				Vector:  "AV:N/AC:L/Au:N/C:P/I:P/A:P",
+				Metrics: v5.NewCvssMetrics(7.5, 10, 6.4),
+				VendorMetadata: map[string]interface{}{
+					"vendor": "debian",
+					"score":  "high",
+				},
+			},
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err = s.AddVulnerabilityMetadata(metadata); err != nil {
+			t.Fatalf("failed to add metadata (import %d): %+v", i, err)
+		}
+
+		actual, err := s.GetVulnerabilityMetadata(metadata.ID, metadata.Namespace)
+		if err != nil {
+			t.Fatalf("failed to get metadata: %+v", err)
+		}
+		if assert.NotNil(t, actual) {
+			assert.Len(t, actual.Cvss, len(metadata.Cvss), "import %d", i)
+		}
+	}
+
+	// the same vector with different metric ordering is a duplicate
+	reordered := metadata
+	reordered.Cvss = []v5.Cvss{metadata.Cvss[0]}
+	reordered.Cvss[0].Vector = "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"
+	if _, err = s.AddVulnerabilityMetadata(reordered); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	actual, err := s.GetVulnerabilityMetadata(metadata.ID, metadata.Namespace)
+	if err != nil {
+		t.Fatalf("failed to get metadata: %+v", err)
+	}
+	if assert.NotNil(t, actual) {
+		assert.Len(t, actual.Cvss, len(metadata.Cvss))
+	}
+}
+
+func TestStore_AddVulnerabilityMetadata_MergePolicy(t *testing.T) {
+	existing := v5.VulnerabilityMetadata{
+		ID:          "CVE-2023-1234",
+		Namespace:   "nvd:cpe",
+		DataSource:  "https://nvd.nist.gov/vuln/detail/CVE-2023-1234",
+		Severity:    "High",
+		URLs:        []string{"https://example.com/a"},
+		Description: "a vulnerabilty with a typo",
+	}
+
+	incoming := existing
+	incoming.Severity = "Critical"
+	incoming.URLs = []string{"https://example.com/b"}
+	incoming.Description = "a vulnerability without a typo"
+
+	tests := []struct {
+		name                string
+		opts                []Option
+		wantErr             assert.ErrorAssertionFunc
+		expectedSeverity    string
+		expectedDescription string
+		expectedURLs        []string
+	}{
+		{
+			name:                "default is to error",
+			wantErr:             assert.Error,
+			expectedSeverity:    existing.Severity,
+			expectedDescription: existing.Description,
+			expectedURLs:        existing.URLs,
+		},
+		{
+			name:                "error",
+			opts:                []Option{WithMetadataMergePolicy(MergePolicyError)},
+			wantErr:             assert.Error,
+			expectedSeverity:    existing.Severity,
+			expectedDescription: existing.Description,
+			expectedURLs:        existing.URLs,
+		},
+		{
+			name:                "keep existing",
+			opts:                []Option{WithMetadataMergePolicy(MergePolicyKeepExisting)},
+			expectedSeverity:    existing.Severity,
+			expectedDescription: existing.Description,
+			expectedURLs:        []string{"https://example.com/a", "https://example.com/b"},
+		},
+		{
+			name:                "prefer incoming",
+			opts:                []Option{WithMetadataMergePolicy(MergePolicyPreferIncoming)},
+			expectedSeverity:    incoming.Severity,
+			expectedDescription: incoming.Description,
+			expectedURLs:        []string{"https://example.com/a", "https://example.com/b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr == nil {
+				tt.wantErr = assert.NoError
+			}
+
+			s, err := New(t.TempDir(), true, tt.opts...)
+			if err != nil {
+				t.Fatalf("could not create store: %+v", err)
+			}
+
+			if _, err = s.AddVulnerabilityMetadata(existing); err != nil {
+				t.Fatalf("failed to add metadata: %+v", err)
+			}
+
+			_, err = s.AddVulnerabilityMetadata(incoming)
+			tt.wantErr(t, err)
+
+			actual, err := s.GetVulnerabilityMetadata(existing.ID, existing.Namespace)
+			if err != nil {
+				t.Fatalf("failed to get metadata: %+v", err)
+			}
+			if assert.NotNil(t, actual) {
+				assert.Equal(t, tt.expectedSeverity, actual.Severity)
+				assert.Equal(t, tt.expectedDescription, actual.Description)
+				assert.Equal(t, tt.expectedURLs, actual.URLs)
+			}
+		})
+	}
+}
+
+func TestStore_UnknownMergePolicy(t *testing.T) {
+	_, err := New(t.TempDir(), true, WithMetadataMergePolicy("overwrite-everything"))
+	assert.ErrorContains(t, err, "unknown metadata merge policy")
+}
+
+func TestStore_AddVulnerabilityMetadata_SeverityUpdates(t *testing.T) {
+	newMetadata := func(severity, url string) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{
+			ID:          "CVE-2023-1234",
+			Namespace:   "nvd:cpe",
+			DataSource:  "https://nvd.nist.gov/vuln/detail/CVE-2023-1234",
+			Severity:    severity,
+			URLs:        []string{url},
+			Description: "a vulnerability",
+			Cvss: []v5.Cvss{
+				{
+					Version: "3.1",
+					Vector:  "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+					Metrics: v5.NewCvssMetrics(9.8, 3.9, 5.9),
+				},
+			},
+		}
+	}
+
+	s, err := New(t.TempDir(), true, WithSeverityUpdates(true))
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	if _, err = s.AddVulnerabilityMetadata(newMetadata("Medium", "https://example.com/a")); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+	if _, err = s.AddVulnerabilityMetadata(newMetadata("Critical", "https://example.com/b")); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	actual, err := s.GetVulnerabilityMetadata("CVE-2023-1234", "nvd:cpe")
+	if err != nil {
+		t.Fatalf("failed to get metadata: %+v", err)
+	}
+	if assert.NotNil(t, actual) {
+		assert.Equal(t, "Critical", actual.Severity)
+		assert.Len(t, actual.Cvss, 1)
+		assert.Equal(t, []string{"https://example.com/a", "https://example.com/b"}, actual.URLs)
+	}
+
+	// description conflicts are still subject to the merge policy
+	conflicting := newMetadata("High", "https://example.com/c")
+	conflicting.Description = "a different vulnerability"
+	_, err = s.AddVulnerabilityMetadata(conflicting)
+	assert.Error(t, err)
+
+	// severity updates must be opted into
+	s, err = New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	if _, err = s.AddVulnerabilityMetadata(newMetadata("Medium", "https://example.com/a")); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+	_, err = s.AddVulnerabilityMetadata(newMetadata("Critical", "https://example.com/b"))
+	assert.Error(t, err)
+}
+
+func TestStore_AddVulnerabilityMetadata_PreservedURLOrder(t *testing.T) {
+	newMetadata := func(urls ...string) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{
+			ID:        "CVE-2023-1234",
+			Namespace: "nvd:cpe",
+			Severity:  "High",
+			URLs:      urls,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected []string
+	}{
+		{
+			name:     "sorted by default",
+			expected: []string{"https://a.example.com", "https://nvd.nist.gov/vuln/detail/CVE-2023-1234", "https://z.example.com"},
+		},
+		{
+			name:     "order preserved",
+			opts:     []Option{WithPreservedURLOrder(true)},
+			expected: []string{"https://nvd.nist.gov/vuln/detail/CVE-2023-1234", "https://z.example.com", "https://a.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(t.TempDir(), true, tt.opts...)
+			if err != nil {
+				t.Fatalf("could not create store: %+v", err)
+			}
+
+			if _, err = s.AddVulnerabilityMetadata(newMetadata("https://nvd.nist.gov/vuln/detail/CVE-2023-1234", "https://z.example.com")); err != nil {
+				t.Fatalf("failed to add metadata: %+v", err)
+			}
+			if _, err = s.AddVulnerabilityMetadata(newMetadata("https://z.example.com", "https://a.example.com", "https://a.example.com")); err != nil {
+				t.Fatalf("failed to add metadata: %+v", err)
+			}
+
+			actual, err := s.GetVulnerabilityMetadata("CVE-2023-1234", "nvd:cpe")
+			if err != nil {
+				t.Fatalf("failed to get metadata: %+v", err)
+			}
+			if assert.NotNil(t, actual) {
+				assert.Equal(t, tt.expected, actual.URLs)
+			}
+		})
+	}
+}
+
+func TestStore_UpsertVulnerability(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(id string, state v5.FixState) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			PackageName:       "curl",
+			Namespace:         "debian:distro:debian:12",
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "deb",
+			Fix: v5.Fix{
+				State: state,
+			},
+		}
+	}
+
+	countRows := func() int64 {
+		t.Helper()
+		count, err := s.CountVulnerabilities()
+		if err != nil {
+			t.Fatalf("failed to count vulnerabilities: %+v", err)
+		}
+		return count
+	}
+
+	assert.NoError(t, s.UpsertVulnerability(newVuln("CVE-1", v5.UnknownFixState)))
+	assert.NoError(t, s.UpsertVulnerability(newVuln("CVE-1", v5.UnknownFixState)))
+	assert.Equal(t, int64(1), countRows())
+
+	// the existing record is replaced, while new records are inserted
+	assert.NoError(t, s.UpsertVulnerability(newVuln("CVE-1", v5.NotFixedState), newVuln("CVE-2", v5.NotFixedState)))
+	assert.Equal(t, int64(2), countRows())
+
+	vulns, err := s.GetVulnerability("debian:distro:debian:12", "CVE-1")
+	assert.NoError(t, err)
+	if assert.Len(t, vulns, 1) {
+		assert.Equal(t, v5.NotFixedState, vulns[0].Fix.State)
+	}
+
+	// records with a different version constraint have a different identity
+	other := newVuln("CVE-1", v5.NotFixedState)
+	other.VersionConstraint = "< 2.0"
+	assert.NoError(t, s.UpsertVulnerability(other))
+	assert.Equal(t, int64(3), countRows())
+
+	// duplicates (from plain adds) are collapsed
+	assert.NoError(t, s.AddVulnerability(newVuln("CVE-2", v5.NotFixedState), newVuln("CVE-2", v5.NotFixedState)))
+	assert.Equal(t, int64(5), countRows())
+	assert.NoError(t, s.UpsertVulnerability(newVuln("CVE-2", v5.WontFixState), newVuln("CVE-2", v5.FixedState)))
+	assert.Equal(t, int64(3), countRows())
+
+	vulns, err = s.GetVulnerability("debian:distro:debian:12", "CVE-2")
+	assert.NoError(t, err)
+	if assert.Len(t, vulns, 1) {
+		assert.Equal(t, v5.FixedState, vulns[0].Fix.State)
+	}
+}
+
+func TestStore_DeleteVulnerability(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(id, packageName, namespace string) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			PackageName:       packageName,
+			Namespace:         namespace,
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "deb",
+		}
+	}
+
+	if err = s.AddVulnerability(
+		newVuln("CVE-1", "curl", "debian:distro:debian:12"),
+		newVuln("CVE-1", "libcurl", "debian:distro:debian:12"),
+		newVuln("CVE-1", "curl", "ubuntu:distro:ubuntu:22.04"),
+	); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+
+	deleted, err := s.DeleteVulnerability("CVE-1", "debian:distro:debian:12")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	vulns, err := s.GetVulnerability("debian:distro:debian:12", "CVE-1")
+	assert.NoError(t, err)
+	assert.Empty(t, vulns)
+
+	vulns, err = s.GetVulnerability("ubuntu:distro:ubuntu:22.04", "CVE-1")
+	assert.NoError(t, err)
+	assert.Len(t, vulns, 1)
+
+	deleted, err = s.DeleteVulnerability("CVE-1", "debian:distro:debian:12")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+}
+
+func TestStore_DeleteVulnerabilityMetadata(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newMetadata := func(id, namespace string) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{
+			ID:         id,
+			Namespace:  namespace,
+			DataSource: "https://example.com/" + id,
+			Severity:   "High",
+		}
+	}
+
+	if _, err = s.AddVulnerabilityMetadata(
+		newMetadata("CVE-1", "nvd:cpe"),
+		newMetadata("CVE-1", "debian:distro:debian:12"),
+		newMetadata("CVE-2", "nvd:cpe"),
+	); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	deleted, err := s.DeleteVulnerabilityMetadata("CVE-1", "nvd:cpe")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	m, err := s.GetVulnerabilityMetadata("CVE-1", "nvd:cpe")
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+
+	for _, remaining := range []struct{ id, namespace string }{
+		{"CVE-1", "debian:distro:debian:12"},
+		{"CVE-2", "nvd:cpe"},
+	} {
+		m, err = s.GetVulnerabilityMetadata(remaining.id, remaining.namespace)
+		assert.NoError(t, err)
+		assert.NotNil(t, m, "%s in %s", remaining.id, remaining.namespace)
+	}
+}
+
+func TestStore_AddVulnerabilityMetadata_Summary(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newMetadata := func(id string) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{
+			ID:         id,
+			Namespace:  "nvd:cpe",
+			DataSource: "https://example.com/" + id,
+			Severity:   "High",
+		}
+	}
+
+	summary, err := s.AddVulnerabilityMetadata(newMetadata("CVE-1"), newMetadata("CVE-2"))
+	assert.NoError(t, err)
+	assert.Equal(t, v5.MetadataImportSummary{Created: 2}, summary)
+
+	summary, err = s.AddVulnerabilityMetadata(newMetadata("CVE-2"), newMetadata("CVE-3"))
+	assert.NoError(t, err)
+	assert.Equal(t, v5.MetadataImportSummary{Created: 1, Merged: 1}, summary)
+
+	// records written before a failure are still reported
+	conflicting := newMetadata("CVE-1")
+	conflicting.Severity = "Low"
+	summary, err = s.AddVulnerabilityMetadata(newMetadata("CVE-4"), conflicting)
+	assert.Error(t, err)
+	assert.Equal(t, v5.MetadataImportSummary{Created: 1}, summary)
+}
+
+func TestStore_AddVulnerabilityMetadataBulk(t *testing.T) {
+	newMetadata := func(id, severity string, urls []string, cvss ...v5.Cvss) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{
+			ID:         id,
+			Namespace:  "nvd:cpe",
+			DataSource: "https://example.com/" + id,
+			Severity:   severity,
+			URLs:       urls,
+			Cvss:       cvss,
+		}
+	}
+
+	cvss2 := v5.Cvss{
+		Version: "2.0",
+		Metrics: v5.NewCvssMetrics(4.1, 5.2, 6.3),
+		Vector:  "AV:N/AC:L/Au:N/C:P/I:P/A:P",
+	}
+	cvss3 := v5.Cvss{
+		Version: "3.0",
+		Metrics: v5.NewCvssMetrics(1.4, 2.5, 3.6),
+		Vector:  "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+	}
+
+	existing := []v5.VulnerabilityMetadata{
+		newMetadata("CVE-1", "High", []string{"https://ancho.re"}, cvss2),
+		newMetadata("CVE-2", "Low", nil),
+	}
+
+	tests := []struct {
+		name     string
+		add      []v5.VulnerabilityMetadata
+		expected v5.MetadataImportSummary
+		err      bool
+	}{
+		{
+			name: "new and existing records",
+			add: []v5.VulnerabilityMetadata{
+				newMetadata("CVE-1", "High", []string{"https://google.com"}, cvss2, cvss3),
+				newMetadata("CVE-3", "Medium", nil),
+			},
+			expected: v5.MetadataImportSummary{Created: 1, Merged: 1},
+		},
+		{
+			name: "duplicate incoming records",
+			add: []v5.VulnerabilityMetadata{
+				newMetadata("CVE-3", "Medium", []string{"https://ancho.re"}, cvss2),
+				newMetadata("CVE-3", "Medium", []string{"https://google.com", "https://ancho.re"}, cvss2, cvss3),
+				newMetadata("CVE-1", "High", []string{"https://google.com"}),
+				newMetadata("CVE-1", "High", []string{"https://yahoo.com"}, cvss3),
+			},
+			expected: v5.MetadataImportSummary{Created: 1, Merged: 3},
+		},
+		{
+			name: "records before a conflict are written",
+			add: []v5.VulnerabilityMetadata{
+				newMetadata("CVE-3", "Medium", nil),
+				newMetadata("CVE-1", "High", []string{"https://google.com"}),
+				newMetadata("CVE-2", "Critical", nil),
+				newMetadata("CVE-4", "Medium", nil),
+			},
+			expected: v5.MetadataImportSummary{Created: 1, Merged: 1},
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			perRecord, err := New(t.TempDir(), true)
+			if err != nil {
+				t.Fatalf("could not create store: %+v", err)
+			}
+			bulk, err := New(t.TempDir(), true)
+			if err != nil {
+				t.Fatalf("could not create store: %+v", err)
+			}
+
+			for _, s := range []v5.Store{perRecord, bulk} {
+				if _, err := s.AddVulnerabilityMetadata(existing...); err != nil {
+					t.Fatalf("failed to add existing metadata: %+v", err)
+				}
+			}
+
+			// the per-record behavior is the reference for the bulk import
+			var perRecordErr error
+			for _, m := range test.add {
+				if _, perRecordErr = perRecord.AddVulnerabilityMetadata(m); perRecordErr != nil {
+					break
+				}
+			}
+			assert.Equal(t, test.err, perRecordErr != nil)
+
+			summary, err := bulk.AddVulnerabilityMetadataBulk(test.add...)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expected, summary)
+
+			allMetadata := func(s v5.Store) []v5.VulnerabilityMetadata {
+				all, err := s.GetAllVulnerabilityMetadata()
+				if err != nil {
+					t.Fatalf("failed to get metadata: %+v", err)
+				}
+				sort.Slice(*all, func(i, j int) bool {
+					return (*all)[i].ID < (*all)[j].ID
+				})
+				return *all
+			}
+
+			for _, d := range deep.Equal(allMetadata(perRecord), allMetadata(bulk)) {
+				t.Errorf("Diff: %+v", d)
+			}
+		})
+	}
+}
+
+func TestStore_AddVulnerabilityMetadataBulk_InvalidCvss(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	summary, err := s.AddVulnerabilityMetadataBulk(
+		v5.VulnerabilityMetadata{ID: "CVE-1", Namespace: "nvd:cpe", Severity: "High"},
+		v5.VulnerabilityMetadata{ID: "CVE-2", Namespace: "nvd:cpe", Severity: "High", Cvss: []v5.Cvss{{Version: "3.1", Vector: "not a vector"}}},
+	)
+	assert.Error(t, err)
+	assert.Equal(t, v5.MetadataImportSummary{}, summary)
+
+	// no records are written when any record is malformed
+	all, err := s.GetAllVulnerabilityMetadata()
+	assert.NoError(t, err)
+	assert.Empty(t, *all)
+}
+
+func TestStore_GetVulnerabilityMetadataBatch(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	// note: enough records to require multiple queries
+	var metadata []v5.VulnerabilityMetadata
+	var keys []v5.MetadataKey
+	for i := 0; i < 2*metadataKeyBatchSize+1; i++ {
+		id := fmt.Sprintf("CVE-%d", i)
+		metadata = append(metadata, v5.VulnerabilityMetadata{
+			ID:         id,
+			Namespace:  "nvd:cpe",
+			DataSource: "https://example.com/" + id,
+			Severity:   "High",
+		})
+		keys = append(keys, v5.MetadataKey{ID: id, Namespace: "nvd:cpe"})
+	}
+	if _, err = s.AddVulnerabilityMetadata(metadata...); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	missing := []v5.MetadataKey{
+		{ID: "CVE-missing", Namespace: "nvd:cpe"},
+		// the namespace must match as well
+		{ID: "CVE-0", Namespace: "debian:distro:debian:12"},
+	}
+
+	// duplicate keys are tolerated
+	actual, err := s.GetVulnerabilityMetadataBatch(append(append(keys, missing...), keys[0]))
+	assert.NoError(t, err)
+	assert.Len(t, actual, len(keys))
+
+	for _, k := range keys {
+		if m, ok := actual[k]; assert.True(t, ok, "missing %+v", k) {
+			assert.Equal(t, k.ID, m.ID)
+			assert.Equal(t, k.Namespace, m.Namespace)
+		}
+	}
+	for _, k := range missing {
+		assert.NotContains(t, actual, k)
+	}
+
+	actual, err = s.GetVulnerabilityMetadataBatch(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, actual)
+}
+
+func TestStore_GetVulnerabilityMetadata_DedupsCvssOnRead(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	v31 := v5.Cvss{
+		Version: "3.1",
+		Vector:  "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		Metrics: v5.NewCvssMetrics(9.8, 3.9, 5.9),
+		Source:  "nvd@nist.gov",
+		Type:    "Primary",
+	}
+	v2 := v5.Cvss{
+		Version: "2.0",
+		Vector:  "AV:N/AC:L/Au:N/C:P/I:P/A:P",
+		Metrics: v5.NewCvssMetrics(7.5, 10, 6.4),
+		Source:  "nvd@nist.gov",
+		Type:    "Primary",
+	}
+	reordered := v31
+	reordered.Vector = "CVSS:3.1/AC:L/AV:N/PR:N/UI:N/S:U/C:H/I:H/A:H"
+
+	// note: duplicates within a single new record are written as-is (as older DB builds may have done)
+	if _, err = s.AddVulnerabilityMetadata(v5.VulnerabilityMetadata{
+		ID:         "CVE-2023-1234",
+		Namespace:  "nvd:cpe",
+		DataSource: "https://nvd.nist.gov/vuln/detail/CVE-2023-1234",
+		Severity:   "Critical",
+		Cvss:       []v5.Cvss{v31, v2, reordered, v2},
+	}); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	var before model.VulnerabilityMetadataModel
+	if err = s.(*store).db.First(&before).Error; err != nil {
+		t.Fatalf("failed to read model: %+v", err)
+	}
+
+	actual, err := s.GetVulnerabilityMetadata("CVE-2023-1234", "nvd:cpe")
+	assert.NoError(t, err)
+	if assert.NotNil(t, actual) {
+		assert.Equal(t, []v5.Cvss{v2, v31}, actual.Cvss)
+	}
+
+	// the stored record is left unchanged
+	var after model.VulnerabilityMetadataModel
+	if err = s.(*store).db.First(&after).Error; err != nil {
+		t.Fatalf("failed to read model: %+v", err)
+	}
+	assert.Equal(t, before.Cvss, after.Cvss)
+
+	var stored []v5.Cvss
+	assert.NoError(t, json.Unmarshal(after.Cvss.ToByteSlice(), &stored))
+	assert.Len(t, stored, 4)
+}
+
+func TestStore_AddVulnerabilityMetadata_MergeLeavesStoredCvss(t *testing.T) {
+	v31 := v5.Cvss{
+		Version: "3.1",
+		Vector:  "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		Metrics: v5.NewCvssMetrics(9.8, 3.9, 5.9),
+		Source:  "nvd@nist.gov",
+		Type:    "Primary",
+	}
+	v2 := v5.Cvss{
+		Version: "2.0",
+		Vector:  "AV:N/AC:L/Au:N/C:P/I:P/A:P",
+		Metrics: v5.NewCvssMetrics(7.5, 10, 6.4),
+		Source:  "nvd@nist.gov",
+		Type:    "Primary",
+	}
+	v40 := v5.Cvss{
+		Version: "4.0",
+		Vector:  "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N",
+		Metrics: v5.NewCvssMetrics(9.3, 0, 0),
+		Source:  "nvd@nist.gov",
+		Type:    "Secondary",
+	}
+
+	newMetadata := func(cvss ...v5.Cvss) v5.VulnerabilityMetadata {
+		return v5.VulnerabilityMetadata{
+			ID:         "CVE-2023-1234",
+			Namespace:  "nvd:cpe",
+			DataSource: "https://nvd.nist.gov/vuln/detail/CVE-2023-1234",
+			Severity:   "Critical",
+			Cvss:       cvss,
+		}
+	}
+
+	tests := []struct {
+		name string
+		add  func(v5.Store, ...v5.VulnerabilityMetadata) (v5.MetadataImportSummary, error)
+	}{
+		{name: "per record", add: v5.Store.AddVulnerabilityMetadata},
+		{name: "bulk", add: v5.Store.AddVulnerabilityMetadataBulk},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(t.TempDir(), true)
+			if err != nil {
+				t.Fatalf("could not create store: %+v", err)
+			}
+
+			storedCvss := func() string {
+				var m model.VulnerabilityMetadataModel
+				if err := s.(*store).db.First(&m).Error; err != nil {
+					t.Fatalf("failed to read model: %+v", err)
+				}
+				return m.Cvss.String
+			}
+
+			// note: duplicates within a single new record are written as-is (as older DB builds may have done)
+			if _, err = tt.add(s, newMetadata(v31, v2, v31)); err != nil {
+				t.Fatalf("failed to add metadata: %+v", err)
+			}
+			before := storedCvss()
+
+			// merging only known entries leaves the stored entries untouched (rather than deduplicated and sorted)
+			_, err = tt.add(s, newMetadata(v2, v31))
+			assert.NoError(t, err)
+			assert.Equal(t, before, storedCvss())
+
+			// merging a new entry only appends the new entry
+			_, err = tt.add(s, newMetadata(v40))
+			assert.NoError(t, err)
+			added, err := json.Marshal(v40)
+			assert.NoError(t, err)
+			assert.Equal(t, strings.TrimSuffix(before, "]")+","+string(added)+"]", storedCvss())
+		})
+	}
+}
+
+func TestStore_HasNamespace(t *testing.T) {
+	s, err := New(t.TempDir(), true, WithBuildTime(time.Now()))
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	if _, err = s.AddVulnerabilityMetadata(v5.VulnerabilityMetadata{
+		ID:         "CVE-2023-1234",
+		Namespace:  "debian:distro:debian:12",
+		DataSource: "https://security-tracker.debian.org/tracker/CVE-2023-1234",
+		Severity:   "High",
+	}); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	tests := []struct {
+		namespace string
+		expected  bool
+	}{
+		{namespace: "debian:distro:debian:12", expected: true},
+		{namespace: "debian:distro:debian:11", expected: false},
+		{namespace: "debian:distro:debian", expected: false},
+		{namespace: "", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			actual, err := s.HasNamespace(tt.namespace)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+
+	// time-scoped views only see namespaces with records at that time
+	actual, err := s.AsOf(time.Now().Add(-time.Hour)).HasNamespace("debian:distro:debian:12")
+	assert.NoError(t, err)
+	assert.False(t, actual)
+}
+
+func TestStore_GetPackageNames(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVulnerability := func(id, namespace, packageName string) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:                id,
+			PackageName:       packageName,
+			Namespace:         namespace,
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+		}
+	}
+
+	if err := s.AddVulnerability(
+		newVulnerability("CVE-1", "github:language:python", "requests"),
+		newVulnerability("CVE-2", "github:language:python", "django"),
+		newVulnerability("CVE-3", "github:language:python", "requests"),
+		newVulnerability("CVE-4", "github:language:javascript", "lodash"),
+	); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+
+	tests := []struct {
+		namespace string
+		expected  []string
+	}{
+		{namespace: "github:language:python", expected: []string{"django", "requests"}},
+		{namespace: "github:language:javascript", expected: []string{"lodash"}},
+		{namespace: "github:language:ruby", expected: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			actual, err := s.GetPackageNames(tt.namespace)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestStore_GetPackageNamespaces(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	if err := s.AddVulnerability(
+		v5.Vulnerability{ID: "CVE-1", Namespace: "nvd:cpe", PackageName: "curl", VersionConstraint: "< 8.0"},
+		v5.Vulnerability{ID: "CVE-1", Namespace: "debian:distro:debian:12", PackageName: "curl", VersionConstraint: "< 8.0"},
+		v5.Vulnerability{ID: "CVE-2", Namespace: "debian:distro:debian:12", PackageName: "openssl", VersionConstraint: "< 3.0"},
+		// the metadata for this namespace has not been imported (yet)
+		v5.Vulnerability{ID: "CVE-3", Namespace: "alpine:distro:alpine:3.20", PackageName: "busybox", VersionConstraint: "< 1.36"},
+	); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+	if _, err := s.AddVulnerabilityMetadata(
+		v5.VulnerabilityMetadata{ID: "CVE-1", Namespace: "nvd:cpe", Severity: "High"},
+		v5.VulnerabilityMetadata{ID: "CVE-1", Namespace: "debian:distro:debian:12", Severity: "High"},
+		v5.VulnerabilityMetadata{ID: "CVE-2", Namespace: "debian:distro:debian:12", Severity: "Low"},
+	); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+
+	packageNamespaces, err := s.GetPackageNamespaces()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alpine:distro:alpine:3.20", "debian:distro:debian:12", "nvd:cpe"}, packageNamespaces)
+
+	metadataNamespaces, err := s.GetVulnerabilityNamespaces()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"debian:distro:debian:12", "nvd:cpe"}, metadataNamespaces)
+}
+
+func TestStore_GetVulnerabilityMatchExclusionsByConstraint(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	debianCurl := v5.VulnerabilityMatchExclusionConstraint{
+		Vulnerability: v5.VulnerabilityExclusionConstraint{Namespace: "debian:distro:debian:12"},
+		Package:       v5.PackageExclusionConstraint{Name: "curl"},
+	}
+	ubuntuCurl := v5.VulnerabilityMatchExclusionConstraint{
+		Vulnerability: v5.VulnerabilityExclusionConstraint{Namespace: "ubuntu:distro:ubuntu:22.04"},
+		Package:       v5.PackageExclusionConstraint{Name: "curl"},
+	}
+	anyOpenssl := v5.VulnerabilityMatchExclusionConstraint{
+		Package: v5.PackageExclusionConstraint{Name: "openssl"},
+	}
+
+	if err = s.AddVulnerabilityMatchExclusion(
+		v5.VulnerabilityMatchExclusion{ID: "CVE-2", Constraints: []v5.VulnerabilityMatchExclusionConstraint{debianCurl, ubuntuCurl}, Justification: "curl"},
+		v5.VulnerabilityMatchExclusion{ID: "CVE-1", Constraints: []v5.VulnerabilityMatchExclusionConstraint{anyOpenssl}, Justification: "openssl"},
+		v5.VulnerabilityMatchExclusion{ID: "CVE-3", Justification: "everything"},
+	); err != nil {
+		t.Fatalf("failed to add exclusions: %+v", err)
+	}
+
+	tests := []struct {
+		name     string
+		filter   v5.VulnerabilityMatchExclusionFilter
+		expected []v5.VulnerabilityMatchExclusion
+	}{
+		{
+			name:   "no filter",
+			filter: v5.VulnerabilityMatchExclusionFilter{},
+			expected: []v5.VulnerabilityMatchExclusion{
+				{ID: "CVE-1", Constraints: []v5.VulnerabilityMatchExclusionConstraint{anyOpenssl}, Justification: "openssl"},
+				{ID: "CVE-2", Constraints: []v5.VulnerabilityMatchExclusionConstraint{debianCurl, ubuntuCurl}, Justification: "curl"},
+				{ID: "CVE-3", Justification: "everything"},
+			},
+		},
+		{
+			name:   "by ID",
+			filter: v5.VulnerabilityMatchExclusionFilter{ID: "CVE-2"},
+			expected: []v5.VulnerabilityMatchExclusion{
+				{ID: "CVE-2", Constraints: []v5.VulnerabilityMatchExclusionConstraint{debianCurl, ubuntuCurl}, Justification: "curl"},
+			},
+		},
+		{
+			name:   "by namespace",
+			filter: v5.VulnerabilityMatchExclusionFilter{Namespace: "debian:distro:debian:12"},
+			expected: []v5.VulnerabilityMatchExclusion{
+				{ID: "CVE-1", Constraints: []v5.VulnerabilityMatchExclusionConstraint{anyOpenssl}, Justification: "openssl"},
+				{ID: "CVE-2", Constraints: []v5.VulnerabilityMatchExclusionConstraint{debianCurl}, Justification: "curl"},
+				{ID: "CVE-3", Justification: "everything"},
+			},
+		},
+		{
+			name:   "by namespace and package",
+			filter: v5.VulnerabilityMatchExclusionFilter{Namespace: "ubuntu:distro:ubuntu:22.04", PackageName: "curl"},
+			expected: []v5.VulnerabilityMatchExclusion{
+				{ID: "CVE-2", Constraints: []v5.VulnerabilityMatchExclusionConstraint{ubuntuCurl}, Justification: "curl"},
+				{ID: "CVE-3", Justification: "everything"},
+			},
+		},
+		{
+			name:   "by ID and unrelated package",
+			filter: v5.VulnerabilityMatchExclusionFilter{ID: "CVE-2", PackageName: "openssl"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := s.GetVulnerabilityMatchExclusionsByConstraint(tt.filter)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestStore_CopyInto(t *testing.T) {
+	source, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	expectedID := v5.NewID(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err = source.SetID(expectedID); err != nil {
+		t.Fatalf("failed to set ID: %+v", err)
+	}
+
+	namespaces := []string{"nvd:cpe", "github:language:python", "debian:distro:debian:12"}
+	for i, namespace := range namespaces {
+		id := fmt.Sprintf("CVE-2024-000%d", i)
+		if err = source.AddVulnerability(
+			v5.Vulnerability{ID: id, Namespace: namespace, PackageName: "pkg-a", VersionConstraint: "< 1.0"},
+			v5.Vulnerability{ID: id, Namespace: namespace, PackageName: "pkg-b", VersionConstraint: "< 2.0"},
+		); err != nil {
+			t.Fatalf("failed to add vulnerabilities: %+v", err)
+		}
+		if _, err = source.AddVulnerabilityMetadata(v5.VulnerabilityMetadata{
+			ID:        id,
+			Namespace: namespace,
+			Severity:  "High",
+		}); err != nil {
+			t.Fatalf("failed to add metadata: %+v", err)
+		}
+		if err = source.AddVulnerabilityMatchExclusion(v5.VulnerabilityMatchExclusion{
+			ID:            id,
+			Justification: "false positive",
+		}); err != nil {
+			t.Fatalf("failed to add match exclusion: %+v", err)
+		}
+	}
+
+	target, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	assert.NoError(t, source.CopyInto(target, []string{"github:language:python"}))
+
+	actualID, err := target.GetID()
+	assert.NoError(t, err)
+	if assert.NotNil(t, actualID) {
+		assert.Equal(t, expectedID, *actualID)
+	}
+
+	vulns, err := target.GetAllVulnerabilities()
+	assert.NoError(t, err)
+	assert.Len(t, *vulns, 2)
+	for _, v := range *vulns {
+		assert.Equal(t, "CVE-2024-0001", v.ID)
+		assert.Equal(t, "github:language:python", v.Namespace)
+	}
+
+	metadata, err := target.GetAllVulnerabilityMetadata()
+	assert.NoError(t, err)
+	if assert.Len(t, *metadata, 1) {
+		assert.Equal(t, "CVE-2024-0001", (*metadata)[0].ID)
+		assert.Equal(t, "github:language:python", (*metadata)[0].Namespace)
+		assert.Equal(t, "High", (*metadata)[0].Severity)
+	}
+
+	for i := range namespaces {
+		id := fmt.Sprintf("CVE-2024-000%d", i)
+		exclusions, err := target.GetVulnerabilityMatchExclusion(id)
+		assert.NoError(t, err)
+		if id == "CVE-2024-0001" {
+			assert.Len(t, exclusions, 1)
+		} else {
+			assert.Empty(t, exclusions)
+		}
+	}
+}
+
+func TestStore_ExportImportJSONL(t *testing.T) {
+	source, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	expectedID := v5.NewID(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err = source.SetID(expectedID); err != nil {
+		t.Fatalf("failed to set ID: %+v", err)
+	}
+
+	if err = source.AddVulnerability(
+		v5.Vulnerability{
+			ID:                "CVE-2024-0001",
+			Namespace:         "github:language:python",
+			PackageName:       "requests",
+			VersionConstraint: "< 2.0",
+			VersionFormat:     "python",
+			CPEs:              []string{"cpe:2.3:a:python:requests:*:*:*:*:*:*:*:*"},
+			RelatedVulnerabilities: []v5.VulnerabilityReference{
+				{ID: "CVE-2024-0001", Namespace: "nvd:cpe"},
+			},
+			Fix: v5.Fix{Versions: []string{"2.0"}, State: v5.FixedState},
+			Provenance: &v5.Provenance{
+				SourceName: "github",
+				SourceURL:  "https://github.com/advisories",
+				FetchedAt:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		v5.Vulnerability{
+			ID:                "CVE-2024-0001",
+			Namespace:         "nvd:cpe",
+			PackageName:       "requests",
+			VersionConstraint: "< 2.0",
+			VersionFormat:     "unknown",
+		},
+	); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+	if _, err = source.AddVulnerabilityMetadata(
+		v5.VulnerabilityMetadata{
+			ID:          "CVE-2024-0001",
+			Namespace:   "nvd:cpe",
+			DataSource:  "https://nvd.nist.gov/vuln/detail/CVE-2024-0001",
+			Severity:    "High",
+			URLs:        []string{"https://ancho.re"},
+			Description: "a description\nspanning lines",
+			Cvss: []v5.Cvss{
+				{
+					Version: "3.1",
+					Metrics: v5.NewCvssMetrics(7.5, 3.9, 3.6),
+					Vector:  "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N",
+				},
+			},
+		},
+		v5.VulnerabilityMetadata{
+			ID:        "CVE-2024-0001",
+			Namespace: "github:language:python",
+			Severity:  "Medium",
+		},
+	); err != nil {
+		t.Fatalf("failed to add metadata: %+v", err)
+	}
+	expectedExclusion := v5.VulnerabilityMatchExclusion{
+		ID: "CVE-2024-0001",
+		Constraints: []v5.VulnerabilityMatchExclusionConstraint{
+			{Package: v5.PackageExclusionConstraint{Name: "requests", Language: "python"}},
+		},
+		Justification: "false positive",
+	}
+	if err = source.AddVulnerabilityMatchExclusion(expectedExclusion); err != nil {
+		t.Fatalf("failed to add match exclusion: %+v", err)
+	}
+
+	var export bytes.Buffer
+	assert.NoError(t, source.ExportJSONL(&export))
+
+	lines := strings.Split(strings.TrimSpace(export.String()), "\n")
+	var types []string
+	for _, l := range lines {
+		var line jsonlLine
+		assert.NoError(t, json.Unmarshal([]byte(l), &line))
+		types = append(types, line.Type)
+	}
+	assert.Equal(t, []string{
+		jsonlIDType,
+		jsonlVulnerabilityType,
+		jsonlVulnerabilityType,
+		jsonlMetadataType,
+		jsonlMetadataType,
+		jsonlExclusionType,
+	}, types)
+
+	target, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	assert.NoError(t, target.ImportJSONL(&export))
+
+	diffs, err := source.DiffStore(target)
+	assert.NoError(t, err)
+	assert.Empty(t, *diffs)
+
+	actualID, err := target.GetID()
+	assert.NoError(t, err)
+	if assert.NotNil(t, actualID) {
+		assert.Equal(t, expectedID, *actualID)
+	}
+
+	expectedVulns, err := source.GetAllVulnerabilities()
+	assert.NoError(t, err)
+	actualVulns, err := target.GetAllVulnerabilities()
+	assert.NoError(t, err)
+	assert.Equal(t, *expectedVulns, *actualVulns)
+
+	exclusions, err := target.GetVulnerabilityMatchExclusion("CVE-2024-0001")
+	assert.NoError(t, err)
+	assert.Equal(t, []v5.VulnerabilityMatchExclusion{expectedExclusion}, exclusions)
+}
+
+func TestStore_ImportJSONL_UnknownType(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	err = s.ImportJSONL(strings.NewReader(`{"type":"vulnerability","record":{"id":"CVE-2024-0001","namespace":"nvd:cpe"}}
+
+{"type":"bogus","record":{}}
+`))
+	assert.ErrorContains(t, err, `line 3: unknown record type "bogus"`)
+}
+
+func TestStore_WAL_ConcurrentReadWrite(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "vulnerability.db")
+
+	s, err := New(dbPath, true, WithWAL())
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	newVuln := func(i int) v5.Vulnerability {
+		return v5.Vulnerability{
+			ID:          fmt.Sprintf("CVE-2024-%04d", i),
+			Namespace:   "nvd:cpe",
+			PackageName: "pkg",
+		}
+	}
+	if err = s.AddVulnerability(newVuln(0)); err != nil {
+		t.Fatalf("failed to add vulnerability: %+v", err)
+	}
+
+	reading := make(chan struct{})
+	written := make(chan struct{})
+	var readErr, writeErr error
+	var before, after int64
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// reader: holds a read transaction open while the writer writes
+	go func() {
+		defer wg.Done()
+		readErr = s.(*store).db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&model.VulnerabilityModel{}).Count(&before).Error; err != nil {
+				close(reading)
+				return err
+			}
+			close(reading)
+			<-written
+			return tx.Model(&model.VulnerabilityModel{}).Count(&after).Error
+		})
+	}()
+
+	// writer: commits while the read transaction is open
+	go func() {
+		defer wg.Done()
+		defer close(written)
+		<-reading
+		for i := 1; i <= 10; i++ {
+			if writeErr = s.AddVulnerability(newVuln(i)); writeErr != nil {
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	assert.NoError(t, readErr)
+	assert.NoError(t, writeErr)
+
+	// the read transaction keeps a consistent snapshot of the DB
+	assert.Equal(t, int64(1), before)
+	assert.Equal(t, int64(1), after)
+
+	count, err := s.CountVulnerabilities()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), count)
+
+	assert.NoError(t, s.Close())
+
+	// the closed DB is a plain rollback journal DB (file format version bytes are 1 for rollback journal, 2 for WAL)
+	assert.NoFileExists(t, dbPath+"-wal")
+	contents, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatalf("failed to read DB: %+v", err)
+	}
+	assert.Equal(t, []byte{1, 1}, contents[18:20])
+
+	s, err = New(dbPath, false)
+	if err != nil {
+		t.Fatalf("could not open store: %+v", err)
+	}
+	count, err = s.CountVulnerabilities()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), count)
+	assert.NoError(t, s.Close())
+}
+
+func TestStore_EnsureIndexes(t *testing.T) {
+	s, err := New(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	if err = s.AddVulnerability(
+		v5.Vulnerability{ID: "CVE-1", PackageName: "pkg-a", Namespace: "nvd:cpe"},
+		v5.Vulnerability{ID: "CVE-2", PackageName: "pkg-b", Namespace: "nvd:cpe"},
+	); err != nil {
+		t.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+
+	db := s.(*store).db
+	queryPlan := func() string {
+		var plan []struct{ Detail string }
+		db.Raw("EXPLAIN QUERY PLAN SELECT * FROM vulnerability WHERE namespace = ? AND package_name = ?", "nvd:cpe", "pkg-a").Scan(&plan)
+		var details []string
+		for _, p := range plan {
+			details = append(details, p.Detail)
+		}
+		return strings.Join(details, "\n")
+	}
+
+	// new DBs already have the index
+	assert.Contains(t, queryPlan(), model.GetVulnerabilityIndexName)
+	assert.NoError(t, s.EnsureIndexes())
+
+	// simulate a DB built without the index
+	if err = db.Exec(fmt.Sprintf("DROP INDEX %s", model.GetVulnerabilityIndexName)).Error; err != nil {
+		t.Fatalf("failed to drop index: %+v", err)
+	}
+	assert.NotContains(t, queryPlan(), model.GetVulnerabilityIndexName)
+
+	assert.NoError(t, s.EnsureIndexes())
+	assert.Contains(t, queryPlan(), model.GetVulnerabilityIndexName)
+
+	vulns, err := s.SearchForVulnerabilities("nvd:cpe", "pkg-a")
+	assert.NoError(t, err)
+	if assert.Len(t, vulns, 1) {
+		assert.Equal(t, "CVE-1", vulns[0].ID)
+	}
+}
+
+func BenchmarkStore_SearchForVulnerabilities(b *testing.B) {
+	s, err := New(b.TempDir(), true)
+	if err != nil {
+		b.Fatalf("could not create store: %+v", err)
+	}
+
+	var vulns []v5.Vulnerability
+	for i := 0; i < 100000; i++ {
+		vulns = append(vulns, v5.Vulnerability{
+			ID:                fmt.Sprintf("CVE-%06d", i),
+			PackageName:       fmt.Sprintf("pkg-%d", i%5000),
+			Namespace:         fmt.Sprintf("namespace-%d", i%10),
+			VersionConstraint: "< 1.0",
+			VersionFormat:     "semver",
+		})
+	}
+	if err = s.AddVulnerability(vulns...); err != nil {
+		b.Fatalf("failed to add vulnerabilities: %+v", err)
+	}
+
+	db := s.(*store).db
+	for _, withIndex := range []bool{false, true} {
+		if withIndex {
+			if err := s.EnsureIndexes(); err != nil {
+				b.Fatal(err)
+			}
+		} else if err := db.Exec(fmt.Sprintf("DROP INDEX %s", model.GetVulnerabilityIndexName)).Error; err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(fmt.Sprintf("index=%v", withIndex), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := s.SearchForVulnerabilities("namespace-3", "pkg-1233"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestNewInMemory(t *testing.T) {
+	s, err := NewInMemory()
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+
+	expectedID := v5.ID{
+		BuildTimestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		SchemaVersion:  v5.SchemaVersion,
+	}
+	if err = s.SetID(expectedID); err != nil {
+		t.Fatalf("failed to set ID: %+v", err)
+	}
+
+	expected := v5.Vulnerability{
+		ID:                "my-cve",
+		PackageName:       "package-name",
+		Namespace:         "my-namespace",
+		VersionConstraint: "< 1.0",
+		VersionFormat:     "semver",
+		CPEs:              []string{"a-cool-cpe"},
+		Fix: v5.Fix{
+			Versions: []string{"1.0.1"},
+			State:    v5.FixedState,
+		},
+	}
+	if err = s.AddVulnerability(expected); err != nil {
+		t.Fatalf("failed to add vulnerability: %+v", err)
+	}
+
+	assertIDReader(t, s, expectedID)
+
+	actual, err := s.GetVulnerability("my-namespace", "my-cve")
+	if err != nil {
+		t.Fatalf("failed to get vulnerability: %+v", err)
+	}
+	assert.Equal(t, []v5.Vulnerability{expected}, actual)
+
+	// each in-memory store is a separate DB
+	other, err := NewInMemory()
+	if err != nil {
+		t.Fatalf("could not create store: %+v", err)
+	}
+	actual, err = other.GetVulnerability("my-namespace", "my-cve")
+	assert.NoError(t, err)
+	assert.Empty(t, actual)
+
+	assert.NoError(t, other.Close())
+	assert.NoError(t, s.Close())
+
+	// the WAL journal mode requires a DB file
+	_, err = NewInMemory(WithWAL())
+	assert.Error(t, err)
+}
+
+func TestStore_WithTransaction(t *testing.T) {
+	vuln := v5.Vulnerability{ID: "CVE-2024-0001", Namespace: "nvd:cpe", PackageName: "curl", VersionConstraint: "< 8.0"}
+	metadata := v5.VulnerabilityMetadata{ID: "CVE-2024-0001", Namespace: "nvd:cpe", Severity: "High"}
+
+	countRows := func(t *testing.T, s v5.Store) (int64, int) {
+		t.Helper()
+		vulns, err := s.CountVulnerabilities()
+		assert.NoError(t, err)
+		all, err := s.GetAllVulnerabilityMetadata()
+		assert.NoError(t, err)
+		return vulns, len(*all)
+	}
+
+	newStores := map[string]func(t *testing.T) v5.Store{
+		"file": func(t *testing.T) v5.Store {
+			s, err := New(t.TempDir(), true)
+			if err != nil {
+				t.Fatalf("could not create store: %+v", err)
+			}
+			return s
+		},
+		"in memory": func(t *testing.T) v5.Store {
+			s, err := NewInMemory()
+			if err != nil {
+				t.Fatalf("could not create store: %+v", err)
+			}
+			return s
+		},
+	}
+
+	for name, newStore := range newStores {
+		t.Run(name, func(t *testing.T) {
+			t.Run("commits on success", func(t *testing.T) {
+				s := newStore(t)
+				err := s.WithTransaction(func(tx v5.Store) error {
+					if err := tx.AddVulnerability(vuln); err != nil {
+						return err
+					}
+					_, err := tx.AddVulnerabilityMetadata(metadata)
+					return err
+				})
+				assert.NoError(t, err)
+
+				vulns, metadataCount := countRows(t, s)
+				assert.Equal(t, int64(1), vulns)
+				assert.Equal(t, 1, metadataCount)
+			})
+
+			t.Run("rolls back on error", func(t *testing.T) {
+				s := newStore(t)
+				expectedErr := errors.New("deliberate failure")
+				err := s.WithTransaction(func(tx v5.Store) error {
+					if err := tx.AddVulnerability(vuln); err != nil {
+						return err
+					}
+					if _, err := tx.AddVulnerabilityMetadata(metadata); err != nil {
+						return err
+					}
+
+					// writes are visible within the transaction
+					vulns, metadataCount := countRows(t, tx)
+					assert.Equal(t, int64(1), vulns)
+					assert.Equal(t, 1, metadataCount)
+
+					return expectedErr
+				})
+				assert.ErrorIs(t, err, expectedErr)
+
+				vulns, metadataCount := countRows(t, s)
+				assert.Zero(t, vulns)
+				assert.Zero(t, metadataCount)
+			})
+
+			t.Run("rolls back on a failed write", func(t *testing.T) {
+				s := newStore(t)
+				if _, err := s.AddVulnerabilityMetadata(metadata); err != nil {
+					t.Fatalf("failed to add metadata: %+v", err)
+				}
+
+				conflicting := metadata
+				conflicting.Severity = "Low"
+				err := s.WithTransaction(func(tx v5.Store) error {
+					if err := tx.AddVulnerability(vuln); err != nil {
+						return err
+					}
+					_, err := tx.AddVulnerabilityMetadata(conflicting)
+					return err
+				})
+				assert.Error(t, err)
+
+				vulns, metadataCount := countRows(t, s)
+				assert.Zero(t, vulns)
+				assert.Equal(t, 1, metadataCount)
+			})
+		})
+	}
+
+	t.Run("cannot close or vacuum within the transaction", func(t *testing.T) {
+		s, err := New(t.TempDir(), true)
+		if err != nil {
+			t.Fatalf("could not create store: %+v", err)
+		}
+		err = s.WithTransaction(func(tx v5.Store) error {
+			assert.ErrorIs(t, tx.Vacuum(), errTransactionalStore)
+			assert.ErrorIs(t, tx.Close(), errTransactionalStore)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, s.Close())
+	})
+}
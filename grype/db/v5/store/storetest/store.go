@@ -0,0 +1,86 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	v5 "github.com/anchore/grype/grype/db/v5"
+	"github.com/anchore/grype/grype/db/v5/store"
+)
+
+// StoreBuilder collects the records for a test store, which is an in-memory v5 store (no DB file is written) populated
+// with exactly the registered records. Since the store is backed by the same implementation as a real DB every
+// StoreReader operation (searches, diffs, exclusions...) behaves the same as it would against a published DB.
+type StoreBuilder struct {
+	t               testing.TB
+	ID              *v5.ID
+	Vulnerabilities []v5.Vulnerability
+	Metadata        []v5.VulnerabilityMetadata
+	MatchExclusions []v5.VulnerabilityMatchExclusion
+}
+
+// NewStore creates a new builder for a test store with no records.
+func NewStore(t testing.TB) *StoreBuilder {
+	t.Helper()
+	return &StoreBuilder{
+		t: t,
+	}
+}
+
+// WithID sets the DB ID of the store (by default a store has no ID).
+func (b *StoreBuilder) WithID(id v5.ID) *StoreBuilder {
+	b.ID = &id
+	return b
+}
+
+// WithVulnerabilities registers vulnerability records to add to the store.
+func (b *StoreBuilder) WithVulnerabilities(vulnerabilities ...v5.Vulnerability) *StoreBuilder {
+	b.Vulnerabilities = append(b.Vulnerabilities, vulnerabilities...)
+	return b
+}
+
+// WithMetadata registers vulnerability metadata records to add to the store.
+func (b *StoreBuilder) WithMetadata(metadata ...v5.VulnerabilityMetadata) *StoreBuilder {
+	b.Metadata = append(b.Metadata, metadata...)
+	return b
+}
+
+// WithMatchExclusions registers vulnerability match exclusion records to add to the store.
+func (b *StoreBuilder) WithMatchExclusions(exclusions ...v5.VulnerabilityMatchExclusion) *StoreBuilder {
+	b.MatchExclusions = append(b.MatchExclusions, exclusions...)
+	return b
+}
+
+// Build creates the store with all registered records, failing the test if any record cannot be written. The store is
+// closed when the test completes.
+func (b *StoreBuilder) Build() v5.Store {
+	b.t.Helper()
+
+	s, err := store.NewInMemory()
+	require.NoError(b.t, err)
+	b.t.Cleanup(func() {
+		require.NoError(b.t, s.Close())
+	})
+
+	if b.ID != nil {
+		require.NoError(b.t, s.SetID(*b.ID))
+	}
+	if len(b.Vulnerabilities) > 0 {
+		require.NoError(b.t, s.AddVulnerability(b.Vulnerabilities...))
+	}
+	if len(b.Metadata) > 0 {
+		_, err := s.AddVulnerabilityMetadata(b.Metadata...)
+		require.NoError(b.t, err)
+	}
+	if len(b.MatchExclusions) > 0 {
+		require.NoError(b.t, s.AddVulnerabilityMatchExclusion(b.MatchExclusions...))
+	}
+	return s
+}
+
+// Reader creates the store with all registered records (see Build) for callers that only need a v5.StoreReader.
+func (b *StoreBuilder) Reader() v5.StoreReader {
+	b.t.Helper()
+	return b.Build()
+}
@@ -0,0 +1,102 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v5 "github.com/anchore/grype/grype/db/v5"
+)
+
+func TestNewStore(t *testing.T) {
+	vulns := []v5.Vulnerability{
+		{
+			ID:                "CVE-2024-1",
+			PackageName:       "pkg-a",
+			Namespace:         "github:language:python",
+			VersionConstraint: "< 1.0.0",
+			VersionFormat:     "python",
+			Fix: v5.Fix{
+				Versions: []string{"1.0.0"},
+				State:    v5.FixedState,
+			},
+		},
+		{
+			ID:                "CVE-2024-2",
+			PackageName:       "pkg-b",
+			Namespace:         "github:language:python",
+			VersionConstraint: "< 2.0.0",
+			VersionFormat:     "python",
+		},
+	}
+	metadata := v5.VulnerabilityMetadata{
+		ID:         "CVE-2024-1",
+		Namespace:  "github:language:python",
+		DataSource: "https://example.com/CVE-2024-1",
+		Severity:   "High",
+	}
+	exclusion := v5.VulnerabilityMatchExclusion{
+		ID:            "CVE-2024-2",
+		Justification: "false positive",
+	}
+	id := v5.NewID(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	reader := NewStore(t).
+		WithID(id).
+		WithVulnerabilities(vulns...).
+		WithMetadata(metadata).
+		WithMatchExclusions(exclusion).
+		Reader()
+
+	actualID, err := reader.GetID()
+	require.NoError(t, err)
+	require.NotNil(t, actualID)
+	assert.Equal(t, id.SchemaVersion, actualID.SchemaVersion)
+	assert.True(t, id.BuildTimestamp.Equal(actualID.BuildTimestamp))
+
+	all, err := reader.GetAllVulnerabilities()
+	require.NoError(t, err)
+	assert.Len(t, *all, 2)
+
+	found, err := reader.SearchForVulnerabilities("github:language:python", "pkg-a")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "CVE-2024-1", found[0].ID)
+	assert.Equal(t, []string{"1.0.0"}, found[0].Fix.Versions)
+
+	actualMetadata, err := reader.GetVulnerabilityMetadata("CVE-2024-1", "github:language:python")
+	require.NoError(t, err)
+	require.NotNil(t, actualMetadata)
+	assert.Equal(t, "High", actualMetadata.Severity)
+
+	exclusions, err := reader.GetVulnerabilityMatchExclusion("CVE-2024-2")
+	require.NoError(t, err)
+	require.Len(t, exclusions, 1)
+	assert.Equal(t, "false positive", exclusions[0].Justification)
+
+	// each built store is independent
+	other := NewStore(t).WithVulnerabilities(vulns[0]).WithMetadata(metadata).Reader()
+
+	diffs, err := reader.DiffStore(other)
+	require.NoError(t, err)
+	require.Len(t, *diffs, 1)
+	assert.Equal(t, "CVE-2024-2", (*diffs)[0].ID)
+
+	diffs, err = reader.DiffStore(reader)
+	require.NoError(t, err)
+	assert.Empty(t, *diffs)
+}
+
+func TestNewStore_Empty(t *testing.T) {
+	reader := NewStore(t).Reader()
+
+	id, err := reader.GetID()
+	require.NoError(t, err)
+	assert.Nil(t, id)
+
+	all, err := reader.GetAllVulnerabilities()
+	require.NoError(t, err)
+	assert.Empty(t, *all)
+}
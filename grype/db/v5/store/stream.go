@@ -0,0 +1,320 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+
+	"gorm.io/gorm"
+
+	v5 "github.com/anchore/grype/grype/db/v5"
+	"github.com/anchore/grype/grype/db/v5/store/model"
+)
+
+// errCursorClosed stops the iteration backing a record cursor once the cursor is closed.
+var errCursorClosed = errors.New("record cursor closed")
+
+// recordCursor reads records one at a time from a callback based iterator (e.g. EachVulnerability), allowing for
+// several ordered record streams to be walked in step. Only the current record is held in memory.
+type recordCursor[T any] struct {
+	next    func() (T, bool)
+	stop    func()
+	current T
+	ok      bool
+	err     error
+}
+
+func newRecordCursor[T any](each func(fn func(T) error) error) *recordCursor[T] {
+	c := &recordCursor[T]{}
+	c.next, c.stop = iter.Pull(func(yield func(T) bool) {
+		err := each(func(record T) error {
+			if !yield(record) {
+				return errCursorClosed
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errCursorClosed) {
+			c.err = err
+		}
+	})
+	c.advance()
+	return c
+}
+
+// advance moves to the next record (ok is false once all records have been read or the iteration failed).
+func (c *recordCursor[T]) advance() {
+	c.current, c.ok = c.next()
+}
+
+// close stops the iteration, releasing any rows still held open. This may be called more than once.
+func (c *recordCursor[T]) close() {
+	c.stop()
+}
+
+// recordGroup holds all vulnerability and metadata records of a vulnerability ID within a namespace.
+type recordGroup[V, M any] struct {
+	key      storeKey
+	vulns    []V
+	metadata []M
+}
+
+// recordStream reads the vulnerability and metadata records of a store one group of records (sharing the same
+// vulnerability ID and namespace) at a time. Both record iterators must be in namespace then ID order.
+type recordStream[V, M any] struct {
+	vulns    *recordCursor[V]
+	metadata *recordCursor[M]
+	vulnKey  func(V) storeKey
+	metaKey  func(M) storeKey
+}
+
+func newRecordStream[V, M any](eachVuln func(func(V) error) error, eachMeta func(func(M) error) error, vulnKey func(V) storeKey, metaKey func(M) storeKey) *recordStream[V, M] {
+	return &recordStream[V, M]{
+		vulns:    newRecordCursor(eachVuln),
+		metadata: newRecordCursor(eachMeta),
+		vulnKey:  vulnKey,
+		metaKey:  metaKey,
+	}
+}
+
+// nextKey returns the key of the next group of records, or false when all records have been read.
+func (r *recordStream[V, M]) nextKey() (storeKey, bool) {
+	var k storeKey
+	var ok bool
+	if r.vulns.ok {
+		k, ok = r.vulnKey(r.vulns.current), true
+	}
+	if r.metadata.ok {
+		if mk := r.metaKey(r.metadata.current); !ok || lessRecordKey(mk, k) {
+			k, ok = mk, true
+		}
+	}
+	return k, ok
+}
+
+// take reads all records with the given key (there are none when the next group of records has a different key).
+func (r *recordStream[V, M]) take(k storeKey) recordGroup[V, M] {
+	g := recordGroup[V, M]{key: k}
+	for r.vulns.ok && r.vulnKey(r.vulns.current) == k {
+		g.vulns = append(g.vulns, r.vulns.current)
+		r.vulns.advance()
+	}
+	for r.metadata.ok && r.metaKey(r.metadata.current) == k {
+		g.metadata = append(g.metadata, r.metadata.current)
+		r.metadata.advance()
+	}
+	return g
+}
+
+func (r *recordStream[V, M]) err() error {
+	return errors.Join(r.vulns.err, r.metadata.err)
+}
+
+func (r *recordStream[V, M]) close() {
+	r.vulns.close()
+	r.metadata.close()
+}
+
+// walkRecordStreams calls the given function with the base and target records of each key found in either stream (in
+// namespace then ID order), stopping at the first error. Only the records of a single key are held in memory at a time.
+func walkRecordStreams[V, M any](base, target *recordStream[V, M], fn func(base, target recordGroup[V, M]) error) error {
+	for {
+		// note: a failed iteration looks exhausted, so must be checked before records are considered missing
+		if err := errors.Join(base.err(), target.err()); err != nil {
+			return err
+		}
+
+		baseKey, baseOK := base.nextKey()
+		targetKey, targetOK := target.nextKey()
+		if !baseOK && !targetOK {
+			return nil
+		}
+
+		k := baseKey
+		if !baseOK || (targetOK && lessRecordKey(targetKey, baseKey)) {
+			k = targetKey
+		}
+
+		if err := fn(base.take(k), target.take(k)); err != nil {
+			return err
+		}
+	}
+}
+
+// lessRecordKey orders keys by namespace then vulnerability ID (the order records are streamed in).
+func lessRecordKey(a, b storeKey) bool {
+	if a.namespace != b.namespace {
+		return a.namespace < b.namespace
+	}
+	return a.id < b.id
+}
+
+type inflatedRecordStream = recordStream[v5.Vulnerability, v5.VulnerabilityMetadata]
+
+type inflatedRecordGroup = recordGroup[v5.Vulnerability, v5.VulnerabilityMetadata]
+
+// newStoreRecordStream streams the (inflated) records read by the given query within the given namespaces (all
+// namespaces when empty).
+func newStoreRecordStream(db *gorm.DB, namespaces []string) *inflatedRecordStream {
+	db = db.Scopes(inNamespaces(namespaces)).Session(&gorm.Session{})
+	return newRecordStream(
+		func(fn func(v5.Vulnerability) error) error {
+			return eachVulnerabilityModel(db, func(m model.VulnerabilityModel) error {
+				vuln, err := m.Inflate()
+				if err != nil {
+					return err
+				}
+				return fn(vuln)
+			})
+		},
+		func(fn func(v5.VulnerabilityMetadata) error) error {
+			return eachMetadataModel(db, func(m model.VulnerabilityMetadataModel) error {
+				metadata, err := m.Inflate()
+				if err != nil {
+					return err
+				}
+				return fn(metadata)
+			})
+		},
+		getVulnerabilityParentKey,
+		getMetadataKey,
+	)
+}
+
+type modelRecordStream = recordStream[model.VulnerabilityModel, model.VulnerabilityMetadataModel]
+
+type modelRecordGroup = recordGroup[model.VulnerabilityModel, model.VulnerabilityMetadataModel]
+
+// newModelRecordStream streams the records read by the given query as stored (without inflating them).
+func newModelRecordStream(db *gorm.DB) *modelRecordStream {
+	db = db.Session(&gorm.Session{})
+	return newRecordStream(
+		func(fn func(model.VulnerabilityModel) error) error {
+			return eachVulnerabilityModel(db, fn)
+		},
+		func(fn func(model.VulnerabilityMetadataModel) error) error {
+			return eachMetadataModel(db, fn)
+		},
+		func(m model.VulnerabilityModel) storeKey {
+			return storeKey{id: m.ID, namespace: m.Namespace}
+		},
+		func(m model.VulnerabilityMetadataModel) storeKey {
+			return storeKey{id: m.ID, namespace: m.Namespace}
+		},
+	)
+}
+
+// toModelRecordGroup converts the inflated records of a group to the models they would be stored as.
+func toModelRecordGroup(g inflatedRecordGroup) modelRecordGroup {
+	m := modelRecordGroup{key: g.key}
+	for _, v := range g.vulns {
+		m.vulns = append(m.vulns, model.NewVulnerabilityModel(v))
+	}
+	for _, md := range g.metadata {
+		m.metadata = append(m.metadata, model.NewVulnerabilityMetadataModel(md))
+	}
+	return m
+}
+
+// newReaderRecordStream streams the records of a store reader within the given namespaces (all namespaces when empty).
+func newReaderRecordStream(reader v5.StoreReader, namespaces []string) *inflatedRecordStream {
+	included := func(namespace string) bool {
+		return len(namespaces) == 0 || slices.Contains(namespaces, namespace)
+	}
+	return newRecordStream(
+		func(fn func(v5.Vulnerability) error) error {
+			return reader.EachVulnerability(func(v v5.Vulnerability) error {
+				if !included(v.Namespace) {
+					return nil
+				}
+				return fn(v)
+			})
+		},
+		func(fn func(v5.VulnerabilityMetadata) error) error {
+			return reader.EachVulnerabilityMetadata(func(m v5.VulnerabilityMetadata) error {
+				if !included(m.Namespace) {
+					return nil
+				}
+				return fn(m)
+			})
+		},
+		getVulnerabilityParentKey,
+		getMetadataKey,
+	)
+}
+
+// walkRecords walks the records of the current sql database and the given store in step (see walkRecordStreams),
+// within the given namespaces (all namespaces when empty).
+func (s *store) walkRecords(targetStore v5.StoreReader, namespaces []string, fn func(base, target inflatedRecordGroup) error) error {
+	t, ok := asStore(targetStore)
+	if !ok {
+		return s.withConnection(func(db *gorm.DB) error {
+			base := newStoreRecordStream(db, namespaces)
+			defer base.close()
+			target := newReaderRecordStream(targetStore, namespaces)
+			defer target.close()
+			return walkRecordStreams(base, target, fn)
+		})
+	}
+
+	return s.withConnections(t, func(db, tdb *gorm.DB) error {
+		base := newStoreRecordStream(db, namespaces)
+		defer base.close()
+		target := newStoreRecordStream(tdb, namespaces)
+		defer target.close()
+		return walkRecordStreams(base, target, fn)
+	})
+}
+
+// withConnections calls the given function with query handles bound to a single connection of this store and of the
+// given store, allowing for several queries of both stores to be read from in step. The connection is shared when both
+// stores use the same connection pool (e.g. a view of this store), so this does not block on stores limited to a single
+// connection (e.g. in-memory stores).
+func (s *store) withConnections(t *store, fn func(db, tdb *gorm.DB) error) error {
+	return s.withConnection(func(db *gorm.DB) error {
+		if sharesConnectionPool(s, t) {
+			return fn(db, t.boundTo(db))
+		}
+		return t.withConnection(func(tdb *gorm.DB) error {
+			return fn(db, tdb)
+		})
+	})
+}
+
+// sharesConnectionPool indicates if both stores issue queries through the same connection pool.
+func sharesConnectionPool(a, b *store) bool {
+	aDB, err := a.db.DB()
+	if err != nil {
+		return false
+	}
+	bDB, err := b.db.DB()
+	if err != nil {
+		return false
+	}
+	return aDB == bDB
+}
+
+// boundTo returns a query handle of the store (retaining any view settings, see AsOf) which issues queries over the
+// connection of the given query handle.
+func (s *store) boundTo(db *gorm.DB) *gorm.DB {
+	ctx := s.db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// note: setting the context clones the statement, so the connection of this store's handle is left untouched
+	tx := s.db.Session(&gorm.Session{Context: ctx})
+	tx.Statement.ConnPool = db.Statement.ConnPool
+	return tx
+}
+
+// withConnection calls the given function with a query handle bound to a single connection of the store, allowing for
+// several queries to be read from in step.
+func (s *store) withConnection(fn func(db *gorm.DB) error) error {
+	if s.transaction {
+		// a transaction is already bound to a single connection
+		return fn(s.db)
+	}
+	return s.db.Connection(func(tx *gorm.DB) error {
+		return fn(tx.Session(&gorm.Session{}))
+	})
+}
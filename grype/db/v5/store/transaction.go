@@ -0,0 +1,34 @@
+package store
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	v5 "github.com/anchore/grype/grype/db/v5"
+)
+
+// errTransactionalStore is returned when closing or vacuuming the store given to a WithTransaction callback.
+var errTransactionalStore = errors.New("not supported within a transaction")
+
+// WithTransaction calls the given function with a view of the store where every read and write is made within a single
+// transaction, which is committed when the function returns nil and is rolled back otherwise (including on panic). This
+// allows for related records (e.g. vulnerabilities and their metadata) to be written together, so a failure midway does
+// not leave the DB partially populated. The transactional store has the same configuration as this store, however it
+// must not be used after the function returns, cannot be closed or vacuumed, and writes nested within another
+// transaction (including calls to WithTransaction) use savepoints. Note that the transaction holds the DB connection, so
+// this store must not be used within the function.
+func (s *store) WithTransaction(fn func(tx v5.Store) error) error {
+	var txStore *store
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		txStore = s.derive(tx)
+		txStore.transaction = true
+		return fn(txStore)
+	})
+	if err == nil && txStore.dirty {
+		s.dirty = true
+		// the DB ID may have been set within the transaction
+		s.lastModified = txStore.lastModified
+	}
+	return err
+}
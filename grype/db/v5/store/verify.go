@@ -0,0 +1,88 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anchore/grype/grype/db/v5/store/model"
+)
+
+// VerificationCheck is one of the checks made when verifying a DB (see Verify).
+type VerificationCheck string
+
+const (
+	// VerifyIntegrity checks the sqlite file structure is consistent (PRAGMA integrity_check).
+	VerifyIntegrity VerificationCheck = "integrity"
+	// VerifyID checks the DB has exactly one ID record.
+	VerifyID VerificationCheck = "id"
+	// VerifyChecksum checks the records within the DB match the checksum recorded in the DB ID (if any).
+	VerifyChecksum VerificationCheck = "checksum"
+)
+
+// VerificationFailure describes why a verification check failed.
+type VerificationFailure struct {
+	Check  VerificationCheck
+	Reason string
+}
+
+// ErrVerification is returned when one or more checks fail while verifying a DB.
+type ErrVerification struct {
+	Failures []VerificationFailure
+}
+
+func (e *ErrVerification) Error() string {
+	reasons := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		reasons[i] = fmt.Sprintf("%s: %s", f.Check, f.Reason)
+	}
+	return fmt.Sprintf("DB verification failed (%s)", strings.Join(reasons, "; "))
+}
+
+// Failed indicates if the given check failed.
+func (e *ErrVerification) Failed(check VerificationCheck) bool {
+	for _, f := range e.Failures {
+		if f.Check == check {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify checks the DB is usable before it is trusted (e.g. after a download): the sqlite file structure must be
+// consistent, there must be exactly one ID record, and when the ID records a checksum the records within the DB must
+// match it. All checks are made, and any failures are returned together as an *ErrVerification. This catches
+// truncated or otherwise corrupt DBs, which may otherwise silently result in no matches.
+func (s *store) Verify() error {
+	var failures []VerificationFailure
+	fail := func(check VerificationCheck, format string, args ...any) {
+		failures = append(failures, VerificationFailure{Check: check, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	var problems []string
+	if err := s.db.Raw("PRAGMA integrity_check").Scan(&problems).Error; err != nil {
+		fail(VerifyIntegrity, "unable to check integrity: %v", err)
+	} else if len(problems) != 1 || problems[0] != "ok" {
+		fail(VerifyIntegrity, "%s", strings.Join(problems, ", "))
+	}
+
+	var ids []model.IDModel
+	if err := s.db.Find(&ids).Error; err != nil {
+		fail(VerifyID, "unable to read DB ID: %v", err)
+	} else if len(ids) != 1 {
+		fail(VerifyID, "expected exactly 1 ID record, found %d", len(ids))
+	}
+
+	if len(ids) == 1 && ids[0].Checksum.Valid {
+		digests, err := s.recordDigests()
+		if err != nil {
+			fail(VerifyChecksum, "unable to digest records: %v", err)
+		} else if actual := fingerprintDigests(digests); actual != ids[0].Checksum.String {
+			fail(VerifyChecksum, "expected %s, got %s", ids[0].Checksum.String, actual)
+		}
+	}
+
+	if len(failures) > 0 {
+		return &ErrVerification{Failures: failures}
+	}
+	return nil
+}
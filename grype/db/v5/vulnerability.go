@@ -25,6 +25,7 @@ type Vulnerability struct {
 	RelatedVulnerabilities []VulnerabilityReference `json:"related_vulnerabilities"` // Other Vulnerabilities that are related to this one (e.g. GHSA relate to CVEs, or how distro CVE relates to NVD record)
 	Fix                    Fix                      `json:"fix"`                     // All information about fixed versions
 	Advisories             []Advisory               `json:"advisories"`              // Any vendor advisories about fixes or other notifications about this vulnerability
+	Provenance             *Provenance              `json:"provenance,omitempty"`    // Where this record originated from (optional, not considered for equality)
 }
 
 type VulnerabilityReference struct {
@@ -32,6 +33,18 @@ type VulnerabilityReference struct {
 	Namespace string `json:"namespace"`
 }
 
+// SearchOptions controls how vulnerabilities are searched for by package.
+type SearchOptions struct {
+	// CaseInsensitivePackageName matches package names irrespective of (ASCII) casing (e.g. for ecosystems like PyPI
+	// where "Flask" and "flask" are the same package). By default package names must match exactly.
+	CaseInsensitivePackageName bool
+	// Limit is the max number of results to return. When Limit or Offset is set, results are ordered by vulnerability
+	// ID, package name, then insertion order so that pages are deterministic. By default all results are returned.
+	Limit int
+	// Offset is the number of (ordered) results to skip before returning results.
+	Offset int
+}
+
 //nolint:gocognit
 func (v *Vulnerability) Equal(vv Vulnerability) bool {
 	equal := v.ID == vv.ID &&
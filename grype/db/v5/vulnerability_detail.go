@@ -0,0 +1,14 @@
+package v5
+
+// VulnerabilityDetail is the complete set of records related to a single vulnerability ID across all namespaces.
+type VulnerabilityDetail struct {
+	ID              string                        `json:"id"`               // The identifier of the vulnerability or advisory
+	Vulnerabilities []Vulnerability               `json:"vulnerabilities"`  // All matching records across namespaces and packages (each with fix information)
+	Metadata        []VulnerabilityMetadata       `json:"metadata"`         // The metadata record for each namespace the vulnerability appears in
+	MatchExclusions []VulnerabilityMatchExclusion `json:"match_exclusions"` // Any match exclusions that apply to the vulnerability
+	Aliases         []VulnerabilityReference      `json:"aliases"`          // The distinct related vulnerabilities with a different ID across all records
+}
+
+type VulnerabilityDetailReader interface {
+	GetVulnerabilityDetail(id string) (VulnerabilityDetail, error)
+}
@@ -12,6 +12,14 @@ type VulnerabilityMatchExclusion struct {
 	Justification string                                  `json:"justification"`         // Justification for the exclusion
 }
 
+// VulnerabilityMatchExclusionFilter selects the match exclusions which may apply to a vulnerability ID, namespace, and
+// package name. Empty fields are not filtered on.
+type VulnerabilityMatchExclusionFilter struct {
+	ID          string
+	Namespace   string
+	PackageName string
+}
+
 // VulnerabilityMatchExclusionConstraint describes criteria for which matches should be excluded
 type VulnerabilityMatchExclusionConstraint struct {
 	Vulnerability VulnerabilityExclusionConstraint `json:"vulnerability,omitempty"` // Vulnerability exclusion criteria
@@ -7,8 +7,16 @@ type VulnerabilityMatchExclusionStore interface {
 
 type VulnerabilityMatchExclusionStoreReader interface {
 	GetVulnerabilityMatchExclusion(id string) ([]VulnerabilityMatchExclusion, error)
+	// GetApplicableExclusions retrieves the exclusions for a vulnerability ID that apply to a specific package version
+	GetApplicableExclusions(id, namespace, packageName, packageVersion string) ([]VulnerabilityMatchExclusion, error)
+	// IsMatchExcluded indicates if a match of a vulnerability ID against a specific package version is excluded, returning the applicable exclusion
+	IsMatchExcluded(id, namespace, packageName, packageVersion string) (bool, *VulnerabilityMatchExclusion, error)
+	// GetVulnerabilityMatchExclusionsByConstraint retrieves the exclusions that may apply to the given vulnerability ID, namespace, and package
+	GetVulnerabilityMatchExclusionsByConstraint(filter VulnerabilityMatchExclusionFilter) ([]VulnerabilityMatchExclusion, error)
 }
 
 type VulnerabilityMatchExclusionStoreWriter interface {
 	AddVulnerabilityMatchExclusion(exclusion ...VulnerabilityMatchExclusion) error
+	// ReplaceVulnerabilityMatchExclusions atomically replaces all exclusions for a vulnerability ID with the given exclusions
+	ReplaceVulnerabilityMatchExclusions(id string, exclusions []VulnerabilityMatchExclusion) error
 }
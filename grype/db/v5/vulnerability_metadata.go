@@ -2,6 +2,7 @@ package v5
 
 import (
 	"reflect"
+	"strings"
 
 	"github.com/anchore/grype/grype/vulnerability"
 )
@@ -16,6 +17,34 @@ type VulnerabilityMetadata struct {
 	URLs         []string `json:"urls"`          // URLs to get more information about the vulnerability or advisory
 	Description  string   `json:"description"`   // Description of the vulnerability
 	Cvss         []Cvss   `json:"cvss"`          // Common Vulnerability Scoring System values
+
+	// StoredSeverity and ComputedSeverity are only populated when the severity is recomputed on read (see
+	// MetadataReadOptions): the severity as stored, and the severity derived from the highest CVSS base score.
+	StoredSeverity   string `json:"stored_severity,omitempty"`
+	ComputedSeverity string `json:"computed_severity,omitempty"`
+}
+
+// MetadataReadOptions controls how vulnerability metadata is interpreted when read from the store.
+type MetadataReadOptions struct {
+	// RecomputeSeverity derives a severity from the highest CVSS base score, populating StoredSeverity and
+	// ComputedSeverity on the returned metadata.
+	RecomputeSeverity bool
+	// PreferComputedSeverity additionally replaces Severity with the computed severity (when one could be computed).
+	PreferComputedSeverity bool
+}
+
+// MetadataKey identifies a single vulnerability metadata record.
+type MetadataKey struct {
+	ID        string
+	Namespace string
+}
+
+// MetadataImportSummary describes the outcome of adding vulnerability metadata records to the store.
+type MetadataImportSummary struct {
+	// Created is the number of records which did not exist in the store before
+	Created int
+	// Merged is the number of records which were merged into an existing record (with the same ID and namespace)
+	Merged int
 }
 
 // Cvss contains select Common Vulnerability Scoring System fields for a vulnerability.
@@ -29,6 +58,10 @@ type Cvss struct {
 	Version        string      `json:"version"` // The version of the CVSS spec, for example 2.0, 3.0, or 3.1
 	Source         string      `json:"source"`  // Identifies the organization that provided the score
 	Type           string      `json:"type"`    // Whether the source is a `primary` or `secondary` source
+	// TemporalVector and EnvironmentalVector are optional metric vectors (e.g. "E:P/RL:O/RC:C") which adjust the
+	// base score, and are considered part of the identity of the CVSS entry.
+	TemporalVector      string `json:"temporal_vector,omitempty"`
+	EnvironmentalVector string `json:"environmental_vector,omitempty"`
 }
 
 // CvssMetrics are the quantitative values that make up a CVSS score.
@@ -44,6 +77,31 @@ type CvssMetrics struct {
 	// It is an optional parameter, so that is why it is a pointer instead of
 	// a regular field
 	ImpactScore *float64 `json:"impact_score"`
+	// TemporalScore and EnvironmentalScore are computed from the temporal and environmental vectors when the
+	// record is read from the store (they are not persisted).
+	TemporalScore      *float64 `json:"temporal_score,omitempty"`
+	EnvironmentalScore *float64 `json:"environmental_score,omitempty"`
+}
+
+// AdjustedScore returns the most specific score available: the environmental score, then the temporal score, and
+// finally the base score.
+func (m CvssMetrics) AdjustedScore() float64 {
+	switch {
+	case m.EnvironmentalScore != nil:
+		return *m.EnvironmentalScore
+	case m.TemporalScore != nil:
+		return *m.TemporalScore
+	default:
+		return m.BaseScore
+	}
+}
+
+// WithoutAdjustedScores returns a copy of the CVSS entry without any computed temporal or environmental scores,
+// leaving only the fields which are persisted.
+func (c Cvss) WithoutAdjustedScores() Cvss {
+	c.Metrics.TemporalScore = nil
+	c.Metrics.EnvironmentalScore = nil
+	return c
 }
 
 func NewCvssMetrics(baseScore, exploitabilityScore, impactScore float64) CvssMetrics {
@@ -54,6 +112,44 @@ func NewCvssMetrics(baseScore, exploitabilityScore, impactScore float64) CvssMet
 	}
 }
 
+// HasSeverityInconsistency indicates if the severity computed from CVSS disagrees with the stored severity. This is
+// only meaningful when the severity was recomputed on read.
+func (v *VulnerabilityMetadata) HasSeverityInconsistency() bool {
+	return v.ComputedSeverity != "" && !strings.EqualFold(v.StoredSeverity, v.ComputedSeverity)
+}
+
+// SeverityFromCVSS derives a severity label from the highest CVSS base score using the standard qualitative severity
+// bands for the CVSS version of that score. False is returned if there are no usable scores.
+func SeverityFromCVSS(cvss []Cvss) (string, bool) {
+	var best *Cvss
+	for idx := range cvss {
+		c := &cvss[idx]
+		if c.Metrics.BaseScore <= 0 || c.Metrics.BaseScore > 10 {
+			continue
+		}
+		if best == nil || c.Metrics.BaseScore > best.Metrics.BaseScore {
+			best = c
+		}
+	}
+
+	if best == nil {
+		return "", false
+	}
+
+	score := best.Metrics.BaseScore
+	switch {
+	case score < 4.0:
+		return "Low", true
+	case score < 7.0:
+		return "Medium", true
+	case score < 9.0 || strings.HasPrefix(best.Version, "2"):
+		// CVSS v2 has no critical band
+		return "High", true
+	default:
+		return "Critical", true
+	}
+}
+
 func (v *VulnerabilityMetadata) Equal(vv VulnerabilityMetadata) bool {
 	equal := v.ID == vv.ID &&
 		v.Namespace == vv.Namespace &&
@@ -7,9 +7,36 @@ type VulnerabilityMetadataStore interface {
 
 type VulnerabilityMetadataStoreReader interface {
 	GetVulnerabilityMetadata(id, namespace string) (*VulnerabilityMetadata, error)
+	// GetVulnerabilityMetadataWithOptions is the same as GetVulnerabilityMetadata, but allows for recomputing the
+	// severity from the stored CVSS scores
+	GetVulnerabilityMetadataWithOptions(id, namespace string, opts MetadataReadOptions) (*VulnerabilityMetadata, error)
+	// GetVulnerabilityMetadataBatch retrieves metadata for many vulnerability ID and namespace pairs at once (keyed by pair)
+	GetVulnerabilityMetadataBatch(keys []MetadataKey) (map[MetadataKey]VulnerabilityMetadata, error)
+	// GetAllVulnerabilityMetadataByID retrieves metadata for a vulnerability ID from every namespace (record source)
+	GetAllVulnerabilityMetadataByID(id string) ([]VulnerabilityMetadata, error)
 	GetAllVulnerabilityMetadata() (*[]VulnerabilityMetadata, error)
+	// CountVulnerabilityMetadata returns the number of metadata records without reading them
+	CountVulnerabilityMetadata() (int64, error)
+	// ForEachMetadata streams every metadata record through the given function, stopping at the first error
+	ForEachMetadata(fn func(VulnerabilityMetadata) error) error
+	// EachVulnerabilityMetadata streams every metadata record one row at a time (in namespace then ID order) through the given function, stopping at the first error
+	EachVulnerabilityMetadata(fn func(VulnerabilityMetadata) error) error
+	// GetRecentlyModifiedMetadata retrieves the most recently added or merged metadata records (most recent first)
+	GetRecentlyModifiedMetadata(limit int) ([]VulnerabilityMetadata, error)
+	// FindSeverityConflicts finds all vulnerability IDs where namespaces disagree on the severity
+	FindSeverityConflicts() ([]SeverityConflict, error)
+	// HasHighSeverityVulnerabilities indicates if there are any Critical or High severity records in the namespace
+	HasHighSeverityVulnerabilities(namespace string) (bool, error)
+	// GetHighSeverityMetadata retrieves all Critical and High severity records in the namespace
+	GetHighSeverityMetadata(namespace string) ([]VulnerabilityMetadata, error)
 }
 
 type VulnerabilityMetadataStoreWriter interface {
-	AddVulnerabilityMetadata(metadata ...VulnerabilityMetadata) error
+	// AddVulnerabilityMetadata stores metadata records, merging into any existing record for the same ID and namespace
+	AddVulnerabilityMetadata(metadata ...VulnerabilityMetadata) (MetadataImportSummary, error)
+	// AddVulnerabilityMetadataBulk is the same as AddVulnerabilityMetadata, but fetches all existing records up front and
+	// writes in batches (intended for large imports)
+	AddVulnerabilityMetadataBulk(metadata ...VulnerabilityMetadata) (MetadataImportSummary, error)
+	// DeleteVulnerabilityMetadata removes the metadata of a vulnerability within a namespace, returning the number of records removed
+	DeleteVulnerabilityMetadata(id, namespace string) (int64, error)
 }
@@ -10,14 +10,50 @@ type VulnerabilityStore interface {
 type VulnerabilityStoreReader interface {
 	// GetVulnerabilityNamespaces retrieves unique list of vulnerability namespaces
 	GetVulnerabilityNamespaces() ([]string, error)
+	// GetPackageNamespaces retrieves the sorted, unique list of namespaces of the vulnerability (package) records, which may differ from the metadata namespaces
+	GetPackageNamespaces() ([]string, error)
+	// HasNamespace indicates if the given vulnerability namespace exists
+	HasNamespace(namespace string) (bool, error)
+	// GetPackageNames retrieves the sorted, unique list of package names within a vulnerability namespace
+	GetPackageNames(namespace string) ([]string, error)
+	// GetVulnerabilitiesByNamespace retrieves all vulnerabilities within a namespace
+	GetVulnerabilitiesByNamespace(namespace string) ([]Vulnerability, error)
 	// GetVulnerability retrieves vulnerabilities by namespace and id
 	GetVulnerability(namespace, id string) ([]Vulnerability, error)
+	// SearchVulnerabilitiesByIDPattern retrieves vulnerabilities across all namespaces with an ID matching a glob pattern (e.g. "GHSA-*")
+	SearchVulnerabilitiesByIDPattern(pattern string) ([]Vulnerability, error)
 	// SearchForVulnerabilities retrieves vulnerabilities by namespace and package
 	SearchForVulnerabilities(namespace, packageName string) ([]Vulnerability, error)
+	// SearchForVulnerabilitiesWithOptions is the same as SearchForVulnerabilities, but allows for case-insensitive package name matching and paging
+	SearchForVulnerabilitiesWithOptions(namespace, packageName string, opts SearchOptions) ([]Vulnerability, error)
+	// GetVulnerabilitiesForPackages retrieves all vulnerabilities in a namespace affecting any of the given packages
+	GetVulnerabilitiesForPackages(namespace string, packageNames []string) ([]Vulnerability, error)
 	GetAllVulnerabilities() (*[]Vulnerability, error)
+	// CountVulnerabilities returns the number of vulnerability records without reading them
+	CountVulnerabilities() (int64, error)
+	// ForEachVulnerability streams every vulnerability through the given function, stopping at the first error
+	ForEachVulnerability(fn func(Vulnerability) error) error
+	// EachVulnerability streams every vulnerability one row at a time (in namespace then ID order) through the given function, stopping at the first error
+	EachVulnerability(fn func(Vulnerability) error) error
+	// GetProvenance retrieves where the records for a vulnerability in a namespace originated from (nil if unknown)
+	GetProvenance(id, namespace string) (*Provenance, error)
+	// GetVulnerabilitiesByConstraintOperator retrieves all vulnerabilities in a namespace with a version constraint using the given operator
+	GetVulnerabilitiesByConstraintOperator(namespace, operator string) ([]Vulnerability, error)
+	// GetVulnerabilitiesAboveCVSS retrieves all vulnerabilities in a namespace with a best CVSS base score at or above the given score
+	GetVulnerabilitiesAboveCVSS(namespace string, minScore float64, includeUnscored bool) ([]Vulnerability, error)
+	// GetAffectingVulnerabilities retrieves the vulnerabilities in a namespace whose constraints apply to the given package version
+	GetAffectingVulnerabilities(namespace, packageName, version string) ([]AffectingVulnerability, error)
+	// GetRemediationOptions retrieves the candidate fix versions for a package along with the vulnerabilities each resolves
+	GetRemediationOptions(namespace, packageName, currentVersion string) ([]RemediationOption, error)
+	// FindOverlappingConstraints reports pairs of vulnerabilities for a package whose version constraints overlap
+	FindOverlappingConstraints(namespace, packageName string) ([]ConstraintOverlap, error)
 }
 
 type VulnerabilityStoreWriter interface {
 	// AddVulnerability inserts a new record of a vulnerability into the store
 	AddVulnerability(vulnerabilities ...Vulnerability) error
+	// UpsertVulnerability inserts a record of a vulnerability into the store, replacing any existing record with the same identity
+	UpsertVulnerability(vulnerabilities ...Vulnerability) error
+	// DeleteVulnerability removes all records of a vulnerability within a namespace, returning the number of records removed
+	DeleteVulnerability(id, namespace string) (int64, error)
 }
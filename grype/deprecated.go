@@ -3,7 +3,6 @@ package grype
 import (
 	"github.com/anchore/grype/grype/distro"
 	"github.com/anchore/grype/grype/match"
-	"github.com/anchore/grype/grype/matcher"
 	"github.com/anchore/grype/grype/pkg"
 	"github.com/anchore/grype/grype/vulnerability"
 	"github.com/anchore/grype/internal/log"
@@ -14,6 +13,16 @@ import (
 
 // TODO: deprecated, will remove before v1.0.0
 func FindVulnerabilities(store vulnerability.Provider, userImageStr string, scopeOpt source.Scope, registryOptions *image.RegistryOptions) (match.Matches, pkg.Context, []pkg.Package, error) {
+	packages, context, _, err := pkg.Provide(userImageStr, defaultProviderConfig(scopeOpt, registryOptions))
+	if err != nil {
+		return match.Matches{}, pkg.Context{}, nil, err
+	}
+
+	matches, err := FindVulnerabilitiesForPackages(store, packages, &context)
+	return matches, context, packages, err
+}
+
+func defaultProviderConfig(scopeOpt source.Scope, registryOptions *image.RegistryOptions) pkg.ProviderConfig {
 	providerConfig := pkg.ProviderConfig{
 		SyftProviderConfig: pkg.SyftProviderConfig{
 			RegistryOptions: registryOptions,
@@ -21,15 +30,7 @@ func FindVulnerabilities(store vulnerability.Provider, userImageStr string, scop
 		},
 	}
 	providerConfig.SBOMOptions.Search.Scope = scopeOpt
-
-	packages, context, _, err := pkg.Provide(userImageStr, providerConfig)
-	if err != nil {
-		return match.Matches{}, pkg.Context{}, nil, err
-	}
-
-	matchers := matcher.NewDefaultMatchers(matcher.Config{})
-
-	return FindVulnerabilitiesForPackage(store, context.Distro, matchers, packages), context, packages, nil
+	return providerConfig
 }
 
 // TODO: deprecated, will remove before v1.0.0
@@ -0,0 +1,417 @@
+package grype
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/search"
+	"github.com/anchore/grype/grype/vulnerability"
+	"github.com/anchore/grype/internal/log"
+	"github.com/anchore/stereoscope/pkg/image"
+	"github.com/anchore/syft/syft/source"
+)
+
+// RejectionReason describes why a candidate vulnerability for a package was not reported as a match.
+type RejectionReason string
+
+const (
+	// RejectedByVersionConstraint indicates the package version does not satisfy the vulnerability version constraint.
+	RejectedByVersionConstraint RejectionReason = "version-constraint-not-satisfied"
+	// RejectedByNamespace indicates the vulnerability is from a namespace that does not apply to the package (e.g. a
+	// different distro or language ecosystem).
+	RejectedByNamespace RejectionReason = "namespace-mismatch"
+	// RejectedByCriteria indicates the vulnerability did not meet other search criteria (e.g. package qualifiers).
+	RejectedByCriteria RejectionReason = "criteria-not-satisfied"
+	// RejectedByProvider indicates the vulnerability provider excluded the vulnerability for a reason that could not be
+	// attributed to a single search criterion.
+	RejectedByProvider RejectionReason = "excluded-by-provider"
+	// RejectedByMatcher indicates the vulnerability was found by a search, but the matcher did not report it.
+	RejectedByMatcher RejectionReason = "not-reported-by-matcher"
+	// RejectedByExclusion indicates the match was dropped by a DB match exclusion or a hard-coded correction.
+	RejectedByExclusion RejectionReason = "exclusion-rule"
+	// RejectedByMatchFilter indicates the match was dropped by the MatchFilter (e.g. below the CVSS threshold).
+	RejectedByMatchFilter RejectionReason = "match-filter"
+	// RejectedByIgnoreFilter indicates the match was ignored by a filter from a matcher (e.g. a language package owned
+	// by an OS package).
+	RejectedByIgnoreFilter RejectionReason = "ignore-filter"
+	// RejectedByIgnoreRule indicates the match was ignored by a user-provided ignore rule.
+	RejectedByIgnoreRule RejectionReason = "ignore-rule"
+	// RejectedByVEX indicates the match was ignored due to a VEX statement.
+	RejectedByVEX RejectionReason = "vex"
+	// RejectedAfterMatching indicates the match was removed after all ignore rules were applied (e.g. by the
+	// VulnerabilityMatcher.PostProcessor).
+	RejectedAfterMatching RejectionReason = "removed-after-matching"
+)
+
+// Explanation records the candidate vulnerabilities considered for a single package while matching, similar to an
+// EXPLAIN for a SQL query.
+type Explanation struct {
+	Package    pkg.Package
+	Candidates []Candidate
+}
+
+// Candidate is a vulnerability that was considered for a package.
+type Candidate struct {
+	Vulnerability vulnerability.Reference
+	// Reported indicates that the candidate was reported as a match
+	Reported bool
+	// Rejection is the reason the candidate was not reported (empty when reported)
+	Rejection RejectionReason
+	// Detail describes the specific cause of the rejection (e.g. the unsatisfied version constraint), or for a
+	// reported candidate the version constraint that was satisfied
+	Detail string
+}
+
+// Rejected returns the candidates that were not reported as a match.
+func (e Explanation) Rejected() []Candidate {
+	var out []Candidate
+	for _, c := range e.Candidates {
+		if !c.Reported {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// FindVulnerabilitiesWithExplanations is the same as FindVulnerabilities, but additionally returns an explanation of
+// the candidate vulnerabilities considered for each package and why each candidate was not reported. This issues
+// additional searches against the vulnerability provider, so is intended for debugging missing matches.
+func FindVulnerabilitiesWithExplanations(store vulnerability.Provider, userImageStr string, scopeOpt source.Scope, registryOptions *image.RegistryOptions) (match.Matches, pkg.Context, []pkg.Package, []Explanation, error) {
+	packages, context, _, err := pkg.Provide(userImageStr, defaultProviderConfig(scopeOpt, registryOptions))
+	if err != nil {
+		return match.Matches{}, pkg.Context{}, nil, nil, err
+	}
+
+	var explanations []Explanation
+	runner := newDefaultVulnerabilityMatcher(store)
+	runner.Explain = func(e Explanation) {
+		explanations = append(explanations, e)
+	}
+
+	matches, _, err := runner.FindMatches(packages, context)
+	if err != nil {
+		return match.Matches{}, context, packages, nil, fmt.Errorf("unable to find vulnerabilities: %w", err)
+	}
+	return *matches, context, packages, explanations, nil
+}
+
+type candidateKey struct {
+	id        string
+	namespace string
+}
+
+func keyOf(ref vulnerability.Reference) candidateKey {
+	return candidateKey{id: ref.ID, namespace: ref.Namespace}
+}
+
+// explanationRecorder collects the candidates considered for each package during a single FindMatches call. A nil
+// recorder records nothing, so it can be used unconditionally.
+type explanationRecorder struct {
+	packages  []pkg.Package
+	byPackage map[pkg.ID]*packageCandidates
+}
+
+// packageCandidates are the candidates for a single package, in the order they were first considered.
+type packageCandidates struct {
+	order      []candidateKey
+	candidates map[candidateKey]*Candidate
+	// found are the candidates returned by the vulnerability provider to the matchers
+	found map[candidateKey]struct{}
+}
+
+func newExplanationRecorder(explain func(Explanation)) *explanationRecorder {
+	if explain == nil {
+		return nil
+	}
+	return &explanationRecorder{
+		byPackage: make(map[pkg.ID]*packageCandidates),
+	}
+}
+
+func (r *explanationRecorder) forPackage(p pkg.Package) *packageCandidates {
+	pc, ok := r.byPackage[p.ID]
+	if !ok {
+		pc = &packageCandidates{
+			candidates: make(map[candidateKey]*Candidate),
+			found:      make(map[candidateKey]struct{}),
+		}
+		r.byPackage[p.ID] = pc
+		r.packages = append(r.packages, p)
+	}
+	return pc
+}
+
+// provider returns a provider which records the candidates considered by the searches for the given package.
+func (r *explanationRecorder) provider(p pkg.Package, provider vulnerability.Provider) vulnerability.Provider {
+	if r == nil {
+		return provider
+	}
+	return &explainingProvider{Provider: provider, candidates: r.forPackage(p)}
+}
+
+// matched records the matches found by the matchers for the package, any other candidate returned by the
+// vulnerability provider was not reported by the matchers.
+func (r *explanationRecorder) matched(p pkg.Package, matches []match.Match) {
+	if r == nil {
+		return
+	}
+	pc := r.forPackage(p)
+	matched := make(map[candidateKey]struct{})
+	for _, m := range matches {
+		matched[keyOf(m.Vulnerability.Reference)] = struct{}{}
+	}
+	for _, key := range pc.order {
+		if _, ok := pc.found[key]; !ok {
+			continue
+		}
+		if _, ok := matched[key]; !ok {
+			pc.reject(key, RejectedByMatcher, "the vulnerability was found by a search, but not reported by the matcher")
+		}
+	}
+}
+
+// rejected records the rejection of the given matches for the package.
+func (r *explanationRecorder) rejected(p pkg.Package, matches []match.Match, rejection RejectionReason, detail string) {
+	if r == nil {
+		return
+	}
+	pc := r.forPackage(p)
+	for _, m := range matches {
+		pc.consider(m.Vulnerability.Reference, rejection, detail)
+	}
+}
+
+// ignored records the rejection of ignored matches (e.g. by exclusions, ignore filters, ignore rules, or VEX
+// statements).
+func (r *explanationRecorder) ignored(ignored []match.IgnoredMatch, rejection func(match.IgnoredMatch) RejectionReason) {
+	if r == nil {
+		return
+	}
+	for _, i := range ignored {
+		pc, ok := r.byPackage[i.Package.ID]
+		if !ok {
+			continue
+		}
+		reason, detail := rejection(i), describeIgnoreRules(i.AppliedIgnoreRules)
+
+		// the ignored record may have been normalized from the candidate that was considered
+		var considered bool
+		for _, ref := range append([]vulnerability.Reference{i.Vulnerability.Reference}, i.Vulnerability.RelatedVulnerabilities...) {
+			if _, ok := pc.candidates[keyOf(ref)]; ok {
+				pc.reject(keyOf(ref), reason, detail)
+				considered = true
+			}
+		}
+		if !considered {
+			pc.consider(i.Vulnerability.Reference, reason, detail)
+		}
+	}
+}
+
+// emit finalizes the candidates relative to the reported matches, calling the given function for each package in the
+// order the packages were searched.
+func (r *explanationRecorder) emit(explain func(Explanation), reported *match.Matches) {
+	if r == nil || explain == nil {
+		return
+	}
+
+	reportedByPackage := make(map[pkg.ID]map[candidateKey]struct{})
+	if reported != nil {
+		for m := range reported.Enumerate() {
+			refs, ok := reportedByPackage[m.Package.ID]
+			if !ok {
+				refs = make(map[candidateKey]struct{})
+				reportedByPackage[m.Package.ID] = refs
+			}
+			refs[keyOf(m.Vulnerability.Reference)] = struct{}{}
+			// the reported record may have been normalized from (or collapsed with) the related records
+			for _, related := range m.Vulnerability.RelatedVulnerabilities {
+				refs[keyOf(related)] = struct{}{}
+			}
+		}
+	}
+
+	for _, p := range r.packages {
+		pc := r.byPackage[p.ID]
+		e := Explanation{Package: p}
+		for _, key := range pc.order {
+			c := *pc.candidates[key]
+			if c.Rejection == "" {
+				if _, ok := reportedByPackage[p.ID][key]; ok {
+					c.Reported = true
+				} else {
+					c.Rejection = RejectedAfterMatching
+					c.Detail = "the match was removed after matching (e.g. by the post-processor)"
+				}
+			}
+			e.Candidates = append(e.Candidates, c)
+		}
+		explain(e)
+	}
+}
+
+// consider records a candidate, retaining the first rejection recorded for the candidate.
+func (pc *packageCandidates) consider(ref vulnerability.Reference, rejection RejectionReason, detail string) {
+	key := keyOf(ref)
+	if _, ok := pc.candidates[key]; ok {
+		pc.reject(key, rejection, detail)
+		return
+	}
+	pc.order = append(pc.order, key)
+	pc.candidates[key] = &Candidate{
+		Vulnerability: vulnerability.Reference{ID: ref.ID, Namespace: ref.Namespace},
+		Rejection:     rejection,
+		Detail:        detail,
+	}
+}
+
+// returned records a candidate returned by the vulnerability provider, which takes precedence over the rejection of
+// the candidate by the criteria of any other search for the package.
+func (pc *packageCandidates) returned(v vulnerability.Vulnerability) {
+	key := keyOf(v.Reference)
+	pc.consider(v.Reference, "", "")
+	pc.found[key] = struct{}{}
+	c := pc.candidates[key]
+	if isSearchRejection(c.Rejection) {
+		c.Rejection = ""
+		c.Detail = ""
+	}
+	if c.Rejection == "" && c.Detail == "" {
+		c.Detail = fmt.Sprintf("version constraint %q is satisfied", constraintString(v))
+	}
+}
+
+func isSearchRejection(r RejectionReason) bool {
+	switch r {
+	case RejectedByVersionConstraint, RejectedByNamespace, RejectedByCriteria, RejectedByProvider:
+		return true
+	}
+	return false
+}
+
+func (pc *packageCandidates) reject(key candidateKey, rejection RejectionReason, detail string) {
+	c := pc.candidates[key]
+	if c.Rejection == "" && rejection != "" {
+		c.Rejection = rejection
+		c.Detail = detail
+	}
+}
+
+func describeIgnoreRules(rules []match.IgnoreRule) string {
+	var parts []string
+	for _, rule := range rules {
+		var desc string
+		switch {
+		case rule.VexStatus != "":
+			desc = fmt.Sprintf("vex-status=%s", rule.VexStatus)
+			if rule.VexJustification != "" {
+				desc += fmt.Sprintf(" (%s)", rule.VexJustification)
+			}
+		case rule.Reason != "":
+			desc = rule.Reason
+		case rule.Vulnerability != "":
+			desc = fmt.Sprintf("vulnerability=%s", rule.Vulnerability)
+		default:
+			desc = fmt.Sprintf("%+v", rule)
+		}
+		parts = append(parts, desc)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func ignoreRejection(i match.IgnoredMatch) RejectionReason {
+	for _, rule := range i.AppliedIgnoreRules {
+		if rule.VexStatus != "" {
+			return RejectedByVEX
+		}
+	}
+	return RejectedByIgnoreRule
+}
+
+// explainingProvider records the candidates considered by each search: the results of each search are the same as the
+// underlying provider, however each search is repeated with only the criteria identifying the vulnerabilities to
+// consider (by package name, CPE, or ID) to find the candidates that the remaining criteria rejected.
+type explainingProvider struct {
+	vulnerability.Provider
+	candidates *packageCandidates
+}
+
+func (p *explainingProvider) FindVulnerabilities(criteria ...vulnerability.Criteria) ([]vulnerability.Vulnerability, error) {
+	results, err := p.Provider.FindVulnerabilities(criteria...)
+	if err != nil {
+		return results, err
+	}
+
+	for _, v := range results {
+		p.candidates.returned(v)
+	}
+
+	for _, row := range search.CriteriaIterator(criteria) {
+		identifying, remaining := splitIdentifyingCriteria(row)
+		if len(identifying) == 0 {
+			// there is no way to enumerate the candidates without searching the entire provider
+			continue
+		}
+
+		candidates, err := p.Provider.FindVulnerabilities(identifying...)
+		if err != nil {
+			log.WithFields("error", err).Debug("unable to search for candidate vulnerabilities to explain")
+			continue
+		}
+
+		for _, v := range candidates {
+			if _, ok := p.candidates.found[keyOf(v.Reference)]; ok {
+				// another search for the package returned the vulnerability
+				continue
+			}
+			rejection, detail := explainRejection(v, remaining)
+			p.candidates.consider(v.Reference, rejection, detail)
+		}
+	}
+
+	return results, nil
+}
+
+func splitIdentifyingCriteria(row []vulnerability.Criteria) (identifying, remaining []vulnerability.Criteria) {
+	for _, c := range row {
+		switch c.(type) {
+		case *search.PackageNameCriteria, *search.CPECriteria, *search.IDCriteria:
+			identifying = append(identifying, c)
+		default:
+			remaining = append(remaining, c)
+		}
+	}
+	return identifying, remaining
+}
+
+// explainRejection determines the first of the given criteria that rejects the vulnerability.
+func explainRejection(v vulnerability.Vulnerability, criteria []vulnerability.Criteria) (RejectionReason, string) {
+	for _, c := range criteria {
+		matches, reason, err := c.MatchesVulnerability(v)
+		if err != nil {
+			return RejectedByCriteria, err.Error()
+		}
+		if matches {
+			continue
+		}
+
+		switch c.(type) {
+		case search.VersionConstraintMatcher:
+			return RejectedByVersionConstraint, fmt.Sprintf("version constraint %q is not satisfied", constraintString(v))
+		case *search.DistroCriteria, *search.EcosystemCriteria:
+			return RejectedByNamespace, fmt.Sprintf("namespace %q: %s", v.Namespace, reason)
+		default:
+			return RejectedByCriteria, reason
+		}
+	}
+	return RejectedByProvider, "the vulnerability was excluded by the vulnerability provider"
+}
+
+func constraintString(v vulnerability.Vulnerability) string {
+	if v.Constraint == nil {
+		return "none"
+	}
+	return v.Constraint.String()
+}
@@ -0,0 +1,71 @@
+package match
+
+import (
+	"fmt"
+
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/version"
+)
+
+// Constraint is a user-supplied version constraint to evaluate packages against, independent of any vulnerability
+// data (e.g. when authoring or testing detection rules).
+type Constraint struct {
+	ID         string         // An identifier for the constraint, reported in the resulting match details (e.g. a rule or vulnerability ID)
+	Expression string         // The version constraint expression (e.g. ">= 1.0, < 1.2.5")
+	Format     version.Format // The version format of the constraint (if unknown, the format is inferred from the package)
+}
+
+// ConstraintParameters describes the package that was evaluated against a user-supplied constraint.
+type ConstraintParameters struct {
+	Package PackageParameter `json:"package"`
+}
+
+// ConstraintResult describes the user-supplied constraint that was satisfied.
+type ConstraintResult struct {
+	ID                string `json:"id"`
+	VersionConstraint string `json:"versionConstraint"`
+}
+
+// EvaluateConstraints evaluates the package version against each of the given constraints using the same constraint
+// parsing and version comparison as matching against vulnerability data, returning a detail for each constraint that
+// the package version satisfies (in the order given). An error is returned if any constraint cannot be parsed or
+// evaluated.
+func EvaluateConstraints(p pkg.Package, constraints []Constraint) ([]Detail, error) {
+	var details []Detail
+	for _, c := range constraints {
+		format := c.Format
+		if format == version.UnknownFormat {
+			format = version.FormatFromPkg(p)
+		}
+
+		constraint, err := version.GetConstraint(c.Expression, format)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse constraint %q (id=%q): %w", c.Expression, c.ID, err)
+		}
+
+		satisfied, err := constraint.Satisfied(version.NewVersion(p.Version, format))
+		if err != nil {
+			return nil, fmt.Errorf("unable to evaluate constraint %q (id=%q) against version %q: %w", c.Expression, c.ID, p.Version, err)
+		}
+		if !satisfied {
+			continue
+		}
+
+		details = append(details, Detail{
+			Type: ExactDirectMatch,
+			SearchedBy: ConstraintParameters{
+				Package: PackageParameter{
+					Name:    p.Name,
+					Version: p.Version,
+				},
+			},
+			Found: ConstraintResult{
+				ID:                c.ID,
+				VersionConstraint: constraint.String(),
+			},
+			Matcher:    UnknownMatcherType,
+			Confidence: 1.0,
+		})
+	}
+	return details, nil
+}
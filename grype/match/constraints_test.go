@@ -0,0 +1,98 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/version"
+	syftPkg "github.com/anchore/syft/syft/pkg"
+)
+
+func TestEvaluateConstraints(t *testing.T) {
+	tests := []struct {
+		name        string
+		pkg         pkg.Package
+		constraints []Constraint
+		expected    []string
+		wantErr     require.ErrorAssertionFunc
+	}{
+		{
+			name: "semver boundaries",
+			pkg:  pkg.Package{Name: "lodash", Version: "4.17.21", Type: syftPkg.NpmPkg},
+			constraints: []Constraint{
+				{ID: "below-upper-bound", Expression: "< 4.17.21", Format: version.SemanticFormat},
+				{ID: "at-upper-bound", Expression: "<= 4.17.21", Format: version.SemanticFormat},
+				{ID: "at-lower-bound", Expression: ">= 4.17.21, < 5.0.0", Format: version.SemanticFormat},
+				{ID: "above-lower-bound", Expression: "> 4.17.21", Format: version.SemanticFormat},
+				{ID: "exact", Expression: "= 4.17.21", Format: version.SemanticFormat},
+				{ID: "wildcard", Expression: "4.17.*", Format: version.SemanticFormat},
+			},
+			expected: []string{"at-upper-bound", "at-lower-bound", "exact", "wildcard"},
+		},
+		{
+			name: "debian versions",
+			pkg:  pkg.Package{Name: "openssl", Version: "3.0.11-1~deb12u1", Type: syftPkg.DebPkg},
+			constraints: []Constraint{
+				// the backport suffix sorts before the release itself
+				{ID: "before-release", Expression: "< 3.0.11-1", Format: version.DebFormat},
+				{ID: "before-backport", Expression: "< 3.0.11-1~deb12u1", Format: version.DebFormat},
+				{ID: "epoch", Expression: "< 1:0.1", Format: version.DebFormat},
+			},
+			expected: []string{"before-release", "epoch"},
+		},
+		{
+			name: "rpm versions",
+			pkg:  pkg.Package{Name: "kernel", Version: "0:4.18.0-513.el8", Type: syftPkg.RpmPkg},
+			constraints: []Constraint{
+				{ID: "fixed-later", Expression: "< 4.18.0-553.el8", Format: version.RpmFormat},
+				{ID: "fixed-earlier", Expression: "< 4.18.0-500.el8", Format: version.RpmFormat},
+			},
+			expected: []string{"fixed-later"},
+		},
+		{
+			name: "format inferred from package",
+			pkg:  pkg.Package{Name: "rails", Version: "7.0.8", Type: syftPkg.GemPkg},
+			constraints: []Constraint{
+				{ID: "gem", Expression: ">= 7.0.0, < 7.0.8.1"},
+				{ID: "other", Expression: "< 7.0.0"},
+			},
+			expected: []string{"gem"},
+		},
+		{
+			name: "invalid constraint",
+			pkg:  pkg.Package{Name: "lodash", Version: "4.17.21", Type: syftPkg.NpmPkg},
+			constraints: []Constraint{
+				{ID: "bad", Expression: "(< 1.0)", Format: version.SemanticFormat},
+			},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.wantErr == nil {
+				test.wantErr = require.NoError
+			}
+
+			details, err := EvaluateConstraints(test.pkg, test.constraints)
+			test.wantErr(t, err)
+			if err != nil {
+				return
+			}
+
+			var ids []string
+			for _, d := range details {
+				assert.Equal(t, ExactDirectMatch, d.Type)
+				assert.Equal(t, ConstraintParameters{Package: PackageParameter{Name: test.pkg.Name, Version: test.pkg.Version}}, d.SearchedBy)
+
+				result, ok := d.Found.(ConstraintResult)
+				require.True(t, ok)
+				ids = append(ids, result.ID)
+			}
+			assert.Equal(t, test.expected, ids)
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package match
 
 import (
+	"bytes"
+	"cmp"
+	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/gohugoio/hashstructure"
@@ -15,6 +19,7 @@ type Detail struct {
 	Found      interface{} // The specific attributes on the vulnerability object that were matched with --this indicates "what" was matched on / within.
 	Matcher    MatcherType // The matcher object that discovered the match.
 	Confidence float64     // The certainty of the match as a ratio (currently unused, reserved for future use).
+	Sources    []string    `hash:"ignore"` // The names of the vulnerability providers the match was found in, in the order searched (only set when matching against multiple providers).
 }
 
 // String is the string representation of select match fields.
@@ -58,31 +63,106 @@ func (m Details) Len() int {
 	return len(m)
 }
 
+// Less orders details by type (exact-direct-match < exact-indirect-match < cpe-match < any unknown type), matcher,
+// the attributes searched by, the attributes found, confidence (highest first), and finally the provider sources. This is
+// a total order over distinguishable details, so sorting details always results in the same order. Since comparing
+// details is costly, prefer Sort when sorting many details.
 func (m Details) Less(i, j int) bool {
-	a := m[i]
-	b := m[j]
+	return compareDetails(m[i], m[j]) < 0
+}
 
-	if a.Type != b.Type {
-		// exact-direct-match < exact-indirect-match < cpe-match
+// Sort sorts the details in place in the same order as Less, computing the values compared for each detail only once.
+func (m Details) Sort() {
+	if len(m) < 2 {
+		return
+	}
+	keys := make([]*detailSortKey, len(m))
+	for idx, d := range m {
+		keys[idx] = newDetailSortKey(d)
+	}
+	slices.SortFunc(keys, compareDetailSortKeys)
+	for idx, k := range keys {
+		m[idx] = k.detail
+	}
+}
+
+func compareDetails(a, b Detail) int {
+	return compareDetailSortKeys(newDetailSortKey(a), newDetailSortKey(b))
+}
 
-		at := typeOrder[a.Type]
-		bt := typeOrder[b.Type]
-		if at == 0 {
-			return false
-		} else if bt == 0 {
-			return true
-		}
-		return at < bt
+// detailSortKey holds the values a detail is ordered by that are costly to compute (see Less).
+type detailSortKey struct {
+	detail     Detail
+	searchedBy []byte
+	found      []byte
+	// id is computed on demand, since it is only compared as a last resort
+	id string
+}
+
+func newDetailSortKey(d Detail) *detailSortKey {
+	return &detailSortKey{
+		detail:     d,
+		searchedBy: attributeKey(d.SearchedBy),
+		found:      attributeKey(d.Found),
+	}
+}
+
+func (k *detailSortKey) ID() string {
+	if k.id == "" {
+		k.id = k.detail.ID()
 	}
+	return k.id
+}
 
-	// sort by confidence
-	if a.Confidence != b.Confidence {
+func compareDetailSortKeys(a, b *detailSortKey) int {
+	if c := compareTypes(a.detail.Type, b.detail.Type); c != 0 {
+		return c
+	}
+	if c := strings.Compare(string(a.detail.Matcher), string(b.detail.Matcher)); c != 0 {
+		return c
+	}
+	if c := bytes.Compare(a.searchedBy, b.searchedBy); c != 0 {
+		return c
+	}
+	if c := bytes.Compare(a.found, b.found); c != 0 {
+		return c
+	}
+	if a.detail.Confidence != b.detail.Confidence {
 		// flipped comparison since we want higher confidence to be first
-		return a.Confidence > b.Confidence
+		return cmp.Compare(b.detail.Confidence, a.detail.Confidence)
+	}
+	if c := strings.Compare(a.ID(), b.ID()); c != 0 {
+		// note: this accounts for any fields not otherwise compared
+		return c
+	}
+	return slices.Compare(a.detail.Sources, b.detail.Sources)
+}
+
+func compareTypes(a, b Type) int {
+	if a == b {
+		return 0
+	}
+	at, aKnown := typeOrder[a]
+	bt, bKnown := typeOrder[b]
+	switch {
+	case aKnown && bKnown:
+		return cmp.Compare(at, bt)
+	case aKnown:
+		return -1
+	case bKnown:
+		return 1
 	}
+	return strings.Compare(string(a), string(b))
+}
 
-	// if the types are the same, then sort by the ID (costly, but deterministic)
-	return strings.Compare(a.ID(), b.ID()) < 0
+// attributeKey is the JSON encoding of a searched-by or found attribute (which is how they are presented), which
+// attributes are compared by.
+func attributeKey(v interface{}) []byte {
+	by, err := json.Marshal(v)
+	if err != nil {
+		return []byte(fmt.Sprintf("%#v", v))
+	}
+	return by
 }
 
 func (m Details) Swap(i, j int) {
@@ -1,6 +1,7 @@
 package match
 
 import (
+	"slices"
 	"sort"
 	"testing"
 
@@ -98,12 +99,42 @@ func TestDetails_Sorting(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			// sorting with precomputed keys results in the same order as sorting by Less
+			details := slices.Clone(tt.details)
+			details.Sort()
+			require.Equal(t, tt.expected, details)
+
 			sort.Sort(tt.details)
 			require.Equal(t, tt.expected, tt.details)
 		})
 	}
 }
 
+func TestDetails_Sorting_TotalOrder(t *testing.T) {
+	// details that the type, confidence, and hash ID alone do not distinguish in a fixed order
+	expected := Details{
+		{Type: ExactDirectMatch, Matcher: DpkgMatcher, SearchedBy: DistroParameters{Namespace: "debian:distro:debian:12", Package: PackageParameter{Name: "openssl"}}},
+		{Type: ExactDirectMatch, Matcher: StockMatcher, SearchedBy: EcosystemParameters{Namespace: "github:language:python", Package: PackageParameter{Name: "requests"}}},
+		{Type: ExactDirectMatch, Matcher: StockMatcher, SearchedBy: EcosystemParameters{Namespace: "github:language:python", Package: PackageParameter{Name: "urllib3"}}},
+		{Type: CPEMatch, Matcher: StockMatcher, SearchedBy: "cpe", Found: "a"},
+		{Type: CPEMatch, Matcher: StockMatcher, SearchedBy: "cpe", Found: "b", Sources: []string{"internal"}},
+		{Type: CPEMatch, Matcher: StockMatcher, SearchedBy: "cpe", Found: "b", Sources: []string{"official"}},
+		{Type: "custom-a", Matcher: StockMatcher},
+		{Type: "custom-b", Matcher: StockMatcher},
+	}
+
+	// every rotation of the details sorts to the same order
+	for i := range expected {
+		details := append(append(Details{}, expected[i:]...), expected[:i]...)
+		sorted := slices.Clone(details)
+		sorted.Sort()
+		require.Equal(t, expected, sorted)
+
+		sort.Sort(details)
+		require.Equal(t, expected, details)
+	}
+}
+
 func TestHasExclusivelyAnyMatchTypes(t *testing.T) {
 	tests := []struct {
 		name     string
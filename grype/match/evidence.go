@@ -0,0 +1,37 @@
+package match
+
+import (
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/syft/syft/file"
+	syftPkg "github.com/anchore/syft/syft/pkg"
+)
+
+// Evidence is the SBOM information that led to the matched package being identified, allowing for a finding to be
+// traced back to its source (e.g. when triaging false positives caused by package misidentification).
+type Evidence struct {
+	// Locations are the locations annotated as the primary evidence for the package (or all package locations
+	// if none are annotated as primary evidence)
+	Locations []file.Location
+	// Metadata is the package metadata that was used for matching
+	Metadata interface{}
+}
+
+// NewEvidence creates the evidence for the given package.
+func NewEvidence(p pkg.Package) Evidence {
+	locations := p.Locations.ToSlice()
+
+	var primary []file.Location
+	for _, l := range locations {
+		if l.Annotations[syftPkg.EvidenceAnnotationKey] == syftPkg.PrimaryEvidenceAnnotation {
+			primary = append(primary, l)
+		}
+	}
+	if len(primary) > 0 {
+		locations = primary
+	}
+
+	return Evidence{
+		Locations: locations,
+		Metadata:  p.Metadata,
+	}
+}
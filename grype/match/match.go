@@ -2,6 +2,7 @@ package match
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -19,6 +20,7 @@ type Match struct {
 	Vulnerability vulnerability.Vulnerability // The vulnerability details of the match.
 	Package       pkg.Package                 // The package used to search for a match.
 	Details       Details                     // all the ways this particular match was made.
+	Evidence      *Evidence                   // the SBOM evidence for the package (optional, not considered for the fingerprint)
 }
 
 // String is the string representation of select match fields.
@@ -37,6 +39,9 @@ func (m Match) Fingerprint() Fingerprint {
 	}
 }
 
+// Merge merges the other match (which must have the same fingerprint) into this match. The details are only re-sorted
+// when details are added, so the details of this match are expected to be sorted already (as is the case for all matches
+// held by Matches).
 func (m *Match) Merge(other Match) error {
 	if other.Fingerprint() != m.Fingerprint() {
 		return ErrCannotMerge
@@ -62,20 +67,27 @@ func (m *Match) Merge(other Match) error {
 		return strings.Compare(referenceID(a), referenceID(b)) < 0
 	})
 
-	// also keep details from the other match that are unique
-	detailIDs := strset.New()
-	for _, d := range m.Details {
-		detailIDs.Add(d.ID())
+	// also keep details from the other match that are unique, while the same detail found in other vulnerability
+	// providers is attributed to those providers as well
+	m.Details = slices.Clone(m.Details)
+	detailIdx := make(map[string]int, len(m.Details))
+	for idx, d := range m.Details {
+		detailIdx[d.ID()] = idx
 	}
+	added := false
 	for _, d := range other.Details {
-		if detailIDs.Has(d.ID()) {
+		if idx, ok := detailIdx[d.ID()]; ok {
+			m.Details[idx].Sources = mergeSources(m.Details[idx].Sources, d.Sources)
 			continue
 		}
 		m.Details = append(m.Details, d)
+		added = true
 	}
 
-	// for stable output
-	sort.Sort(m.Details)
+	// for stable output (the details are already sorted when there are none added, see Matches.Add)
+	if added {
+		m.Details.Sort()
+	}
 
 	// retain all unique CPEs for consistent output
 	m.Vulnerability.CPEs = cpe.Merge(m.Vulnerability.CPEs, other.Vulnerability.CPEs)
@@ -87,6 +99,16 @@ func (m *Match) Merge(other Match) error {
 	return nil
 }
 
+// mergeSources returns the distinct sources of both lists, in the order they first appear.
+func mergeSources(sources, other []string) []string {
+	for _, s := range other {
+		if !slices.Contains(sources, s) {
+			sources = append(slices.Clip(sources), s)
+		}
+	}
+	return sources
+}
+
 // referenceID returns an "ID" string for a vulnerability.Reference
 func referenceID(r vulnerability.Reference) string {
 	return fmt.Sprintf("%s:%s", r.Namespace, r.ID)
@@ -231,6 +231,71 @@ func TestMatch_Merge(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "merges the sources of duplicate details",
+			m1: Match{
+				Vulnerability: vulnerability.Vulnerability{
+					Reference: vulnerability.Reference{
+						ID:        "CVE-2023-0001",
+						Namespace: "namespace",
+					},
+				},
+				Package: pkg.Package{
+					ID: "pkg1",
+				},
+				Details: Details{
+					{
+						Type:       ExactDirectMatch,
+						SearchedBy: "attr1",
+						Found:      "value1",
+						Matcher:    "matcher1",
+						Sources:    []string{"official"},
+					},
+				},
+			},
+			m2: Match{
+				Vulnerability: vulnerability.Vulnerability{
+					Reference: vulnerability.Reference{
+						ID:        "CVE-2023-0001",
+						Namespace: "namespace",
+					},
+				},
+				Package: pkg.Package{
+					ID: "pkg1",
+				},
+				Details: Details{
+					{
+						Type:       ExactDirectMatch,
+						SearchedBy: "attr1",
+						Found:      "value1",
+						Matcher:    "matcher1",
+						Sources:    []string{"internal", "official"},
+					},
+				},
+			},
+			expectedErr: nil,
+			expected: Match{
+				Vulnerability: vulnerability.Vulnerability{
+					Reference: vulnerability.Reference{
+						ID:        "CVE-2023-0001",
+						Namespace: "namespace",
+					},
+					CPEs: []cpe.CPE{},
+				},
+				Package: pkg.Package{
+					ID: "pkg1",
+				},
+				Details: Details{
+					{
+						Type:       ExactDirectMatch,
+						SearchedBy: "attr1",
+						Found:      "value1",
+						Matcher:    "matcher1",
+						Sources:    []string{"official", "internal"},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1,12 +1,14 @@
 package match
 
 import (
+	"slices"
 	"sort"
 
 	"github.com/scylladb/go-set/strset"
 
 	"github.com/anchore/grype/grype/pkg"
 	"github.com/anchore/grype/internal/log"
+	syftPkg "github.com/anchore/syft/syft/pkg"
 )
 
 type Matches struct {
@@ -66,9 +68,12 @@ func (r *Matches) Diff(other Matches) *Matches {
 	return &diff
 }
 
+// Add adds (or merges) the given matches. The details of every match are kept in sorted order (see Details.Less), so
+// the same matches always have the same details order regardless of the order in which they were added.
 func (r *Matches) Add(matches ...Match) {
 	for _, newMatch := range matches {
 		newFp := newMatch.Fingerprint()
+		newMatch.Details = sortedDetails(newMatch.Details)
 
 		// add or merge the new match with an existing match
 		r.addOrMerge(newMatch, newFp)
@@ -101,7 +106,7 @@ func (r *Matches) addOrMerge(newMatch Match, newFp Fingerprint) {
 		if err := existingMatch.Merge(newMatch); err != nil {
 			log.WithFields("original", existingMatch.String(), "new", newMatch.String(), "error", err).Warn("unable to merge matches")
 			// at least capture the additional details
-			existingMatch.Details = append(existingMatch.Details, newMatch.Details...)
+			existingMatch.Details = sortedDetails(append(existingMatch.Details, newMatch.Details...))
 		}
 
 		r.byFingerprint[newFp] = existingMatch
@@ -162,7 +167,7 @@ func (r *Matches) replace(m Match, ogFp, newFp Fingerprint, extraDetails ...Deta
 	// update the match
 	delete(r.byFingerprint, ogFp)
 	m.Details = append(m.Details, extraDetails...)
-	sort.Sort(m.Details)
+	m.Details.Sort()
 	r.byFingerprint[newFp] = m
 	return true
 }
@@ -189,11 +194,62 @@ func (r *Matches) Sorted() []Match {
 	return matches
 }
 
+// ByLayer returns all matches organized by the container image layer (filesystem ID) that introduced the matched
+// package. The primary evidence location of the package is preferred when determining the layer. Matches for packages
+// without any layer information are keyed by an empty string.
+func (r *Matches) ByLayer() map[string][]Match {
+	return r.groupBy(func(m Match) string {
+		return packageLayer(m.Package)
+	})
+}
+
+// ByModule returns all matches organized by the module (the containing package, as derived from SBOM relationships)
+// of the matched package. Matches for packages without a module are keyed by an empty string.
+func (r *Matches) ByModule() map[string][]Match {
+	return r.groupBy(func(m Match) string {
+		return m.Package.Module
+	})
+}
+
+func (r *Matches) groupBy(key func(Match) string) map[string][]Match {
+	groups := make(map[string][]Match)
+	for _, m := range r.Sorted() {
+		k := key(m)
+		groups[k] = append(groups[k], m)
+	}
+	return groups
+}
+
+func packageLayer(p pkg.Package) string {
+	locations := p.Locations.ToSlice()
+	for _, l := range locations {
+		if l.FileSystemID != "" && l.Annotations[syftPkg.EvidenceAnnotationKey] == syftPkg.PrimaryEvidenceAnnotation {
+			return l.FileSystemID
+		}
+	}
+	for _, l := range locations {
+		if l.FileSystemID != "" {
+			return l.FileSystemID
+		}
+	}
+	return ""
+}
+
 // Count returns the total number of matches in a result
 func (r *Matches) Count() int {
 	return len(r.byFingerprint)
 }
 
+// sortedDetails returns a sorted copy of the given details (leaving the original slice untouched).
+func sortedDetails(details Details) Details {
+	if details == nil {
+		return nil
+	}
+	sorted := slices.Clone(details)
+	sorted.Sort()
+	return sorted
+}
+
 func hasMatchType(details Details, ty Type) bool {
 	for _, d := range details {
 		if d.Type == ty {
@@ -702,3 +702,91 @@ func TestMatches_Add_Merge(t *testing.T) {
 		})
 	}
 }
+
+func TestMatches_Add_SortsDetails(t *testing.T) {
+	p := pkg.Package{
+		ID:      pkg.ID(uuid.NewString()),
+		Name:    "requests",
+		Version: "2.0.0",
+		Type:    syftPkg.PythonPkg,
+	}
+	newMatch := func(details ...Detail) Match {
+		return Match{
+			Vulnerability: vulnerability.Vulnerability{
+				Reference: vulnerability.Reference{ID: "CVE-2020-0010", Namespace: "github:language:python"},
+			},
+			Package: p,
+			Details: details,
+		}
+	}
+
+	cpeDetail := Detail{Type: CPEMatch, Matcher: PythonMatcher, SearchedBy: CPEParameters{Namespace: "nvd:cpe", CPEs: []string{"cpe:2.3:a:python:requests:2.0.0:*:*:*:*:*:*:*"}}}
+	directDetail := Detail{Type: ExactDirectMatch, Matcher: PythonMatcher, SearchedBy: EcosystemParameters{Namespace: "github:language:python", Package: PackageParameter{Name: "requests", Version: "2.0.0"}}}
+	stockDetail := Detail{Type: ExactDirectMatch, Matcher: StockMatcher, SearchedBy: EcosystemParameters{Namespace: "github:language:python", Package: PackageParameter{Name: "requests", Version: "2.0.0"}}}
+
+	first := NewMatches(newMatch(cpeDetail, stockDetail), newMatch(directDetail))
+	second := NewMatches(newMatch(directDetail), newMatch(stockDetail), newMatch(cpeDetail))
+
+	require.Len(t, first.Sorted(), 1)
+	require.Len(t, second.Sorted(), 1)
+	expected := Details{directDetail, stockDetail, cpeDetail}
+	assert.Equal(t, expected, first.Sorted()[0].Details)
+	assert.Equal(t, expected, second.Sorted()[0].Details)
+}
+
+func TestMatches_ByLayer_ByModule(t *testing.T) {
+	newMatch := func(vulnID, module string, locations ...file.Location) Match {
+		return Match{
+			Vulnerability: vulnerability.Vulnerability{
+				Reference: vulnerability.Reference{
+					ID: vulnID,
+				},
+			},
+			Package: pkg.Package{
+				ID:        pkg.ID(uuid.NewString()),
+				Name:      "package-" + vulnID,
+				Version:   "1.0.0",
+				Type:      syftPkg.NpmPkg,
+				Locations: file.NewLocationSet(locations...),
+				Module:    module,
+			},
+		}
+	}
+
+	baseLayer := file.NewLocationFromCoordinates(file.NewCoordinates("/usr/lib/node_modules/a/package.json", "sha256:base")).
+		WithAnnotation(syftPkg.EvidenceAnnotationKey, syftPkg.PrimaryEvidenceAnnotation)
+	appLayer := file.NewLocationFromCoordinates(file.NewCoordinates("/app/node_modules/b/package.json", "sha256:app")).
+		WithAnnotation(syftPkg.EvidenceAnnotationKey, syftPkg.PrimaryEvidenceAnnotation)
+	// supporting evidence in a lower layer should not be considered the layer that introduced the package
+	supporting := file.NewLocationFromCoordinates(file.NewCoordinates("/usr/share/doc/b/LICENSE", "sha256:base")).
+		WithAnnotation(syftPkg.EvidenceAnnotationKey, syftPkg.SupportingEvidenceAnnotation)
+
+	matches := NewMatches(
+		newMatch("CVE-1", "frontend", baseLayer),
+		newMatch("CVE-2", "frontend", supporting, appLayer),
+		newMatch("CVE-3", "backend", appLayer),
+		newMatch("CVE-4", ""),
+	)
+
+	ids := func(groups map[string][]Match) map[string][]string {
+		out := make(map[string][]string)
+		for k, ms := range groups {
+			for _, m := range ms {
+				out[k] = append(out[k], m.Vulnerability.ID)
+			}
+		}
+		return out
+	}
+
+	assert.Equal(t, map[string][]string{
+		"sha256:base": {"CVE-1"},
+		"sha256:app":  {"CVE-2", "CVE-3"},
+		"":            {"CVE-4"},
+	}, ids(matches.ByLayer()))
+
+	assert.Equal(t, map[string][]string{
+		"frontend": {"CVE-1", "CVE-2"},
+		"backend":  {"CVE-3"},
+		"":         {"CVE-4"},
+	}, ids(matches.ByModule()))
+}
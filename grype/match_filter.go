@@ -0,0 +1,64 @@
+package grype
+
+import (
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/vulnerability"
+	"github.com/anchore/grype/internal/log"
+)
+
+// MatchFilter describes which matches are kept while matching (see VulnerabilityMatcher.MatchFilter).
+type MatchFilter struct {
+	// MinimumCVSS is the minimum CVSS base score of a match to be kept, where the highest base score across all CVSS
+	// entries for the vulnerability is used. Zero keeps all matches.
+	MinimumCVSS float64
+	// KeepUnscored keeps matches for vulnerabilities with no CVSS base scores at all, which are otherwise dropped when
+	// a MinimumCVSS is set.
+	KeepUnscored bool
+}
+
+// apply partitions the given matches into the matches to keep and the matches below the threshold.
+func (f MatchFilter) apply(provider vulnerability.MetadataProvider, matches []match.Match) (kept []match.Match, dropped []match.Match) {
+	if f.MinimumCVSS <= 0 {
+		return matches, nil
+	}
+
+	for _, m := range matches {
+		if f.keep(provider, m) {
+			kept = append(kept, m)
+		} else {
+			dropped = append(dropped, m)
+		}
+	}
+	return kept, dropped
+}
+
+func (f MatchFilter) keep(provider vulnerability.MetadataProvider, m match.Match) bool {
+	metadata := m.Vulnerability.Metadata
+	if metadata == nil {
+		var err error
+		metadata, err = provider.VulnerabilityMetadata(m.Vulnerability.Reference)
+		if err != nil {
+			log.WithFields("vuln", m.Vulnerability.ID, "namespace", m.Vulnerability.Namespace, "error", err).Debug("unable to fetch vulnerability metadata")
+		}
+	}
+
+	score, scored := metadata.HighestBaseScore()
+	if !scored {
+		return f.KeepUnscored
+	}
+	return score >= f.MinimumCVSS
+}
+
+func logFilteredPackageMatches(p pkg.Package, filtered []match.Match) {
+	if len(filtered) == 0 {
+		return
+	}
+
+	log.WithFields("package", displayPackage(p)).Debugf("dropped %d vulnerability matches below the CVSS threshold", len(filtered))
+	for idx, m := range filtered {
+		arm := selectArm(idx, len(filtered))
+
+		log.WithFields("vuln", m.Vulnerability.ID).Debugf("  %s", arm)
+	}
+}
@@ -0,0 +1,157 @@
+package grype
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/scylladb/go-set/strset"
+
+	"github.com/anchore/grype/grype/distro"
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/vulnerability"
+	"github.com/anchore/grype/internal/log"
+	"github.com/anchore/stereoscope/pkg/image"
+	"github.com/anchore/syft/syft/source"
+)
+
+// NamedProvider is a vulnerability provider along with a name identifying it, which is used to attribute each match
+// detail to the provider that produced it (e.g. "official" vs "internal").
+type NamedProvider struct {
+	Name     string
+	Provider vulnerability.Provider
+}
+
+// FindVulnerabilitiesInProviders is the same as FindVulnerabilities, but searches multiple vulnerability providers (see
+// FindVulnerabilitiesForPackageInProviders).
+func FindVulnerabilitiesInProviders(providers []NamedProvider, userImageStr string, scopeOpt source.Scope, registryOptions *image.RegistryOptions) (match.Matches, pkg.Context, []pkg.Package, error) {
+	packages, context, _, err := pkg.Provide(userImageStr, defaultProviderConfig(scopeOpt, registryOptions))
+	if err != nil {
+		return match.Matches{}, pkg.Context{}, nil, err
+	}
+
+	matches, err := FindVulnerabilitiesForPackageInProviders(providers, context.Distro, nil, packages)
+	if err != nil {
+		return match.Matches{}, pkg.Context{}, nil, err
+	}
+	return matches, context, packages, nil
+}
+
+// FindVulnerabilitiesForPackageInProviders searches each of the given vulnerability providers concurrently, merging
+// all matches into a single result. Every match detail is attributed to the providers that produced it: when the same
+// match is found in multiple providers, the matches are merged and the details are attributed to each provider (in
+// provider order). The given function is called once for each provider to create the matchers to search with, since
+// matchers may keep state between searches (the default matchers are used when nil). Progress is reported as a single
+// search across all providers. An error from any provider fails the entire search.
+func FindVulnerabilitiesForPackageInProviders(providers []NamedProvider, d *distro.Distro, newMatchers func() []match.Matcher, packages []pkg.Package) (merged match.Matches, err error) {
+	// note: packages processed are reported as found by each provider, while matches are only reported once merged
+	// (so matches found in multiple providers are counted once)
+	progressMonitor := trackMatcher(len(packages) * len(providers))
+	defer func() {
+		progressMonitor.SetCompleted()
+		if err != nil {
+			progressMonitor.MatchesDiscovered.SetError(err)
+		}
+	}()
+
+	results := make([]*match.Matches, len(providers))
+	errs := make([]error, len(providers))
+
+	var wg sync.WaitGroup
+	for idx, p := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			runner := newDefaultVulnerabilityMatcher(p.Provider)
+			if newMatchers != nil {
+				runner.Matchers = newMatchers()
+			}
+			providerMonitor, _ := newMonitor(len(packages))
+			providerMonitor.PackagesProcessed = progressMonitor.PackagesProcessed
+			runner.progressMonitor = &providerMonitor
+
+			matches, _, err := runner.FindMatches(packages, pkg.Context{
+				Distro: d,
+			})
+			if err != nil {
+				errs[idx] = fmt.Errorf("unable to find vulnerabilities in provider %q: %w", p.Name, err)
+				return
+			}
+			results[idx] = matches
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return match.NewMatches(), err
+	}
+
+	// note: results are merged in provider order (not completion order) so the order of attribution is deterministic
+	merged = match.NewMatches()
+	for idx, matches := range results {
+		name := providers[idx].Name
+		for _, m := range matches.Sorted() {
+			m.Details = withSource(m.Details, name)
+			if _, ok := findIdenticalMatch(merged, m); ok {
+				log.WithFields("vuln", m.Vulnerability.ID, "package", displayPackage(m.Package), "provider", name).
+					Debug("identical match found in multiple vulnerability providers, merging attribution")
+			}
+			merged.Add(m)
+		}
+	}
+
+	byName := make(map[string]vulnerability.Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name] = p.Provider
+	}
+	for _, m := range merged.Sorted() {
+		// note: severities are resolved against the first provider the match was found in
+		provider := providers[0].Provider
+		if len(m.Details) > 0 && len(m.Details[0].Sources) > 0 {
+			provider = byName[m.Details[0].Sources[0]]
+		}
+		updateVulnerabilityList(progressMonitor, []match.Match{m}, nil, nil, provider)
+	}
+	progressMonitor.MatchesDiscovered.Set(int64(merged.Count()))
+	logListSummary(progressMonitor)
+
+	return merged, nil
+}
+
+func withSource(details match.Details, source string) match.Details {
+	out := make(match.Details, len(details))
+	for idx, d := range details {
+		d.Sources = []string{source}
+		out[idx] = d
+	}
+	return out
+}
+
+// findIdenticalMatch returns the existing match for the same package and vulnerability that already has every
+// detail of the given match (irrespective of which provider the details were found in).
+func findIdenticalMatch(matches match.Matches, m match.Match) (match.Match, bool) {
+	for _, existing := range matches.GetByPkgID(m.Package.ID) {
+		if existing.Fingerprint() != m.Fingerprint() || len(existing.Details) == 0 {
+			continue
+		}
+
+		ids := strset.New()
+		for _, d := range existing.Details {
+			ids.Add(d.ID())
+		}
+
+		identical := true
+		for _, d := range m.Details {
+			if !ids.Has(d.ID()) {
+				identical = false
+				break
+			}
+		}
+		if identical {
+			return existing, true
+		}
+	}
+	return match.Match{}, false
+}
@@ -0,0 +1,118 @@
+package grype
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/matcher"
+	matcherMock "github.com/anchore/grype/grype/matcher/mock"
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/version"
+	"github.com/anchore/grype/grype/vulnerability"
+	"github.com/anchore/grype/grype/vulnerability/mock"
+	"github.com/anchore/grype/internal/bus"
+	syftPkg "github.com/anchore/syft/syft/pkg"
+)
+
+func TestFindVulnerabilitiesForPackageInProviders(t *testing.T) {
+	internalVulnerabilities := []vulnerability.Vulnerability{
+		// the same record as found in the official provider
+		{
+			Reference: vulnerability.Reference{
+				ID:        "GHSA-2014-fake-3",
+				Namespace: "github:language:ruby",
+			},
+			PackageName: "activerecord",
+			Constraint:  version.MustGetConstraint("< 3.7.6", version.UnknownFormat),
+		},
+		// a record only known to the internal provider
+		{
+			Reference: vulnerability.Reference{
+				ID:        "INTERNAL-2014-fake-1",
+				Namespace: "github:language:ruby",
+			},
+			PackageName: "activerecord",
+			Constraint:  version.MustGetConstraint("< 4.0.0", version.UnknownFormat),
+		},
+	}
+
+	providers := []NamedProvider{
+		{Name: "official", Provider: mock.VulnerabilityProvider(testVulnerabilities()...)},
+		{Name: "internal", Provider: mock.VulnerabilityProvider(internalVulnerabilities...)},
+	}
+
+	packages := []pkg.Package{
+		{
+			ID:       pkg.ID(uuid.NewString()),
+			Name:     "activerecord",
+			Version:  "3.7.5",
+			Type:     syftPkg.GemPkg,
+			Language: syftPkg.Ruby,
+		},
+	}
+
+	listener := &busListener{}
+	bus.Set(listener)
+	defer bus.Set(nil)
+
+	// matchers may keep state between searches, so are created for each provider
+	var created atomic.Int32
+	newMatchers := func() []match.Matcher {
+		created.Add(1)
+		return matcher.NewDefaultMatchers(matcher.Config{})
+	}
+
+	actual, err := FindVulnerabilitiesForPackageInProviders(providers, nil, newMatchers, packages)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), created.Load())
+
+	sources := make(map[string][][]string)
+	for _, m := range actual.Sorted() {
+		for _, d := range m.Details {
+			sources[m.Vulnerability.ID] = append(sources[m.Vulnerability.ID], d.Sources)
+		}
+	}
+
+	assert.Equal(t, map[string][][]string{
+		// the identical match is merged, attributing the details to both providers
+		"GHSA-2014-fake-3":     {{"official", "internal"}},
+		"INTERNAL-2014-fake-1": {{"internal"}},
+	}, sources)
+
+	// progress is reported as a single search across all providers, counting merged matches once
+	assert.Equal(t, 1, listener.started)
+	assert.Equal(t, int64(2), listener.matching.PackagesProcessed.Current())
+	assert.Equal(t, int64(2), listener.matching.PackagesProcessed.Size())
+	assert.Equal(t, int64(2), listener.matching.MatchesDiscovered.Current())
+}
+
+func TestFindVulnerabilitiesForPackageInProviders_Error(t *testing.T) {
+	providers := []NamedProvider{
+		{Name: "official", Provider: mock.VulnerabilityProvider(testVulnerabilities()...)},
+		{Name: "broken", Provider: mock.VulnerabilityProvider()},
+	}
+
+	failing := matcherMock.New(syftPkg.GemPkg, func(_ vulnerability.Provider, _ pkg.Package) ([]match.Match, []match.IgnoreFilter, error) {
+		return nil, nil, match.NewFatalError(match.UnknownMatcherType, errors.New("some error"))
+	})
+
+	packages := []pkg.Package{
+		{
+			ID:      pkg.ID(uuid.NewString()),
+			Name:    "activerecord",
+			Version: "3.7.5",
+			Type:    syftPkg.GemPkg,
+		},
+	}
+
+	_, err := FindVulnerabilitiesForPackageInProviders(providers, nil, func() []match.Matcher { return []match.Matcher{failing} }, packages)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `provider "official"`)
+	assert.Contains(t, err.Error(), `provider "broken"`)
+}
@@ -0,0 +1,79 @@
+package grype
+
+import (
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/vulnerability"
+	"github.com/anchore/stereoscope/pkg/image"
+	"github.com/anchore/syft/syft/source"
+)
+
+// NewVulnerabilities are the results of a scan relative to the matches of a prior (baseline) scan.
+type NewVulnerabilities struct {
+	// Introduced are matches that are not present in the baseline at all.
+	Introduced match.Matches
+	// Changed are matches that are present in the baseline, but whose severity or fix state differs from the baseline.
+	Changed match.Matches
+}
+
+// newVulnerabilityKey identifies a match across scans by the vulnerability ID and package identity (name, version,
+// and type). Package IDs are not used since they are not guaranteed to be stable across scans.
+type newVulnerabilityKey struct {
+	vulnerabilityID string
+	packageName     string
+	packageVersion  string
+	packageType     string
+}
+
+func newVulnerabilityKeyFromMatch(m match.Match) newVulnerabilityKey {
+	return newVulnerabilityKey{
+		vulnerabilityID: m.Vulnerability.ID,
+		packageName:     m.Package.Name,
+		packageVersion:  m.Package.Version,
+		packageType:     string(m.Package.Type),
+	}
+}
+
+// FindNewVulnerabilities scans the given user input and returns only the matches not already present in the given
+// baseline matches (typically from a scan of a prior revision of the same artifact).
+func FindNewVulnerabilities(store vulnerability.Provider, userImageStr string, scopeOpt source.Scope, registryOptions *image.RegistryOptions, baseline match.Matches) (NewVulnerabilities, pkg.Context, []pkg.Package, error) {
+	matches, context, packages, err := FindVulnerabilities(store, userImageStr, scopeOpt, registryOptions)
+	if err != nil {
+		return NewVulnerabilities{}, pkg.Context{}, nil, err
+	}
+
+	return NewVulnerabilitiesSince(baseline, matches), context, packages, nil
+}
+
+// NewVulnerabilitiesSince compares the current matches against the baseline matches, returning matches that have been
+// introduced since the baseline as well as matches that exist in both but have changed (e.g. the severity increased).
+func NewVulnerabilitiesSince(baseline, current match.Matches) NewVulnerabilities {
+	baselineByKey := make(map[newVulnerabilityKey]match.Match)
+	for m := range baseline.Enumerate() {
+		baselineByKey[newVulnerabilityKeyFromMatch(m)] = m
+	}
+
+	result := NewVulnerabilities{
+		Introduced: match.NewMatches(),
+		Changed:    match.NewMatches(),
+	}
+
+	for m := range current.Enumerate() {
+		previous, exists := baselineByKey[newVulnerabilityKeyFromMatch(m)]
+		switch {
+		case !exists:
+			result.Introduced.Add(m)
+		case matchSeverity(previous) != matchSeverity(m) || previous.Vulnerability.Fix.State != m.Vulnerability.Fix.State:
+			result.Changed.Add(m)
+		}
+	}
+
+	return result
+}
+
+func matchSeverity(m match.Match) vulnerability.Severity {
+	if m.Vulnerability.Metadata == nil {
+		return vulnerability.UnknownSeverity
+	}
+	return vulnerability.ParseSeverity(m.Vulnerability.Metadata.Severity)
+}
@@ -0,0 +1,62 @@
+package grype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/vulnerability"
+	syftPkg "github.com/anchore/syft/syft/pkg"
+)
+
+func TestNewVulnerabilitiesSince(t *testing.T) {
+	newMatch := func(pkgID pkg.ID, vulnID, pkgVersion, severity string) match.Match {
+		return match.Match{
+			Vulnerability: vulnerability.Vulnerability{
+				Reference: vulnerability.Reference{
+					ID:        vulnID,
+					Namespace: "github:language:ruby",
+				},
+				Metadata: &vulnerability.Metadata{
+					ID:       vulnID,
+					Severity: severity,
+				},
+			},
+			Package: pkg.Package{
+				ID:      pkgID,
+				Name:    "activerecord",
+				Version: pkgVersion,
+				Type:    syftPkg.GemPkg,
+			},
+		}
+	}
+
+	baseline := match.NewMatches(
+		newMatch("baseline-1", "CVE-inherited", "3.7.5", "medium"),
+		newMatch("baseline-1", "CVE-rescored", "3.7.5", "medium"),
+		newMatch("baseline-1", "CVE-fixed", "3.7.5", "high"),
+	)
+
+	// note: package IDs differ between scans, identity is based on name, version, and type
+	current := match.NewMatches(
+		newMatch("current-1", "CVE-inherited", "3.7.5", "medium"),
+		newMatch("current-1", "CVE-rescored", "3.7.5", "critical"),
+		newMatch("current-1", "CVE-introduced", "3.7.5", "low"),
+	)
+
+	result := NewVulnerabilitiesSince(baseline, current)
+
+	var introduced []string
+	for _, m := range result.Introduced.Sorted() {
+		introduced = append(introduced, m.Vulnerability.ID)
+	}
+	var changed []string
+	for _, m := range result.Changed.Sorted() {
+		changed = append(changed, m.Vulnerability.ID)
+	}
+
+	assert.Equal(t, []string{"CVE-introduced"}, introduced)
+	assert.Equal(t, []string{"CVE-rescored"}, changed)
+}
@@ -0,0 +1,36 @@
+package grype
+
+import (
+	"fmt"
+
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/matcher"
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/vulnerability"
+)
+
+// FindVulnerabilitiesForPackages matches the given packages against the vulnerability provider using the default
+// matchers. This is for callers that already have packages (e.g. cataloged with the syft library API and converted
+// with pkg.FromCollection) and so do not need the user input to be parsed and cataloged. The context may be nil,
+// otherwise the distro of the context is used for packages without a distro.
+func FindVulnerabilitiesForPackages(store vulnerability.Provider, packages []pkg.Package, context *pkg.Context) (match.Matches, error) {
+	if context == nil {
+		context = &pkg.Context{}
+	}
+
+	runner := newDefaultVulnerabilityMatcher(store)
+	matches, _, err := runner.FindMatches(packages, *context)
+	if err != nil {
+		return match.NewMatches(), fmt.Errorf("unable to find vulnerabilities: %w", err)
+	}
+	return *matches, nil
+}
+
+func newDefaultVulnerabilityMatcher(store vulnerability.Provider) VulnerabilityMatcher {
+	exclusionProvider, _ := store.(match.ExclusionProvider) // TODO v5 is an exclusion provider, but v6 is not
+	return VulnerabilityMatcher{
+		VulnerabilityProvider: store,
+		ExclusionProvider:     exclusionProvider,
+		Matchers:              matcher.NewDefaultMatchers(matcher.Config{}),
+	}
+}
@@ -0,0 +1,63 @@
+package grype
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/grype/grype/distro"
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/vulnerability/mock"
+	syftPkg "github.com/anchore/syft/syft/pkg"
+)
+
+func TestFindVulnerabilitiesForPackages(t *testing.T) {
+	vp := mock.VulnerabilityProvider(testVulnerabilities()...)
+
+	packages := []pkg.Package{
+		{
+			ID:      pkg.ID(uuid.NewString()),
+			Name:    "neutron",
+			Version: "2013.1.1-1",
+			Type:    syftPkg.DebPkg,
+		},
+		{
+			ID:       pkg.ID(uuid.NewString()),
+			Name:     "activerecord",
+			Version:  "3.7.5",
+			Type:     syftPkg.GemPkg,
+			Language: syftPkg.Ruby,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		context  *pkg.Context
+		expected []string
+	}{
+		{
+			name:     "no context",
+			expected: []string{"GHSA-2014-fake-3"},
+		},
+		{
+			name:     "distro from the context",
+			context:  &pkg.Context{Distro: &distro.Distro{Type: "debian", Version: "8"}},
+			expected: []string{"CVE-2014-fake-1", "GHSA-2014-fake-3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := FindVulnerabilitiesForPackages(vp, packages, tt.context)
+			require.NoError(t, err)
+
+			var ids []string
+			for _, m := range matches.Sorted() {
+				ids = append(ids, m.Vulnerability.ID)
+			}
+			assert.ElementsMatch(t, tt.expected, ids)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+
+	"github.com/anchore/syft/syft/format/cyclonedxjson"
+	"github.com/anchore/syft/syft/format/cyclonedxxml"
+	"github.com/anchore/syft/syft/linux"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+const cycloneDXDistroPropertyPrefix = "syft:distro:"
+
+func isCycloneDX(fmtID sbom.FormatID) bool {
+	return fmtID == cyclonedxjson.ID || fmtID == cyclonedxxml.ID
+}
+
+// applyCycloneDXDistro re-reads the CycloneDX document to set the linux distribution of the SBOM when syft did not
+// find one. syft only considers top-level "operating-system" components, however other tools (and container images
+// described as the subject of the BOM) place the operating system component within the metadata component or nested
+// within other components.
+func applyCycloneDXDistro(s *sbom.SBOM, fmtID sbom.FormatID, reader io.ReadSeeker) error {
+	if s == nil || s.Artifacts.LinuxDistribution != nil || !isCycloneDX(fmtID) {
+		return nil
+	}
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to read CycloneDX document: %w", err)
+	}
+
+	fileFormat := cyclonedx.BOMFileFormatJSON
+	if fmtID == cyclonedxxml.ID {
+		fileFormat = cyclonedx.BOMFileFormatXML
+	}
+
+	bom := cyclonedx.BOM{}
+	if err := cyclonedx.NewBOMDecoder(reader, fileFormat).Decode(&bom); err != nil {
+		return fmt.Errorf("unable to read CycloneDX document: %w", err)
+	}
+
+	var candidates []cyclonedx.Component
+	if bom.Metadata != nil && bom.Metadata.Component != nil {
+		candidates = append(candidates, *bom.Metadata.Component)
+	}
+	if bom.Components != nil {
+		candidates = append(candidates, *bom.Components...)
+	}
+
+	if c := findOSComponent(candidates); c != nil {
+		s.Artifacts.LinuxDistribution = linuxReleaseFromOSComponent(c)
+	}
+	return nil
+}
+
+// findOSComponent returns the first "operating-system" component, searching the given components before any nested
+// components.
+func findOSComponent(components []cyclonedx.Component) *cyclonedx.Component {
+	for i := range components {
+		if components[i].Type == cyclonedx.ComponentTypeOS {
+			return &components[i]
+		}
+	}
+	for i := range components {
+		if components[i].Components == nil {
+			continue
+		}
+		if c := findOSComponent(*components[i].Components); c != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// linuxReleaseFromOSComponent maps an "operating-system" component to a linux release, preferring the distro
+// properties written by syft over the SWID, name, and version of the component.
+func linuxReleaseFromOSComponent(c *cyclonedx.Component) *linux.Release {
+	var name, version string
+	if c.SWID != nil {
+		name = c.SWID.Name
+		version = c.SWID.Version
+	}
+	if name == "" {
+		name = c.Name
+	}
+	if version == "" {
+		version = c.Version
+	}
+
+	rel := &linux.Release{
+		PrettyName: c.Description,
+		Name:       name,
+		ID:         name,
+		Version:    version,
+		VersionID:  version,
+		CPEName:    c.CPE,
+	}
+
+	if c.Properties != nil {
+		for _, p := range *c.Properties {
+			field, ok := strings.CutPrefix(p.Name, cycloneDXDistroPropertyPrefix)
+			if !ok || p.Value == "" {
+				continue
+			}
+			switch {
+			case field == "id":
+				rel.ID = p.Value
+			case field == "name":
+				rel.Name = p.Value
+			case field == "versionID":
+				rel.VersionID = p.Value
+			case field == "version":
+				rel.Version = p.Value
+			case field == "versionCodename":
+				rel.VersionCodename = p.Value
+			case field == "prettyName":
+				rel.PrettyName = p.Value
+			case strings.HasPrefix(field, "idLike"):
+				// e.g. "syft:distro:idLike:0"
+				rel.IDLike = append(rel.IDLike, p.Value)
+			}
+		}
+	}
+
+	if rel.PrettyName == "" {
+		rel.PrettyName = rel.Name
+	}
+
+	return rel
+}
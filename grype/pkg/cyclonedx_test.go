@@ -0,0 +1,141 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft/format/cyclonedxjson"
+	"github.com/anchore/syft/syft/format/cyclonedxxml"
+	"github.com/anchore/syft/syft/linux"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+func Test_applyCycloneDXDistro(t *testing.T) {
+	tests := []struct {
+		name     string
+		fmtID    sbom.FormatID
+		document string
+		existing *linux.Release
+		expected *linux.Release
+	}{
+		{
+			name:  "operating system in the metadata component",
+			fmtID: cyclonedxjson.ID,
+			document: `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {
+    "component": {"type": "operating-system", "name": "alpine", "version": "3.18.4"}
+  },
+  "components": [{"type": "library", "name": "musl", "version": "1.2.4-r2"}]
+}`,
+			expected: &linux.Release{
+				PrettyName: "alpine",
+				Name:       "alpine",
+				ID:         "alpine",
+				Version:    "3.18.4",
+				VersionID:  "3.18.4",
+			},
+		},
+		{
+			name:  "operating system nested in the metadata component with syft distro properties",
+			fmtID: cyclonedxjson.ID,
+			document: `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {
+    "component": {
+      "type": "container",
+      "name": "my-image",
+      "components": [
+        {
+          "type": "operating-system",
+          "name": "ubuntu",
+          "version": "20.04",
+          "description": "Ubuntu 20.04.6 LTS",
+          "properties": [
+            {"name": "syft:distro:id", "value": "ubuntu"},
+            {"name": "syft:distro:idLike:0", "value": "debian"},
+            {"name": "syft:distro:versionCodename", "value": "focal"},
+            {"name": "syft:distro:versionID", "value": "20.04"}
+          ]
+        }
+      ]
+    }
+  },
+  "components": [{"type": "library", "name": "libc6", "version": "2.31-0ubuntu9.9"}]
+}`,
+			expected: &linux.Release{
+				PrettyName:      "Ubuntu 20.04.6 LTS",
+				Name:            "ubuntu",
+				ID:              "ubuntu",
+				IDLike:          []string{"debian"},
+				Version:         "20.04",
+				VersionID:       "20.04",
+				VersionCodename: "focal",
+			},
+		},
+		{
+			name:  "operating system in an XML document",
+			fmtID: cyclonedxxml.ID,
+			document: `<?xml version="1.0" encoding="UTF-8"?>
+<bom xmlns="http://cyclonedx.org/schema/bom/1.5" version="1">
+  <metadata>
+    <component type="operating-system">
+      <name>debian</name>
+      <version>8</version>
+    </component>
+  </metadata>
+  <components>
+    <component type="library">
+      <name>apt-dev</name>
+      <version>1.8.2</version>
+    </component>
+  </components>
+</bom>`,
+			expected: &linux.Release{
+				PrettyName: "debian",
+				Name:       "debian",
+				ID:         "debian",
+				Version:    "8",
+				VersionID:  "8",
+			},
+		},
+		{
+			name:  "no operating system component",
+			fmtID: cyclonedxjson.ID,
+			document: `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {"component": {"type": "container", "name": "my-image"}},
+  "components": [{"type": "library", "name": "musl", "version": "1.2.4-r2"}]
+}`,
+		},
+		{
+			name:     "the distro found by syft is kept",
+			fmtID:    cyclonedxjson.ID,
+			document: `not read`,
+			existing: &linux.Release{ID: "alpine", VersionID: "3.18.4"},
+			expected: &linux.Release{ID: "alpine", VersionID: "3.18.4"},
+		},
+		{
+			name:     "other formats are not read",
+			fmtID:    "spdx-json",
+			document: `not read`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &sbom.SBOM{
+				Artifacts: sbom.Artifacts{
+					LinuxDistribution: tt.existing,
+				},
+			}
+			require.NoError(t, applyCycloneDXDistro(s, tt.fmtID, strings.NewReader(tt.document)))
+			assert.Equal(t, tt.expected, s.Artifacts.LinuxDistribution)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package pkg
+
+import (
+	"github.com/anchore/packageurl-go"
+	"github.com/anchore/syft/syft/artifact"
+	syftPkg "github.com/anchore/syft/syft/pkg"
+)
+
+// moduleEnhancer returns an Enhancer that sets the Module of each package to the name of the package that contains
+// it (per the SBOM relationships), e.g. the project within a monorepo SBOM or the parent component within a
+// CycloneDX SBOM with nested components.
+func moduleEnhancer(relationships []artifact.Relationship) Enhancer {
+	parents := make(map[artifact.ID]string)
+	for _, r := range relationships {
+		if r.Type != artifact.ContainsRelationship {
+			continue
+		}
+		parent, ok := r.From.(syftPkg.Package)
+		if !ok {
+			continue
+		}
+		if _, ok := r.To.(syftPkg.Package); !ok {
+			continue
+		}
+		parents[r.To.ID()] = parent.Name
+	}
+
+	return func(out *Package, _ packageurl.PackageURL, p syftPkg.Package) {
+		if module, ok := parents[p.ID()]; ok {
+			out.Module = module
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/anchore/syft/syft/artifact"
+	syftPkg "github.com/anchore/syft/syft/pkg"
+)
+
+func Test_moduleEnhancer(t *testing.T) {
+	project := syftPkg.Package{Name: "frontend", Version: "1.0.0", Type: syftPkg.NpmPkg}
+	project.SetID()
+	dependency := syftPkg.Package{Name: "lodash", Version: "4.17.20", Type: syftPkg.NpmPkg}
+	dependency.SetID()
+	transitive := syftPkg.Package{Name: "left-pad", Version: "1.3.0", Type: syftPkg.NpmPkg}
+	transitive.SetID()
+
+	relationships := []artifact.Relationship{
+		{
+			From: project,
+			To:   dependency,
+			Type: artifact.ContainsRelationship,
+		},
+		{
+			// only containment is considered for modules
+			From: dependency,
+			To:   transitive,
+			Type: artifact.DependencyOfRelationship,
+		},
+	}
+
+	collection := syftPkg.NewCollection(project, dependency, transitive)
+	packages := FromCollection(collection, SynthesisConfig{}, moduleEnhancer(relationships))
+
+	modules := make(map[string]string)
+	for _, p := range packages {
+		modules[p.Name] = p.Module
+	}
+
+	assert.Equal(t, map[string]string{
+		"frontend": "",
+		"lodash":   "frontend",
+		"left-pad": "",
+	}, modules)
+}
@@ -43,6 +43,7 @@ type Package struct {
 	PURL      string       // the Package URL (see https://github.com/package-url/purl-spec)
 	Upstreams []UpstreamPackage
 	Metadata  interface{} // This is NOT 1-for-1 the syft metadata! Only the select data needed for vulnerability matching
+	Module    string      // the name of the package that contains this package, derived from SBOM relationships (if any)
 }
 
 func New(p syftPkg.Package, enhancers ...Enhancer) Package {
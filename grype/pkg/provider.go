@@ -3,6 +3,7 @@ package pkg
 import (
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/bmatcuk/doublestar/v2"
 
@@ -24,6 +25,23 @@ func Provide(userInput string, config ProviderConfig) ([]Package, Context, *sbom
 	return packages, ctx, s, nil
 }
 
+// ProvideFromReader provides a set of packages and context metadata from the SBOM document read from the given reader
+// (e.g. an SBOM piped from syft), using the same format detection as for SBOM files.
+func ProvideFromReader(reader io.Reader, config ProviderConfig) ([]Package, Context, *sbom.SBOM, error) {
+	packages, ctx, s, err := syftSBOMReaderProvider(reader, config)
+	if err != nil {
+		return nil, Context{}, nil, err
+	}
+	if len(config.Exclusions) > 0 {
+		packages, err = filterPackageExclusions(packages, config.Exclusions)
+		if err != nil {
+			return nil, Context{}, nil, err
+		}
+	}
+	setContextDistro(packages, &ctx)
+	return packages, ctx, s, nil
+}
+
 // Provide a set of packages and context metadata describing where they were sourced from.
 func provide(userInput string, config ProviderConfig) ([]Package, Context, *sbom.SBOM, error) {
 	packages, ctx, s, err := purlProvider(userInput, config)
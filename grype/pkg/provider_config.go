@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"github.com/anchore/grype/grype/distro"
 	"github.com/anchore/stereoscope/pkg/image"
 	"github.com/anchore/syft/syft"
 )
@@ -17,6 +18,9 @@ type SyftProviderConfig struct {
 	Exclusions             []string
 	Name                   string
 	DefaultImagePullSource string
+	// Distro, when set, is used as the distro of the packages read from an SBOM document, taking precedence over any
+	// distro declared within the SBOM (e.g. for an SBOM missing the OS release information).
+	Distro *distro.Distro
 }
 
 type SynthesisConfig struct {
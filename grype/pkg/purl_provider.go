@@ -5,7 +5,9 @@ import (
 	"io"
 	"strings"
 
+	"github.com/anchore/packageurl-go"
 	"github.com/anchore/syft/syft/format"
+	syftPkg "github.com/anchore/syft/syft/pkg"
 	"github.com/anchore/syft/syft/sbom"
 	"github.com/anchore/syft/syft/source"
 )
@@ -19,6 +21,10 @@ type PURLLiteralMetadata struct {
 	PURL string
 }
 
+// purlLiteralEnhancers are the enhancers for a single package described only by a PURL given directly by the user.
+// PURLs read from a file are decoded as an SBOM and only use purlEnhancers (see sbomPackages).
+var purlLiteralEnhancers = []Enhancer{setUpstreamsFromPURL, setDistroFromPURL, setLanguageFromPURLType}
+
 func purlProvider(userInput string, config ProviderConfig) ([]Package, Context, *sbom.SBOM, error) {
 	reader, ctx, err := getPurlReader(userInput)
 	if err != nil {
@@ -30,10 +36,16 @@ func purlProvider(userInput string, config ProviderConfig) ([]Package, Context,
 		return nil, Context{}, nil, fmt.Errorf("unable to decode purl: %w", err)
 	}
 
-	return FromCollection(s.Artifacts.Packages, config.SynthesisConfig, purlEnhancers...), ctx, s, nil
+	return FromCollection(s.Artifacts.Packages, config.SynthesisConfig, purlLiteralEnhancers...), ctx, s, nil
 }
 
 func getPurlReader(userInput string) (r io.Reader, ctx Context, err error) {
+	// a single PURL may be given explicitly (e.g. "purl:pkg:npm/lodash@4.17.15"), otherwise the "purl:" prefix refers
+	// to a file listing PURLs (which is read as an SBOM)
+	if literal, ok := strings.CutPrefix(userInput, purlInputPrefix); ok && strings.HasPrefix(literal, singlePurlInputPrefix) {
+		userInput = literal
+	}
+
 	if strings.HasPrefix(userInput, singlePurlInputPrefix) {
 		ctx.Source = &source.Description{
 			Metadata: PURLLiteralMetadata{
@@ -44,3 +56,15 @@ func getPurlReader(userInput string) (r io.Reader, ctx Context, err error) {
 	}
 	return nil, ctx, errDoesNotProvide
 }
+
+// setLanguageFromPURLType sets the language of a package with a PURL type unknown to syft to the PURL type (other than
+// the "generic" type), allowing for the package to be matched within the language namespace of the ecosystem.
+func setLanguageFromPURLType(out *Package, purl packageurl.PackageURL, _ syftPkg.Package) {
+	if out.Type != syftPkg.UnknownPkg || out.Language != syftPkg.UnknownLanguage {
+		return
+	}
+	if purl.Type == "" || purl.Type == packageurl.TypeGeneric {
+		return
+	}
+	out.Language = syftPkg.Language(purl.Type)
+}
@@ -279,6 +279,59 @@ func Test_PurlProvider(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:      "takes a single purl with the purl prefix",
+			userInput: "purl:pkg:npm/lodash@4.17.15",
+			context: Context{
+				Source: &source.Description{
+					Metadata: PURLLiteralMetadata{PURL: "pkg:npm/lodash@4.17.15"},
+				},
+			},
+			pkgs: []Package{
+				{
+					Name:     "lodash",
+					Version:  "4.17.15",
+					Type:     pkg.NpmPkg,
+					Language: pkg.JavaScript,
+					PURL:     "pkg:npm/lodash@4.17.15",
+				},
+			},
+		},
+		{
+			name:      "language from a purl type unknown to syft",
+			userInput: "pkg:idris/my-package@1.0.5",
+			context: Context{
+				Source: &source.Description{
+					Metadata: PURLLiteralMetadata{PURL: "pkg:idris/my-package@1.0.5"},
+				},
+			},
+			pkgs: []Package{
+				{
+					Name:     "my-package",
+					Version:  "1.0.5",
+					Type:     pkg.UnknownPkg,
+					Language: "idris",
+					PURL:     "pkg:idris/my-package@1.0.5",
+				},
+			},
+		},
+		{
+			name:      "no language from a generic purl",
+			userInput: "pkg:generic/my-package@1.0.5",
+			context: Context{
+				Source: &source.Description{
+					Metadata: PURLLiteralMetadata{PURL: "pkg:generic/my-package@1.0.5"},
+				},
+			},
+			pkgs: []Package{
+				{
+					Name:    "my-package",
+					Version: "1.0.5",
+					Type:    pkg.UnknownPkg,
+					PURL:    "pkg:generic/my-package@1.0.5",
+				},
+			},
+		},
 		{
 			name:      "fails on purl list input",
 			userInput: "purl:test-fixtures/purl/invalid-purl.txt",
@@ -0,0 +1,205 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	spdxJSON "github.com/spdx/tools-golang/json"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/tagvalue"
+
+	"github.com/anchore/grype/internal/log"
+	"github.com/anchore/packageurl-go"
+	"github.com/anchore/syft/syft/artifact"
+	"github.com/anchore/syft/syft/cpe"
+	"github.com/anchore/syft/syft/format/spdxjson"
+	"github.com/anchore/syft/syft/format/spdxtagvalue"
+	syftPkg "github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+const (
+	spdxPurlRefType         = "purl"
+	spdxCpe22RefType        = "cpe22Type"
+	spdxMavenCentralRefType = "maven-central"
+	spdxNpmRefType          = "npm"
+	spdxNugetRefType        = "nuget"
+)
+
+// spdxPackageRefs are the identifiers from the external references of an SPDX package, which are only partially
+// carried over when syft decodes the document (only the first "purl" reference and any "cpe23Type" references).
+type spdxPackageRefs struct {
+	purls []string
+	cpes  []string
+}
+
+func isSPDX(fmtID sbom.FormatID) bool {
+	return fmtID == spdxjson.ID || fmtID == spdxtagvalue.ID
+}
+
+// applySPDXPackageRefs re-reads the SPDX document and updates the decoded packages with the identifiers from the
+// external references of each package that syft does not use:
+//   - when a package has multiple PURLs, the first PURL with a known package type is used (e.g. a "pkg:deb/..." PURL
+//     over a "pkg:generic/..." PURL), setting the package type and language from it
+//   - when a package has no "purl" reference, the PURL is derived from a package manager reference ("maven-central",
+//     "npm", or "nuget") or taken from a reference of another type with a PURL locator
+//   - CPEs from "cpe22Type" references are added alongside the "cpe23Type" references
+func applySPDXPackageRefs(s *sbom.SBOM, fmtID sbom.FormatID, reader io.ReadSeeker) error {
+	if s == nil || s.Artifacts.Packages == nil || !isSPDX(fmtID) {
+		return nil
+	}
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to read SPDX document: %w", err)
+	}
+
+	var doc *spdx.Document
+	var err error
+	if fmtID == spdxjson.ID {
+		doc, err = spdxJSON.Read(reader)
+	} else {
+		doc, err = tagvalue.Read(reader)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read SPDX document: %w", err)
+	}
+
+	for _, p := range doc.Packages {
+		if p == nil || p.PackageSPDXIdentifier == "" {
+			continue
+		}
+		existing := s.Artifacts.Packages.Package(artifact.ID(p.PackageSPDXIdentifier))
+		if existing == nil {
+			// not all SPDX packages are packages in the syft model (e.g. the package describing the source)
+			continue
+		}
+
+		updated, changed := applyRefs(*existing, refsFromSPDXPackage(p))
+		if changed {
+			s.Artifacts.Packages.Delete(existing.ID())
+			s.Artifacts.Packages.Add(updated)
+		}
+	}
+	return nil
+}
+
+func refsFromSPDXPackage(p *spdx.Package) spdxPackageRefs {
+	var refs spdxPackageRefs
+	var otherPURLs []string
+	for _, r := range p.PackageExternalReferences {
+		if r == nil {
+			continue
+		}
+		locator := strings.TrimSpace(r.Locator)
+		switch {
+		case r.RefType == spdxPurlRefType:
+			refs.purls = append(refs.purls, locator)
+		case r.RefType == spdxCpe22RefType:
+			refs.cpes = append(refs.cpes, locator)
+		case strings.HasPrefix(locator, "pkg:"):
+			otherPURLs = append(otherPURLs, locator)
+		default:
+			if purl := purlFromPackageManagerRef(r.RefType, locator); purl != "" {
+				otherPURLs = append(otherPURLs, purl)
+			}
+		}
+	}
+	if len(refs.purls) == 0 {
+		refs.purls = otherPURLs
+	}
+	return refs
+}
+
+// purlFromPackageManagerRef converts the locator of an SPDX package manager reference (see SPDX spec Appendix VI) into
+// a PURL, returning an empty string for unsupported reference types or malformed locators.
+func purlFromPackageManagerRef(refType, locator string) string {
+	switch refType {
+	case spdxMavenCentralRefType:
+		// e.g. "org.apache.tomcat:tomcat:9.0.0.M4"
+		fields := strings.Split(locator, ":")
+		if len(fields) != 3 || fields[0] == "" || fields[1] == "" || fields[2] == "" {
+			return ""
+		}
+		return packageurl.NewPackageURL(packageurl.TypeMaven, fields[0], fields[1], fields[2], nil, "").String()
+	case spdxNpmRefType:
+		// e.g. "http-server@0.3.0" or "@angular/core@16.0.0"
+		idx := strings.LastIndex(locator, "@")
+		if idx <= 0 || idx == len(locator)-1 {
+			return ""
+		}
+		namespace, name := "", locator[:idx]
+		if i := strings.Index(name, "/"); strings.HasPrefix(name, "@") && i > 0 {
+			namespace, name = name[:i], name[i+1:]
+		}
+		return packageurl.NewPackageURL(packageurl.TypeNPM, namespace, name, locator[idx+1:], nil, "").String()
+	case spdxNugetRefType:
+		// e.g. "Microsoft.AspNet.MVC/5.0.0"
+		fields := strings.Split(locator, "/")
+		if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+			return ""
+		}
+		return packageurl.NewPackageURL(packageurl.TypeNuget, "", fields[0], fields[1], nil, "").String()
+	}
+	return ""
+}
+
+func applyRefs(p syftPkg.Package, refs spdxPackageRefs) (syftPkg.Package, bool) {
+	var changed bool
+
+	if purl, ok := preferredPURL(refs.purls); ok && purl.String() != p.PURL && isUnknownPkgType(p.Type) {
+		p.PURL = purl.String()
+		p.Type = syftPkg.TypeByName(purl.Type)
+		p.Language = syftPkg.LanguageByName(purl.Type)
+		changed = true
+	}
+
+	for _, value := range refs.cpes {
+		c, err := cpe.New(value, cpe.DeclaredSource)
+		if err != nil {
+			log.WithFields("cpe", value, "package", p.Name, "error", err).Debug("unable to parse SPDX CPE")
+			continue
+		}
+		if !containsCPE(p.CPEs, c) {
+			p.CPEs = append(p.CPEs, c)
+			changed = true
+		}
+	}
+
+	return p, changed
+}
+
+// preferredPURL returns the first PURL with a known package type, otherwise the first valid PURL.
+func preferredPURL(values []string) (packageurl.PackageURL, bool) {
+	var first *packageurl.PackageURL
+	for _, value := range values {
+		purl, err := packageurl.FromString(value)
+		if err != nil {
+			log.WithFields("purl", value, "error", err).Debug("unable to parse SPDX PURL")
+			continue
+		}
+		if !isUnknownPkgType(syftPkg.TypeByName(purl.Type)) {
+			return purl, true
+		}
+		if first == nil {
+			first = &purl
+		}
+	}
+	if first == nil {
+		return packageurl.PackageURL{}, false
+	}
+	return *first, true
+}
+
+func isUnknownPkgType(t syftPkg.Type) bool {
+	return t == "" || t == syftPkg.UnknownPkg
+}
+
+func containsCPE(cpes []cpe.CPE, c cpe.CPE) bool {
+	for _, existing := range cpes {
+		if existing.Attributes.BindToFmtString() == c.Attributes.BindToFmtString() {
+			return true
+		}
+	}
+	return false
+}
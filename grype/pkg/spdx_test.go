@@ -0,0 +1,165 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/syft/syft/cpe"
+	syftPkg "github.com/anchore/syft/syft/pkg"
+)
+
+func Test_purlFromPackageManagerRef(t *testing.T) {
+	tests := []struct {
+		refType  string
+		locator  string
+		expected string
+	}{
+		{
+			refType:  "maven-central",
+			locator:  "org.apache.tomcat:tomcat:9.0.0.M4",
+			expected: "pkg:maven/org.apache.tomcat/tomcat@9.0.0.M4",
+		},
+		{
+			refType: "maven-central",
+			locator: "org.apache.tomcat:tomcat",
+		},
+		{
+			refType:  "npm",
+			locator:  "http-server@0.3.0",
+			expected: "pkg:npm/http-server@0.3.0",
+		},
+		{
+			refType:  "npm",
+			locator:  "@angular/core@16.0.0",
+			expected: "pkg:npm/%40angular/core@16.0.0",
+		},
+		{
+			refType: "npm",
+			locator: "@angular/core",
+		},
+		{
+			refType: "npm",
+			locator: "http-server@",
+		},
+		{
+			refType:  "nuget",
+			locator:  "Microsoft.AspNet.MVC/5.0.0",
+			expected: "pkg:nuget/Microsoft.AspNet.MVC@5.0.0",
+		},
+		{
+			refType: "nuget",
+			locator: "Microsoft.AspNet.MVC",
+		},
+		{
+			refType: "bower",
+			locator: "modernizr#2.6.2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.refType+" "+tt.locator, func(t *testing.T) {
+			assert.Equal(t, tt.expected, purlFromPackageManagerRef(tt.refType, tt.locator))
+		})
+	}
+}
+
+func Test_applyRefs(t *testing.T) {
+	tests := []struct {
+		name            string
+		pkg             syftPkg.Package
+		refs            []*spdx.PackageExternalReference
+		expectedChanged bool
+		expectedPURL    string
+		expectedType    syftPkg.Type
+		expectedLang    syftPkg.Language
+		expectedCPEs    []string
+	}{
+		{
+			name: "multiple PURLs prefer a known package type",
+			pkg:  syftPkg.Package{Name: "openssl", PURL: "pkg:generic/openssl@1.1.1", Type: syftPkg.UnknownPkg},
+			refs: []*spdx.PackageExternalReference{
+				{Category: "PACKAGE-MANAGER", RefType: "purl", Locator: "pkg:generic/openssl@1.1.1"},
+				{Category: "PACKAGE-MANAGER", RefType: "purl", Locator: "pkg:deb/debian/openssl@1.1.1?distro=debian-11"},
+			},
+			expectedChanged: true,
+			expectedPURL:    "pkg:deb/debian/openssl@1.1.1?distro=debian-11",
+			expectedType:    syftPkg.DebPkg,
+		},
+		{
+			name: "multiple PURLs keep the first known package type",
+			pkg:  syftPkg.Package{Name: "requests", PURL: "pkg:pypi/requests@2.0.0", Type: syftPkg.PythonPkg, Language: syftPkg.Python},
+			refs: []*spdx.PackageExternalReference{
+				{Category: "PACKAGE-MANAGER", RefType: "purl", Locator: "pkg:pypi/requests@2.0.0"},
+				{Category: "PACKAGE-MANAGER", RefType: "purl", Locator: "pkg:deb/debian/python3-requests@2.0.0"},
+			},
+			expectedPURL: "pkg:pypi/requests@2.0.0",
+			expectedType: syftPkg.PythonPkg,
+			expectedLang: syftPkg.Python,
+		},
+		{
+			name: "no PURL with a package manager reference",
+			pkg:  syftPkg.Package{Name: "log4j-core"},
+			refs: []*spdx.PackageExternalReference{
+				{Category: "PACKAGE-MANAGER", RefType: "maven-central", Locator: "org.apache.logging.log4j:log4j-core:2.14.1"},
+			},
+			expectedChanged: true,
+			expectedPURL:    "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1",
+			expectedType:    syftPkg.JavaPkg,
+			expectedLang:    syftPkg.Java,
+		},
+		{
+			name: "no PURL with a PURL locator in another reference type",
+			pkg:  syftPkg.Package{Name: "lodash"},
+			refs: []*spdx.PackageExternalReference{
+				{Category: "OTHER", RefType: "package-url", Locator: "pkg:npm/lodash@4.17.20"},
+			},
+			expectedChanged: true,
+			expectedPURL:    "pkg:npm/lodash@4.17.20",
+			expectedType:    syftPkg.NpmPkg,
+			expectedLang:    syftPkg.JavaScript,
+		},
+		{
+			name: "CPE 2.2 references are added",
+			pkg: syftPkg.Package{
+				Name: "my-package",
+				CPEs: []cpe.CPE{cpe.Must("cpe:2.3:a:my-package:my-package:1.0.5:*:*:*:*:*:*:*", cpe.DeclaredSource)},
+			},
+			refs: []*spdx.PackageExternalReference{
+				{Category: "SECURITY", RefType: "cpe23Type", Locator: "cpe:2.3:a:my-package:my-package:1.0.5:*:*:*:*:*:*:*"},
+				{Category: "SECURITY", RefType: "cpe22Type", Locator: "cpe:/a:my-package:my-package:1.0.5"},
+				{Category: "SECURITY", RefType: "cpe22Type", Locator: "cpe:/a:bogus:my-package:1.0.5"},
+			},
+			expectedChanged: true,
+			expectedCPEs: []string{
+				"cpe:2.3:a:my-package:my-package:1.0.5:*:*:*:*:*:*:*",
+				"cpe:2.3:a:bogus:my-package:1.0.5:*:*:*:*:*:*:*",
+			},
+		},
+		{
+			name: "invalid references are ignored",
+			pkg:  syftPkg.Package{Name: "my-package"},
+			refs: []*spdx.PackageExternalReference{
+				{Category: "PACKAGE-MANAGER", RefType: "purl", Locator: "not a purl"},
+				{Category: "SECURITY", RefType: "cpe22Type", Locator: "not a cpe"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs := refsFromSPDXPackage(&spdx.Package{PackageExternalReferences: tt.refs})
+			actual, changed := applyRefs(tt.pkg, refs)
+			require.Equal(t, tt.expectedChanged, changed)
+			assert.Equal(t, tt.expectedPURL, actual.PURL)
+			assert.Equal(t, tt.expectedType, actual.Type)
+			assert.Equal(t, tt.expectedLang, actual.Language)
+
+			var cpes []string
+			for _, c := range actual.CPEs {
+				cpes = append(cpes, c.Attributes.BindToFmtString())
+			}
+			assert.Equal(t, tt.expectedCPEs, cpes)
+		})
+	}
+}
@@ -37,7 +37,7 @@ func syftProvider(userInput string, config ProviderConfig) ([]Package, Context,
 
 	pkgCatalog := removePackagesByOverlap(s.Artifacts.Packages, s.Relationships, d)
 
-	packages := FromCollection(pkgCatalog, config.SynthesisConfig)
+	packages := FromCollection(pkgCatalog, config.SynthesisConfig, moduleEnhancer(s.Relationships))
 	pkgCtx := Context{
 		Source: &srcDescription,
 		Distro: d,
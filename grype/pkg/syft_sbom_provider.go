@@ -19,6 +19,9 @@ import (
 	"github.com/anchore/syft/syft/sbom"
 )
 
+// stdinSBOMInput is the user input indicating the SBOM document should be read from stdin.
+const stdinSBOMInput = "sbom:-"
+
 type SBOMFileMetadata struct {
 	Path string
 }
@@ -29,6 +32,29 @@ func syftSBOMProvider(userInput string, config ProviderConfig) ([]Package, Conte
 		return nil, Context{}, nil, err
 	}
 
+	return sbomPackages(s, fmtID, path, config)
+}
+
+// syftSBOMReaderProvider provides packages from the SBOM document read from the given reader, detecting the format of
+// the document the same as for SBOM files.
+func syftSBOMReaderProvider(r io.Reader, config ProviderConfig) ([]Package, Context, *sbom.SBOM, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, Context{}, nil, fmt.Errorf("failed reading SBOM: %w", err)
+	}
+
+	s, fmtID, err := readSBOM(bytes.NewReader(b))
+	if err != nil {
+		if errors.Is(err, errDoesNotProvide) {
+			err = fmt.Errorf("unable to detect the format of the SBOM document")
+		}
+		return nil, Context{}, nil, err
+	}
+
+	return sbomPackages(s, fmtID, "", config)
+}
+
+func sbomPackages(s *sbom.SBOM, fmtID sbom.FormatID, path string, config ProviderConfig) ([]Package, Context, *sbom.SBOM, error) {
 	src := s.Source
 	if src.Metadata == nil && path != "" {
 		src.Metadata = SBOMFileMetadata{
@@ -37,20 +63,39 @@ func syftSBOMProvider(userInput string, config ProviderConfig) ([]Package, Conte
 	}
 
 	d := distro.FromRelease(s.Artifacts.LinuxDistribution)
+	if config.Distro != nil {
+		log.WithFields("distro", config.Distro.String()).Debug("overriding the distro of the SBOM")
+		d = config.Distro
+	}
 
 	catalog := removePackagesByOverlap(s.Artifacts.Packages, s.Relationships, d)
 
-	var enhancers []Enhancer
+	enhancers := []Enhancer{moduleEnhancer(s.Relationships)}
 	if fmtID != syftjson.ID {
-		enhancers = purlEnhancers
+		enhancers = append(enhancers, purlEnhancers...)
+	}
+
+	packages := FromCollection(catalog, config.SynthesisConfig, enhancers...)
+	if config.Distro != nil {
+		overrideDistro(packages, config.Distro)
 	}
 
-	return FromCollection(catalog, config.SynthesisConfig, enhancers...), Context{
+	return packages, Context{
 		Source: &src,
 		Distro: d,
 	}, s, nil
 }
 
+// overrideDistro replaces the distro of packages that declare one (e.g. from a PURL "distro" qualifier), packages
+// without a distro are matched against the distro of the context.
+func overrideDistro(packages []Package, d *distro.Distro) {
+	for i := range packages {
+		if packages[i].Distro != nil {
+			packages[i].Distro = d
+		}
+	}
+}
+
 func getSBOM(userInput string) (*sbom.SBOM, sbom.FormatID, string, error) {
 	reader, path, err := getSBOMReader(userInput)
 	if err != nil {
@@ -71,6 +116,14 @@ func readSBOM(reader io.ReadSeeker) (*sbom.SBOM, sbom.FormatID, error) {
 		return nil, "", errDoesNotProvide
 	}
 
+	if err := applySPDXPackageRefs(s, fmtID, reader); err != nil {
+		log.WithFields("error", err).Warn("unable to read package external references from SPDX document")
+	}
+
+	if err := applyCycloneDXDistro(s, fmtID, reader); err != nil {
+		log.WithFields("error", err).Warn("unable to read operating system component from CycloneDX document")
+	}
+
 	return s, fmtID, nil
 }
 
@@ -86,6 +139,10 @@ func getSBOMReader(userInput string) (io.ReadSeeker, string, error) {
 		}
 		return decodeStdin(r)
 
+	case userInput == stdinSBOMInput:
+		// the user has explicitly asked for the SBOM to be read from stdin
+		return decodeStdin(os.Stdin)
+
 	case explicitlySpecifyingPurlList(userInput):
 		filepath := strings.TrimPrefix(userInput, purlInputPrefix)
 		return openFile(filepath)
@@ -1,6 +1,8 @@
 package pkg
 
 import (
+	"io"
+	"os"
 	"slices"
 	"strings"
 	"testing"
@@ -522,3 +524,84 @@ func Test_PurlList(t *testing.T) {
 		})
 	}
 }
+
+func Test_PurlList_LanguageNotInferredFromPURLType(t *testing.T) {
+	// only a single PURL given directly has its language inferred from the PURL type, PURLs read from a file do not
+	packages, _, _, err := Provide("purl:test-fixtures/purl/unknown-type-purl.txt", ProviderConfig{})
+	require.NoError(t, err)
+	require.Len(t, packages, 1)
+	assert.Equal(t, pkg.UnknownPkg, packages[0].Type)
+	assert.Equal(t, pkg.UnknownLanguage, packages[0].Language)
+
+	packages, _, _, err = Provide("purl:pkg:idris/my-package@1.0.5", ProviderConfig{})
+	require.NoError(t, err)
+	require.Len(t, packages, 1)
+	assert.Equal(t, pkg.Language("idris"), packages[0].Language)
+}
+
+func TestProvideFromReader(t *testing.T) {
+	const fixture = "test-fixtures/syft-multiple-ecosystems.json"
+
+	expected, _, _, err := Provide("sbom:"+fixture, ProviderConfig{})
+	require.NoError(t, err)
+	require.NotEmpty(t, expected)
+
+	// the reader is not seekable, the same as an SBOM piped to stdin
+	f, err := os.Open(fixture)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+
+	packages, _, s, err := ProvideFromReader(io.NopCloser(f), ProviderConfig{})
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	assert.ElementsMatch(t, packageNames(expected), packageNames(packages))
+
+	_, _, _, err = ProvideFromReader(strings.NewReader("not an sbom"), ProviderConfig{})
+	require.Error(t, err)
+}
+
+func TestProvide_SBOMFromStdin(t *testing.T) {
+	f, err := os.Open("test-fixtures/syft-multiple-ecosystems.json")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+
+	stdin := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() { os.Stdin = stdin })
+
+	packages, _, _, err := Provide("sbom:-", ProviderConfig{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, packages)
+}
+
+func TestProvide_SBOMDistroOverride(t *testing.T) {
+	const fixture = "sbom:test-fixtures/syft-multiple-ecosystems.json"
+
+	_, ctx, _, err := Provide(fixture, ProviderConfig{})
+	require.NoError(t, err)
+	require.NotNil(t, ctx.Distro)
+	require.Equal(t, distro.Alpine, ctx.Distro.Type)
+
+	// the explicit distro takes precedence over the distro declared in the SBOM
+	override := distro.NewFromNameVersion("debian", "11")
+	packages, ctx, _, err := Provide(fixture, ProviderConfig{
+		SyftProviderConfig: SyftProviderConfig{
+			Distro: override,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, override, ctx.Distro)
+	for _, p := range packages {
+		if p.Distro != nil {
+			assert.Equal(t, override, p.Distro, p.Name)
+		}
+	}
+}
+
+func packageNames(packages []Package) []string {
+	var names []string
+	for _, p := range packages {
+		names = append(names, p.Name)
+	}
+	return names
+}
@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/anchore/grype/grype/internal/packagemetadata"
 	"github.com/anchore/grype/grype/match"
 	"github.com/anchore/grype/grype/pkg"
 	"github.com/anchore/grype/grype/version"
 	"github.com/anchore/grype/grype/vulnerability"
 	"github.com/anchore/grype/internal/log"
+	"github.com/anchore/syft/syft/file"
 )
 
 // Match is a single item for the JSON array reported
@@ -17,6 +19,14 @@ type Match struct {
 	RelatedVulnerabilities []VulnerabilityMetadata `json:"relatedVulnerabilities"`
 	MatchDetails           []MatchDetails          `json:"matchDetails"`
 	Artifact               Package                 `json:"artifact"`
+	Evidence               *MatchEvidence          `json:"evidence,omitempty"`
+}
+
+// MatchEvidence is the SBOM evidence that led to the artifact being identified (only present when requested)
+type MatchEvidence struct {
+	Locations    file.Locations `json:"locations"`
+	MetadataType string         `json:"metadataType,omitempty"`
+	Metadata     interface{}    `json:"metadata,omitempty"`
 }
 
 // MatchDetails contains all data that indicates how the result match was found
@@ -69,14 +79,35 @@ func newMatch(m match.Match, p pkg.Package, metadataProvider vulnerability.Metad
 		}
 	}
 
+	vuln := NewVulnerability(m.Vulnerability, metadata, format)
+	vuln.Fix.UpgradeTo = m.Vulnerability.UpgradeTarget(p.Version, format)
+
 	return &Match{
-		Vulnerability:          NewVulnerability(m.Vulnerability, metadata, format),
+		Vulnerability:          vuln,
 		Artifact:               newPackage(p),
 		RelatedVulnerabilities: relatedVulnerabilities,
 		MatchDetails:           details,
+		Evidence:               newMatchEvidence(m.Evidence),
 	}, nil
 }
 
+func newMatchEvidence(e *match.Evidence) *MatchEvidence {
+	if e == nil {
+		return nil
+	}
+
+	locations := e.Locations
+	if locations == nil {
+		locations = make([]file.Location, 0)
+	}
+
+	return &MatchEvidence{
+		Locations:    locations,
+		MetadataType: packagemetadata.JSONName(e.Metadata),
+		Metadata:     e.Metadata,
+	}
+}
+
 func getFix(m match.Match, p pkg.Package, format version.Format) *FixDetails {
 	suggested := calculateSuggestedFixedVersion(p, m.Vulnerability.Fix.Versions, format)
 	if suggested == "" {
@@ -21,6 +21,7 @@ type Package struct {
 	Upstreams    []UpstreamPackage `json:"upstreams"`
 	MetadataType string            `json:"metadataType,omitempty"`
 	Metadata     interface{}       `json:"metadata,omitempty"`
+	Module       string            `json:"module,omitempty"`
 }
 
 type UpstreamPackage struct {
@@ -61,5 +62,6 @@ func newPackage(p pkg.Package) Package {
 		Upstreams:    upstreams,
 		MetadataType: packagemetadata.JSONName(p.Metadata),
 		Metadata:     p.Metadata,
+		Module:       p.Module,
 	}
 }
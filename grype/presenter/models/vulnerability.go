@@ -16,8 +16,9 @@ type Vulnerability struct {
 }
 
 type Fix struct {
-	Versions []string `json:"versions"`
-	State    string   `json:"state"`
+	Versions  []string `json:"versions"`
+	State     string   `json:"state"`
+	UpgradeTo string   `json:"upgradeTo,omitempty"` // the closest fixed-in version greater than the installed version of the matched package
 }
 
 type Advisory struct {
@@ -0,0 +1,23 @@
+package grype
+
+import (
+	"io"
+
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/vulnerability"
+)
+
+// FindVulnerabilitiesFromReader is the same as FindVulnerabilities, but matches against the packages of the SBOM
+// document read from the given reader (e.g. an SBOM piped from syft) instead of a user input. The SBOM format is
+// detected the same as for "sbom:<path>" inputs. Note that "sbom:-" may also be given to FindVulnerabilities to read
+// the SBOM document from stdin.
+func FindVulnerabilitiesFromReader(store vulnerability.Provider, reader io.Reader) (match.Matches, pkg.Context, []pkg.Package, error) {
+	packages, context, _, err := pkg.ProvideFromReader(reader, pkg.ProviderConfig{})
+	if err != nil {
+		return match.Matches{}, pkg.Context{}, nil, err
+	}
+
+	matches, err := FindVulnerabilitiesForPackages(store, packages, &context)
+	return matches, context, packages, err
+}
@@ -0,0 +1,89 @@
+package grype
+
+import (
+	"runtime/debug"
+	"time"
+
+	v5 "github.com/anchore/grype/grype/db/v5"
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/vulnerability"
+	"github.com/anchore/grype/internal/log"
+	"github.com/anchore/stereoscope/pkg/image"
+	"github.com/anchore/syft/syft/source"
+)
+
+// ScanContext describes the circumstances of a scan, allowing for downstream reports to be self-describing.
+type ScanContext struct {
+	// Target is the user input that was scanned (e.g. an image reference or SBOM path)
+	Target string `json:"target"`
+	// Timestamp is when the scan was performed
+	Timestamp time.Time `json:"timestamp"`
+	// GrypeVersion is the version of the grype module used to perform the scan (empty if unknown)
+	GrypeVersion string `json:"grypeVersion,omitempty"`
+	// DBID identifies the vulnerability DB used for the scan (nil if the provider does not describe the DB ID)
+	DBID *v5.ID `json:"dbID,omitempty"`
+	// Options are the (non-sensitive) options used for the scan
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// NewScanContext captures the context for a scan of the given target against the given vulnerability provider. The
+// DB ID is fetched from the provider when it is able to describe it (see v5.IDReader).
+func NewScanContext(target string, provider vulnerability.Provider, options map[string]string) ScanContext {
+	return ScanContext{
+		Target:       target,
+		Timestamp:    time.Now().UTC(),
+		GrypeVersion: grypeVersion(),
+		DBID:         dbID(provider),
+		Options:      options,
+	}
+}
+
+// FindVulnerabilitiesWithScanContext is the same as FindVulnerabilities, but additionally returns the context of the scan.
+func FindVulnerabilitiesWithScanContext(store vulnerability.Provider, userImageStr string, scopeOpt source.Scope, registryOptions *image.RegistryOptions) (match.Matches, pkg.Context, []pkg.Package, ScanContext, error) {
+	scanContext := NewScanContext(userImageStr, store, map[string]string{
+		"scope": scopeOpt.String(),
+	})
+
+	matches, context, packages, err := FindVulnerabilities(store, userImageStr, scopeOpt, registryOptions)
+	if err != nil {
+		return match.Matches{}, pkg.Context{}, nil, ScanContext{}, err
+	}
+
+	return matches, context, packages, scanContext, nil
+}
+
+func dbID(provider vulnerability.Provider) *v5.ID {
+	reader, ok := provider.(interface {
+		GetID() (*v5.ID, error)
+	})
+	if !ok {
+		return nil
+	}
+
+	id, err := reader.GetID()
+	if err != nil {
+		log.WithFields("error", err).Debug("unable to fetch vulnerability DB ID")
+		return nil
+	}
+	return id
+}
+
+func grypeVersion() string {
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	const grypeModule = "github.com/anchore/grype"
+	if buildInfo.Main.Path == grypeModule {
+		return buildInfo.Main.Version
+	}
+
+	for _, d := range buildInfo.Deps {
+		if d.Path == grypeModule {
+			return d.Version
+		}
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package grype
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v5 "github.com/anchore/grype/grype/db/v5"
+	"github.com/anchore/grype/grype/vulnerability"
+	"github.com/anchore/grype/grype/vulnerability/mock"
+)
+
+type idProvider struct {
+	vulnerability.Provider
+	id *v5.ID
+}
+
+func (p idProvider) GetID() (*v5.ID, error) {
+	return p.id, nil
+}
+
+func TestNewScanContext(t *testing.T) {
+	vp := mock.VulnerabilityProvider(testVulnerabilities()...)
+	id := v5.NewID(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+
+	before := time.Now().UTC()
+	actual := NewScanContext("alpine:latest", idProvider{Provider: vp, id: &id}, map[string]string{"scope": "squashed"})
+
+	assert.Equal(t, "alpine:latest", actual.Target)
+	assert.False(t, actual.Timestamp.Before(before))
+	assert.Equal(t, &id, actual.DBID)
+	assert.Equal(t, map[string]string{"scope": "squashed"}, actual.Options)
+
+	// providers which cannot describe the DB ID are still supported
+	assert.Nil(t, NewScanContext("alpine:latest", vp, nil).DBID)
+
+	encoded, err := json.Marshal(actual)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"dbID":{"build_timestamp":"2024-05-01T12:00:00Z","schema_version":5}`)
+}
@@ -41,6 +41,11 @@ func GetConstraint(constStr string, format Format) (Constraint, error) {
 	case UnknownFormat:
 		c, err = newFuzzyConstraint(constStr, "unknown")
 	default:
+		if _, ok := lookupCustomFormat(format); ok {
+			var g genericConstraint
+			g, err = newGenericConstraint(format, constStr)
+			return customConstraint{genericConstraint: g}, err
+		}
 		return nil, fmt.Errorf("could not find constraint for given format: %s", format)
 	}
 
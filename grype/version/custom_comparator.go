@@ -0,0 +1,114 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// VersionComparator compares two raw version values of a caller-defined version format. This returns -1, 0, or 1 if
+// version a is smaller, equal, or larger than version b, respectively.
+type VersionComparator interface {
+	Compare(a, b string) (int, error)
+}
+
+// VersionComparatorFunc is an adapter to allow the use of ordinary functions as a VersionComparator.
+type VersionComparatorFunc func(a, b string) (int, error)
+
+func (f VersionComparatorFunc) Compare(a, b string) (int, error) {
+	return f(a, b)
+}
+
+type customFormat struct {
+	name       string
+	comparator VersionComparator
+}
+
+// customFormats holds all formats registered with RegisterComparator. Custom formats are allocated format values
+// after all built-in formats.
+var customFormats = struct {
+	sync.RWMutex
+	byName   map[string]Format
+	byFormat map[Format]customFormat
+}{
+	byName:   make(map[string]Format),
+	byFormat: make(map[Format]customFormat),
+}
+
+// RegisterComparator adds support for a caller-defined version format (e.g. a proprietary build numbering scheme)
+// such that constraints tagged with the given format name will be evaluated with the given comparator. Packages
+// with an unknown version format are compared using the comparator of the constraint being evaluated, before any
+// attempt at fuzzy matching. Registering an already registered format name replaces the comparator. Built-in format
+// names cannot be overridden.
+func RegisterComparator(format string, cmp VersionComparator) (Format, error) {
+	name := strings.ToLower(strings.TrimSpace(format))
+	if name == "" {
+		return UnknownFormat, fmt.Errorf("no format name provided")
+	}
+	if cmp == nil {
+		return UnknownFormat, fmt.Errorf("no comparator provided for format %q", format)
+	}
+	if existing := parseBuiltinFormat(name); existing != UnknownFormat || name == strings.ToLower(UnknownFormat.String()) {
+		return UnknownFormat, fmt.Errorf("cannot override built-in version format %q", format)
+	}
+
+	customFormats.Lock()
+	defer customFormats.Unlock()
+
+	f, ok := customFormats.byName[name]
+	if !ok {
+		f = Format(len(formatStr) + len(customFormats.byName))
+		customFormats.byName[name] = f
+	}
+	customFormats.byFormat[f] = customFormat{
+		name:       name,
+		comparator: cmp,
+	}
+
+	return f, nil
+}
+
+func lookupCustomFormat(f Format) (customFormat, bool) {
+	customFormats.RLock()
+	defer customFormats.RUnlock()
+	c, ok := customFormats.byFormat[f]
+	return c, ok
+}
+
+func parseCustomFormat(userStr string) Format {
+	customFormats.RLock()
+	defer customFormats.RUnlock()
+	if f, ok := customFormats.byName[strings.ToLower(userStr)]; ok {
+		return f
+	}
+	return UnknownFormat
+}
+
+var _ Comparator = (*customVersion)(nil)
+
+type customVersion struct {
+	raw        string
+	comparator VersionComparator
+}
+
+func (v customVersion) Compare(other *Version) (int, error) {
+	if other == nil {
+		return -1, ErrNoVersionProvided
+	}
+	return v.comparator.Compare(v.raw, other.Raw)
+}
+
+var _ Constraint = (*customConstraint)(nil)
+
+// customConstraint is a range constraint for a registered custom format.
+type customConstraint struct {
+	genericConstraint
+}
+
+func (c customConstraint) Satisfied(version *Version) (bool, error) {
+	if version != nil && version.Format == UnknownFormat {
+		// the package version format could not be determined, so defer to the format of the constraint
+		version = NewVersion(version.Raw, c.Fmt)
+	}
+	return c.genericConstraint.Satisfied(version)
+}
@@ -0,0 +1,116 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildNumberComparator is an example of a proprietary version scheme, where versions are internal build numbers
+// in the form "build-<N>" (which would otherwise be compared lexically by the fuzzy comparator).
+var buildNumberComparator = VersionComparatorFunc(func(a, b string) (int, error) {
+	parse := func(v string) (int, error) {
+		n, err := strconv.Atoi(strings.TrimPrefix(v, "build-"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid build number %q: %w", v, err)
+		}
+		return n, nil
+	}
+
+	left, err := parse(a)
+	if err != nil {
+		return 0, err
+	}
+	right, err := parse(b)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case left < right:
+		return -1, nil
+	case left > right:
+		return 1, nil
+	}
+	return 0, nil
+})
+
+func TestRegisterComparator(t *testing.T) {
+	format, err := RegisterComparator("Acme-Build", buildNumberComparator)
+	require.NoError(t, err)
+
+	assert.Equal(t, format, ParseFormat("acme-build"))
+	assert.Equal(t, "acme-build", format.String())
+
+	// registering again replaces the comparator without allocating a new format
+	again, err := RegisterComparator("acme-build", buildNumberComparator)
+	require.NoError(t, err)
+	assert.Equal(t, format, again)
+
+	constraint, err := GetConstraint("< build-120", format)
+	require.NoError(t, err)
+	assert.Equal(t, format, constraint.Format())
+
+	tests := []struct {
+		name    string
+		version *Version
+		want    bool
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "unknown package format defers to the registered comparator",
+			version: NewVersion("build-99", UnknownFormat),
+			want:    true,
+		},
+		{
+			name:    "numeric rather than lexical comparison",
+			version: NewVersion("build-1000", UnknownFormat),
+			want:    false,
+		},
+		{
+			name:    "explicit custom format",
+			version: NewVersion("build-119", format),
+			want:    true,
+		},
+		{
+			name:    "other known formats are not comparable",
+			version: NewVersion("1.0.0", SemanticFormat),
+			want:    false,
+			wantErr: require.Error,
+		},
+		{
+			name:    "comparator errors are propagated",
+			version: NewVersion("nightly", UnknownFormat),
+			want:    false,
+			wantErr: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr == nil {
+				tt.wantErr = require.NoError
+			}
+			got, err := constraint.Satisfied(tt.version)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRegisterComparator_invalid(t *testing.T) {
+	_, err := RegisterComparator("semver", buildNumberComparator)
+	assert.Error(t, err)
+
+	_, err = RegisterComparator("unknown", buildNumberComparator)
+	assert.Error(t, err)
+
+	_, err = RegisterComparator("", buildNumberComparator)
+	assert.Error(t, err)
+
+	_, err = RegisterComparator("acme-nil", nil)
+	assert.Error(t, err)
+}
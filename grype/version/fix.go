@@ -0,0 +1,29 @@
+package version
+
+import "sort"
+
+// FixVersionsAbove returns the given fix versions (in the format of the given version) that are greater than the given
+// version, lowest first. Duplicates and fix versions that cannot be compared with the given version are skipped.
+func FixVersionsAbove(v *Version, fixVersions []string) []*Version {
+	seen := make(map[string]struct{})
+	var above []*Version
+	for _, raw := range fixVersions {
+		if _, ok := seen[raw]; ok {
+			continue
+		}
+		seen[raw] = struct{}{}
+
+		candidate := NewVersion(raw, v.Format)
+		if cmp, err := candidate.Compare(v); err != nil || cmp <= 0 {
+			continue
+		}
+		above = append(above, candidate)
+	}
+
+	sort.SliceStable(above, func(i, j int) bool {
+		cmp, err := above[i].Compare(above[j])
+		return err == nil && cmp < 0
+	})
+
+	return above
+}
@@ -0,0 +1,57 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixVersionsAbove(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     *Version
+		fixVersions []string
+		expected    []string
+	}{
+		{
+			name:        "no fix versions",
+			version:     NewVersion("1.0.0", SemanticFormat),
+			fixVersions: nil,
+			expected:    nil,
+		},
+		{
+			name:        "lowest first",
+			version:     NewVersion("1.0.0", SemanticFormat),
+			fixVersions: []string{"3.0.0", "1.2.0", "2.0.0"},
+			expected:    []string{"1.2.0", "2.0.0", "3.0.0"},
+		},
+		{
+			name:        "versions at or below are skipped",
+			version:     NewVersion("2.0.0", SemanticFormat),
+			fixVersions: []string{"1.0.0", "2.0.0", "2.0.1"},
+			expected:    []string{"2.0.1"},
+		},
+		{
+			name:        "duplicates are skipped",
+			version:     NewVersion("1.0.0", SemanticFormat),
+			fixVersions: []string{"1.1.0", "1.1.0"},
+			expected:    []string{"1.1.0"},
+		},
+		{
+			name:        "unparsable versions are skipped",
+			version:     NewVersion("1.0.0", SemanticFormat),
+			fixVersions: []string{"not-a-version!", "1.1.0"},
+			expected:    []string{"1.1.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual []string
+			for _, v := range FixVersionsAbove(tt.version, tt.fixVersions) {
+				actual = append(actual, v.Raw)
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
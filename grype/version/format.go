@@ -57,6 +57,13 @@ var Formats = []Format{
 }
 
 func ParseFormat(userStr string) Format {
+	if f := parseBuiltinFormat(userStr); f != UnknownFormat {
+		return f
+	}
+	return parseCustomFormat(userStr)
+}
+
+func parseBuiltinFormat(userStr string) Format {
 	switch strings.ToLower(userStr) {
 	case strings.ToLower(SemanticFormat.String()), "semver":
 		return SemanticFormat
@@ -118,6 +125,9 @@ func FormatFromPkg(p pkg.Package) Format {
 }
 
 func (f Format) String() string {
+	if c, ok := lookupCustomFormat(f); ok {
+		return c.name
+	}
 	if int(f) >= len(formatStr) || f < 0 {
 		return formatStr[0]
 	}
@@ -40,7 +40,7 @@ func newFuzzyConstraint(phrase, hint string) (fuzzyConstraint, error) {
 check:
 	for _, units := range constraints.Units {
 		for _, unit := range units {
-			if !pseudoSemverPattern.MatchString(unit.Version) {
+			if unit.Wildcard || !pseudoSemverPattern.MatchString(unit.Version) {
 				valid = false
 				break check
 			}
@@ -175,3 +175,56 @@ func TestGenericConstraint_Invalid(t *testing.T) {
 		})
 	}
 }
+
+func TestGenericConstraint_Satisfied_Wildcard(t *testing.T) {
+	tests := []struct {
+		constraint string
+		format     Format
+		version    string
+		expected   bool
+	}{
+		// semantic versions
+		{constraint: "1.2.*", format: SemanticFormat, version: "1.2.9", expected: true},
+		{constraint: "1.2.*", format: SemanticFormat, version: "1.2", expected: true},
+		{constraint: "1.2.*", format: SemanticFormat, version: "v1.2.0", expected: true},
+		{constraint: "1.2.*", format: SemanticFormat, version: "1.2.0-beta.1", expected: true},
+		{constraint: "1.2.*", format: SemanticFormat, version: "1.3.0", expected: false},
+		{constraint: "1.2.*", format: SemanticFormat, version: "1.3.0-alpha", expected: false},
+		{constraint: "1.2.*", format: SemanticFormat, version: "1.20.0", expected: false},
+		{constraint: "1.2.*", format: SemanticFormat, version: "1.1.9", expected: false},
+		{constraint: "1.2.*, < 1.2.5", format: SemanticFormat, version: "1.2.4", expected: true},
+		{constraint: "1.2.*, < 1.2.5", format: SemanticFormat, version: "1.2.9", expected: false},
+		{constraint: "1.2.* || 2.*", format: SemanticFormat, version: "2.0.1", expected: true},
+		// debian versions
+		{constraint: "1.2.*", format: DebFormat, version: "1.2.9-1", expected: true},
+		{constraint: "1.2.*", format: DebFormat, version: "0:1.2.9-1", expected: true},
+		{constraint: "1.2.*", format: DebFormat, version: "1.2~rc1-1", expected: true},
+		{constraint: "1.2.*", format: DebFormat, version: "1.3.0-1", expected: false},
+		{constraint: "1.2.*", format: DebFormat, version: "1.3~beta1-1", expected: false},
+		{constraint: "1.2.*", format: DebFormat, version: "1:1.2.9-1", expected: false},
+		{constraint: "1:1.2.*", format: DebFormat, version: "1:1.2.9-1", expected: true},
+		// rpm versions
+		{constraint: "1.2.*", format: RpmFormat, version: "1.2.9-1.el8", expected: true},
+		{constraint: "1.2.*", format: RpmFormat, version: "1.3.0-1.el8", expected: false},
+		// apk versions
+		{constraint: "1.2.*", format: ApkFormat, version: "1.2.9-r0", expected: true},
+		{constraint: "1.2.*", format: ApkFormat, version: "1.3.0-r0", expected: false},
+		// unknown (fuzzy) versions
+		{constraint: "1.2.*", format: UnknownFormat, version: "1.2.9", expected: true},
+		{constraint: "1.2.*", format: UnknownFormat, version: "1.3.0", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.constraint+" "+test.format.String()+" "+test.version, func(t *testing.T) {
+			constraint, err := GetConstraint(test.constraint, test.format)
+			require.NoError(t, err)
+
+			satisfied, err := constraint.Satisfied(NewVersion(test.version, test.format))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, satisfied)
+
+			// the wildcard is retained as-is as the description of the constraint (e.g. within match details)
+			assert.Contains(t, constraint.String(), test.constraint)
+		})
+	}
+}
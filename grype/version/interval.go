@@ -0,0 +1,157 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Interval is a contiguous range of versions. An empty bound is unbounded in that direction.
+type Interval struct {
+	Lower          string
+	LowerInclusive bool
+	Upper          string
+	UpperInclusive bool
+}
+
+func (i Interval) String() string {
+	var parts []string
+	if i.Lower != "" {
+		op := GT
+		if i.LowerInclusive {
+			op = GTE
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", op, i.Lower))
+	}
+	if i.Upper != "" {
+		op := LT
+		if i.UpperInclusive {
+			op = LTE
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", op, i.Upper))
+	}
+	if len(parts) == 0 {
+		return "*"
+	}
+	if i.Lower != "" && i.Lower == i.Upper {
+		return fmt.Sprintf("%s %s", EQ, i.Lower)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ConstraintIntervals returns the version intervals described by the given constraint phrase (one per "||" group).
+// An empty phrase matches all versions. Groups that cannot be satisfied by any version are omitted.
+func ConstraintIntervals(phrase string, format Format) ([]Interval, error) {
+	if strings.TrimSpace(phrase) == "" {
+		return []Interval{{}}, nil
+	}
+
+	expression, err := parseRangeExpression(phrase)
+	if err != nil {
+		return nil, err
+	}
+
+	var intervals []Interval
+	for _, group := range expression.Units {
+		interval, satisfiable := Interval{}, true
+		for _, unit := range group {
+			if unit.Wildcard {
+				return nil, fmt.Errorf("wildcard version %q cannot be represented as an interval", unit.Version+wildcardSuffix)
+			}
+			var bound Interval
+			switch unit.Operator {
+			case EQ:
+				bound = Interval{Lower: unit.Version, LowerInclusive: true, Upper: unit.Version, UpperInclusive: true}
+			case GT:
+				bound = Interval{Lower: unit.Version}
+			case GTE:
+				bound = Interval{Lower: unit.Version, LowerInclusive: true}
+			case LT:
+				bound = Interval{Upper: unit.Version}
+			case LTE:
+				bound = Interval{Upper: unit.Version, UpperInclusive: true}
+			default:
+				return nil, fmt.Errorf("unknown operator: %s", unit.Operator)
+			}
+
+			interval, satisfiable, err = IntersectIntervals(interval, bound, format)
+			if err != nil {
+				return nil, err
+			}
+			if !satisfiable {
+				break
+			}
+		}
+		if satisfiable {
+			intervals = append(intervals, interval)
+		}
+	}
+	return intervals, nil
+}
+
+// IntersectIntervals returns the intersection of the two intervals, and false if the intervals do not overlap.
+func IntersectIntervals(a, b Interval, format Format) (Interval, bool, error) {
+	result := a
+
+	if b.Lower != "" {
+		cmp, err := compareBounds(a.Lower, b.Lower, format)
+		if err != nil {
+			return Interval{}, false, err
+		}
+		switch {
+		case a.Lower == "" || cmp < 0:
+			result.Lower, result.LowerInclusive = b.Lower, b.LowerInclusive
+		case cmp == 0:
+			result.LowerInclusive = a.LowerInclusive && b.LowerInclusive
+		}
+	}
+
+	if b.Upper != "" {
+		cmp, err := compareBounds(a.Upper, b.Upper, format)
+		if err != nil {
+			return Interval{}, false, err
+		}
+		switch {
+		case a.Upper == "" || cmp > 0:
+			result.Upper, result.UpperInclusive = b.Upper, b.UpperInclusive
+		case cmp == 0:
+			result.UpperInclusive = a.UpperInclusive && b.UpperInclusive
+		}
+	}
+
+	empty, err := result.isEmpty(format)
+	if err != nil {
+		return Interval{}, false, err
+	}
+	return result, !empty, nil
+}
+
+// isEmpty indicates if no version can satisfy the interval bounds.
+func (i Interval) isEmpty(format Format) (bool, error) {
+	if i.Lower == "" || i.Upper == "" {
+		return false, nil
+	}
+	cmp, err := compareBounds(i.Lower, i.Upper, format)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case cmp > 0:
+		return true, nil
+	case cmp == 0:
+		return !i.LowerInclusive || !i.UpperInclusive, nil
+	}
+	return false, nil
+}
+
+// compareBounds compares two bound versions; an empty bound compares as equal to anything (callers are expected to
+// handle unbounded values explicitly).
+func compareBounds(a, b string, format Format) (int, error) {
+	if a == "" || b == "" {
+		return 0, nil
+	}
+	cmp, err := NewVersion(a, format).Compare(NewVersion(b, format))
+	if err != nil {
+		return 0, fmt.Errorf("uncomparable versions %q vs %q: %w", a, b, err)
+	}
+	return cmp, nil
+}
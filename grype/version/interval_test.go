@@ -0,0 +1,85 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraintIntervals(t *testing.T) {
+	tests := []struct {
+		phrase   string
+		expected []string
+	}{
+		{phrase: "", expected: []string{"*"}},
+		{phrase: ">= 1.0, < 2.0", expected: []string{">= 1.0, < 2.0"}},
+		{phrase: "> 1.0, >= 1.5, <= 3.0, < 4.0", expected: []string{">= 1.5, <= 3.0"}},
+		{phrase: "< 1.0 || = 2.0", expected: []string{"< 1.0", "= 2.0"}},
+		{phrase: "> 2.0, < 1.0 || >= 3.0", expected: []string{">= 3.0"}},
+		{phrase: ">= 1.0, < 1.0", expected: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.phrase, func(t *testing.T) {
+			intervals, err := ConstraintIntervals(test.phrase, SemanticFormat)
+			require.NoError(t, err)
+
+			var actual []string
+			for _, i := range intervals {
+				actual = append(actual, i.String())
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestIntersectIntervals(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Interval
+		expected string
+		overlaps bool
+	}{
+		{
+			name:     "overlapping ranges",
+			a:        Interval{Lower: "1.0", LowerInclusive: true, Upper: "2.0"},
+			b:        Interval{Lower: "1.5", Upper: "3.0"},
+			expected: "> 1.5, < 2.0",
+			overlaps: true,
+		},
+		{
+			name:     "touching inclusive bounds",
+			a:        Interval{Upper: "2.0", UpperInclusive: true},
+			b:        Interval{Lower: "2.0", LowerInclusive: true},
+			expected: "= 2.0",
+			overlaps: true,
+		},
+		{
+			name: "touching exclusive bound",
+			a:    Interval{Upper: "2.0"},
+			b:    Interval{Lower: "2.0", LowerInclusive: true},
+		},
+		{
+			name: "disjoint ranges",
+			a:    Interval{Lower: "1.0", Upper: "2.0"},
+			b:    Interval{Lower: "3.0"},
+		},
+		{
+			name:     "unbounded",
+			a:        Interval{},
+			b:        Interval{Lower: "3.0"},
+			expected: "> 3.0",
+			overlaps: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, ok, err := IntersectIntervals(test.a, test.b, SemanticFormat)
+			require.NoError(t, err)
+			assert.Equal(t, test.overlaps, ok)
+			if ok {
+				assert.Equal(t, test.expected, actual.String())
+			}
+		})
+	}
+}
@@ -12,7 +12,8 @@ const (
 
 type Operator string
 
-func parseOperator(op string) (Operator, error) {
+// ParseOperator parses a range operator, where an empty operator is treated as equality.
+func ParseOperator(op string) (Operator, error) {
 	switch op {
 	case string(EQ), "":
 		return EQ, nil
@@ -0,0 +1,81 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// constraintStringPattern matches the string representation of a constraint (see Constraint.String), which is the
+// constraint phrase followed by the lowercase format name in parentheses (e.g. "< 2.0 (unknown)").
+var constraintStringPattern = regexp.MustCompile(`^(.*?)\s*\(([^()\s]+)\)$`)
+
+// ParsedConstraint is a version constraint parsed from its string representation (see ParseConstraint), allowing for
+// checking if versions satisfy the constraint without constructing Version objects.
+type ParsedConstraint struct {
+	constraint Constraint
+}
+
+// ParseConstraint parses the string representation of a version constraint as reported in match details (e.g.
+// "< 2.0 (unknown)" or "< 1.2.3-r0 (apk)"), where the parenthesized format suffix determines the version format used
+// for comparisons. A constraint without a format suffix is parsed as the unknown (fuzzy) format, the same as a
+// constraint from a vulnerability record without a version format.
+func ParseConstraint(constStr string) (ParsedConstraint, error) {
+	phrase := strings.TrimSpace(constStr)
+	format := UnknownFormat
+
+	if m := constraintStringPattern.FindStringSubmatch(phrase); m != nil {
+		var err error
+		format, err = parseConstraintFormat(m[2])
+		if err != nil {
+			return ParsedConstraint{}, err
+		}
+		phrase = m[1]
+
+		// empty constraints are represented as "none", or an empty quoted string for the KB format
+		if phrase == "none" || phrase == `""` {
+			phrase = ""
+		}
+	}
+
+	c, err := GetConstraint(phrase, format)
+	if err != nil {
+		return ParsedConstraint{}, err
+	}
+	return ParsedConstraint{constraint: c}, nil
+}
+
+func parseConstraintFormat(name string) (Format, error) {
+	if strings.EqualFold(name, UnknownFormat.String()) {
+		return UnknownFormat, nil
+	}
+	format := ParseFormat(name)
+	if format == UnknownFormat {
+		return UnknownFormat, fmt.Errorf("unsupported constraint version format: %q", name)
+	}
+	return format, nil
+}
+
+// Constraint returns the underlying constraint.
+func (c ParsedConstraint) Constraint() Constraint {
+	return c.constraint
+}
+
+// Format returns the version format of the constraint.
+func (c ParsedConstraint) Format() Format {
+	return c.constraint.Format()
+}
+
+func (c ParsedConstraint) String() string {
+	return c.constraint.String()
+}
+
+// Satisfied indicates if the given version (in the format of the constraint) satisfies the constraint. An empty
+// version is treated as no version at all.
+func (c ParsedConstraint) Satisfied(version string) (bool, error) {
+	var v *Version
+	if version != "" {
+		v = NewVersion(version, c.constraint.Format())
+	}
+	return c.constraint.Satisfied(v)
+}
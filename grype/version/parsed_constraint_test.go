@@ -0,0 +1,108 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		format     Format
+		satisfied  map[string]bool
+		wantErr    require.ErrorAssertionFunc
+	}{
+		{
+			constraint: "< 2.0 (unknown)",
+			format:     UnknownFormat,
+			satisfied:  map[string]bool{"1.9.1": true, "1.0.5": true, "2.0": false, "2.0.1": false},
+		},
+		{
+			// no format suffix is the same as the unknown format
+			constraint: "< 2.0",
+			format:     UnknownFormat,
+			satisfied:  map[string]bool{"1.9.1": true, "2.0.1": false},
+		},
+		{
+			constraint: ">= 1.0.0, < 1.2.3 (semantic)",
+			format:     SemanticFormat,
+			satisfied:  map[string]bool{"0.9.0": false, "1.0.0": true, "1.2.2": true, "1.2.3": false},
+		},
+		{
+			constraint: "< 1.2.3-r4 (apk)",
+			format:     ApkFormat,
+			satisfied:  map[string]bool{"1.2.3-r3": true, "1.2.3-r4": false, "1.2.4-r0": false},
+		},
+		{
+			constraint: "< 2.4.52-1ubuntu4.3 (deb)",
+			format:     DebFormat,
+			satisfied:  map[string]bool{"2.4.52-1ubuntu4.2": true, "2.4.52-1ubuntu4.3": false, "1:2.4.0-1": false},
+		},
+		{
+			constraint: "< 1.0 || >= 2.0, < 2.1 (semantic)",
+			format:     SemanticFormat,
+			satisfied:  map[string]bool{"0.9": true, "1.5": false, "2.0.5": true, "2.1": false},
+		},
+		{
+			constraint: "none (deb)",
+			format:     DebFormat,
+			satisfied:  map[string]bool{"1.0-1": true},
+		},
+		{
+			constraint: "< 2.0 (not-a-format)",
+			wantErr:    require.Error,
+		},
+		{
+			constraint: "< 2.0 (apk",
+			wantErr:    require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			if tt.wantErr == nil {
+				tt.wantErr = require.NoError
+			}
+
+			c, err := ParseConstraint(tt.constraint)
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.format, c.Format())
+			for v, expected := range tt.satisfied {
+				actual, err := c.Satisfied(v)
+				require.NoError(t, err)
+				assert.Equal(t, expected, actual, "version %q", v)
+			}
+		})
+	}
+}
+
+func TestParseConstraint_RoundTrip(t *testing.T) {
+	for _, format := range []Format{UnknownFormat, SemanticFormat, ApkFormat, DebFormat, RpmFormat, PythonFormat} {
+		t.Run(format.String(), func(t *testing.T) {
+			original, err := GetConstraint("> 1.0, < 2.0", format)
+			require.NoError(t, err)
+
+			parsed, err := ParseConstraint(original.String())
+			require.NoError(t, err)
+			assert.Equal(t, original.Value(), parsed.Constraint().Value())
+			assert.Equal(t, original.Format(), parsed.Format())
+			assert.Equal(t, original.String(), parsed.String())
+		})
+	}
+}
+
+func TestParsedConstraint_Satisfied_NoVersion(t *testing.T) {
+	c, err := ParseConstraint("< 2.0 (semantic)")
+	require.NoError(t, err)
+
+	// a non-empty constraint is never satisfied without a version
+	satisfied, err := c.Satisfied("")
+	require.NoError(t, err)
+	assert.False(t, satisfied)
+}
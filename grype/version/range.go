@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/anchore/grype/internal/stringutil"
 )
@@ -16,8 +17,14 @@ var constraintPartPattern = regexp.MustCompile(`\s*(?P<prefix>[^><=a-zA-Z0-9().'
 type rangeUnit struct {
 	Operator Operator
 	Version  string
+	// Wildcard indicates that the unit matches all versions with the given version as a prefix (e.g. "1.2.*"), in
+	// which case Version is the prefix without the trailing wildcard (e.g. "1.2") and the operator is always EQ.
+	Wildcard bool
 }
 
+// wildcardSuffix is the suffix of a version that indicates all versions with the preceding prefix (e.g. "1.2.*").
+const wildcardSuffix = ".*"
+
 func parseRange(phrase string) (*rangeUnit, error) {
 	match := stringutil.MatchCaptureGroups(constraintPartPattern, phrase)
 	version, exists := match["version"]
@@ -45,13 +52,23 @@ func parseRange(phrase string) (*rangeUnit, error) {
 		version = unquoted
 	}
 
-	op, err := parseOperator(opStr)
+	op, err := ParseOperator(opStr)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse constraint operator=%q: %+v", opStr, err)
 	}
+
+	var wildcard bool
+	if prefix, ok := strings.CutSuffix(version, wildcardSuffix); ok && prefix != "" {
+		if op != EQ {
+			return nil, fmt.Errorf("wildcard version %q may only be used with the %q operator", version, EQ)
+		}
+		version, wildcard = prefix, true
+	}
+
 	return &rangeUnit{
 		Operator: op,
 		Version:  version,
+		Wildcard: wildcard,
 	}, nil
 }
 
@@ -87,6 +104,37 @@ func (c *rangeUnit) Satisfied(comparison int) bool {
 	}
 }
 
+// satisfiedByPrefix indicates if the given version is within the series of versions described by a wildcard unit (e.g.
+// "1.2.*" includes "1.2", "1.2.9", and "1.2-r1", but not "1.20" or "1.3.0"). Version format specific prefixes that do
+// not affect the version series (e.g. a leading "v" for semantic versions or an implied zero epoch for distro
+// versions) are normalized on both sides before comparing.
+func (c *rangeUnit) satisfiedByPrefix(format Format, version string) bool {
+	prefix := normalizeVersionPrefix(format, c.Version)
+	version = normalizeVersionPrefix(format, version)
+
+	rest, ok := strings.CutPrefix(version, prefix)
+	if !ok {
+		return false
+	}
+	// the prefix must end at a component boundary (e.g. "1.2" is not a prefix of "1.20")
+	return rest == "" || !unicode.IsDigit(rune(rest[0]))
+}
+
+func normalizeVersionPrefix(format Format, version string) string {
+	switch format {
+	case SemanticFormat, GolangFormat:
+		return strings.TrimPrefix(version, "v")
+	case DebFormat, RpmFormat:
+		if epoch, rest, ok := strings.Cut(version, ":"); ok {
+			if strings.TrimLeft(epoch, "0") == "" {
+				return rest
+			}
+			return version
+		}
+	}
+	return version
+}
+
 // validateVersion scans the version string and validates characters outside of quotes.
 // invalid characters within quotes are allowed, but unbalanced quotes are not allowed.
 func validateVersion(version string) error {
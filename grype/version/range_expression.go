@@ -45,6 +45,12 @@ func (c *simpleRangeExpression) satisfied(format Format, version *Version) (bool
 	for i, andOperand := range c.Units {
 		allSatisfied := true
 		for j, andUnit := range andOperand {
+			if andUnit.Wildcard {
+				if !andUnit.satisfiedByPrefix(format, version.Raw) {
+					allSatisfied = false
+				}
+				continue
+			}
 			result, err := version.Compare(&Version{
 				Format: format,
 				Raw:    andUnit.Version,
@@ -123,3 +129,25 @@ func scanExpression(phrase string) ([][]string, error) {
 
 	return orGroups, nil
 }
+
+// ConstraintOperators returns the distinct range operators used within the given constraint phrase, in the order
+// they first appear.
+func ConstraintOperators(phrase string) ([]Operator, error) {
+	expression, err := parseRangeExpression(phrase)
+	if err != nil {
+		return nil, err
+	}
+
+	var operators []Operator
+	seen := make(map[Operator]struct{})
+	for _, andUnits := range expression.Units {
+		for _, unit := range andUnits {
+			if _, ok := seen[unit.Operator]; ok {
+				continue
+			}
+			seen[unit.Operator] = struct{}{}
+			operators = append(operators, unit.Operator)
+		}
+	}
+	return operators, nil
+}
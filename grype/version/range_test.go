@@ -112,6 +112,26 @@ func TestParseRangeUnit(t *testing.T) {
 				Version:  "1.0",
 			},
 		},
+		{
+			phrase: "1.2.*",
+			expected: &rangeUnit{
+				Operator: EQ,
+				Version:  "1.2",
+				Wildcard: true,
+			},
+		},
+		{
+			phrase: "= 1.2.*",
+			expected: &rangeUnit{
+				Operator: EQ,
+				Version:  "1.2",
+				Wildcard: true,
+			},
+		},
+		{
+			phrase:    "< 1.2.*",
+			wantError: require.Error,
+		},
 	}
 
 	for _, test := range tests {
@@ -74,6 +74,10 @@ func (v *Version) getComparator(format Format) (Comparator, error) {
 	case UnknownFormat:
 		comparator, err = newFuzzyVersion(v.Raw)
 	default:
+		if custom, ok := lookupCustomFormat(format); ok {
+			comparator = customVersion{raw: v.Raw, comparator: custom.comparator}
+			break
+		}
 		err = fmt.Errorf("no comparator available for format %q", v.Format)
 	}
 
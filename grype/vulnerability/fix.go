@@ -1,5 +1,7 @@
 package vulnerability
 
+import "github.com/anchore/grype/grype/version"
+
 type FixState string
 
 const (
@@ -26,3 +28,30 @@ type Fix struct {
 func (f FixState) String() string {
 	return string(f)
 }
+
+// UpgradeTarget returns the recommended version to upgrade the given installed version to in order to no longer be
+// affected by the vulnerability: the closest fixed-in version greater than the installed version. When there are fixes
+// on several release branches (backports) this selects the fix on the branch of the installed version rather than the
+// newest fix overall. Fixed-in versions that still satisfy the vulnerability constraint are not considered. An empty
+// string is returned when there is no such version (or the installed version cannot be parsed).
+func (v Vulnerability) UpgradeTarget(installed string, format version.Format) string {
+	if installed == "" || len(v.Fix.Versions) == 0 {
+		return ""
+	}
+
+	current := version.NewVersion(installed, format)
+	if err := current.Validate(); err != nil {
+		return ""
+	}
+
+	for _, candidate := range version.FixVersionsAbove(current, v.Fix.Versions) {
+		if v.Constraint != nil {
+			if vulnerable, err := v.Constraint.Satisfied(candidate); err == nil && vulnerable {
+				continue
+			}
+		}
+		return candidate.Raw
+	}
+
+	return ""
+}
@@ -0,0 +1,106 @@
+package vulnerability
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/anchore/grype/grype/version"
+)
+
+func TestVulnerability_UpgradeTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint string
+		fixes      []string
+		installed  string
+		format     version.Format
+		expected   string
+	}{
+		{
+			name:      "no fix versions",
+			installed: "1.0.0",
+			format:    version.SemanticFormat,
+			expected:  "",
+		},
+		{
+			name:      "single fix version",
+			fixes:     []string{"1.2.0"},
+			installed: "1.0.0",
+			format:    version.SemanticFormat,
+			expected:  "1.2.0",
+		},
+		{
+			name:      "closest fix on the same branch is preferred over the newest fix (backport)",
+			fixes:     []string{"3.4.0", "1.2.1", "2.1.3"},
+			installed: "2.0.5",
+			format:    version.SemanticFormat,
+			expected:  "2.1.3",
+		},
+		{
+			name:      "lowest fix greater than the installed version",
+			fixes:     []string{"3.4.0", "2.1.3", "1.2.1"},
+			installed: "1.0.0",
+			format:    version.SemanticFormat,
+			expected:  "1.2.1",
+		},
+		{
+			name:      "all fixes are older than the installed version",
+			fixes:     []string{"1.2.1", "2.1.3"},
+			installed: "3.0.0",
+			format:    version.SemanticFormat,
+			expected:  "",
+		},
+		{
+			name:      "fix equal to the installed version is not an upgrade",
+			fixes:     []string{"1.2.1"},
+			installed: "1.2.1",
+			format:    version.SemanticFormat,
+			expected:  "",
+		},
+		{
+			name:       "fixes still within the vulnerable range are skipped",
+			constraint: "< 2.0.0",
+			fixes:      []string{"1.5.0", "2.0.0"},
+			installed:  "1.0.0",
+			format:     version.SemanticFormat,
+			expected:   "2.0.0",
+		},
+		{
+			name:      "invalid fix versions are skipped",
+			fixes:     []string{"not-a-version!", "1:1.2.0-1"},
+			installed: "1:1.0.0-1",
+			format:    version.DebFormat,
+			expected:  "1:1.2.0-1",
+		},
+		{
+			name:      "os package versions",
+			fixes:     []string{"1.2.3-r5", "1.2.3-r2"},
+			installed: "1.2.3-r1",
+			format:    version.ApkFormat,
+			expected:  "1.2.3-r2",
+		},
+		{
+			name:      "no installed version",
+			fixes:     []string{"1.2.0"},
+			installed: "",
+			format:    version.SemanticFormat,
+			expected:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vuln := Vulnerability{
+				Fix: Fix{
+					Versions: tt.fixes,
+					State:    FixStateFixed,
+				},
+			}
+			if tt.constraint != "" {
+				vuln.Constraint = version.MustGetConstraint(tt.constraint, tt.format)
+			}
+			assert.Equal(t, tt.expected, vuln.UpgradeTarget(tt.installed, tt.format))
+		})
+	}
+}
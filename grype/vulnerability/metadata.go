@@ -1,6 +1,7 @@
 package vulnerability
 
 import (
+	"slices"
 	"strings"
 	"time"
 )
@@ -16,6 +17,10 @@ type Metadata struct {
 	KnownExploited []KnownExploited
 	EPSS           []EPSS
 
+	// SeverityDerived indicates that Severity was not provided by the vulnerability data source but was
+	// instead derived from namespace-specific advisory data (see SeverityResolver)
+	SeverityDerived bool
+
 	// calculated as-needed
 	risk float64
 }
@@ -37,6 +42,28 @@ func (m *Metadata) RiskScore() float64 {
 	return m.risk
 }
 
+// HighestBaseScore returns the highest CVSS base score across all CVSS entries, and false if there are no (valid) CVSS
+// base scores at all.
+func (m *Metadata) HighestBaseScore() (float64, bool) {
+	if m == nil {
+		return 0, false
+	}
+	scores := validBaseScores(m.Cvss...)
+	if len(scores) == 0 {
+		return 0, false
+	}
+	return slices.Max(scores), true
+}
+
+// HasSeverity indicates if the metadata carries any severity information, either as a known severity label or as
+// at least one CVSS score.
+func (m *Metadata) HasSeverity() bool {
+	if m == nil {
+		return false
+	}
+	return ParseSeverity(m.Severity) != UnknownSeverity || len(m.Cvss) > 0
+}
+
 func riskScore(m Metadata) float64 {
 	return min(threat(m)*severity(m)*kevModifier(m), 1.0) * 100.0
 }
@@ -135,6 +162,21 @@ type CvssMetrics struct {
 	BaseScore           float64
 	ExploitabilityScore *float64
 	ImpactScore         *float64
+	TemporalScore       *float64 // only present when temporal metrics were provided
+	EnvironmentalScore  *float64 // only present when environmental metrics were provided
+}
+
+// AdjustedScore returns the most specific score available: the environmental score, then the temporal score, and
+// finally the base score.
+func (m CvssMetrics) AdjustedScore() float64 {
+	switch {
+	case m.EnvironmentalScore != nil:
+		return *m.EnvironmentalScore
+	case m.TemporalScore != nil:
+		return *m.TemporalScore
+	default:
+		return m.BaseScore
+	}
 }
 
 type KnownExploited struct {
@@ -303,6 +303,83 @@ func TestAverageCVSS(t *testing.T) {
 	}
 }
 
+func TestMetadata_HighestBaseScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata *Metadata
+		expected float64
+		scored   bool
+	}{
+		{
+			name: "nil metadata",
+		},
+		{
+			name:     "no cvss",
+			metadata: &Metadata{Severity: "high"},
+		},
+		{
+			name: "multiple valid scores",
+			metadata: &Metadata{Cvss: []Cvss{
+				{Metrics: CvssMetrics{BaseScore: 5.0}},
+				{Metrics: CvssMetrics{BaseScore: 9.8}},
+				{Metrics: CvssMetrics{BaseScore: 7.5}},
+			}},
+			expected: 9.8,
+			scored:   true,
+		},
+		{
+			name: "all invalid scores",
+			metadata: &Metadata{Cvss: []Cvss{
+				{Metrics: CvssMetrics{BaseScore: 0}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, scored := tt.metadata.HighestBaseScore()
+			assert.Equal(t, tt.scored, scored)
+			assert.Equal(t, tt.expected, score)
+		})
+	}
+}
+
+func TestMetadata_HasSeverity(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata *Metadata
+		expected bool
+	}{
+		{
+			name: "nil metadata",
+		},
+		{
+			name:     "no severity or cvss",
+			metadata: &Metadata{},
+		},
+		{
+			name:     "unknown severity",
+			metadata: &Metadata{Severity: "Unknown"},
+		},
+		{
+			name:     "known severity",
+			metadata: &Metadata{Severity: "high"},
+			expected: true,
+		},
+		{
+			name:     "cvss only",
+			metadata: &Metadata{Cvss: []Cvss{{Metrics: CvssMetrics{BaseScore: 7.5}}}},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.metadata.HasSeverity())
+		})
+	}
+}
+
 func TestThreat(t *testing.T) {
 	tests := []struct {
 		name     string
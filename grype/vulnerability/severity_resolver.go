@@ -0,0 +1,37 @@
+package vulnerability
+
+import "strings"
+
+// SeverityResolver derives a severity for a vulnerability from namespace-specific advisory data. Resolvers are
+// consulted only when there is no severity or CVSS information available from the vulnerability metadata.
+type SeverityResolver interface {
+	// ResolveSeverity returns the derived severity and true, or false if no severity could be derived
+	ResolveSeverity(v Vulnerability) (Severity, bool)
+}
+
+// SeverityResolverFunc is an adapter to allow the use of ordinary functions as a SeverityResolver.
+type SeverityResolverFunc func(v Vulnerability) (Severity, bool)
+
+func (f SeverityResolverFunc) ResolveSeverity(v Vulnerability) (Severity, bool) {
+	return f(v)
+}
+
+// NamespaceSeverityResolver dispatches to a SeverityResolver based on the vulnerability namespace. Keys are namespace
+// prefixes (e.g. "debian:distro:debian"), where the longest matching prefix is used.
+type NamespaceSeverityResolver map[string]SeverityResolver
+
+func (r NamespaceSeverityResolver) ResolveSeverity(v Vulnerability) (Severity, bool) {
+	var selected SeverityResolver
+	var selectedPrefix string
+	for prefix, resolver := range r {
+		if strings.HasPrefix(v.Namespace, prefix) && (selected == nil || len(prefix) > len(selectedPrefix)) {
+			selected, selectedPrefix = resolver, prefix
+		}
+	}
+
+	if selected == nil {
+		return UnknownSeverity, false
+	}
+
+	return selected.ResolveSeverity(v)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"runtime/debug"
 	"slices"
+	"sort"
 	"strings"
 
 	"github.com/wagoodman/go-partybus"
@@ -21,6 +22,7 @@ import (
 	"github.com/anchore/grype/grype/vulnerability"
 	"github.com/anchore/grype/internal/bus"
 	"github.com/anchore/grype/internal/log"
+	"github.com/anchore/syft/syft/file"
 	syftPkg "github.com/anchore/syft/syft/pkg"
 )
 
@@ -37,6 +39,45 @@ type VulnerabilityMatcher struct {
 	FailSeverity          *vulnerability.Severity
 	NormalizeByCVE        bool
 	VexProcessor          *vex.Processor
+	// SeverityResolver is consulted to derive a severity for matches whose vulnerability metadata carries
+	// no severity or CVSS information (e.g. distro advisories that only encode severity in the advisory itself)
+	SeverityResolver vulnerability.SeverityResolver
+	// PostProcessor, when set, is given the full set of remaining matches after all ignore rules, exclusions, and VEX
+	// statements have been applied, and may return a transformed set of matches (e.g. enriched, tagged, or further
+	// filtered). The result is used for the fail-on-severity check and is what is returned to the caller. Any error
+	// returned by the processor is propagated.
+	PostProcessor func(match.Matches) (match.Matches, error)
+	// IncludeEvidence attaches the SBOM evidence (locations and package metadata) that led to the package being
+	// identified to each match. This is opt-in since it can considerably increase the size of results.
+	IncludeEvidence bool
+	// ExpandAliases normalizes each match to a canonical vulnerability ID (preferring the CVE alias of a non-CVE
+	// record) and collapses matches for the same package that are the same vulnerability under different IDs into a
+	// single match, recording the original IDs as related vulnerabilities.
+	ExpandAliases bool
+	// DedupePackages collapses matches for the same canonical package (name, version, and type) that was cataloged
+	// multiple times (e.g. at different locations) into a single match, retaining the union of locations and details.
+	DedupePackages bool
+	// FailFast stops searching for matches as soon as a match at or above the FailSeverity is found, returning only that
+	// match (along with grypeerr.ErrAboveSeverityThreshold). This is intended for pass/fail gates where only the
+	// outcome matters: in this mode the returned matches (and coverage) are partial, describing only the packages
+	// searched up to that point. Severities are resolved during matching for this purpose, pulling metadata from the
+	// vulnerability provider as needed. Ignore filters returned by matchers for packages later in the list cannot be
+	// considered. This has no effect without a FailSeverity or when a VexProcessor is configured (since VEX statements
+	// can only be applied relative to all matches).
+	FailFast bool
+	// MatchFilter, when set, drops matches as they are found (before ignore rules, normalization, and VEX statements
+	// are applied), for instance to only report matches at or above a CVSS base score. Dropped matches are not reported
+	// as ignored matches.
+	MatchFilter *MatchFilter
+	// Explain, when set, is called for each package searched (in order) with the candidate vulnerabilities that were
+	// considered for the package and the reason each candidate was not reported (e.g. an unsatisfied version
+	// constraint, namespace mismatch, or ignore rule). This issues additional searches against the vulnerability
+	// provider in order to find the candidates, so is intended for debugging missing matches.
+	Explain func(Explanation)
+
+	// progressMonitor, when set, is reported to rather than publishing a new monitor for each search (see
+	// FindVulnerabilitiesForPackageInProviders). The monitor is left incomplete, since it is owned by the caller.
+	progressMonitor *monitorWriter
 }
 
 func (m *VulnerabilityMatcher) FailAtOrAboveSeverity(severity *vulnerability.Severity) *VulnerabilityMatcher {
@@ -54,49 +95,95 @@ func (m *VulnerabilityMatcher) WithIgnoreRules(ignoreRules []match.IgnoreRule) *
 	return m
 }
 
-func (m *VulnerabilityMatcher) FindMatches(pkgs []pkg.Package, context pkg.Context) (remainingMatches *match.Matches, ignoredMatches []match.IgnoredMatch, err error) {
-	progressMonitor := trackMatcher(len(pkgs))
+func (m *VulnerabilityMatcher) FindMatches(pkgs []pkg.Package, context pkg.Context) (*match.Matches, []match.IgnoredMatch, error) {
+	remainingMatches, ignoredMatches, _, err := m.FindMatchesWithCoverage(pkgs, context)
+	return remainingMatches, ignoredMatches, err
+}
 
-	defer func() {
-		progressMonitor.Ignored.Set(int64(len(ignoredMatches)))
-		progressMonitor.SetCompleted()
-		if err != nil {
-			progressMonitor.MatchesDiscovered.SetError(err)
-		}
-	}()
+// FindMatchesWithCoverage is the same as FindMatches, but additionally reports which packages could not be evaluated
+// against the vulnerability provider at all (e.g. no applicable namespace), along with the reason for each.
+func (m *VulnerabilityMatcher) FindMatchesWithCoverage(pkgs []pkg.Package, context pkg.Context) (remainingMatches *match.Matches, ignoredMatches []match.IgnoredMatch, coverage Coverage, err error) {
+	progressMonitor := m.progressMonitor
+	if progressMonitor == nil {
+		progressMonitor = trackMatcher(len(pkgs))
+
+		defer func() {
+			progressMonitor.Ignored.Set(int64(len(ignoredMatches)))
+			progressMonitor.SetCompleted()
+			if err != nil {
+				progressMonitor.MatchesDiscovered.SetError(err)
+			}
+		}()
+	}
 
-	remainingMatches, ignoredMatches, err = m.findDBMatches(pkgs, context, progressMonitor)
+	recorder := newExplanationRecorder(m.Explain)
+
+	remainingMatches, ignoredMatches, coverage, err = m.findDBMatches(pkgs, context, progressMonitor, recorder)
 	if err != nil {
 		err = fmt.Errorf("unable to find matches against vulnerability database: %w", err)
-		return remainingMatches, ignoredMatches, err
+		return remainingMatches, ignoredMatches, coverage, err
+	}
+
+	if m.SeverityResolver != nil {
+		resolved := m.resolveMissingSeverities(*remainingMatches)
+		remainingMatches = &resolved
 	}
 
 	remainingMatches, ignoredMatches, err = m.findVEXMatches(context, remainingMatches, ignoredMatches, progressMonitor)
 	if err != nil {
 		err = fmt.Errorf("unable to find matches against VEX sources: %w", err)
-		return remainingMatches, ignoredMatches, err
+		return remainingMatches, ignoredMatches, coverage, err
+	}
+	recorder.ignored(ignoredMatches, ignoreRejection)
+
+	if m.IncludeEvidence {
+		remainingMatches = withEvidence(*remainingMatches)
 	}
 
+	if m.PostProcessor != nil {
+		var processed match.Matches
+		processed, err = m.PostProcessor(*remainingMatches)
+		if err != nil {
+			err = fmt.Errorf("unable to post-process matches: %w", err)
+			return remainingMatches, ignoredMatches, coverage, err
+		}
+		remainingMatches = &processed
+	}
+
+	recorder.emit(m.Explain, remainingMatches)
+
 	if m.FailSeverity != nil && hasSeverityAtOrAbove(m.VulnerabilityProvider, *m.FailSeverity, *remainingMatches) {
 		err = grypeerr.ErrAboveSeverityThreshold
-		return remainingMatches, ignoredMatches, err
+		return remainingMatches, ignoredMatches, coverage, err
 	}
 
-	logListSummary(progressMonitor)
+	if m.progressMonitor == nil {
+		logListSummary(progressMonitor)
+	}
 
 	logIgnoredMatches(ignoredMatches)
 
-	return remainingMatches, ignoredMatches, nil
+	return remainingMatches, ignoredMatches, coverage, nil
 }
 
-func (m *VulnerabilityMatcher) findDBMatches(pkgs []pkg.Package, context pkg.Context, progressMonitor *monitorWriter) (*match.Matches, []match.IgnoredMatch, error) {
+func withEvidence(matches match.Matches) *match.Matches {
+	result := match.NewMatches()
+	for m := range matches.Enumerate() {
+		evidence := match.NewEvidence(m.Package)
+		m.Evidence = &evidence
+		result.Add(m)
+	}
+	return &result
+}
+
+func (m *VulnerabilityMatcher) findDBMatches(pkgs []pkg.Package, context pkg.Context, progressMonitor *monitorWriter, recorder *explanationRecorder) (*match.Matches, []match.IgnoredMatch, Coverage, error) {
 	var ignoredMatches []match.IgnoredMatch
 
 	log.Trace("finding matches against DB")
-	matches, err := m.searchDBForMatches(context.Distro, pkgs, progressMonitor)
+	matches, coverage, err := m.searchDBForMatches(context.Distro, pkgs, progressMonitor, recorder)
 	if err != nil {
 		if match.IsFatalError(err) {
-			return nil, nil, err
+			return nil, nil, coverage, err
 		}
 
 		// other errors returned from matchers during searchDBForMatches were being
@@ -121,7 +208,54 @@ func (m *VulnerabilityMatcher) findDBMatches(pkgs []pkg.Package, context pkg.Con
 		ignoredMatches = m.mergeIgnoredMatches(originalIgnoredMatches, ignoredMatches)
 	}
 
-	return &matches, ignoredMatches, nil
+	if m.ExpandAliases {
+		// as with normalization, the ignore rules are applied again relative to the canonical IDs
+		originalIgnoredMatches := ignoredMatches
+		matches, ignoredMatches = m.applyIgnoreRules(m.expandAliases(matches))
+		ignoredMatches = m.mergeIgnoredMatches(originalIgnoredMatches, ignoredMatches)
+	}
+
+	if m.DedupePackages {
+		matches = dedupePackages(matches)
+	}
+
+	return &matches, ignoredMatches, coverage, nil
+}
+
+// resolveMissingSeverities derives severities for matches lacking severity information using the configured resolver.
+// Any derived severity is flagged on the match metadata so that it can be distinguished from source-provided values.
+func (m *VulnerabilityMatcher) resolveMissingSeverities(matches match.Matches) match.Matches {
+	resolved := match.NewMatches()
+	for mt := range matches.Enumerate() {
+		metadata := mt.Vulnerability.Metadata
+		if metadata == nil {
+			var err error
+			metadata, err = m.VulnerabilityProvider.VulnerabilityMetadata(mt.Vulnerability.Reference)
+			if err != nil {
+				log.WithFields("vuln", mt.Vulnerability.ID, "namespace", mt.Vulnerability.Namespace, "error", err).Debug("unable to fetch vulnerability metadata")
+			}
+		}
+
+		if !metadata.HasSeverity() {
+			if sev, ok := m.SeverityResolver.ResolveSeverity(mt.Vulnerability); ok {
+				derived := vulnerability.Metadata{
+					ID:        mt.Vulnerability.ID,
+					Namespace: mt.Vulnerability.Namespace,
+				}
+				if metadata != nil {
+					derived = *metadata
+				}
+				derived.Severity = sev.String()
+				derived.SeverityDerived = true
+				mt.Vulnerability.Metadata = &derived
+
+				log.WithFields("vuln", mt.Vulnerability.ID, "namespace", mt.Vulnerability.Namespace, "severity", derived.Severity).Trace("derived severity from namespace advisory data")
+			}
+		}
+
+		resolved.Add(mt)
+	}
+	return resolved
 }
 
 func (m *VulnerabilityMatcher) mergeIgnoredMatches(allIgnoredMatches ...[]match.IgnoredMatch) []match.IgnoredMatch {
@@ -142,17 +276,21 @@ func (m *VulnerabilityMatcher) searchDBForMatches(
 	d *distro.Distro,
 	packages []pkg.Package,
 	progressMonitor *monitorWriter,
-) (match.Matches, error) {
+	recorder *explanationRecorder,
+) (match.Matches, Coverage, error) {
 	var allMatches []match.Match
 	var allIgnorers []match.IgnoreFilter
+	coverage := Coverage{Total: len(packages)}
 	matcherIndex, defaultMatcher := newMatcherIndex(m.Matchers)
 
 	if defaultMatcher == nil {
 		defaultMatcher = stock.NewStockMatcher(stock.MatcherConfig{UseCPEs: true})
 	}
 
+	failFast := m.FailFast && m.FailSeverity != nil && m.VexProcessor == nil
+
 	var matcherErrs []error
-	for _, p := range packages {
+	for idx, p := range packages {
 		progressMonitor.PackagesProcessed.Increment()
 		log.WithFields("package", displayPackage(p)).Trace("searching for vulnerability matches")
 
@@ -166,11 +304,16 @@ func (m *VulnerabilityMatcher) searchDBForMatches(
 		if !ok {
 			matchAgainst = []match.Matcher{defaultMatcher}
 		}
+		tracker := &searchTrackingProvider{Provider: recorder.provider(p, m.VulnerabilityProvider), distroReason: "the distro of the package"}
+		if orig == nil {
+			tracker.distroReason = "the distro of the scan target"
+		}
+		var packageMatches, matcherMatches []match.Match
 		for _, theMatcher := range matchAgainst {
-			matches, ignorers, err := callMatcherSafely(theMatcher, m.VulnerabilityProvider, p)
+			matches, ignorers, err := callMatcherSafely(theMatcher, tracker, p)
 			if err != nil {
 				if match.IsFatalError(err) {
-					return match.Matches{}, err
+					return match.Matches{}, coverage, err
 				}
 
 				log.WithFields("error", err, "package", displayPackage(p)).Warn("matcher returned error")
@@ -178,14 +321,23 @@ func (m *VulnerabilityMatcher) searchDBForMatches(
 			}
 
 			allIgnorers = append(allIgnorers, ignorers...)
+			matcherMatches = append(matcherMatches, matches...)
 
 			// Filter out matches based on records in the database exclusion table and hard-coded rules
 			filtered, dropped := match.ApplyExplicitIgnoreRules(m.ExclusionProvider, match.NewMatches(matches...))
+			recorder.ignored(dropped, func(match.IgnoredMatch) RejectionReason { return RejectedByExclusion })
 
 			additionalMatches := filtered.Sorted()
+			if m.MatchFilter != nil {
+				var belowThreshold []match.Match
+				additionalMatches, belowThreshold = m.MatchFilter.apply(m.VulnerabilityProvider, additionalMatches)
+				logFilteredPackageMatches(p, belowThreshold)
+				recorder.rejected(p, belowThreshold, RejectedByMatchFilter, fmt.Sprintf("below the minimum CVSS base score of %v", m.MatchFilter.MinimumCVSS))
+			}
 			logPackageMatches(p, additionalMatches)
 			logExplicitDroppedPackageMatches(p, dropped)
 			allMatches = append(allMatches, additionalMatches...)
+			packageMatches = append(packageMatches, additionalMatches...)
 
 			progressMonitor.MatchesDiscovered.Add(int64(len(additionalMatches)))
 
@@ -195,12 +347,32 @@ func (m *VulnerabilityMatcher) searchDBForMatches(
 			updateVulnerabilityList(progressMonitor, additionalMatches, nil, dropped, m.VulnerabilityProvider)
 		}
 
+		recorder.matched(p, matcherMatches)
+
+		if reason, skipped := skipReasonFor(p, tracker.searched); skipped {
+			log.WithFields("package", displayPackage(p), "reason", reason).Trace("package was not evaluated against the vulnerability provider")
+			coverage.Skipped = append(coverage.Skipped, SkippedPackage{Package: p, Reason: reason})
+		}
+		if len(tracker.namespaces) > 0 {
+			coverage.Namespaces = append(coverage.Namespaces, PackageNamespaces{Package: p, Namespaces: tracker.namespaces})
+		}
+
 		p.Distro = orig
+
+		if failFast {
+			if mt, found := m.firstMatchAtOrAboveFailSeverity(packageMatches, allIgnorers); found {
+				log.WithFields("package", displayPackage(p), "vuln", mt.Vulnerability.ID).Debug("found match at or above the fail severity, stopping search")
+				coverage.Total = idx + 1
+				progressMonitor.MatchesDiscovered.Set(1)
+				return match.NewMatches(mt), coverage, errors.Join(matcherErrs...)
+			}
+		}
 	}
 
 	// apply ignores based on matchers returning ignore rules
 	filtered, dropped := match.ApplyIgnoreFilters(allMatches, ignoredMatchFilter(allIgnorers))
 	logIgnoredMatches(dropped)
+	recorder.ignored(dropped, func(match.IgnoredMatch) RejectionReason { return RejectedByIgnoreFilter })
 
 	// get deduplicated set of matches
 	res := match.NewMatches(filtered...)
@@ -208,7 +380,35 @@ func (m *VulnerabilityMatcher) searchDBForMatches(
 	// update the total discovered matches after removing all duplicates and ignores
 	progressMonitor.MatchesDiscovered.Set(int64(res.Count()))
 
-	return res, errors.Join(matcherErrs...)
+	return res, coverage, errors.Join(matcherErrs...)
+}
+
+// firstMatchAtOrAboveFailSeverity returns the first of the given matches that would remain after all ignore rules are
+// applied whose (resolved) severity is at or above the fail severity.
+func (m *VulnerabilityMatcher) firstMatchAtOrAboveFailSeverity(matches []match.Match, ignorers []match.IgnoreFilter) (match.Match, bool) {
+	// note: the given matches have already had the explicit (DB exclusion) ignore rules applied
+	filtered, _ := match.ApplyIgnoreFilters(matches, ignoredMatchFilter(ignorers))
+
+	candidates := match.NewMatches()
+	for _, mt := range filtered {
+		if m.NormalizeByCVE || m.ExpandAliases {
+			// the severity is evaluated relative to the record that will be reported
+			mt = m.normalizeByCVE(mt)
+		}
+		candidates.Add(mt)
+	}
+
+	candidates, _ = m.applyIgnoreRules(candidates)
+	if m.SeverityResolver != nil {
+		candidates = m.resolveMissingSeverities(candidates)
+	}
+
+	for _, mt := range candidates.Sorted() {
+		if hasSeverityAtOrAbove(m.VulnerabilityProvider, *m.FailSeverity, match.NewMatches(mt)) {
+			return mt, true
+		}
+	}
+	return match.Match{}, false
 }
 
 func callMatcherSafely(m match.Matcher, vp vulnerability.Provider, p pkg.Package) (matches []match.Match, ignoredMatches []match.IgnoreFilter, err error) {
@@ -312,6 +512,140 @@ func (m *VulnerabilityMatcher) normalizeByCVE(match match.Match) match.Match {
 	return match
 }
 
+// expandAliases collapses matches for the same package which are the same vulnerability under different IDs (e.g. a
+// GHSA and the CVE it aliases) into a single match with the canonical (CVE) ID.
+func (m *VulnerabilityMatcher) expandAliases(matches match.Matches) match.Matches {
+	type aliasKey struct {
+		packageID       pkg.ID
+		vulnerabilityID string
+	}
+
+	type candidate struct {
+		match   match.Match
+		aliased bool
+	}
+
+	var keys []aliasKey
+	groups := make(map[aliasKey][]candidate)
+	for _, original := range matches.Sorted() {
+		normalized := m.normalizeByCVE(original)
+		k := aliasKey{
+			packageID:       normalized.Package.ID,
+			vulnerabilityID: normalized.Vulnerability.ID,
+		}
+		if _, exists := groups[k]; !exists {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], candidate{
+			match:   normalized,
+			aliased: normalized.Vulnerability.ID != original.Vulnerability.ID,
+		})
+	}
+
+	result := match.NewMatches()
+	for _, k := range keys {
+		group := groups[k]
+
+		// prefer a match made directly against the canonical record, since the vulnerability data (e.g. fix
+		// information) describes the canonical vulnerability and not an alias
+		sort.SliceStable(group, func(i, j int) bool {
+			return !group[i].aliased && group[j].aliased
+		})
+
+		canonical := group[0].match
+		for _, other := range group[1:] {
+			mergeAliasedMatch(&canonical, other.match)
+		}
+
+		result.Add(canonical)
+	}
+	return result
+}
+
+// mergeAliasedMatch merges the related vulnerabilities and details of another match for the same vulnerability (e.g.
+// an aliased match) into the canonical match.
+func mergeAliasedMatch(canonical *match.Match, other match.Match) {
+	seenRefs := make(map[vulnerability.Reference]struct{})
+	for _, r := range canonical.Vulnerability.RelatedVulnerabilities {
+		seenRefs[r] = struct{}{}
+	}
+	for _, r := range other.Vulnerability.RelatedVulnerabilities {
+		if _, ok := seenRefs[r]; ok || (r.ID == canonical.Vulnerability.ID && r.Namespace == canonical.Vulnerability.Namespace) {
+			continue
+		}
+		seenRefs[r] = struct{}{}
+		canonical.Vulnerability.RelatedVulnerabilities = append(canonical.Vulnerability.RelatedVulnerabilities, r)
+	}
+
+	sort.Slice(canonical.Vulnerability.RelatedVulnerabilities, func(i, j int) bool {
+		a := canonical.Vulnerability.RelatedVulnerabilities[i]
+		b := canonical.Vulnerability.RelatedVulnerabilities[j]
+		if a.ID == b.ID {
+			return a.Namespace < b.Namespace
+		}
+		return a.ID < b.ID
+	})
+
+	seenDetails := make(map[string]struct{})
+	for _, d := range canonical.Details {
+		seenDetails[d.ID()] = struct{}{}
+	}
+	for _, d := range other.Details {
+		if _, ok := seenDetails[d.ID()]; ok {
+			continue
+		}
+		seenDetails[d.ID()] = struct{}{}
+		canonical.Details = append(canonical.Details, d)
+	}
+	canonical.Details.Sort()
+}
+
+// dedupePackages collapses matches for the same vulnerability against the same canonical package (name, version, and
+// type) into a single match. The first package instance (by match sort order) is kept, with the locations of all other
+// instances added to it.
+func dedupePackages(matches match.Matches) match.Matches {
+	type packageKey struct {
+		name            string
+		version         string
+		packageType     syftPkg.Type
+		vulnerabilityID string
+		namespace       string
+	}
+
+	var keys []packageKey
+	groups := make(map[packageKey][]match.Match)
+	for _, mt := range matches.Sorted() {
+		k := packageKey{
+			name:            mt.Package.Name,
+			version:         mt.Package.Version,
+			packageType:     mt.Package.Type,
+			vulnerabilityID: mt.Vulnerability.ID,
+			namespace:       mt.Vulnerability.Namespace,
+		}
+		if _, exists := groups[k]; !exists {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], mt)
+	}
+
+	result := match.NewMatches()
+	for _, k := range keys {
+		group := groups[k]
+
+		canonical := group[0]
+		if len(group) > 1 {
+			locations := file.NewLocationSet(canonical.Package.Locations.ToSlice()...)
+			for _, other := range group[1:] {
+				locations.Add(other.Package.Locations.ToSlice()...)
+				mergeAliasedMatch(&canonical, other)
+			}
+			canonical.Package.Locations = locations
+		}
+		result.Add(canonical)
+	}
+	return result
+}
+
 // ignoreRulesByLocation implements match.IgnoreFilter to filter each matching
 // package that overlaps by location and have the same vulnerability ID (CVE)
 type ignoreRulesByLocation struct {
@@ -418,6 +752,15 @@ func hasSeverityAtOrAbove(store vulnerability.MetadataProvider, severity vulnera
 			continue
 		}
 
+		if m.Vulnerability.Metadata != nil && m.Vulnerability.Metadata.SeverityDerived {
+			// the provider has no severity for this vulnerability, use the severity derived during matching
+			metadata = m.Vulnerability.Metadata
+		}
+
+		if metadata == nil {
+			continue
+		}
+
 		if vulnerability.ParseSeverity(metadata.Severity) >= severity {
 			return true
 		}
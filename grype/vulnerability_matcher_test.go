@@ -1475,14 +1475,717 @@ func TestCallMatcherSafely_RecoverFromPanic(t *testing.T) {
 
 type busListener struct {
 	matching monitor.Matching
+	started  int
 }
 
 func (b *busListener) Publish(e partybus.Event) {
 	if e.Type == event.VulnerabilityScanningStarted {
 		if m, ok := e.Value.(monitor.Matching); ok {
 			b.matching = m
+			b.started++
 		}
 	}
 }
 
 var _ partybus.Publisher = (*busListener)(nil)
+
+func TestVulnerabilityMatcher_resolveMissingSeverities(t *testing.T) {
+	neutronPkg := pkg.Package{
+		ID:      pkg.ID(uuid.NewString()),
+		Name:    "neutron",
+		Version: "2013.1.1-1",
+		Type:    syftPkg.DebPkg,
+	}
+
+	// CVE-2013-fake-2 has no metadata (thus no severity or CVSS), CVE-2014-fake-1 has a "medium" severity
+	vp := mock.VulnerabilityProvider(testVulnerabilities()...)
+
+	resolver := vulnerability.NamespaceSeverityResolver{
+		"debian:distro": vulnerability.SeverityResolverFunc(func(v vulnerability.Vulnerability) (vulnerability.Severity, bool) {
+			for _, a := range v.Advisories {
+				if a.ID == "DSA-fake-high" {
+					return vulnerability.HighSeverity, true
+				}
+			}
+			return vulnerability.UnknownSeverity, false
+		}),
+	}
+
+	advisories := []vulnerability.Advisory{{ID: "DSA-fake-high"}}
+	matches := match.NewMatches(
+		match.Match{
+			Vulnerability: vulnerability.Vulnerability{
+				Reference:  vulnerability.Reference{ID: "CVE-2013-fake-2", Namespace: "debian:distro:debian:8"},
+				Advisories: advisories,
+			},
+			Package: neutronPkg,
+		},
+		match.Match{
+			Vulnerability: vulnerability.Vulnerability{
+				Reference:  vulnerability.Reference{ID: "CVE-2014-fake-1", Namespace: "debian:distro:debian:8"},
+				Advisories: advisories,
+			},
+			Package: neutronPkg,
+		},
+	)
+
+	m := VulnerabilityMatcher{
+		VulnerabilityProvider: vp,
+		SeverityResolver:      resolver,
+	}
+
+	resolved := m.resolveMissingSeverities(matches)
+	require.Equal(t, 2, resolved.Count())
+
+	for _, mt := range resolved.Sorted() {
+		switch mt.Vulnerability.ID {
+		case "CVE-2013-fake-2":
+			require.NotNil(t, mt.Vulnerability.Metadata)
+			assert.Equal(t, "high", mt.Vulnerability.Metadata.Severity)
+			assert.True(t, mt.Vulnerability.Metadata.SeverityDerived)
+		case "CVE-2014-fake-1":
+			// existing severity information is never overridden
+			assert.Nil(t, mt.Vulnerability.Metadata)
+		}
+	}
+
+	threshold := vulnerability.HighSeverity
+	assert.True(t, hasSeverityAtOrAbove(vp, threshold, resolved))
+	assert.False(t, hasSeverityAtOrAbove(vp, threshold, matches))
+}
+
+func TestVulnerabilityMatcher_FindMatchesWithCoverage(t *testing.T) {
+	vp := mock.VulnerabilityProvider(testVulnerabilities()...)
+
+	neutronPkg := pkg.Package{
+		ID:      pkg.ID(uuid.NewString()),
+		Name:    "neutron",
+		Version: "2013.1.1-1",
+		Type:    syftPkg.DebPkg,
+	}
+
+	noDistroPkg := pkg.Package{
+		ID:      pkg.ID(uuid.NewString()),
+		Name:    "musl",
+		Version: "1.2.3-r0",
+		Type:    syftPkg.ApkPkg,
+	}
+
+	unknownVersionPkg := pkg.Package{
+		ID:       pkg.ID(uuid.NewString()),
+		Name:     "activerecord",
+		Type:     syftPkg.GemPkg,
+		Language: syftPkg.Ruby,
+	}
+
+	nichePkg := pkg.Package{
+		ID:      pkg.ID(uuid.NewString()),
+		Name:    "some-addon",
+		Version: "1.0.0",
+		Type:    syftPkg.Type("niche-ecosystem"),
+	}
+
+	m := &VulnerabilityMatcher{
+		VulnerabilityProvider: vp,
+		Matchers:              matcher.NewDefaultMatchers(matcher.Config{}),
+	}
+
+	_, _, coverage, err := m.FindMatchesWithCoverage(
+		[]pkg.Package{neutronPkg, noDistroPkg, unknownVersionPkg, nichePkg},
+		pkg.Context{},
+	)
+	require.NoError(t, err)
+
+	reasons := make(map[string]SkipReason)
+	for _, s := range coverage.Skipped {
+		reasons[s.Package.Name] = s.Reason
+	}
+
+	assert.Equal(t, 4, coverage.Total)
+	assert.Equal(t, map[string]SkipReason{
+		"neutron":      SkipReasonNoDistro,
+		"musl":         SkipReasonNoDistro,
+		"activerecord": SkipReasonUnknownVersion,
+		"some-addon":   SkipReasonNoApplicableNamespace,
+	}, reasons)
+	assert.Equal(t, 0, coverage.Evaluated())
+
+	_, _, coverage, err = m.FindMatchesWithCoverage(
+		[]pkg.Package{neutronPkg},
+		pkg.Context{Distro: &distro.Distro{Type: "debian", Version: "8"}},
+	)
+	require.NoError(t, err)
+	assert.Empty(t, coverage.Skipped)
+	assert.Equal(t, 1, coverage.Evaluated())
+}
+
+func TestCoverage_PackageResults(t *testing.T) {
+	vp := mock.VulnerabilityProvider(testVulnerabilities()...)
+
+	vulnerablePkg := pkg.Package{
+		ID:      pkg.ID(uuid.NewString()),
+		Name:    "neutron",
+		Version: "2013.1.1-1",
+		Type:    syftPkg.DebPkg,
+	}
+
+	cleanPkg := pkg.Package{
+		ID:      pkg.ID(uuid.NewString()),
+		Name:    "neutron",
+		Version: "2015.1.1-1",
+		Type:    syftPkg.DebPkg,
+	}
+
+	nichePkg := pkg.Package{
+		ID:      pkg.ID(uuid.NewString()),
+		Name:    "some-addon",
+		Version: "1.0.0",
+		Type:    syftPkg.Type("niche-ecosystem"),
+	}
+
+	m := &VulnerabilityMatcher{
+		VulnerabilityProvider: vp,
+		Matchers:              matcher.NewDefaultMatchers(matcher.Config{}),
+	}
+
+	packages := []pkg.Package{vulnerablePkg, cleanPkg, nichePkg}
+	matches, _, coverage, err := m.FindMatchesWithCoverage(packages, pkg.Context{Distro: &distro.Distro{Type: "debian", Version: "8"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []PackageResult{
+		{Package: vulnerablePkg, Status: PackageStatusMatched},
+		{Package: cleanPkg, Status: PackageStatusClean},
+		{Package: nichePkg, Status: PackageStatusSkipped, SkipReason: SkipReasonNoApplicableNamespace},
+	}, coverage.PackageResults(packages, *matches))
+
+	// packages not considered for matching are not reported
+	coverage.Total = 1
+	assert.Equal(t, []PackageResult{
+		{Package: vulnerablePkg, Status: PackageStatusMatched},
+	}, coverage.PackageResults(packages, *matches))
+}
+
+func TestVulnerabilityMatcher_PostProcessor(t *testing.T) {
+	vp := mock.VulnerabilityProvider(testVulnerabilities()...)
+
+	neutronPkg := pkg.Package{
+		ID:      pkg.ID(uuid.NewString()),
+		Name:    "neutron",
+		Version: "2013.1.1-1",
+		Type:    syftPkg.DebPkg,
+	}
+
+	context := pkg.Context{
+		Distro: &distro.Distro{
+			Type:    "debian",
+			Version: "8",
+		},
+	}
+
+	t.Run("transforms results", func(t *testing.T) {
+		var received int
+		m := &VulnerabilityMatcher{
+			VulnerabilityProvider: vp,
+			Matchers:              matcher.NewDefaultMatchers(matcher.Config{}),
+			PostProcessor: func(matches match.Matches) (match.Matches, error) {
+				received = matches.Count()
+				return match.NewMatches(), nil
+			},
+		}
+
+		actual, _, err := m.FindMatches([]pkg.Package{neutronPkg}, context)
+		require.NoError(t, err)
+		assert.Equal(t, 1, received)
+		assert.Equal(t, 0, actual.Count())
+	})
+
+	t.Run("propagates errors", func(t *testing.T) {
+		processorErr := errors.New("processor failed")
+		m := &VulnerabilityMatcher{
+			VulnerabilityProvider: vp,
+			Matchers:              matcher.NewDefaultMatchers(matcher.Config{}),
+			PostProcessor: func(matches match.Matches) (match.Matches, error) {
+				return matches, processorErr
+			},
+		}
+
+		_, _, err := m.FindMatches([]pkg.Package{neutronPkg}, context)
+		require.ErrorIs(t, err, processorErr)
+	})
+}
+
+func TestVulnerabilityMatcher_IncludeEvidence(t *testing.T) {
+	vp := mock.VulnerabilityProvider(testVulnerabilities()...)
+
+	primary := file.NewLocation("/var/lib/dpkg/status").WithAnnotation(syftPkg.EvidenceAnnotationKey, syftPkg.PrimaryEvidenceAnnotation)
+	supporting := file.NewLocation("/usr/share/doc/neutron/copyright").WithAnnotation(syftPkg.EvidenceAnnotationKey, syftPkg.SupportingEvidenceAnnotation)
+
+	neutronPkg := pkg.Package{
+		ID:        pkg.ID(uuid.NewString()),
+		Name:      "neutron",
+		Version:   "2013.1.1-1",
+		Type:      syftPkg.DebPkg,
+		Locations: file.NewLocationSet(primary, supporting),
+		Metadata:  pkg.PURLLiteralMetadata{PURL: "pkg:deb/debian/neutron@2013.1.1-1"},
+	}
+
+	context := pkg.Context{
+		Distro: &distro.Distro{
+			Type:    "debian",
+			Version: "8",
+		},
+	}
+
+	for _, include := range []bool{false, true} {
+		m := &VulnerabilityMatcher{
+			VulnerabilityProvider: vp,
+			Matchers:              matcher.NewDefaultMatchers(matcher.Config{}),
+			IncludeEvidence:       include,
+		}
+
+		actual, _, err := m.FindMatches([]pkg.Package{neutronPkg}, context)
+		require.NoError(t, err)
+		require.Equal(t, 1, actual.Count())
+
+		for _, mt := range actual.Sorted() {
+			if !include {
+				assert.Nil(t, mt.Evidence)
+				continue
+			}
+			require.NotNil(t, mt.Evidence)
+			assert.Equal(t, []file.Location{primary}, mt.Evidence.Locations)
+			assert.Equal(t, pkg.PURLLiteralMetadata{PURL: "pkg:deb/debian/neutron@2013.1.1-1"}, mt.Evidence.Metadata)
+		}
+	}
+}
+
+func TestVulnerabilityMatcher_ExpandAliases(t *testing.T) {
+	vp := mock.VulnerabilityProvider(testVulnerabilities()...)
+
+	activerecordPkg := pkg.Package{
+		ID:      pkg.ID(uuid.NewString()),
+		Name:    "activerecord",
+		Version: "3.7.5",
+		CPEs: []cpe.CPE{
+			cpe.Must("cpe:2.3:*:activerecord:activerecord:*:*:*:*:*:rails:*:*", ""),
+		},
+		Type:     syftPkg.GemPkg,
+		Language: syftPkg.Ruby,
+	}
+
+	newMatcher := func(expand bool) *VulnerabilityMatcher {
+		return &VulnerabilityMatcher{
+			VulnerabilityProvider: vp,
+			Matchers: matcher.NewDefaultMatchers(matcher.Config{
+				Ruby: ruby.MatcherConfig{UseCPEs: true},
+			}),
+			ExpandAliases: expand,
+		}
+	}
+
+	// without expansion the GHSA and its CVE alias are reported separately
+	actual, _, err := newMatcher(false).FindMatches([]pkg.Package{activerecordPkg}, pkg.Context{})
+	require.NoError(t, err)
+	var ids []string
+	for _, mt := range actual.Sorted() {
+		ids = append(ids, mt.Vulnerability.ID)
+	}
+	assert.ElementsMatch(t, []string{"CVE-2014-fake-3", "GHSA-2014-fake-3"}, ids)
+
+	// with expansion they collapse to a single match under the canonical CVE
+	actual, _, err = newMatcher(true).FindMatches([]pkg.Package{activerecordPkg}, pkg.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 1, actual.Count())
+
+	mt := actual.Sorted()[0]
+	assert.Equal(t, "CVE-2014-fake-3", mt.Vulnerability.ID)
+	assert.Equal(t, "nvd:cpe", mt.Vulnerability.Namespace)
+	assert.Contains(t, mt.Vulnerability.RelatedVulnerabilities, vulnerability.Reference{
+		ID:        "GHSA-2014-fake-3",
+		Namespace: "github:language:ruby",
+	})
+
+	var found []string
+	for _, d := range mt.Details {
+		switch r := d.Found.(type) {
+		case match.EcosystemResult:
+			found = append(found, r.VulnerabilityID)
+		case match.CPEResult:
+			found = append(found, r.VulnerabilityID)
+		}
+	}
+	assert.ElementsMatch(t, []string{"CVE-2014-fake-3", "GHSA-2014-fake-3"}, found)
+}
+
+func TestVulnerabilityMatcher_DedupePackages(t *testing.T) {
+	vp := mock.VulnerabilityProvider(testVulnerabilities()...)
+
+	newPkg := func(location string) pkg.Package {
+		return pkg.Package{
+			ID:        pkg.ID(uuid.NewString()),
+			Name:      "activerecord",
+			Version:   "3.7.5",
+			Type:      syftPkg.GemPkg,
+			Language:  syftPkg.Ruby,
+			Locations: file.NewLocationSet(file.NewLocation(location)),
+		}
+	}
+
+	// the same logical package listed twice at different locations
+	packages := []pkg.Package{
+		newPkg("/app/vendor/Gemfile.lock"),
+		newPkg("/other/Gemfile.lock"),
+	}
+
+	newMatcher := func(dedupe bool) *VulnerabilityMatcher {
+		return &VulnerabilityMatcher{
+			VulnerabilityProvider: vp,
+			Matchers:              matcher.NewDefaultMatchers(matcher.Config{}),
+			DedupePackages:        dedupe,
+		}
+	}
+
+	actual, _, err := newMatcher(false).FindMatches(packages, pkg.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 2, actual.Count())
+
+	actual, _, err = newMatcher(true).FindMatches(packages, pkg.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 1, actual.Count())
+
+	mt := actual.Sorted()[0]
+	assert.Equal(t, "GHSA-2014-fake-3", mt.Vulnerability.ID)
+
+	var locations []string
+	for _, l := range mt.Package.Locations.ToSlice() {
+		locations = append(locations, l.RealPath)
+	}
+	assert.ElementsMatch(t, []string{"/app/vendor/Gemfile.lock", "/other/Gemfile.lock"}, locations)
+
+	// the details found for each instance are identical, so are not repeated
+	assert.Len(t, mt.Details, 1)
+}
+
+func TestVulnerabilityMatcher_FailFast(t *testing.T) {
+	vp := mock.VulnerabilityProvider(testVulnerabilities()...)
+
+	neutronPkg := pkg.Package{
+		ID:      pkg.ID(uuid.NewString()),
+		Name:    "neutron",
+		Version: "2013.1.1-1",
+		Type:    syftPkg.DebPkg,
+	}
+
+	activerecordPkg := pkg.Package{
+		ID:      pkg.ID(uuid.NewString()),
+		Name:    "activerecord",
+		Version: "3.7.5",
+		CPEs: []cpe.CPE{
+			cpe.Must("cpe:2.3:*:activerecord:activerecord:*:*:*:*:*:rails:*:*", ""),
+		},
+		Type:     syftPkg.GemPkg,
+		Language: syftPkg.Ruby,
+	}
+
+	// never searched, since the search stops at the prior package
+	laterPkg := neutronPkg
+	laterPkg.ID = pkg.ID(uuid.NewString())
+	laterPkg.Version = "2012.1.1-1"
+
+	context := pkg.Context{
+		Distro: &distro.Distro{
+			Type:    "debian",
+			Version: "8",
+		},
+	}
+
+	newMatcher := func(failFast bool, severity vulnerability.Severity) *VulnerabilityMatcher {
+		return &VulnerabilityMatcher{
+			VulnerabilityProvider: vp,
+			Matchers: matcher.NewDefaultMatchers(matcher.Config{
+				Ruby: ruby.MatcherConfig{UseCPEs: true},
+			}),
+			FailSeverity: &severity,
+			FailFast:     failFast,
+		}
+	}
+
+	packages := []pkg.Package{neutronPkg, activerecordPkg, laterPkg}
+
+	ids := func(matches *match.Matches) []string {
+		var out []string
+		for _, mt := range matches.Sorted() {
+			out = append(out, mt.Vulnerability.ID)
+		}
+		return out
+	}
+
+	// without failing fast, all packages are searched
+	actual, _, coverage, err := newMatcher(false, vulnerability.HighSeverity).FindMatchesWithCoverage(packages, context)
+	require.ErrorIs(t, err, grypeerr.ErrAboveSeverityThreshold)
+	assert.Equal(t, 3, coverage.Total)
+	assert.Greater(t, actual.Count(), 2)
+
+	// the first package only has a medium severity match, so the search stops at the second package
+	actual, _, coverage, err = newMatcher(true, vulnerability.HighSeverity).FindMatchesWithCoverage(packages, context)
+	require.ErrorIs(t, err, grypeerr.ErrAboveSeverityThreshold)
+	assert.Equal(t, 2, coverage.Total)
+	assert.Equal(t, []string{"CVE-2014-fake-3"}, ids(actual))
+
+	actual, _, coverage, err = newMatcher(true, vulnerability.MediumSeverity).FindMatchesWithCoverage(packages, context)
+	require.ErrorIs(t, err, grypeerr.ErrAboveSeverityThreshold)
+	assert.Equal(t, 1, coverage.Total)
+	assert.Equal(t, []string{"CVE-2014-fake-1"}, ids(actual))
+
+	// nothing at or above the severity results in a full search
+	actual, _, coverage, err = newMatcher(true, vulnerability.CriticalSeverity).FindMatchesWithCoverage([]pkg.Package{neutronPkg, laterPkg}, context)
+	require.NoError(t, err)
+	assert.Equal(t, 2, coverage.Total)
+	assert.NotEmpty(t, ids(actual))
+}
+
+func TestVulnerabilityMatcher_MatchFilter(t *testing.T) {
+	newVuln := func(id string, scores ...float64) vulnerability.Vulnerability {
+		metadata := vulnerability.Metadata{Severity: "medium"}
+		for _, score := range scores {
+			metadata.Cvss = append(metadata.Cvss, vulnerability.Cvss{
+				Version: "3.1",
+				Metrics: vulnerability.CvssMetrics{BaseScore: score},
+			})
+		}
+		return vulnerability.Vulnerability{
+			Reference: vulnerability.Reference{
+				ID:        id,
+				Namespace: "github:language:ruby",
+				Internal:  metadata,
+			},
+			PackageName: "activerecord",
+			Constraint:  version.MustGetConstraint("< 3.7.6", version.UnknownFormat),
+		}
+	}
+
+	vp := mock.VulnerabilityProvider(
+		newVuln("GHSA-critical", 9.8),
+		newVuln("GHSA-at-threshold", 7.0),
+		newVuln("GHSA-low", 4.3),
+		newVuln("GHSA-mixed", 5.0, 7.5), // the highest score is used
+		newVuln("GHSA-unscored"),
+		newVuln("GHSA-zero-score", 0), // zero base scores are invalid, so this is unscored
+	)
+
+	activerecordPkg := pkg.Package{
+		ID:       pkg.ID(uuid.NewString()),
+		Name:     "activerecord",
+		Version:  "3.7.5",
+		Type:     syftPkg.GemPkg,
+		Language: syftPkg.Ruby,
+	}
+
+	tests := []struct {
+		name     string
+		filter   *MatchFilter
+		expected []string
+	}{
+		{
+			name:     "no filter",
+			expected: []string{"GHSA-at-threshold", "GHSA-critical", "GHSA-low", "GHSA-mixed", "GHSA-unscored", "GHSA-zero-score"},
+		},
+		{
+			name:     "no threshold",
+			filter:   &MatchFilter{},
+			expected: []string{"GHSA-at-threshold", "GHSA-critical", "GHSA-low", "GHSA-mixed", "GHSA-unscored", "GHSA-zero-score"},
+		},
+		{
+			name:     "drop unscored",
+			filter:   &MatchFilter{MinimumCVSS: 7.0},
+			expected: []string{"GHSA-at-threshold", "GHSA-critical", "GHSA-mixed"},
+		},
+		{
+			name:     "keep unscored",
+			filter:   &MatchFilter{MinimumCVSS: 7.0, KeepUnscored: true},
+			expected: []string{"GHSA-at-threshold", "GHSA-critical", "GHSA-mixed", "GHSA-unscored", "GHSA-zero-score"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &VulnerabilityMatcher{
+				VulnerabilityProvider: vp,
+				Matchers:              matcher.NewDefaultMatchers(matcher.Config{}),
+				MatchFilter:           tt.filter,
+			}
+
+			actual, ignored, err := m.FindMatches([]pkg.Package{activerecordPkg}, pkg.Context{})
+			require.NoError(t, err)
+			assert.Empty(t, ignored)
+
+			var ids []string
+			for _, mt := range actual.Sorted() {
+				ids = append(ids, mt.Vulnerability.ID)
+			}
+			assert.ElementsMatch(t, tt.expected, ids)
+		})
+	}
+}
+
+func TestVulnerabilityMatcher_Explain(t *testing.T) {
+	vulns := append(testVulnerabilities(), vulnerability.Vulnerability{
+		Reference: vulnerability.Reference{
+			ID:        "CVE-2014-fake-7",
+			Namespace: "debian:distro:debian:9",
+		},
+		PackageName: "neutron",
+		Constraint:  version.MustGetConstraint("< 2014.1.3-6", version.DebFormat),
+	})
+	vp := mock.VulnerabilityProvider(vulns...)
+
+	neutronPkg := pkg.Package{
+		ID:      pkg.ID(uuid.NewString()),
+		Name:    "neutron",
+		Version: "2013.1.1-1",
+		Type:    syftPkg.DebPkg,
+	}
+
+	type candidate struct {
+		id        string
+		reported  bool
+		rejection RejectionReason
+	}
+
+	tests := []struct {
+		name        string
+		ignoreRules []match.IgnoreRule
+		expected    []candidate
+	}{
+		{
+			name: "candidates rejected by the search criteria",
+			expected: []candidate{
+				{id: "CVE-2014-fake-1", reported: true},
+				{id: "CVE-2013-fake-2", rejection: RejectedByVersionConstraint},
+				{id: "CVE-2014-fake-7", rejection: RejectedByNamespace},
+			},
+		},
+		{
+			name: "candidates rejected by ignore rules",
+			ignoreRules: []match.IgnoreRule{
+				{Vulnerability: "CVE-2014-fake-1"},
+			},
+			expected: []candidate{
+				{id: "CVE-2014-fake-1", rejection: RejectedByIgnoreRule},
+				{id: "CVE-2013-fake-2", rejection: RejectedByVersionConstraint},
+				{id: "CVE-2014-fake-7", rejection: RejectedByNamespace},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var explanations []Explanation
+			m := &VulnerabilityMatcher{
+				VulnerabilityProvider: vp,
+				Matchers:              matcher.NewDefaultMatchers(matcher.Config{}),
+				IgnoreRules:           tt.ignoreRules,
+				Explain: func(e Explanation) {
+					explanations = append(explanations, e)
+				},
+			}
+
+			_, _, err := m.FindMatches([]pkg.Package{neutronPkg}, pkg.Context{
+				Distro: &distro.Distro{Type: "debian", Version: "8"},
+			})
+			require.NoError(t, err)
+			require.Len(t, explanations, 1)
+			assert.Equal(t, neutronPkg.ID, explanations[0].Package.ID)
+
+			var actual []candidate
+			for _, c := range explanations[0].Candidates {
+				actual = append(actual, candidate{id: c.Vulnerability.ID, reported: c.Reported, rejection: c.Rejection})
+				if c.Rejection != "" {
+					assert.NotEmpty(t, c.Detail)
+				}
+			}
+			assert.ElementsMatch(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestVulnerabilityMatcher_FindMatchesWithCoverage_Namespaces(t *testing.T) {
+	vp := mock.VulnerabilityProvider(testVulnerabilities()...)
+
+	neutronPkg := pkg.Package{
+		ID:      pkg.ID(uuid.NewString()),
+		Name:    "neutron",
+		Version: "2013.1.1-1",
+		Type:    syftPkg.DebPkg,
+	}
+
+	activerecordPkg := pkg.Package{
+		ID:       pkg.ID(uuid.NewString()),
+		Name:     "activerecord",
+		Version:  "3.7.5",
+		Type:     syftPkg.GemPkg,
+		Language: syftPkg.Ruby,
+		CPEs: []cpe.CPE{
+			cpe.Must("cpe:2.3:*:activerecord:activerecord:3.7.5:*:*:*:*:rails:*:*", ""),
+		},
+	}
+
+	unmatchedPkg := pkg.Package{
+		ID:       pkg.ID(uuid.NewString()),
+		Name:     "requests",
+		Version:  "2.0.0",
+		Type:     syftPkg.PythonPkg,
+		Language: syftPkg.Python,
+	}
+
+	m := &VulnerabilityMatcher{
+		VulnerabilityProvider: vp,
+		Matchers: matcher.NewDefaultMatchers(matcher.Config{
+			Ruby: ruby.MatcherConfig{UseCPEs: true},
+		}),
+	}
+
+	_, _, coverage, err := m.FindMatchesWithCoverage(
+		[]pkg.Package{neutronPkg, activerecordPkg, unmatchedPkg},
+		pkg.Context{Distro: &distro.Distro{Type: "debian", Version: "8"}},
+	)
+	require.NoError(t, err)
+
+	namespaces := make(map[string][]SearchedNamespace)
+	for _, n := range coverage.Namespaces {
+		namespaces[n.Package.Name] = n.Namespaces
+	}
+
+	assert.Equal(t, map[string][]SearchedNamespace{
+		"neutron": {
+			{
+				Kind:      NamespaceKindDistro,
+				Namespace: "distro:debian:8",
+				Reason:    "the distro of the scan target is debian 8",
+				Found:     []string{"debian:distro:debian:8"},
+			},
+		},
+		"activerecord": {
+			{
+				Kind:      NamespaceKindLanguage,
+				Namespace: "language:ruby",
+				Reason:    `the language of the package is "ruby"`,
+				Found:     []string{"github:language:ruby"},
+			},
+			{
+				Kind:      NamespaceKindCPE,
+				Namespace: "cpe",
+				Reason:    "the package has CPEs",
+				Found:     []string{"nvd:cpe"},
+			},
+		},
+		// the namespace is reported even though there are no matches for the package
+		"requests": {
+			{
+				Kind:      NamespaceKindLanguage,
+				Namespace: "language:python",
+				Reason:    `the language of the package is "python"`,
+			},
+		},
+	}, namespaces)
+}
@@ -64,6 +64,89 @@ func ParseMetricsFromVector(vector string) (*vulnerability.CvssMetrics, error) {
 	}
 }
 
+// CanonicalVector returns the given vector with metrics in the order defined by the CVSS specification (per the
+// version indicated by the vector prefix), so that semantically-identical vectors compare as equal.
+func CanonicalVector(vector string) (string, error) {
+	switch {
+	case strings.HasPrefix(vector, "CVSS:3.0"):
+		cvss, err := gocvss30.ParseVector(vector)
+		if err != nil {
+			return "", fmt.Errorf("unable to parse CVSS v3 vector: %w", err)
+		}
+		return cvss.Vector(), nil
+	case strings.HasPrefix(vector, "CVSS:3.1"):
+		cvss, err := gocvss31.ParseVector(vector)
+		if err != nil {
+			return "", fmt.Errorf("unable to parse CVSS v3.1 vector: %w", err)
+		}
+		return cvss.Vector(), nil
+	case strings.HasPrefix(vector, "CVSS:4.0"):
+		cvss, err := gocvss40.ParseVector(vector)
+		if err != nil {
+			return "", fmt.Errorf("unable to parse CVSS v4.0 vector: %w", err)
+		}
+		return cvss.Vector(), nil
+	default:
+		// should be CVSS v2.0 or is invalid
+		cvss, err := gocvss20.ParseVector(vector)
+		if err != nil {
+			return "", fmt.Errorf("unable to parse CVSS v2 vector: %w", err)
+		}
+		return cvss.Vector(), nil
+	}
+}
+
+// ParseAdjustedScores computes the temporal and environmental scores for the given base vector combined with the given
+// temporal and environmental metric vectors (e.g. "E:P/RL:O/RC:C"). A score is only returned for each non-empty
+// vector. Adjusted scores are not supported for CVSS v4.0 (which has no separate temporal or environmental score).
+func ParseAdjustedScores(vector, temporalVector, environmentalVector string) (temporal, environmental *float64, err error) {
+	if temporalVector == "" && environmentalVector == "" {
+		return nil, nil, nil
+	}
+
+	combined := vector
+	for _, v := range []string{temporalVector, environmentalVector} {
+		if v = strings.Trim(v, "/"); v != "" {
+			combined += "/" + v
+		}
+	}
+
+	var temporalScore, environmentalScore float64
+	switch {
+	case strings.HasPrefix(vector, "CVSS:3.0"):
+		cvss, err := gocvss30.ParseVector(combined)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to parse CVSS v3 vector: %w", err)
+		}
+		temporalScore, environmentalScore = cvss.TemporalScore(), cvss.EnvironmentalScore()
+	case strings.HasPrefix(vector, "CVSS:3.1"):
+		cvss, err := gocvss31.ParseVector(combined)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to parse CVSS v3.1 vector: %w", err)
+		}
+		temporalScore, environmentalScore = cvss.TemporalScore(), cvss.EnvironmentalScore()
+	case strings.HasPrefix(vector, "CVSS:4.0"):
+		return nil, nil, fmt.Errorf("temporal and environmental scores are not supported for CVSS v4.0")
+	default:
+		// should be CVSS v2.0 or is invalid
+		cvss, err := gocvss20.ParseVector(combined)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to parse CVSS v2 vector: %w", err)
+		}
+		temporalScore, environmentalScore = cvss.TemporalScore(), cvss.EnvironmentalScore()
+	}
+
+	if temporalVector != "" {
+		t := roundScore(temporalScore)
+		temporal = &t
+	}
+	if environmentalVector != "" {
+		e := roundScore(environmentalScore)
+		environmental = &e
+	}
+	return temporal, environmental, nil
+}
+
 func SeverityFromBaseScore(bs float64) vulnerability.Severity {
 	switch {
 	case bs >= 10.0:
@@ -193,3 +193,108 @@ func TestSeverityFromBaseScore(t *testing.T) {
 func ptr(f float64) *float64 {
 	return &f
 }
+
+func TestParseAdjustedScores(t *testing.T) {
+	tests := []struct {
+		name                string
+		vector              string
+		temporalVector      string
+		environmentalVector string
+		wantTemporal        *float64
+		wantEnvironmental   *float64
+		wantErr             require.ErrorAssertionFunc
+	}{
+		{
+			name:   "no adjustments",
+			vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		},
+		{
+			name:           "CVSS 3.1 temporal",
+			vector:         "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+			temporalVector: "E:U/RL:O/RC:U",
+			wantTemporal:   ptr(7.8),
+		},
+		{
+			name:                "CVSS 3.1 temporal and environmental",
+			vector:              "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+			temporalVector:      "E:U/RL:O/RC:U",
+			environmentalVector: "/MAV:L/MC:N/",
+			wantTemporal:        ptr(7.8),
+			wantEnvironmental:   ptr(6.2),
+		},
+		{
+			name:           "CVSS 2.0 temporal",
+			vector:         "AV:N/AC:L/Au:N/C:P/I:P/A:P",
+			temporalVector: "E:U/RL:OF/RC:UC",
+			wantTemporal:   ptr(5.0),
+		},
+		{
+			name:           "CVSS 4.0 is unsupported",
+			vector:         "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N",
+			temporalVector: "E:U",
+			wantErr:        require.Error,
+		},
+		{
+			name:           "invalid temporal vector",
+			vector:         "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+			temporalVector: "E:bogus",
+			wantErr:        require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr == nil {
+				tt.wantErr = require.NoError
+			}
+			temporal, environmental, err := ParseAdjustedScores(tt.vector, tt.temporalVector, tt.environmentalVector)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantTemporal, temporal)
+			assert.Equal(t, tt.wantEnvironmental, environmental)
+		})
+	}
+}
+
+func TestCanonicalVector(t *testing.T) {
+	tests := []struct {
+		name    string
+		vector  string
+		want    string
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:   "CVSS 3.1 already canonical",
+			vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+			want:   "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		},
+		{
+			name:   "CVSS 3.1 out of order",
+			vector: "CVSS:3.1/AC:L/AV:N/PR:N/UI:N/S:U/A:H/I:H/C:H",
+			want:   "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		},
+		{
+			name:   "CVSS 3.0 out of order",
+			vector: "CVSS:3.0/PR:N/AV:N/AC:L/UI:N/S:U/C:H/I:H/A:H",
+			want:   "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		},
+		{
+			name:   "CVSS 2.0",
+			vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P",
+			want:   "AV:N/AC:L/Au:N/C:P/I:P/A:P",
+		},
+		{
+			name:    "invalid vector",
+			vector:  "CVSS:3.1/AV:X",
+			wantErr: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr == nil {
+				tt.wantErr = require.NoError
+			}
+			got, err := CanonicalVector(tt.vector)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package parquet
+
+import "encoding/binary"
+
+// thrift compact protocol type identifiers
+const (
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// compactWriter encodes thrift structures with the compact protocol, which is how parquet encodes page headers and
+// file metadata.
+type compactWriter struct {
+	buf          []byte
+	lastField    int16
+	parentFields []int16
+}
+
+func (c *compactWriter) structBegin() {
+	c.parentFields = append(c.parentFields, c.lastField)
+	c.lastField = 0
+}
+
+func (c *compactWriter) structEnd() {
+	c.buf = append(c.buf, 0) // stop field
+	c.lastField = c.parentFields[len(c.parentFields)-1]
+	c.parentFields = c.parentFields[:len(c.parentFields)-1]
+}
+
+func (c *compactWriter) fieldBegin(id int16, fieldType byte) {
+	if delta := id - c.lastField; delta > 0 && delta <= 15 {
+		c.buf = append(c.buf, byte(delta)<<4|fieldType)
+	} else {
+		c.buf = append(c.buf, fieldType)
+		c.buf = binary.AppendVarint(c.buf, int64(id))
+	}
+	c.lastField = id
+}
+
+func (c *compactWriter) structFieldBegin(id int16) {
+	c.fieldBegin(id, compactStruct)
+	c.structBegin()
+}
+
+func (c *compactWriter) listFieldBegin(id int16, elementType byte, size int) {
+	c.fieldBegin(id, compactList)
+	if size < 15 {
+		c.buf = append(c.buf, byte(size)<<4|elementType)
+		return
+	}
+	c.buf = append(c.buf, 0xf0|elementType)
+	c.buf = binary.AppendUvarint(c.buf, uint64(size))
+}
+
+func (c *compactWriter) i32Field(id int16, v int32) {
+	c.fieldBegin(id, compactI32)
+	c.i32(v)
+}
+
+func (c *compactWriter) i64Field(id int16, v int64) {
+	c.fieldBegin(id, compactI64)
+	c.buf = binary.AppendVarint(c.buf, v)
+}
+
+func (c *compactWriter) binaryField(id int16, v string) {
+	c.fieldBegin(id, compactBinary)
+	c.binary(v)
+}
+
+func (c *compactWriter) i32(v int32) {
+	// binary.AppendVarint applies zigzag encoding, as is expected by the compact protocol
+	c.buf = binary.AppendVarint(c.buf, int64(v))
+}
+
+func (c *compactWriter) binary(v string) {
+	c.buf = binary.AppendUvarint(c.buf, uint64(len(v)))
+	c.buf = append(c.buf, v...)
+}
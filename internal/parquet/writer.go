@@ -0,0 +1,245 @@
+// Package parquet provides a minimal streaming writer for Apache Parquet files. Only what is needed for exporting
+// tabular string data is supported: every column is an optional (nullable) UTF-8 string, values are PLAIN encoded,
+// and pages are not compressed. Each row group is written as soon as it is given, bounding memory to a single row
+// group.
+package parquet
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const magic = "PAR1"
+
+// parquet format enum values (see parquet.thrift)
+const (
+	typeByteArray      = 6
+	repetitionOptional = 1
+	convertedTypeUTF8  = 0
+	encodingPlain      = 0
+	encodingRLE        = 3
+	codecUncompressed  = 0
+	pageTypeData       = 0
+	fileFormatVersion  = 1
+)
+
+// Writer writes rows of nullable string columns to an underlying writer in the parquet format. Close must be called
+// to write the file footer.
+type Writer struct {
+	w         *countingWriter
+	columns   []string
+	rowGroups []rowGroup
+	numRows   int64
+	createdBy string
+	closed    bool
+}
+
+type rowGroup struct {
+	columns       []columnChunk
+	numRows       int64
+	totalByteSize int64
+}
+
+type columnChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+// NewWriter creates a parquet writer for the given column names, writing the file header immediately.
+func NewWriter(w io.Writer, createdBy string, columns ...string) (*Writer, error) {
+	if len(columns) == 0 {
+		return nil, errors.New("at least one column is required")
+	}
+
+	cw := &countingWriter{w: w}
+	if _, err := cw.Write([]byte(magic)); err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		w:         cw,
+		columns:   columns,
+		createdBy: createdBy,
+	}, nil
+}
+
+// WriteRowGroup writes the given rows as a single row group. Each row must have a value for every column (in column
+// order), where invalid values are written as nulls.
+func (pw *Writer) WriteRowGroup(rows [][]sql.NullString) error {
+	if pw.closed {
+		return errors.New("writer is closed")
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	for i, row := range rows {
+		if len(row) != len(pw.columns) {
+			return fmt.Errorf("row %d has %d values, expected %d", i, len(row), len(pw.columns))
+		}
+	}
+
+	group := rowGroup{numRows: int64(len(rows))}
+	for col := range pw.columns {
+		offset := pw.w.n
+		if err := pw.writeColumnPage(rows, col); err != nil {
+			return fmt.Errorf("unable to write column %q: %w", pw.columns[col], err)
+		}
+		chunk := columnChunk{
+			offset:    offset,
+			size:      pw.w.n - offset,
+			numValues: int64(len(rows)),
+		}
+		group.totalByteSize += chunk.size
+		group.columns = append(group.columns, chunk)
+	}
+
+	pw.rowGroups = append(pw.rowGroups, group)
+	pw.numRows += group.numRows
+	return nil
+}
+
+// writeColumnPage writes all values of a single column within the given rows as one (v1) data page.
+func (pw *Writer) writeColumnPage(rows [][]sql.NullString, col int) error {
+	levels := make([]byte, 0, len(rows))
+	var values []byte
+	for _, row := range rows {
+		v := row[col]
+		if !v.Valid {
+			levels = append(levels, 0)
+			continue
+		}
+		levels = append(levels, 1)
+		values = binary.LittleEndian.AppendUint32(values, uint32(len(v.String)))
+		values = append(values, v.String...)
+	}
+
+	encodedLevels := encodeRLE(levels)
+	page := binary.LittleEndian.AppendUint32(nil, uint32(len(encodedLevels)))
+	page = append(page, encodedLevels...)
+	page = append(page, values...)
+
+	header := &compactWriter{}
+	header.structBegin()
+	header.i32Field(1, pageTypeData)
+	header.i32Field(2, int32(len(page)))
+	header.i32Field(3, int32(len(page)))
+	header.structFieldBegin(5)
+	header.i32Field(1, int32(len(rows)))
+	header.i32Field(2, encodingPlain)
+	header.i32Field(3, encodingRLE)
+	header.i32Field(4, encodingRLE)
+	header.structEnd()
+	header.structEnd()
+
+	if _, err := pw.w.Write(header.buf); err != nil {
+		return err
+	}
+	_, err := pw.w.Write(page)
+	return err
+}
+
+// Close writes the file footer (schema and row group metadata). The underlying writer is not closed.
+func (pw *Writer) Close() error {
+	if pw.closed {
+		return nil
+	}
+	pw.closed = true
+
+	footer := pw.fileMetadata()
+	if _, err := pw.w.Write(footer); err != nil {
+		return err
+	}
+	if _, err := pw.w.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))); err != nil {
+		return err
+	}
+	_, err := pw.w.Write([]byte(magic))
+	return err
+}
+
+func (pw *Writer) fileMetadata() []byte {
+	c := &compactWriter{}
+	c.structBegin()
+	c.i32Field(1, fileFormatVersion)
+
+	// the schema is flattened depth-first, starting with the root element
+	c.listFieldBegin(2, compactStruct, len(pw.columns)+1)
+	c.structBegin()
+	c.binaryField(4, "schema")
+	c.i32Field(5, int32(len(pw.columns)))
+	c.structEnd()
+	for _, name := range pw.columns {
+		c.structBegin()
+		c.i32Field(1, typeByteArray)
+		c.i32Field(3, repetitionOptional)
+		c.binaryField(4, name)
+		c.i32Field(6, convertedTypeUTF8)
+		c.structEnd()
+	}
+
+	c.i64Field(3, pw.numRows)
+
+	c.listFieldBegin(4, compactStruct, len(pw.rowGroups))
+	for _, group := range pw.rowGroups {
+		c.structBegin()
+		c.listFieldBegin(1, compactStruct, len(group.columns))
+		for i, chunk := range group.columns {
+			c.structBegin()
+			c.i64Field(2, chunk.offset)
+			c.structFieldBegin(3)
+			c.i32Field(1, typeByteArray)
+			c.listFieldBegin(2, compactI32, 2)
+			c.i32(encodingPlain)
+			c.i32(encodingRLE)
+			c.listFieldBegin(3, compactBinary, 1)
+			c.binary(pw.columns[i])
+			c.i32Field(4, codecUncompressed)
+			c.i64Field(5, chunk.numValues)
+			c.i64Field(6, chunk.size)
+			c.i64Field(7, chunk.size)
+			c.i64Field(9, chunk.offset)
+			c.structEnd()
+			c.structEnd()
+		}
+		c.i64Field(2, group.totalByteSize)
+		c.i64Field(3, group.numRows)
+		c.structEnd()
+	}
+
+	if pw.createdBy != "" {
+		c.binaryField(6, pw.createdBy)
+	}
+	c.structEnd()
+	return c.buf
+}
+
+// encodeRLE encodes the given definition levels (with a max level of 1, so a bit width of 1) using the RLE/bit-packing
+// hybrid encoding, emitting RLE runs only.
+func encodeRLE(levels []byte) []byte {
+	var out []byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		out = binary.AppendUvarint(out, uint64(j-i)<<1)
+		// the run value is stored in ceil(bit width / 8) = 1 byte
+		out = append(out, levels[i])
+		i = j
+	}
+	return out
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,97 @@
+package parquet
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeRLE(t *testing.T) {
+	tests := []struct {
+		name     string
+		levels   []byte
+		expected []byte
+	}{
+		{
+			name:     "empty",
+			levels:   nil,
+			expected: nil,
+		},
+		{
+			name:     "single run",
+			levels:   []byte{1, 1, 1},
+			expected: []byte{3 << 1, 1},
+		},
+		{
+			name:     "alternating runs",
+			levels:   []byte{1, 0, 0, 1},
+			expected: []byte{1 << 1, 1, 2 << 1, 0, 1 << 1, 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, encodeRLE(test.levels))
+		})
+	}
+}
+
+func TestCompactWriter(t *testing.T) {
+	c := &compactWriter{}
+	c.structBegin()
+	c.i32Field(1, 3)
+	c.i64Field(20, -1)
+	c.structFieldBegin(21)
+	c.binaryField(1, "a")
+	c.structEnd()
+	c.listFieldBegin(22, compactI32, 2)
+	c.i32(1)
+	c.i32(2)
+	c.structEnd()
+
+	assert.Equal(t, []byte{
+		0x15, 0x06, // field 1 (delta 1), i32 3 (zigzag 6)
+		0x06, 0x28, 0x01, // field 20 (long form, zigzag 40), i64 -1 (zigzag 1)
+		0x1c,            // field 21 (delta 1), struct
+		0x18, 0x01, 'a', // field 1 (delta 1), binary "a"
+		0x00,                   // end of nested struct
+		0x19, 0x25, 0x02, 0x04, // field 22 (delta 1), list of two i32 values
+		0x00, // end of struct
+	}, c.buf)
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "test", "id", "severity")
+	require.NoError(t, err)
+
+	require.NoError(t, w.WriteRowGroup([][]sql.NullString{
+		{{String: "CVE-1", Valid: true}, {String: "High", Valid: true}},
+		{{String: "CVE-2", Valid: true}, {}},
+	}))
+	require.Error(t, w.WriteRowGroup([][]sql.NullString{{{String: "CVE-3", Valid: true}}}))
+	require.NoError(t, w.Close())
+
+	data := buf.Bytes()
+	assert.Equal(t, magic, string(data[:4]))
+	assert.Equal(t, magic, string(data[len(data)-4:]))
+
+	footerLength := int(binary.LittleEndian.Uint32(data[len(data)-8 : len(data)-4]))
+	require.Less(t, footerLength, len(data)-12)
+	footer := data[len(data)-8-footerLength : len(data)-8]
+	assert.Contains(t, string(footer), "severity")
+	assert.Contains(t, string(footer), "test")
+
+	plain := func(s string) []byte {
+		return append(binary.LittleEndian.AppendUint32(nil, uint32(len(s))), s...)
+	}
+	assert.True(t, bytes.Contains(data, append(plain("CVE-1"), plain("CVE-2")...)))
+	// the null severity has no value, only a definition level
+	assert.True(t, bytes.Contains(data, append([]byte{4, 0, 0, 0, 1 << 1, 1, 1 << 1, 0}, plain("High")...)))
+
+	_, err = NewWriter(&buf, "test")
+	assert.Error(t, err)
+}
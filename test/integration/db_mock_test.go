@@ -196,6 +196,14 @@ func newMockDbProvider() vulnerability.Provider {
 			PackageName: "apt-dev",
 			Constraint:  version.MustGetConstraint("<= 1.8.2", version.DebFormat), // was: "dpkg"
 		},
+		{
+			Reference: vulnerability.Reference{
+				ID:        "CVE-dpkg-libssl-debian-11",
+				Namespace: "debian:distro:debian:11",
+			},
+			PackageName: "libssl1.1",
+			Constraint:  version.MustGetConstraint("< 1.1.1n-0+deb11u4", version.DebFormat),
+		},
 		{
 			Reference: vulnerability.Reference{
 				ID:        "CVE-rpmdb-dive",
@@ -835,7 +835,14 @@ func TestMatchByImage(t *testing.T) {
 				)
 			}
 
-			result.Details = result.Details[:len(result.Details)-1]
+			// note: details are sorted, so the VEX detail is not necessarily the last detail
+			var details match.Details
+			for _, d := range result.Details {
+				if d.Matcher != match.OpenVexMatcher {
+					details = append(details, d)
+				}
+			}
+			result.Details = details
 			actualResults := match.NewMatches()
 			actualResults.Add(result)
 
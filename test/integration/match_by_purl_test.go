@@ -0,0 +1,55 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anchore/grype/grype"
+	"github.com/anchore/syft/syft/source"
+)
+
+func TestMatchByPURL(t *testing.T) {
+	tests := []struct {
+		name               string
+		input              string
+		expectedIDs        []string
+		expectedNamespaces []string
+	}{
+		{
+			// the same package as the unknown package SBOM fixture
+			name:               "language package",
+			input:              "purl:pkg:idris/my-package@1.0.5",
+			expectedIDs:        []string{"CVE-bogus-my-package-2-idris"},
+			expectedNamespaces: []string{"github:language:idris"},
+		},
+		{
+			name:  "language package not vulnerable",
+			input: "purl:pkg:idris/my-package@2.0.0",
+		},
+		{
+			name:               "distro qualified package",
+			input:              "purl:pkg:deb/debian/libssl1.1@1.1.1n-0+deb11u3?distro=debian-11",
+			expectedIDs:        []string{"CVE-dpkg-libssl-debian-11"},
+			expectedNamespaces: []string{"debian:distro:debian:11"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vp := newMockDbProvider()
+			matches, _, packages, err := grype.FindVulnerabilities(vp, tt.input, source.SquashedScope, nil)
+			require.NoError(t, err)
+			require.Len(t, packages, 1)
+
+			var ids, namespaces []string
+			for _, m := range matches.Sorted() {
+				ids = append(ids, m.Vulnerability.ID)
+				namespaces = append(namespaces, m.Vulnerability.Namespace)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedNamespaces, namespaces)
+		})
+	}
+}
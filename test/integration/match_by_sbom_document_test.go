@@ -1,7 +1,10 @@
 package integration
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -11,7 +14,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/anchore/grype/grype"
+	"github.com/anchore/grype/grype/distro"
 	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/matcher"
 	"github.com/anchore/grype/grype/pkg"
 	"github.com/anchore/syft/syft/source"
 )
@@ -44,6 +49,105 @@ func TestMatchBySBOMDocument(t *testing.T) {
 				},
 			},
 		},
+		{
+			// the same package as the unknown package fixture (which has no PURL, so is not matched by language), along
+			// with a package with multiple PURLs and a package only identified by an npm package manager reference
+			name:        "SPDX JSON with external references",
+			fixture:     "test-fixtures/sbom/spdx-sbom-with-external-refs.json",
+			expectedIDs: []string{"CVE-javascript-validator", "CVE-python-pygments"},
+			expectedDetails: []match.Detail{
+				{
+					Type: match.ExactDirectMatch,
+					SearchedBy: match.EcosystemParameters{
+						Language:  "javascript",
+						Namespace: "github:language:javascript",
+						Package:   match.PackageParameter{Name: "npm", Version: "6.14.0"},
+					},
+					Found: match.EcosystemResult{
+						VersionConstraint: "> 5, < 7.2.1 (unknown)",
+						VulnerabilityID:   "CVE-javascript-validator",
+					},
+					Matcher:    match.JavascriptMatcher,
+					Confidence: 1,
+				},
+				{
+					Type: match.ExactDirectMatch,
+					SearchedBy: match.EcosystemParameters{
+						Language:  "python",
+						Namespace: "github:language:python",
+						Package:   match.PackageParameter{Name: "pygments", Version: "2.6.1"},
+					},
+					Found: match.EcosystemResult{
+						VersionConstraint: "< 2.6.2 (python)",
+						VulnerabilityID:   "CVE-python-pygments",
+					},
+					Matcher:    match.PythonMatcher,
+					Confidence: 1,
+				},
+			},
+		},
+		{
+			// the same package as the unknown package fixture (which has no PURL, so is not matched by language), along
+			// with a package with multiple PURLs and a package only identified by an npm package manager reference
+			name:        "SPDX tag-value with external references",
+			fixture:     "test-fixtures/sbom/spdx-sbom-with-external-refs.spdx",
+			expectedIDs: []string{"CVE-javascript-validator", "CVE-python-pygments"},
+			expectedDetails: []match.Detail{
+				{
+					Type: match.ExactDirectMatch,
+					SearchedBy: match.EcosystemParameters{
+						Language:  "javascript",
+						Namespace: "github:language:javascript",
+						Package:   match.PackageParameter{Name: "npm", Version: "6.14.0"},
+					},
+					Found: match.EcosystemResult{
+						VersionConstraint: "> 5, < 7.2.1 (unknown)",
+						VulnerabilityID:   "CVE-javascript-validator",
+					},
+					Matcher:    match.JavascriptMatcher,
+					Confidence: 1,
+				},
+				{
+					Type: match.ExactDirectMatch,
+					SearchedBy: match.EcosystemParameters{
+						Language:  "python",
+						Namespace: "github:language:python",
+						Package:   match.PackageParameter{Name: "pygments", Version: "2.6.1"},
+					},
+					Found: match.EcosystemResult{
+						VersionConstraint: "< 2.6.2 (python)",
+						VulnerabilityID:   "CVE-python-pygments",
+					},
+					Matcher:    match.PythonMatcher,
+					Confidence: 1,
+				},
+			},
+		},
+		{
+			// the operating system is only described within the metadata component (the container image)
+			name:        "CycloneDX with the distro in the metadata component",
+			fixture:     "test-fixtures/sbom/cyclonedx-sbom-with-metadata-distro.json",
+			expectedIDs: []string{"CVE-dpkg-apt"},
+			expectedDetails: []match.Detail{
+				{
+					Type: match.ExactDirectMatch,
+					SearchedBy: match.DistroParameters{
+						Distro: match.DistroIdentification{
+							Type:    "debian",
+							Version: "8",
+						},
+						Namespace: "debian:distro:debian:8",
+						Package:   match.PackageParameter{Name: "apt-dev", Version: "1.8.2"},
+					},
+					Found: match.DistroResult{
+						VersionConstraint: "<= 1.8.2 (deb)",
+						VulnerabilityID:   "CVE-dpkg-apt",
+					},
+					Matcher:    match.DpkgMatcher,
+					Confidence: 1,
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -74,3 +178,186 @@ func TestMatchBySBOMDocument(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchBySBOMDocument_DeterministicDetails(t *testing.T) {
+	fixtures := []string{
+		"test-fixtures/sbom/syft-sbom-with-unknown-packages.json",
+		"test-fixtures/sbom/syft-sbom-with-kb-packages.json",
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture, func(t *testing.T) {
+			scan := func() []byte {
+				vp := newMockDbProvider()
+				matches, _, _, err := grype.FindVulnerabilities(vp, fmt.Sprintf("sbom:%s", fixture), source.SquashedScope, nil)
+				require.NoError(t, err)
+
+				var details [][]match.Detail
+				for _, m := range matches.Sorted() {
+					details = append(details, m.Details)
+				}
+				by, err := json.Marshal(details)
+				require.NoError(t, err)
+				return by
+			}
+
+			assert.Equal(t, string(scan()), string(scan()))
+		})
+	}
+}
+
+func TestMatchBySBOMDocument_FromReader(t *testing.T) {
+	f, err := os.Open("test-fixtures/sbom/syft-sbom-with-unknown-packages.json")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+
+	vp := newMockDbProvider()
+	// note: the reader is not seekable, so this is the same as an SBOM piped to stdin
+	matches, _, _, err := grype.FindVulnerabilitiesFromReader(vp, io.NopCloser(f))
+	require.NoError(t, err)
+
+	ids := strset.New()
+	for _, m := range matches.Sorted() {
+		ids.Add(m.Vulnerability.ID)
+	}
+	assert.ElementsMatch(t, []string{"CVE-bogus-my-package-2-idris"}, ids.List())
+}
+
+func TestMatchBySBOMDocument_Explain(t *testing.T) {
+	vp := newMockDbProvider()
+	matches, _, _, explanations, err := grype.FindVulnerabilitiesWithExplanations(vp, "sbom:test-fixtures/sbom/syft-sbom-with-unknown-packages.json", source.SquashedScope, nil)
+	require.NoError(t, err)
+
+	ids := strset.New()
+	for _, m := range matches.Sorted() {
+		ids.Add(m.Vulnerability.ID)
+	}
+	assert.ElementsMatch(t, []string{"CVE-bogus-my-package-2-idris"}, ids.List())
+
+	var explanation *grype.Explanation
+	for i := range explanations {
+		if explanations[i].Package.Name == "my-package" {
+			explanation = &explanations[i]
+		}
+	}
+	require.NotNil(t, explanation)
+
+	candidates := make(map[string]grype.Candidate)
+	for _, c := range explanation.Candidates {
+		candidates[c.Vulnerability.ID] = c
+	}
+
+	// the single match is traceable to the constraint evaluation
+	reported := candidates["CVE-bogus-my-package-2-idris"]
+	assert.True(t, reported.Reported)
+	assert.Equal(t, `version constraint "< 2.0 (unknown)" is satisfied`, reported.Detail)
+
+	// the CPE-based records for the same package name are not considered for a language package
+	for _, id := range []string{"CVE-bogus-my-package-1", "CVE-bogus-my-package-2-never-match"} {
+		assert.False(t, candidates[id].Reported, id)
+		assert.Equal(t, grype.RejectedByNamespace, candidates[id].Rejection, id)
+	}
+	assert.Len(t, explanation.Rejected(), 2)
+}
+
+func TestMatchBySBOMDocument_DistroOverride(t *testing.T) {
+	const fixture = "sbom:test-fixtures/sbom/cyclonedx-sbom-without-distro.json"
+
+	tests := []struct {
+		name        string
+		distro      *distro.Distro
+		expectedIDs []string
+	}{
+		{
+			// OS packages cannot be matched without a distro
+			name: "no override",
+		},
+		{
+			name:        "override",
+			distro:      distro.NewFromNameVersion("debian", "11"),
+			expectedIDs: []string{"CVE-dpkg-libssl-debian-11"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packages, context, _, err := pkg.Provide(fixture, pkg.ProviderConfig{
+				SyftProviderConfig: pkg.SyftProviderConfig{
+					Distro: tt.distro,
+				},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.distro, context.Distro)
+
+			matches, err := grype.FindVulnerabilitiesForPackages(newMockDbProvider(), packages, &context)
+			require.NoError(t, err)
+
+			var ids []string
+			for _, m := range matches.Sorted() {
+				ids = append(ids, m.Vulnerability.ID)
+				for _, d := range m.Details {
+					assert.Equal(t, match.DpkgMatcher, d.Matcher)
+				}
+			}
+			assert.ElementsMatch(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestMatchBySBOMDocument_Namespaces(t *testing.T) {
+	packages, context, _, err := pkg.Provide("sbom:test-fixtures/sbom/syft-sbom-with-unknown-packages.json", pkg.ProviderConfig{})
+	require.NoError(t, err)
+
+	runner := grype.VulnerabilityMatcher{
+		VulnerabilityProvider: newMockDbProvider(),
+		Matchers:              matcher.NewDefaultMatchers(matcher.Config{}),
+	}
+	_, _, coverage, err := runner.FindMatchesWithCoverage(packages, context)
+	require.NoError(t, err)
+
+	// the namespace of the match detail is traceable to the namespace selected by the language of the package
+	require.Len(t, coverage.Namespaces, 1)
+	assert.Equal(t, "my-package", coverage.Namespaces[0].Package.Name)
+	assert.Equal(t, []grype.SearchedNamespace{
+		{
+			Kind:      grype.NamespaceKindLanguage,
+			Namespace: "language:idris",
+			Reason:    `the language of the package is "idris"`,
+			Found:     []string{"github:language:idris"},
+		},
+	}, coverage.Namespaces[0].Namespaces)
+}
+
+func TestMatchBySBOMDocument_PackageResults(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedStatus grype.PackageStatus
+	}{
+		{
+			// the package of an unknown type is still evaluated by the namespace of its language
+			name:           "unknown package type",
+			input:          "sbom:test-fixtures/sbom/syft-sbom-with-unknown-packages.json",
+			expectedStatus: grype.PackageStatusMatched,
+		},
+		{
+			name:           "unknown package type not vulnerable",
+			input:          "purl:pkg:idris/my-package@2.0.0",
+			expectedStatus: grype.PackageStatusClean,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vp := newMockDbProvider()
+			_, _, packages, results, err := grype.FindVulnerabilitiesWithPackageResults(vp, tt.input, source.SquashedScope, nil)
+			require.NoError(t, err)
+			require.Len(t, packages, 1)
+
+			require.Len(t, results, 1)
+			assert.Equal(t, "my-package", results[0].Package.Name)
+			assert.Equal(t, tt.expectedStatus, results[0].Status)
+			assert.Empty(t, results[0].SkipReason)
+		})
+	}
+}